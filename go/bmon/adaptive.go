@@ -0,0 +1,44 @@
+package bmon
+
+import (
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Adaptive polling (-adaptive, or D while monitoring) sets the update
+// interval from recent volatility, the same max − min of the last prices
+// that colors the spinner: go-mode speed (5s) when the price is moving,
+// easing off to golong speed (20s) when it is flat. A quiet market then
+// costs a quarter of the API calls. -adaptive starts in golong, for its 24h
+// session.
+var adaptiveSteps = []struct {
+	below    float64 // volatility under this...
+	interval time.Duration
+}{
+	{10, 20 * time.Second},
+	{50, 15 * time.Second},
+	{100, 10 * time.Second},
+}
+
+const adaptiveFastest = 5 * time.Second
+
+// adaptiveInterval returns the update interval for the current volatility.
+func (m tuiModel) adaptiveInterval() time.Duration {
+	volatility := getSparklineRange(m.volatilityHistory())
+	for _, step := range adaptiveSteps {
+		if volatility < step.below {
+			return step.interval
+		}
+	}
+	return adaptiveFastest
+}
+
+// adaptiveLabel shows the current interval, e.g. " ~15s", while adaptive
+// polling is on.
+func (m tuiModel) adaptiveLabel() string {
+	if !m.adaptive || m.mode == modeLanding {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(" ~" + m.currentInterval().String())
+}
@@ -0,0 +1,139 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Price alarms: -above and -below (or the A key) set prices that sound an
+// alarm once when a fetched price reaches them. A fired alarm is cleared, so
+// it does not repeat; with -exit-on-alarm bmon quits instead, for scripts
+// that wait for a price.
+const alarmFlash = 3 * time.Second
+
+// checkAlarms fires any alarm price reached and returns the message to show,
+// or "" if none fired.
+func (m *tuiModel) checkAlarms(price float64) string {
+	return fireAlarms(&m.alarmAbove, &m.alarmBelow, price)
+}
+
+// fireAlarms clears each alarm price that price has reached, zero meaning
+// unset, and describes what fired, or returns "" if nothing did.
+func fireAlarms(above, below *float64, price float64) string {
+	var fired []string
+	if *above > 0 && price >= *above {
+		fired = append(fired, "above "+formatMoney(*above))
+		slog.Info("price alarm", "side", "above", "alarm", *above, "price", price)
+		*above = 0
+	}
+	if *below > 0 && price <= *below {
+		fired = append(fired, "below "+formatMoney(*below))
+		slog.Info("price alarm", "side", "below", "alarm", *below, "price", price)
+		*below = 0
+	}
+	if len(fired) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Alarm: BTC is %s (now %s)", strings.Join(fired, " and "), formatMoney(price))
+}
+
+// alarmToneCmd plays the alarm sound (by default three rising beeps,
+// unlike the single beeps of the -s price cues) off the UI goroutine, so the
+// screen keeps drawing meanwhile. It sounds whether or not -s is on.
+func alarmToneCmd() tea.Cmd {
+	return func() tea.Msg {
+		playSound(soundAlarm)
+		return nil
+	}
+}
+
+// applyAlarmInput sets alarms from the A prompt: ">100000" or "<90000" sets
+// one side, a bare price sets the side it lies on from the current price,
+// and "-" clears both.
+func (m *tuiModel) applyAlarmInput(input string) error {
+	input = strings.ReplaceAll(strings.TrimSpace(input), ",", "")
+	input = strings.TrimPrefix(input, "$")
+	if input == "" {
+		return nil
+	}
+	if input == "-" {
+		m.alarmAbove, m.alarmBelow = 0, 0
+		slog.Info("price alarms cleared")
+		return nil
+	}
+	side := ""
+	if strings.HasPrefix(input, ">") || strings.HasPrefix(input, "<") {
+		side, input = input[:1], strings.TrimSpace(strings.TrimLeft(input[1:], "$"))
+	}
+	price, err := strconv.ParseFloat(input, 64)
+	if err != nil || price <= 0 {
+		return fmt.Errorf("not a price: %s", input)
+	}
+	if side == "" {
+		side = "<"
+		if price > currentBtcPrice {
+			side = ">"
+		}
+	}
+	if side == ">" {
+		m.alarmAbove = price
+	} else {
+		m.alarmBelow = price
+	}
+	slog.Info("price alarm set", "side", side, "price", price)
+	return nil
+}
+
+// alarmLabel describes the armed alarms, e.g. " [>$100,000.00 <$90,000.00]",
+// or "" if there are none.
+func (m tuiModel) alarmLabel() string {
+	var parts []string
+	if m.alarmAbove > 0 {
+		parts = append(parts, ">"+formatMoney(m.alarmAbove))
+	}
+	if m.alarmBelow > 0 {
+		parts = append(parts, "<"+formatMoney(m.alarmBelow))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(" [" + strings.Join(parts, " ") + "]")
+}
+
+// alarmPrompt is the A prompt line while it is open.
+func (m tuiModel) alarmPrompt() string {
+	prompt := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("Alarm (>price, <price, - to clear): ") + m.alarmInput + "_"
+	if m.alarmError != "" {
+		prompt += lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render("  " + m.alarmError)
+	}
+	return prompt
+}
+
+// handleAlarmKey edits the A prompt. Enter applies it and Esc closes it.
+func (m tuiModel) handleAlarmKey(msg tea.KeyMsg) tuiModel {
+	switch key := msg.String(); key {
+	case "enter":
+		if err := m.applyAlarmInput(m.alarmInput); err != nil {
+			m.alarmError = err.Error()
+			return m
+		}
+		m.alarmEditing, m.alarmInput, m.alarmError = false, "", ""
+	case "esc":
+		m.alarmEditing, m.alarmInput, m.alarmError = false, "", ""
+	case "backspace":
+		if len(m.alarmInput) > 0 {
+			m.alarmInput = m.alarmInput[:len(m.alarmInput)-1]
+		}
+	default:
+		if len(key) == 1 && strings.ContainsAny(key, "0123456789.,<>$-") {
+			m.alarmInput += key
+		}
+	}
+	return m
+}
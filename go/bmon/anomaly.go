@@ -0,0 +1,72 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// A single update that moves the price by [Display] AnomalyPercent or more
+// (default 1%) is a sudden move: the price line flashes magenta three times
+// and the move is logged, separately from the green/red flash, which follows
+// the change from the baseline. AnomalyPercent = 0 turns it off.
+//
+//	[Display]
+//	AnomalyPercent = 1
+const (
+	defaultAnomalyPercent = 1.0
+	anomalyFrame          = 200 * time.Millisecond
+	anomalyFrames         = 6 // on and off, three times
+)
+
+var anomalyColor = lipgloss.Color("5")
+
+type anomalyFrameMsg struct{}
+
+func anomalyFrameCmd() tea.Cmd {
+	return tea.Tick(anomalyFrame, func(time.Time) tea.Msg { return anomalyFrameMsg{} })
+}
+
+// checkAnomaly starts the flash when price moved AnomalyPercent or more from
+// prev, the price one update earlier. It returns the command that drives the
+// flash, or nil.
+func (m *tuiModel) checkAnomaly(prev, price float64) tea.Cmd {
+	limit := settings.Display.AnomalyPercent
+	if limit <= 0 || prev <= 0 {
+		return nil
+	}
+	pct := (price - prev) / prev * 100
+	if math.Abs(pct) < limit {
+		return nil
+	}
+	slog.Warn("sudden price move", "change_pct", pct, "from", prev, "price", price, "limit_pct", limit)
+	m.anomalyAt = time.Now()
+	m.anomalyText = fmt.Sprintf("Sudden move: %+.2f%% in one update (%s to %s)", pct, formatMoney(prev), formatMoney(price))
+	return anomalyFrameCmd()
+}
+
+// anomalyFrameStep keeps redrawing until the flash is over.
+func (m tuiModel) anomalyFrameStep() tea.Cmd {
+	if time.Since(m.anomalyAt) >= anomalyFrames*anomalyFrame {
+		return nil
+	}
+	return anomalyFrameCmd()
+}
+
+// anomalyOn reports whether the flash is in an "on" frame.
+func (m tuiModel) anomalyOn() bool {
+	if m.anomalyAt.IsZero() {
+		return false
+	}
+	frame := int(time.Since(m.anomalyAt) / anomalyFrame)
+	return frame < anomalyFrames && frame%2 == 0
+}
+
+// anomalyStyle is the style for the price line in an "on" frame.
+func anomalyStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Background(anomalyColor).Foreground(lipgloss.Color("15"))
+}
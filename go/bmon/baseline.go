@@ -0,0 +1,144 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The baseline is the price that changes are measured from. It starts as
+// the price when monitoring began and R resets it to the current price. B
+// cycles through the other kinds: the price 24 hours ago and the 1-hour
+// average, both from a history fetch, and a trailing baseline that follows
+// the highest price since it was chosen, so the change shows the drawdown
+// from the peak.
+const (
+	baselineStart    = ""
+	baseline24h      = "24h"
+	baselineSMA      = "1h avg"
+	baselineTrailing = "trailing"
+)
+
+// baselineMsg carries a fetched baseline back to the TUI. seq tells a reply
+// to an earlier B press, which is dropped.
+type baselineMsg struct {
+	seq   int
+	kind  string
+	price float64
+	err   error
+}
+
+func nextBaseline(kind string) string {
+	switch kind {
+	case baselineStart:
+		return baseline24h
+	case baseline24h:
+		return baselineSMA
+	case baselineSMA:
+		return baselineTrailing
+	default:
+		return baselineStart
+	}
+}
+
+// resetBaseline makes the current price the baseline. A trailing baseline
+// stays trailing; a fetched one goes back to a plain baseline.
+func (m *tuiModel) resetBaseline() {
+	m.monitorStartPrice = currentBtcPrice
+	if m.baselineKind != baselineTrailing {
+		m.baselineKind = baselineStart
+	}
+	m.baselineError = ""
+}
+
+// cycleBaseline moves to the next kind of baseline, returning the command
+// that fetches it when it comes from history.
+func (m *tuiModel) cycleBaseline() tea.Cmd {
+	m.baselineKind = nextBaseline(m.baselineKind)
+	m.baselineError = ""
+	m.baselineSeq++
+	switch m.baselineKind {
+	case baseline24h, baselineSMA:
+		m.baselinePending = true
+		return fetchBaselineCmd(m.baselineSeq, m.baselineKind)
+	default:
+		m.baselinePending = false
+		m.monitorStartPrice = currentBtcPrice
+		return nil
+	}
+}
+
+func fetchBaselineCmd(seq int, kind string) tea.Cmd {
+	return func() tea.Msg {
+		price, err := fetchBaseline(kind, time.Now())
+		return baselineMsg{seq: seq, kind: kind, price: price, err: err}
+	}
+}
+
+// fetchBaseline returns the price 24 hours before now, or the average over
+// the hour before now.
+func fetchBaseline(kind string, now time.Time) (float64, error) {
+	if kind == baseline24h {
+		target := now.Add(-24 * time.Hour)
+		points, err := getBtcHistory(target.Add(-15*time.Minute), target.Add(15*time.Minute))
+		if err != nil {
+			return 0, err
+		}
+		best := -1
+		for i, p := range points {
+			if best < 0 || math.Abs(p.at.Sub(target).Seconds()) < math.Abs(points[best].at.Sub(target).Seconds()) {
+				best = i
+			}
+		}
+		if best < 0 {
+			return 0, fmt.Errorf("no price near %s", target.Format(time.RFC3339))
+		}
+		return points[best].price, nil
+	}
+	points, err := getBtcHistory(now.Add(-time.Hour), now)
+	if err != nil {
+		return 0, err
+	}
+	if len(points) == 0 {
+		return 0, fmt.Errorf("no prices in the last hour")
+	}
+	sum := 0.0
+	for _, p := range points {
+		sum += p.price
+	}
+	return sum / float64(len(points)), nil
+}
+
+// applyBaseline handles a fetched baseline.
+func (m *tuiModel) applyBaseline(msg baselineMsg) {
+	if msg.seq != m.baselineSeq {
+		return
+	}
+	m.baselinePending = false
+	if msg.err != nil {
+		slog.Warn("baseline fetch failed", "kind", msg.kind, "err", msg.err)
+		m.baselineError = "unavailable"
+		return
+	}
+	slog.Info("baseline set", "kind", msg.kind, "price", msg.price)
+	m.monitorStartPrice = msg.price
+}
+
+// baselineLabel names a baseline other than the start price, e.g. " [base 24h]".
+func (m tuiModel) baselineLabel() string {
+	if m.baselineKind == baselineStart {
+		return ""
+	}
+	text := "base " + m.baselineKind
+	switch {
+	case m.baselinePending:
+		text += "…"
+	case m.baselineError != "":
+		text += ": " + m.baselineError
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(" [" + text + "]")
+}
@@ -0,0 +1,158 @@
+package bmon
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The big ticker (T, or -big to start with it) fills the screen with the
+// price in large block digits for a wall dashboard. The border shows the
+// change from the baseline: green for up, red for down, white for flat. It
+// turns thick while the price line would flash, and yellow for an alarm.
+const bigGlyphRows = 5
+
+// bigGlyphs draws each character on a 5-row grid; '#' is a filled cell.
+var bigGlyphs = map[rune][bigGlyphRows]string{
+	'0':      {"####", "#  #", "#  #", "#  #", "####"},
+	'1':      {"  # ", " ## ", "  # ", "  # ", " ###"},
+	'2':      {"####", "   #", "####", "#   ", "####"},
+	'3':      {"####", "   #", " ###", "   #", "####"},
+	'4':      {"#  #", "#  #", "####", "   #", "   #"},
+	'5':      {"####", "#   ", "####", "   #", "####"},
+	'6':      {"####", "#   ", "####", "#  #", "####"},
+	'7':      {"####", "   #", "  # ", " #  ", " #  "},
+	'8':      {"####", "#  #", "####", "#  #", "####"},
+	'9':      {"####", "#  #", "####", "   #", "####"},
+	',':      {"  ", "  ", "  ", " #", "# "},
+	'.':      {" ", " ", " ", " ", "#"},
+	'\'':     {"#", "#", " ", " ", " "},
+	'’':      {"#", "#", " ", " ", " "}, // Swiss grouping
+	' ':      {" ", " ", " ", " ", " "},
+	'\u00a0': {" ", " ", " ", " ", " "},
+	'\u202f': {" ", " ", " ", " ", " "},
+}
+
+// bigText renders s in block digits at scale (each cell scale wide and
+// scale tall), skipping characters the font does not have.
+func bigText(s string, scale int) string {
+	rows := make([]strings.Builder, bigGlyphRows*scale)
+	first := true
+	for _, r := range s {
+		g, ok := bigGlyphs[r]
+		if !ok {
+			continue
+		}
+		for i := 0; i < bigGlyphRows; i++ {
+			var line strings.Builder
+			if !first {
+				line.WriteString(strings.Repeat(" ", scale))
+			}
+			for _, c := range g[i] {
+				cell := " "
+				if c == '#' {
+					cell = "█"
+				}
+				line.WriteString(strings.Repeat(cell, scale))
+			}
+			for j := 0; j < scale; j++ {
+				rows[i*scale+j].WriteString(line.String())
+			}
+		}
+		first = false
+	}
+	out := make([]string, len(rows))
+	for i := range rows {
+		out[i] = rows[i].String()
+	}
+	return strings.Join(out, "\n")
+}
+
+// bigTextWidth is the width of bigText(s, 1).
+func bigTextWidth(s string) int {
+	w, n := 0, 0
+	for _, r := range s {
+		if g, ok := bigGlyphs[r]; ok {
+			w += len([]rune(g[0]))
+			n++
+		}
+	}
+	if n > 1 {
+		w += n - 1
+	}
+	return w
+}
+
+// bigScale is the largest scale, up to 4, at which text fits in width x height
+// with room for the border, padding and the line under the digits.
+func bigScale(text string, width, height int) int {
+	scale := 1
+	for s := 2; s <= 4; s++ {
+		if bigTextWidth(text)*s+8 > width || bigGlyphRows*s+6 > height {
+			break
+		}
+		scale = s
+	}
+	return scale
+}
+
+func (m tuiModel) bigTickerView() string {
+	width, height := m.width, m.height
+	if width <= 0 {
+		width = 80
+	}
+	if height <= 0 {
+		height = 24
+	}
+	text := formatUSD(currentBtcPrice)
+	if m.satsMode {
+		text = formatSats(satsPer(currentBtcPrice))
+	}
+	digits := bigText(text, bigScale(text, width, height))
+
+	priceChange := currentBtcPrice - m.monitorStartPrice
+	borderColor := lipgloss.Color("15")
+	caption := "Bitcoin (" + fiat.Code + ")"
+	if m.satsMode {
+		caption = satsUnit()
+	}
+	if priceChange >= 0.01 {
+		borderColor = lipgloss.Color("2")
+		caption += "  " + m.changeText(priceChange)
+	} else if priceChange <= -0.01 {
+		borderColor = lipgloss.Color("1")
+		caption += "  " + m.changeText(priceChange)
+	}
+	caption += m.sourceTag() + m.staleLabel() + m.baselineLabel() + m.alarmLabel() + m.snoozeLabel() + retryDetailLabel()
+
+	border := lipgloss.RoundedBorder()
+	if time.Now().Before(m.alarmUntil) {
+		borderColor = lipgloss.Color("11")
+		border = lipgloss.ThickBorder()
+	} else if m.anomalyOn() {
+		borderColor = anomalyColor
+		border = lipgloss.ThickBorder()
+	} else if time.Now().Before(m.flashUntil) && (priceChange >= 0.01 || priceChange <= -0.01) {
+		border = lipgloss.ThickBorder()
+	}
+	box := lipgloss.NewStyle().
+		Border(border).
+		BorderForeground(borderColor).
+		Padding(1, 2).
+		Render(lipgloss.NewStyle().Foreground(borderColor).Render(digits) + "\n\n" + caption)
+
+	below := ""
+	if m.statusBar {
+		below = m.statusLine()
+	}
+	if m.alarmEditing {
+		below = m.alarmPrompt()
+	} else if m.alarmFired != "" {
+		below = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(m.alarmFired)
+	}
+	if below != "" {
+		box += "\n" + below
+	}
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
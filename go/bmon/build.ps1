@@ -41,6 +41,12 @@ if (Test-Path $binDir) {
     Remove-Item -Path $binDir -Recurse -Force
 }
 
+# Version stamped into the binary (shown by --version; compared by --check-update).
+# Keep it in step with version.Default in main.go, which a plain go build uses.
+$Version = "1.6"
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Version=$Version -X kreftus/shared/version.Date=$BuildDate"
+
 Write-Host "Tidying Go modules..."
 go mod tidy
 
@@ -61,27 +67,27 @@ try {
     Write-Host "Building for Windows 32-bit (x86)..."
     Write-Host "  -> Generating 32-bit resources..." -ForegroundColor DarkGray
     $env:GOOS = "windows"; $env:GOARCH = "386"; $env:CGO_ENABLED = "0"
-    windres -F pe-i386 -i bmon.rc -o bmon.syso -I .
+    windres -F pe-i386 -i bmon.rc -o cmd/bmon/bmon.syso -I .
     Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray
-    go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid=" -o ".\bin\win\x86\bmon.exe" .
+    go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid= $VersionFlags" -o ".\bin\win\x86\bmon.exe" ./cmd/bmon
 
     Write-Host "Building for Windows 64-bit (amd64)..."
     Write-Host "  -> Generating 64-bit resources..." -ForegroundColor DarkGray
     $env:GOOS = "windows"; $env:GOARCH = "amd64"; $env:CGO_ENABLED = "0"
-    windres -F pe-x86-64 -i bmon.rc -o bmon.syso -I .
+    windres -F pe-x86-64 -i bmon.rc -o cmd/bmon/bmon.syso -I .
     Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray
-    go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid=" -o ".\bin\win\x64\bmon.exe" .
+    go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid= $VersionFlags" -o ".\bin\win\x64\bmon.exe" ./cmd/bmon
 }
 finally {
-    if (Test-Path "bmon.syso") { Remove-Item "bmon.syso" -Force }
+    if (Test-Path "cmd/bmon/bmon.syso") { Remove-Item "cmd/bmon/bmon.syso" -Force }
 }
 
 # --- 4. Linux Builds ---
 Write-Host "Building for Linux 32-bit (x86)..."
-$env:GOOS = "linux"; $env:GOARCH = "386"; $env:CGO_ENABLED = "0"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid=" -o ".\bin\linux\x86\bmon" .
+$env:GOOS = "linux"; $env:GOARCH = "386"; $env:CGO_ENABLED = "0"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid= $VersionFlags" -o ".\bin\linux\x86\bmon" ./cmd/bmon
 
 Write-Host "Building for Linux 64-bit (amd64)..."
-$env:GOOS = "linux"; $env:GOARCH = "amd64"; $env:CGO_ENABLED = "0"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid=" -o ".\bin\linux\amd64\bmon" .
+$env:GOOS = "linux"; $env:GOARCH = "amd64"; $env:CGO_ENABLED = "0"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -trimpath -buildvcs=false -ldflags="-s -w -buildid= $VersionFlags" -o ".\bin\linux\amd64\bmon" ./cmd/bmon
 
 # --- 5. Optional UPX compression (only when -upx is specified) ---
 if ($useUpx) {
@@ -0,0 +1,141 @@
+package bmon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The candle view (C) groups fetched prices into 1-minute or 5-minute OHLC
+// candles and draws them above the price line, one column per candle across
+// the terminal width. C cycles 1m, 5m and off.
+const (
+	candleHeight     = 8
+	candleKeep       = 24 * time.Hour // samples kept for the candle view
+	candleMaxSamples = 25000
+)
+
+type candle struct {
+	start                  time.Time
+	open, high, low, close float64
+}
+
+// nextCandleWindow returns the window C switches to from w (0 is off).
+func nextCandleWindow(w time.Duration) time.Duration {
+	switch w {
+	case 0:
+		return time.Minute
+	case time.Minute:
+		return 5 * time.Minute
+	default:
+		return 0
+	}
+}
+
+// buildCandles groups points, oldest first, into candles of window and
+// returns the newest max of them.
+func buildCandles(points []pricePoint, window time.Duration, max int) []candle {
+	var candles []candle
+	for _, p := range points {
+		start := p.at.Truncate(window)
+		if n := len(candles); n > 0 && candles[n-1].start.Equal(start) {
+			c := &candles[n-1]
+			if p.price > c.high {
+				c.high = p.price
+			}
+			if p.price < c.low {
+				c.low = p.price
+			}
+			c.close = p.price
+			continue
+		}
+		candles = append(candles, candle{start: start, open: p.price, high: p.price, low: p.price, close: p.price})
+	}
+	if len(candles) > max {
+		candles = candles[len(candles)-max:]
+	}
+	return candles
+}
+
+// addSample records price for the candle view, dropping samples older than
+// candleKeep.
+func addSample(points []pricePoint, p pricePoint) []pricePoint {
+	points = append(points, p)
+	cut := p.at.Add(-candleKeep)
+	drop := 0
+	for drop < len(points) && points[drop].at.Before(cut) {
+		drop++
+	}
+	if over := len(points) - drop - candleMaxSamples; over > 0 {
+		drop += over
+	}
+	return points[drop:]
+}
+
+// candleChart draws the candle view: a title row, then candleHeight rows
+// with a wick (│) and body (█) per column, green for a rise and red for a
+// fall.
+func (m tuiModel) candleChart() string {
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	candles := buildCandles(m.samples, m.candleWindow, width-1)
+	label := fmt.Sprintf(" %s candles", strings.TrimSuffix(m.candleWindow.String(), "0s"))
+	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	if len(candles) == 0 {
+		return gray.Render(label+" (waiting for prices)") + strings.Repeat("\n", candleHeight)
+	}
+	low, high := candles[0].low, candles[0].high
+	for _, c := range candles {
+		if c.low < low {
+			low = c.low
+		}
+		if c.high > high {
+			high = c.high
+		}
+	}
+	title := gray.Render(fmt.Sprintf("%s · high %s · low %s", label, formatMoney(high), formatMoney(low)))
+
+	// row r covers prices from low+r*step up to low+(r+1)*step, top row last
+	step := (high - low) / candleHeight
+	row := func(price float64) int {
+		if step <= 0 {
+			return candleHeight / 2
+		}
+		r := int((price - low) / step)
+		if r >= candleHeight {
+			r = candleHeight - 1
+		}
+		return r
+	}
+	green := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	red := lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	lines := []string{title}
+	for r := candleHeight - 1; r >= 0; r-- {
+		var b strings.Builder
+		b.WriteString(" ")
+		for _, c := range candles {
+			style := green
+			if c.close < c.open {
+				style = red
+			}
+			bodyLow, bodyHigh := row(c.open), row(c.close)
+			if bodyLow > bodyHigh {
+				bodyLow, bodyHigh = bodyHigh, bodyLow
+			}
+			switch {
+			case r >= bodyLow && r <= bodyHigh:
+				b.WriteString(style.Render("█"))
+			case r >= row(c.low) && r <= row(c.high):
+				b.WriteString(style.Render("│"))
+			default:
+				b.WriteString(" ")
+			}
+		}
+		lines = append(lines, b.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,13 @@
+// Command bmon is the standalone bmon price monitor; the program itself lives
+// in package bmon so the kreftus launcher can link it in too.
+package main
+
+import (
+	"os"
+
+	"bmon"
+)
+
+func main() {
+	os.Exit(bmon.Run(os.Args[1:]))
+}
@@ -1,8 +0,0 @@
-//go:build !windows
-
-package main
-
-// On non-Windows platforms these are simple no-ops or direct prints.
-
-// non-Windows placeholder used by main
-func configureWindowsConsole() {}
@@ -0,0 +1,138 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"kreftus/shared/notify"
+)
+
+// Daemon mode (--daemon) runs without the TUI: it fetches the price every
+// -interval seconds and sends a notification through the shared notify
+// channels when an -above/-below alarm is reached or the price moves -move
+// percent from the last notified price. Each event is also printed with a
+// timestamp, so the output reads as a log when run as a service.
+const (
+	defaultDaemonInterval = 60 * time.Second
+	minDaemonInterval     = 5 * time.Second
+)
+
+func runDaemon(args Args) {
+	if args.alarmAbove <= 0 && args.alarmBelow <= 0 && args.movePercent <= 0 {
+		color.Red("--daemon needs something to watch: -above <price>, -below <price> or -move <percent>.")
+		os.Exit(1)
+	}
+	interval := args.daemonInterval
+	if interval <= 0 {
+		interval = defaultDaemonInterval
+	}
+	if interval < minDaemonInterval {
+		interval = minDaemonInterval
+	}
+
+	notifier := notify.Load("bmon")
+	if args.webhook != "" {
+		notifier.Webhook = args.webhook
+	}
+	if !notifier.Desktop && notifier.Webhook == "" {
+		// a bell alone is easy to miss from a background process
+		notifier.Desktop = true
+	}
+
+	var pl *priceLog
+	if args.priceLogPath != "" {
+		var err error
+		if pl, err = openPriceLog(args.priceLogPath); err != nil {
+			slog.Error("price log open failed", "path", args.priceLogPath, "err", err)
+			color.Red("Could not open price log %s: %v", args.priceLogPath, err)
+			os.Exit(1)
+		}
+		defer pl.Close()
+	}
+
+	above, below := args.alarmAbove, args.alarmBelow
+	baseline := currentBtcPrice
+	failing := false
+	slog.Info("daemon started", "interval", interval, "above", above, "below", below, "move", args.movePercent)
+	daemonPrintf("Watching BTC at %s every %s.", formatMoney(baseline), interval)
+
+	for {
+		if event := daemonCheck(&above, &below, &baseline, currentBtcPrice, args.movePercent); event != "" {
+			daemonPrintf("%s", event)
+			if err := notifier.Send("Bitcoin price", event); err != nil {
+				slog.Warn("notification failed", "err", err)
+				daemonPrintf("Notification failed: %v", err)
+			}
+			if args.exitOnAlarm {
+				return
+			}
+		}
+		if above <= 0 && below <= 0 && args.movePercent <= 0 {
+			daemonPrintf("All alarms have fired; nothing left to watch.")
+			return
+		}
+
+		time.Sleep(interval)
+		price, source, err := getBtcPrice()
+		clearRetryIndicator()
+		if err != nil {
+			slog.Error("daemon price fetch failed", "err", err)
+			if !failing {
+				// notify once per outage, not every interval
+				daemonPrintf("Price fetch failed: %v", err)
+				notifier.Send("Price fetch failed", err.Error())
+				failing = true
+			}
+			continue
+		}
+		if failing {
+			daemonPrintf("Price fetch recovered.")
+			failing = false
+		}
+		currentBtcPrice = price
+		if source != currentPriceSource {
+			daemonPrintf("Prices now from %s.", source)
+			currentPriceSource = source
+		}
+		if pl != nil {
+			if err := pl.Write(priceSample{Time: time.Now(), Price: price, Change: price - baseline, Mode: "daemon"}); err != nil {
+				slog.Error("price log write failed, logging stopped", "path", args.priceLogPath, "err", err)
+				pl.Close()
+				pl = nil
+			}
+		}
+	}
+}
+
+// daemonCheck returns the event for price, or "" if there is none. Alarms
+// fire once; a move of movePercent or more from baseline moves the baseline
+// to price, so the next move is measured from there.
+func daemonCheck(above, below, baseline *float64, price, movePercent float64) string {
+	if event := fireAlarms(above, below, price); event != "" {
+		*baseline = price
+		return event
+	}
+	if movePercent <= 0 || *baseline <= 0 {
+		return ""
+	}
+	change := (price - *baseline) / *baseline * 100
+	if math.Abs(change) < movePercent {
+		return ""
+	}
+	direction := "up"
+	if change < 0 {
+		direction = "down"
+	}
+	event := fmt.Sprintf("BTC is %s %.2f%% to %s (from %s)", direction, math.Abs(change), formatMoney(price), formatMoney(*baseline))
+	slog.Info("price move", "change_pct", change, "price", price, "from", *baseline)
+	*baseline = price
+	return event
+}
+
+func daemonPrintf(format string, a ...interface{}) {
+	fmt.Printf("%s  %s\n", time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, a...))
+}
@@ -0,0 +1,72 @@
+package bmon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// --timeout, --retries and --backoff tune LiveCoinWatch price fetches (by
+// default 10 seconds per attempt, 5 attempts, and a 2 second wait before the
+// first retry that doubles after each). With --debug the TUI also spells out
+// the retry in progress next to the price.
+const (
+	maxFetchAttempts = 9 // the retry indicator is one digit
+	retryDetailWidth = 60
+)
+
+// parseSeconds reads a duration such as "1500ms" or a plain number of
+// seconds.
+func parseSeconds(s string) (time.Duration, bool) {
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, true
+	}
+	if v, err := strconv.ParseFloat(s, 64); err == nil && v > 0 {
+		return time.Duration(v * float64(time.Second)), true
+	}
+	return 0, false
+}
+
+// applyFetchTuning replaces the fetch defaults with any flags given.
+func applyFetchTuning(args Args) {
+	if args.fetchTimeout > 0 {
+		liveCoinWatchTimeout = args.fetchTimeout
+	}
+	if args.fetchAttempts > 0 {
+		priceFetchAttempts = min(args.fetchAttempts, maxFetchAttempts)
+	}
+	if args.fetchBackoff > 0 {
+		priceRetryBaseDelay = args.fetchBackoff
+	}
+	slog.Debug("fetch tuning", "timeout", liveCoinWatchTimeout, "attempts", priceFetchAttempts, "backoff", priceRetryBaseDelay)
+}
+
+// debugEnabled reports whether bmon runs with --debug.
+func debugEnabled() bool {
+	return slog.Default().Enabled(context.Background(), slog.LevelDebug)
+}
+
+// noteRetryDetail records the retry in progress for the --debug readout.
+func noteRetryDetail(attempt int, wait time.Duration, err error) {
+	setRetryDetail(fmt.Sprintf("retry %d/%d in %s: %v", attempt+1, priceFetchAttempts, wait.Round(100*time.Millisecond), err))
+}
+
+// retryDetailLabel is the --debug retry readout, e.g.
+// " [retry 2/5 in 4.3s: coins/single returned status 502]", or "".
+func retryDetailLabel() string {
+	if !debugEnabled() {
+		return ""
+	}
+	detail := getRetryDetail()
+	if detail == "" {
+		return ""
+	}
+	if r := []rune(detail); len(r) > retryDetailWidth {
+		detail = string(r[:retryDetailWidth-1]) + "…"
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(" [" + detail + "]")
+}
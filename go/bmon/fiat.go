@@ -0,0 +1,81 @@
+package bmon
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// -fiat picks the currency prices are quoted in (USD by default). The code
+// is passed to every price source, and amounts are written with the
+// currency's symbol and its usual separators. A code not in fiatCurrencies
+// still works if the sources know it, written as "1,234.56 SEK".
+type fiatCurrency struct {
+	Code     string
+	Symbol   string
+	Locale   string // separators, e.g. "de" for 1.234,56
+	Suffix   bool   // symbol after the amount
+	Decimals int
+}
+
+var fiatCurrencies = []fiatCurrency{
+	{"USD", "$", "en-US", false, 2},
+	{"EUR", "€", "de-DE", true, 2},
+	{"GBP", "£", "en-GB", false, 2},
+	{"JPY", "¥", "ja-JP", false, 0},
+	{"CAD", "C$", "en-CA", false, 2},
+	{"AUD", "A$", "en-AU", false, 2},
+	{"CHF", "CHF ", "de-CH", false, 2},
+	{"INR", "₹", "en-IN", false, 2},
+}
+
+// fiat is the currency in use; see setFiat.
+var fiat = fiatCurrencies[0]
+
+// setFiat selects the currency for code, e.g. "eur".
+func setFiat(code string) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) < 3 || len(code) > 4 || strings.IndexFunc(code, func(r rune) bool { return r < 'A' || r > 'Z' }) >= 0 {
+		return fmt.Errorf("not a currency code: %q", code)
+	}
+	for _, c := range fiatCurrencies {
+		if c.Code == code {
+			fiat = c
+			return nil
+		}
+	}
+	fiat = fiatCurrency{Code: code, Symbol: " " + code, Locale: "en", Suffix: true, Decimals: 2}
+	return nil
+}
+
+// formatUSD formats v with the grouping and decimals of the quoted currency,
+// without a symbol. (It predates -fiat, hence the name.)
+func formatUSD(v float64) string {
+	p := message.NewPrinter(language.Make(fiat.Locale))
+	return p.Sprintf(fmt.Sprintf("%%0.%df", fiat.Decimals), v)
+}
+
+// formatMoney formats v in the quoted currency, e.g. $1,234.56 or 1.234,56 €.
+func formatMoney(v float64) string {
+	sign := ""
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	if fiat.Suffix {
+		if !strings.HasPrefix(fiat.Symbol, " ") {
+			return sign + formatUSD(v) + " " + fiat.Symbol
+		}
+		return sign + formatUSD(v) + fiat.Symbol
+	}
+	return sign + fiat.Symbol + formatUSD(v)
+}
+
+// signedMoney formats d with a leading + or -, e.g. +$1,234.56.
+func signedMoney(d float64) string {
+	if d < 0 {
+		return formatMoney(d)
+	}
+	return "+" + formatMoney(d)
+}
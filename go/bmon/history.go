@@ -0,0 +1,160 @@
+package bmon
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// The sparkline starts from the last hour of LiveCoinWatch history instead
+// of a flat line. W switches it between the session window (the last
+// prices, one per glyph) and the hour window (the last hour in equal
+// slices, one per glyph). It is sparkPoints glyphs wide until the terminal
+// size is known, then stretches to fit (see sparkWidthFor).
+const (
+	sparkWindowSession = "session"
+	sparkWindowHour    = "hour"
+	sparkPoints        = 14
+	sparkMaxPoints     = 200
+	// sparkReserve leaves room on the price line for the spinner, price,
+	// change and tags.
+	sparkReserve = 48
+)
+
+// pricePoint is a price and when it was seen.
+type pricePoint struct {
+	at    time.Time
+	price float64
+}
+
+// seedHistory holds the history fetched at startup, oldest first; empty if
+// the fetch failed.
+var seedHistory []pricePoint
+
+type historyResponse struct {
+	History []struct {
+		Date int64   `json:"date"`
+		Rate float64 `json:"rate"`
+	} `json:"history"`
+}
+
+// fetchHistorySeed loads the last hour of BTC prices into seedHistory. It
+// makes one attempt: without history the sparkline just starts flat.
+func fetchHistorySeed() {
+	points, err := getBtcHistory(time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		slog.Warn("sparkline history unavailable, starting flat", "err", err)
+		return
+	}
+	slog.Debug("sparkline history fetched", "points", len(points))
+	seedHistory = points
+}
+
+func getBtcHistory(start, end time.Time) ([]pricePoint, error) {
+	var histResp historyResponse
+	err := liveCoinWatchClient(1).Post(context.Background(), "coins/single/history", map[string]interface{}{
+		"currency": fiat.Code,
+		"code":     "BTC",
+		"start":    start.UnixMilli(),
+		"end":      end.UnixMilli(),
+		"meta":     false,
+	}, &histResp)
+	if err != nil {
+		return nil, err
+	}
+	var points []pricePoint
+	for _, h := range histResp.History {
+		if h.Rate > 0 {
+			points = append(points, pricePoint{at: time.UnixMilli(h.Date), price: h.Rate})
+		}
+	}
+	return points, nil
+}
+
+// seedSparkline returns the prices to start the session window with: the
+// latest history points, ending with the current price.
+func seedSparkline(points []pricePoint, current float64) []float64 {
+	var history []float64
+	for _, p := range points {
+		history = append(history, p.price)
+	}
+	history = append(history, current)
+	if len(history) > sparkPoints {
+		history = history[len(history)-sparkPoints:]
+	}
+	return history
+}
+
+// hourSparkline splits the hour before now into n slices and takes the last
+// price seen in each. An empty slice repeats the one before it; slices
+// before the first price are left out.
+func hourSparkline(points []pricePoint, now time.Time, n int) []float64 {
+	start := now.Add(-time.Hour)
+	slice := time.Hour / time.Duration(n)
+	var out []float64
+	last, seen := 0.0, false
+	i := 0
+	for s := 1; s <= n; s++ {
+		end := start.Add(time.Duration(s) * slice)
+		if s == n {
+			end = now // time.Hour/n may be rounded down
+		}
+		for ; i < len(points) && !points[i].at.After(end); i++ {
+			last, seen = points[i].price, true
+		}
+		if seen {
+			out = append(out, last)
+		}
+	}
+	return out
+}
+
+// trimHourPoints drops points older than an hour before now.
+func trimHourPoints(points []pricePoint, now time.Time) []pricePoint {
+	cut := now.Add(-time.Hour)
+	for len(points) > 0 && points[0].at.Before(cut) {
+		points = points[1:]
+	}
+	return points
+}
+
+// sparkData returns the prices the sparkline shows in the current window.
+func (m tuiModel) sparkData() []float64 {
+	if m.sparkWindow == sparkWindowHour {
+		return hourSparkline(m.hourPoints, time.Now(), m.sparkWidth)
+	}
+	return m.history
+}
+
+// sparkWidthFor returns the sparkline width for a terminal width columns wide.
+func sparkWidthFor(width int) int {
+	w := width - sparkReserve
+	if w < sparkPoints {
+		return sparkPoints
+	}
+	if w > sparkMaxPoints {
+		return sparkMaxPoints
+	}
+	return w
+}
+
+// lastPrices returns the prices of the newest n points.
+func lastPrices(points []pricePoint, n int) []float64 {
+	if len(points) > n {
+		points = points[len(points)-n:]
+	}
+	prices := make([]float64, len(points))
+	for i, p := range points {
+		prices[i] = p.price
+	}
+	return prices
+}
+
+// volatilityHistory is the last sparkPoints prices, which the volatility
+// colors are scaled for whatever the sparkline width.
+func (m tuiModel) volatilityHistory() []float64 {
+	if len(m.history) > sparkPoints {
+		return m.history[len(m.history)-sparkPoints:]
+	}
+	return m.history
+}
@@ -0,0 +1,41 @@
+package bmon
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// -json prints one fetched price as a single JSON line and exits, e.g.
+//
+//	{"price":97123.45,"currency":"USD","time":"2025-01-02T15:04:05Z","source":"LiveCoinWatch"}
+//
+// so other scripts can read bmon without screen handling. On failure it
+// prints the error to stderr and exits 1.
+type jsonQuote struct {
+	Price    float64 `json:"price"`
+	Currency string  `json:"currency"`
+	Time     string  `json:"time"`
+	Source   string  `json:"source"`
+}
+
+func printJSONQuote() {
+	price, source, err := fetchPrice(true)
+	clearRetryIndicator()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bmon: could not retrieve Bitcoin price: %v\n", err)
+		os.Exit(1)
+	}
+	out, err := json.Marshal(jsonQuote{
+		Price:    price,
+		Currency: fiat.Code,
+		Time:     time.Now().UTC().Format(time.RFC3339),
+		Source:   source,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bmon: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(string(out))
+}
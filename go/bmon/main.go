@@ -1,12 +1,13 @@
-package main
+// Package bmon is the bmon Bitcoin price monitor. Run is its entry point for
+// cmd/bmon and the kreftus launcher.
+package bmon
 
 import (
 	"bufio"
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"io"
-	"math"
-	"net/http"
+	"log/slog"
 	"os"
 	"os/exec"
 	"os/signal"
@@ -22,21 +23,38 @@ import (
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
 	"github.com/fatih/color"
-	"golang.org/x/text/language"
-	"golang.org/x/text/message"
 	"gopkg.in/ini.v1"
-)
-
-const (
-	version = "1.6"
-	date    = "2025-08-07@1430"
+	"kreftus/shared/apikey"
+	"kreftus/shared/console"
+	"kreftus/shared/lcw"
+	"kreftus/shared/logging"
+	"kreftus/shared/notify"
+	"kreftus/shared/version"
 )
 
 // Configuration structure
 type Config struct {
 	Settings struct {
-		ApiKey string `ini:"ApiKey"`
+		ApiKey     string `ini:"ApiKey"`
+		SessionCSV bool   `ini:"SessionCSV"`
 	} `ini:"Settings"`
+	Display struct {
+		VelocityThreshold float64 `ini:"VelocityThreshold"`
+		StaleIntervals    int     `ini:"StaleIntervals"`
+		StaleBeep         bool    `ini:"StaleBeep"`
+		AnomalyPercent    float64 `ini:"AnomalyPercent"`
+	} `ini:"Display"`
+	Sounds struct {
+		UpTick          string `ini:"UpTick"`
+		DownTick        string `ini:"DownTick"`
+		Alarm           string `ini:"Alarm"`
+		Stale           string `ini:"Stale"`
+		UpTickCommand   string `ini:"UpTickCommand"`
+		DownTickCommand string `ini:"DownTickCommand"`
+		AlarmCommand    string `ini:"AlarmCommand"`
+		StaleCommand    string `ini:"StaleCommand"`
+		SnoozeMinutes   int    `ini:"SnoozeMinutes"`
+	} `ini:"Sounds"`
 }
 
 // API response structure
@@ -46,66 +64,153 @@ type APIResponse struct {
 
 // Global variables
 var (
-	apiKey          string
-	currentBtcPrice float64
+	apiKey             string
+	currentBtcPrice    float64
+	currentPriceSource string // provider of currentBtcPrice
 )
 
 // (legacy mode settings removed; TUI handles timing and spinners)
 
 // Command line arguments structure
 type Args struct {
-	goMode         bool
-	golongMode     bool
-	kMode          bool
-	klMode         bool
-	sound          bool
-	sparkline      bool
-	volatilitySpinner   bool
-	help           bool
-	config         bool
-	conversionMode string
-	conversionVal  float64
+	goMode            bool
+	golongMode        bool
+	kMode             bool
+	klMode            bool
+	sound             bool
+	sparkline         bool
+	volatilitySpinner bool
+	help              bool
+	config            bool
+	conversionMode    string
+	conversionVal     float64
+	alarmAbove        float64
+	alarmBelow        float64
+	exitOnAlarm       bool
+	priceLogPath      string
+	daemon            bool
+	daemonInterval    time.Duration
+	movePercent       float64
+	webhook           string
+	fiat              string
+	bigTicker         bool
+	satsMode          bool
+	adaptive          bool
+	watchAbove        float64
+	watchBelow        float64
+	watchTimeout      time.Duration
+	jsonOutput        bool
+	fetchTimeout      time.Duration
+	fetchAttempts     int
+	fetchBackoff      time.Duration
+	pairA             string
+	pairB             string
+	recordSessions    bool
+	serveLogPath      string
+	serveKeep         int
 }
 
-func main() {
+// Run starts bmon with argv, the command line after the program name, and
+// returns the exit code. cmd/bmon and the kreftus launcher both call it.
+func Run(argv []string) int {
+	version.Default("1.6")
+	argv = logging.Init("bmon", append([]string{"bmon"}, argv...))[1:]
+	defer logging.Close()
+	if version.HandleArgs("bmon", argv) {
+		return 0
+	}
 	// Ensure Windows console uses UTF-8 and supports ANSI (no-op on non-Windows)
-	configureWindowsConsole()
+	console.Setup()
+	console.PreferUnicodeFont()
 	// Set up signal handling for Ctrl+C
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
 
 	go func() {
 		<-sigChan
+		if watching {
+			os.Exit(watchExitInterrupted)
+		}
+		if serving {
+			return // runServeLog closes its log and exits
+		}
 		// Restore terminal state and exit cleanly
 		fmt.Print("\033[?25h") // Show cursor
 		os.Exit(0)
 	}()
 
 	// Parse command line arguments
-	args := parseArgs()
+	args := parseArgs(argv)
+	serving = args.serveLogPath != ""
+	loadSettings()
+	applyFetchTuning(args)
 
 	// Handle help (no config needed)
 	if args.help {
 		printHelp()
-		return
+		return 0
 	}
 
 	// Handle config menu (loads/edits config itself, then exit)
 	if args.config {
 		runConfigMenu()
-		return
+		return 0
+	}
+
+	if args.fiat != "" {
+		if err := setFiat(args.fiat); err != nil {
+			color.Red("Invalid -fiat: %v", err)
+			return 1
+		}
+	}
+
+	if args.watchAbove > 0 || args.watchBelow > 0 {
+		watching = true
+	}
+
+	// Service mode has no terminal, so it must not reach onboarding
+	if serving {
+		return runServeLog(args)
 	}
 
 	// Initialize configuration
 	if err := initConfig(); err != nil {
 		color.Red("Failed to initialize configuration: %v", err)
-		os.Exit(1)
+		return 1
+	}
+
+	// Watch mode is silent; scripts read its exit code
+	if watching {
+		return runWatch(args)
+	}
+
+	// Single-shot JSON output for scripts
+	if args.jsonOutput {
+		printJSONQuote()
+		return 0
+	}
+
+	// Pair mode has its own screen
+	if args.pairA != "" {
+		runPair(args)
+		return 0
 	}
 
 	// Handle conversion modes
 	if args.conversionMode != "" {
 		handleConversion(args)
-		return
+		return 0
+	}
+
+	// Daemon mode runs headless, so it skips the screen setup below
+	if args.daemon {
+		if err := fetchInitialPrice(); err != nil {
+			printCreditsReset(err)
+			color.Red("Failed to fetch initial price: %v", err)
+			return 1
+		}
+		runDaemon(args)
+		return 0
 	}
 
 	// Get initial price - show appropriate message based on mode
@@ -119,19 +224,22 @@ func main() {
 	}
 
 	if err := fetchInitialPrice(); err != nil {
+		printCreditsReset(err)
 		color.Red("Failed to fetch initial price: %v", err)
-		os.Exit(1)
+		return 1
 	}
+	fetchHistorySeed()
 
 	// Handle monitoring modes via Bubble Tea TUI
 	runTUI(args)
+	return 0
 }
 
-func parseArgs() Args {
+func parseArgs(argv []string) Args {
 	args := Args{}
 
-	for i := 1; i < len(os.Args); i++ {
-		arg := os.Args[i]
+	for i := 0; i < len(argv); i++ {
+		arg := argv[i]
 		switch arg {
 		case "-go", "-g":
 			args.goMode = true
@@ -151,33 +259,149 @@ func parseArgs() Args {
 			args.help = true
 		case "-config":
 			args.config = true
+		case "-above", "-below":
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(strings.ReplaceAll(argv[i+1], ",", ""), 64); err == nil && val > 0 {
+					if arg == "-above" {
+						args.alarmAbove = val
+					} else {
+						args.alarmBelow = val
+					}
+					i++
+				}
+			}
+		case "-exit-on-alarm":
+			args.exitOnAlarm = true
+		case "--daemon", "-daemon":
+			args.daemon = true
+		case "-interval":
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(argv[i+1], 64); err == nil && val > 0 {
+					args.daemonInterval = time.Duration(val * float64(time.Second))
+					i++
+				}
+			}
+		case "-move":
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(strings.TrimSuffix(argv[i+1], "%"), 64); err == nil && val > 0 {
+					args.movePercent = val
+					i++
+				}
+			}
+		case "-webhook":
+			if i+1 < len(argv) {
+				args.webhook = argv[i+1]
+				i++
+			}
+		case "--watch-above", "-watch-above", "--watch-below", "-watch-below":
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(strings.ReplaceAll(argv[i+1], ",", ""), 64); err == nil && val > 0 {
+					if strings.HasSuffix(arg, "above") {
+						args.watchAbove = val
+					} else {
+						args.watchBelow = val
+					}
+					i++
+				}
+			}
+		case "--watch-timeout", "-watch-timeout":
+			value := ""
+			if i+1 < len(argv) {
+				value = argv[i+1]
+			}
+			d, ok := parseWatchTimeout(value)
+			if !ok {
+				color.Red("%s needs a duration such as 90m or 2h, or a number of minutes, not %q", arg, value)
+				os.Exit(watchExitError)
+			}
+			args.watchTimeout = d
+			i++
+		case "-big":
+			args.bigTicker = true
+		case "-sats":
+			args.satsMode = true
+		case "-json", "--json":
+			args.jsonOutput = true
+		case "-sessions":
+			args.recordSessions = true
+		case "-pair", "--pair":
+			if i+2 < len(argv) {
+				args.pairA, args.pairB = argv[i+1], argv[i+2]
+				i += 2
+			}
+		case "--timeout", "-timeout":
+			if i+1 < len(argv) {
+				if d, ok := parseSeconds(argv[i+1]); ok {
+					args.fetchTimeout = d
+					i++
+				}
+			}
+		case "--retries", "-retries":
+			if i+1 < len(argv) {
+				if val, err := strconv.Atoi(argv[i+1]); err == nil && val > 0 {
+					args.fetchAttempts = val
+					i++
+				}
+			}
+		case "--backoff", "-backoff":
+			if i+1 < len(argv) {
+				if d, ok := parseSeconds(argv[i+1]); ok {
+					args.fetchBackoff = d
+					i++
+				}
+			}
+		case "--serve-log", "-serve-log":
+			if i+1 < len(argv) {
+				args.serveLogPath = argv[i+1]
+				i++
+			}
+		case "-keep":
+			if i+1 < len(argv) {
+				if val, err := strconv.Atoi(argv[i+1]); err == nil {
+					args.serveKeep = val
+					i++
+				}
+			}
+		case "-adaptive", "-ad":
+			args.adaptive = true
+			args.golongMode = true
+		case "-fiat":
+			if i+1 < len(argv) {
+				args.fiat = argv[i+1]
+				i++
+			}
+		case "-log":
+			if i+1 < len(argv) {
+				args.priceLogPath = argv[i+1]
+				i++
+			}
 		case "-bu":
-			if i+1 < len(os.Args) {
-				if val, err := strconv.ParseFloat(os.Args[i+1], 64); err == nil {
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(argv[i+1], 64); err == nil {
 					args.conversionMode = "bu"
 					args.conversionVal = val
 					i++
 				}
 			}
 		case "-ub":
-			if i+1 < len(os.Args) {
-				if val, err := strconv.ParseFloat(os.Args[i+1], 64); err == nil {
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(argv[i+1], 64); err == nil {
 					args.conversionMode = "ub"
 					args.conversionVal = val
 					i++
 				}
 			}
 		case "-us":
-			if i+1 < len(os.Args) {
-				if val, err := strconv.ParseFloat(os.Args[i+1], 64); err == nil {
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(argv[i+1], 64); err == nil {
 					args.conversionMode = "us"
 					args.conversionVal = val
 					i++
 				}
 			}
 		case "-su":
-			if i+1 < len(os.Args) {
-				if val, err := strconv.ParseFloat(os.Args[i+1], 64); err == nil {
+			if i+1 < len(argv) {
+				if val, err := strconv.ParseFloat(argv[i+1], 64); err == nil {
 					args.conversionMode = "su"
 					args.conversionVal = val
 					i++
@@ -190,34 +414,48 @@ func parseArgs() Args {
 }
 
 func initConfig() error {
-	// Get executable directory
-	exePath, err := os.Executable()
-	if err != nil {
+	if ok, err := loadAPIKey(); ok || err != nil {
 		return err
 	}
-	exeDir := filepath.Dir(exePath)
 
-	// Try bmon.ini first
-	bmonPath := filepath.Join(exeDir, "bmon.ini")
-	if cfg, err := loadConfig(bmonPath); err == nil && cfg.Settings.ApiKey != "" {
-		apiKey = cfg.Settings.ApiKey
-		return nil
+	// No valid config found, start onboarding
+	return runOnboarding()
+}
+
+// loadAPIKey sets apiKey from the shared keys file or a legacy ini file and
+// reports whether it found one.
+func loadAPIKey() (bool, error) {
+	// The LiveCoinWatch key is shared with vbtc through the common keys file.
+	if key := apikey.Load(apikey.LiveCoinWatch); key != "" {
+		apiKey = key
+		return true, nil
 	}
 
-	// Try vbtc.ini as fallback
-	vbtcPath := filepath.Join(exeDir, "vbtc.ini")
-	if cfg, err := loadConfig(vbtcPath); err == nil && cfg.Settings.ApiKey != "" {
-		apiKey = cfg.Settings.ApiKey
-		return nil
+	// Get executable directory
+	exePath, err := os.Executable()
+	if err != nil {
+		return false, err
 	}
+	exeDir := filepath.Dir(exePath)
 
-	// No valid config found, start onboarding
-	return runOnboarding(bmonPath)
+	// Fall back to legacy per-tool ini files (bmon.ini first, then vbtc.ini)
+	// and promote the key to the shared keys file so other tools find it.
+	for _, name := range []string{"bmon.ini", "vbtc.ini"} {
+		if cfg, err := loadConfig(filepath.Join(exeDir, name)); err == nil && cfg.Settings.ApiKey != "" {
+			apiKey = cfg.Settings.ApiKey
+			apikey.Save(apikey.LiveCoinWatch, apiKey)
+			return true, nil
+		}
+	}
+	return false, nil
 }
 
 func loadConfig(path string) (*Config, error) {
-	cfg := &Config{}
+	return loadConfigInto(path, &Config{})
+}
 
+// loadConfigInto reads path over the values already in cfg.
+func loadConfigInto(path string, cfg *Config) (*Config, error) {
 	iniFile, err := ini.Load(path)
 	if err != nil {
 		return cfg, err
@@ -230,45 +468,29 @@ func loadConfig(path string) (*Config, error) {
 	return cfg, nil
 }
 
-func saveConfig(path string, apiKey string) error {
-	cfg := ini.Empty()
-	cfg.Section("Settings").Key("ApiKey").SetValue(apiKey)
-	return cfg.SaveTo(path)
+// liveCoinWatch returns the shared provider with bmon's validator, which also
+// reports exhausted daily credits instead of treating them as a bad key.
+func liveCoinWatch() apikey.Provider {
+	p := apikey.LiveCoinWatch
+	p.Validate = testAPIKey
+	return p
 }
 
-func runOnboarding(configPath string) error {
+func runOnboarding() error {
 	color.Yellow("*** bmon First Time Setup ***")
 	color.White("A LiveCoinWatch API key is required to monitor prices.")
-	color.Green("Get a free key at: https://www.livecoinwatch.com/tools/api")
 
 	reader := bufio.NewReader(os.Stdin)
-
-	for {
-		fmt.Print("Please enter your LiveCoinWatch API Key (or press Enter to exit): ")
-		input, _ := reader.ReadString('\n')
-		input = strings.TrimSpace(input)
-
-		if input == "" {
-			return fmt.Errorf("setup cancelled by user")
-		}
-
-		if testAPIKey(input) {
-			if err := saveConfig(configPath, input); err != nil {
-				color.Red("API Key was valid, but failed to save to %s. Please check file permissions.", configPath)
-				fmt.Print("Press Enter to exit.")
-				reader.ReadString('\n')
-				return err
-			}
-
-			apiKey = input
-			color.Green("API Key is valid and has been saved to bmon.ini.")
-			fmt.Print("Press Enter to start monitoring.")
-			reader.ReadString('\n')
-			return nil
-		} else {
-			color.Yellow("Invalid API Key. Please try again.")
-		}
+	key, err := apikey.Prompt(reader, liveCoinWatch())
+	if err != nil {
+		return err
 	}
+
+	apiKey = key
+	color.Green("API Key is valid and has been saved.")
+	fmt.Print("Press Enter to start monitoring.")
+	reader.ReadString('\n')
+	return nil
 }
 
 func runConfigMenu() {
@@ -280,9 +502,13 @@ func runConfigMenu() {
 	exeDir := filepath.Dir(exePath)
 	bmonPath := filepath.Join(exeDir, "bmon.ini")
 	vbtcPath := filepath.Join(exeDir, "vbtc.ini")
+	sharedPath, _ := apikey.Path()
 
 	var currentKey, configPath string
-	if cfg, err := loadConfig(bmonPath); err == nil && cfg.Settings.ApiKey != "" {
+	if key := apikey.Load(apikey.LiveCoinWatch); key != "" {
+		currentKey = key
+		configPath = sharedPath
+	} else if cfg, err := loadConfig(bmonPath); err == nil && cfg.Settings.ApiKey != "" {
 		currentKey = cfg.Settings.ApiKey
 		configPath = bmonPath
 	} else if cfg, err := loadConfig(vbtcPath); err == nil && cfg.Settings.ApiKey != "" {
@@ -320,11 +546,11 @@ func runConfigMenu() {
 	}
 
 	if testAPIKey(input) {
-		if err := saveConfig(bmonPath, input); err != nil {
-			color.Red("API Key was valid, but failed to save to bmon.ini.")
+		if err := apikey.Save(apikey.LiveCoinWatch, input); err != nil {
+			color.Red("API Key was valid, but failed to save: %v", err)
 			os.Exit(1)
 		}
-		color.Green("API Key saved to bmon.ini.")
+		color.Green("API Key saved to %s.", sharedPath)
 	} else {
 		color.Red("Invalid API Key. No changes saved.")
 		os.Exit(1)
@@ -336,39 +562,19 @@ func testAPIKey(key string) bool {
 		return false
 	}
 
-	url := "https://api.livecoinwatch.com/coins/single"
-	payload := map[string]interface{}{
-		"currency": "USD",
+	client := &lcw.Client{APIKey: key, Attempts: 1, Timeout: liveCoinWatchTimeout}
+	var apiResp APIResponse
+	err := client.Post(context.Background(), "coins/single", map[string]interface{}{
+		"currency": fiat.Code,
 		"code":     "BTC",
 		"meta":     false,
+	}, &apiResp)
+	if errors.Is(err, lcw.ErrCreditsExhausted) {
+		resetWait := timeUntilMidnightUTC()
+		color.Red("API Credits reset in: %s", resetWait)
+		os.Exit(1)
 	}
-
-	jsonData, _ := json.Marshal(payload)
-
-	req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
-	if err != nil {
-		return false
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", key)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return false
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == 403 {
-		body, _ := io.ReadAll(resp.Body)
-		if strings.Contains(string(body), "No more daily credits remaining. Renewal is at midnight UTC.") {
-			resetWait := timeUntilMidnightUTC()
-			color.Red("API Credits reset in: %s", resetWait)
-			os.Exit(1)
-		}
-	}
-	return resp.StatusCode == 200
+	return err == nil
 }
 
 func timeUntilMidnightUTC() string {
@@ -384,126 +590,60 @@ func timeUntilMidnightUTC() string {
 }
 
 func fetchInitialPrice() error {
-	price, err := getBtcPriceWithContext(true)
+	price, source, err := fetchPrice(true)
 	if err != nil {
 		return err
 	}
 
 	currentBtcPrice = price
+	currentPriceSource = source
 	return nil
 }
 
-func getBtcPrice() (float64, error) {
-	return getBtcPriceWithContext(false)
+func getBtcPrice() (float64, string, error) {
+	return fetchPrice(false)
 }
 
-func getBtcPriceWithContext(isInitialFetch bool) (float64, error) {
-	if apiKey == "" {
-		return 0, fmt.Errorf("API key is null or empty")
+// getLiveCoinWatchPrice fetches from LiveCoinWatch with retries; fetchPrice
+// falls back to the other sources when it fails.
+func getLiveCoinWatchPrice(isInitialFetch bool) (float64, error) {
+	client := liveCoinWatchClient(priceFetchAttempts)
+	client.OnRetry = func(_ string, attempt int, wait time.Duration, err error) {
+		// Show timeout message for initial fetch on first retry
+		if isInitialFetch && attempt == 1 {
+			fmt.Print("\r")
+			color.Yellow("  Timeout, retrying...")
+		}
+		// Show yellow digit for current attempt
+		noteRetry(attempt)
+		noteRetryDetail(attempt, wait, err)
 	}
 
-	url := "https://api.livecoinwatch.com/coins/single"
-	payload := map[string]interface{}{
-		"currency": "USD",
+	var apiResp APIResponse
+	err := client.Post(context.Background(), "coins/single", map[string]interface{}{
+		"currency": fiat.Code,
 		"code":     "BTC",
 		"meta":     false,
-	}
-
-	jsonData, _ := json.Marshal(payload)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-
-	// Retry logic
-	maxAttempts := 5
-	baseDelay := 2 * time.Second
-
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		// Create a fresh request each attempt (request bodies are one-shot)
-		req, err := http.NewRequest("POST", url, strings.NewReader(string(jsonData)))
-		if err != nil {
-			return 0, err
-		}
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("x-api-key", apiKey)
-
-		resp, err := client.Do(req)
-		if err != nil {
-			if attempt >= maxAttempts {
-				// Final failure: show red '5' indicator for TUI
-				setRetryIndicator("5", "1", true)
-				return 0, fmt.Errorf("API call failed after %d attempts: %v", maxAttempts, err)
-			}
-
-			// Show timeout message for initial fetch on first retry
-			if isInitialFetch && attempt == 1 {
-				fmt.Print("\r")
-				color.Yellow("  Timeout, retrying...")
-			}
-
-			// Exponential backoff with jitter
-			backoff := time.Duration(math.Pow(2, float64(attempt-1))) * baseDelay
-			jitter := time.Duration(time.Now().UnixNano()%1000) * time.Millisecond
-			sleepTime := backoff + jitter
-
-			// Show yellow digit for current attempt (1-4)
-			setRetryIndicator(strconv.Itoa(attempt), "11", true)
-
-			time.Sleep(sleepTime)
-
-			continue
-		}
-
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return 0, err
-		}
-
-		if resp.StatusCode == 403 && strings.Contains(string(body), "No more daily credits remaining. Renewal is at midnight UTC.") {
-			clearRetryIndicator()
-			resetWait := timeUntilMidnightUTC()
-			color.Red("API Credits reset in: %s", resetWait)
-			os.Exit(1)
-		}
-
-		if resp.StatusCode != 200 {
-			if attempt >= maxAttempts {
-				setRetryIndicator("5", "1", true)
-				return 0, fmt.Errorf("API returned status %d", resp.StatusCode)
-			}
-			backoff := time.Duration(math.Pow(2, float64(attempt-1))) * baseDelay
-			jitter := time.Duration(time.Now().UnixNano()%1000) * time.Millisecond
-			time.Sleep(backoff + jitter)
-			setRetryIndicator(strconv.Itoa(attempt), "11", true)
-			continue
-		}
-
-		var apiResp APIResponse
-		if err := json.Unmarshal(body, &apiResp); err != nil {
-			return 0, err
-		}
-
-		if apiResp.Rate <= 0 {
-			if attempt >= maxAttempts {
-				setRetryIndicator("5", "1", true)
-				return 0, fmt.Errorf("invalid price returned")
-			}
-			// treat as transient; set yellow digit and retry with backoff
-			setRetryIndicator(strconv.Itoa(attempt), "11", true)
-			backoff := time.Duration(math.Pow(2, float64(attempt-1))) * baseDelay
-			jitter := time.Duration(time.Now().UnixNano()%1000) * time.Millisecond
-			time.Sleep(backoff + jitter)
-
-			continue
-		}
-
-		// Success: clear indicator so spinner resumes
+	}, &apiResp)
+	if errors.Is(err, lcw.ErrCreditsExhausted) {
+		slog.Error("API daily credits exhausted")
 		clearRetryIndicator()
-		return apiResp.Rate, nil
+		return 0, errCreditsExhausted
+	}
+	if err == nil && apiResp.Rate <= 0 {
+		err = fmt.Errorf("invalid price returned")
+	}
+	if err != nil {
+		// Final failure: show red digit indicator for TUI
+		setRetryIndicator(strconv.Itoa(priceFetchAttempts), "1", true)
+		setRetryDetail(fmt.Sprintf("failed: %v", err))
+		return 0, err
 	}
 
-	return 0, fmt.Errorf("failed to get price after all attempts")
+	// Success: clear indicator so spinner resumes
+	clearRetryIndicator()
+	slog.Debug("price fetched", "rate", apiResp.Rate)
+	return apiResp.Rate, nil
 }
 
 // (legacy line-warning flag removed; retry indicator handles UI signaling)
@@ -514,6 +654,7 @@ var (
 	retryActive bool
 	retryDigit  string
 	retryColor  string
+	retryDetail string // the retry in progress, for the --debug readout
 )
 
 func setRetryIndicator(digit string, color string, active bool) {
@@ -524,8 +665,27 @@ func setRetryIndicator(digit string, color string, active bool) {
 	retryMu.Unlock()
 }
 
+// noteRetry shows the yellow digit for a retry and counts it.
+func noteRetry(attempt int) {
+	fetchRetries.Add(1)
+	setRetryIndicator(strconv.Itoa(attempt), "11", true)
+}
+
 func clearRetryIndicator() {
 	setRetryIndicator("", "", false)
+	setRetryDetail("")
+}
+
+func setRetryDetail(detail string) {
+	retryMu.Lock()
+	retryDetail = detail
+	retryMu.Unlock()
+}
+
+func getRetryDetail() string {
+	retryMu.RLock()
+	defer retryMu.RUnlock()
+	return retryDetail
 }
 
 func getRetryIndicator() (bool, string, string) {
@@ -538,9 +698,9 @@ func getRetryIndicator() (bool, string, string) {
 
 // (legacy console width and line clearing helpers removed)
 
-func getSparkline(history []float64) string {
+func getSparkline(history []float64, width int) string {
 	if len(history) < 2 {
-		return strings.Repeat(" ", 14)
+		return strings.Repeat(" ", width)
 	}
 
 	// Choose a charset that renders reliably. On Windows when launched
@@ -561,7 +721,7 @@ func getSparkline(history []float64) string {
 
 	priceRange := maxPrice - minPrice
 	if priceRange < 0.00000001 {
-		return strings.Repeat(" ", 14)
+		return strings.Repeat(" ", width)
 	}
 
 	// Build as runes to measure by glyph count, not bytes
@@ -575,12 +735,12 @@ func getSparkline(history []float64) string {
 		sparkRunes = append(sparkRunes, sparkChars[charIndex])
 	}
 
-	// Ensure exactly 14 glyphs (truncate keeping most recent on the right)
-	if len(sparkRunes) > 14 {
-		sparkRunes = sparkRunes[len(sparkRunes)-14:]
+	// Ensure exactly width glyphs (truncate keeping most recent on the right)
+	if len(sparkRunes) > width {
+		sparkRunes = sparkRunes[len(sparkRunes)-width:]
 	}
-	if len(sparkRunes) < 14 {
-		pad := make([]rune, 14-len(sparkRunes))
+	if len(sparkRunes) < width {
+		pad := make([]rune, width-len(sparkRunes))
 		for i := range pad {
 			pad[i] = ' '
 		}
@@ -626,14 +786,14 @@ func volatilitySpinnerColorCode(d float64) string {
 
 func (m tuiModel) volatilityForegroundColor() lipgloss.Color {
 	if m.volatilitySpinnerEnabled && m.sparklineEnabled {
-		return lipgloss.Color(volatilitySpinnerColorCode(getSparklineRange(m.history)))
+		return lipgloss.Color(volatilitySpinnerColorCode(getSparklineRange(m.volatilityHistory())))
 	}
 	return lipgloss.Color("15")
 }
 
 func (m tuiModel) applyVolatilityBackground(style lipgloss.Style) lipgloss.Style {
 	if m.volatilitySpinnerEnabled && m.sparklineEnabled {
-		return style.Background(lipgloss.Color(volatilitySpinnerColorCode(getSparklineRange(m.history))))
+		return style.Background(lipgloss.Color(volatilitySpinnerColorCode(getSparklineRange(m.volatilityHistory()))))
 	}
 	return style
 }
@@ -652,7 +812,7 @@ func spinnerStyle(m tuiModel) lipgloss.Style {
 	if !m.volatilitySpinnerEnabled || !m.sparklineEnabled {
 		return style.Foreground(lipgloss.Color("15"))
 	}
-	return style.Foreground(lipgloss.Color(volatilitySpinnerColorCode(getSparklineRange(m.history))))
+	return style.Foreground(lipgloss.Color(volatilitySpinnerColorCode(getSparklineRange(m.volatilityHistory()))))
 }
 
 func syncSpinnerStyle(m tuiModel) tuiModel {
@@ -690,18 +850,10 @@ func getSparkChars() []rune {
 	return []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
 }
 
-func playSound(frequency int, duration int) {
-	if runtime.GOOS == "windows" {
-		exec.Command("powershell", "-c", fmt.Sprintf("[console]::beep(%d, %d)", frequency, duration)).Run()
-	} else {
-		// For Linux, use a different approach
-		exec.Command("echo", "-e", "\\a").Run()
-	}
-}
-
 func handleConversion(args Args) {
-	price, err := getBtcPriceWithContext(true)
+	price, _, err := fetchPrice(true)
 	if err != nil {
+		printCreditsReset(err)
 		color.Red("Could not retrieve Bitcoin price. Cannot perform conversion.")
 		os.Exit(1)
 	}
@@ -709,7 +861,7 @@ func handleConversion(args Args) {
 	switch args.conversionMode {
 	case "bu":
 		usdValue := args.conversionVal * price
-		fmt.Printf("$%s\n", formatUSD(usdValue))
+		fmt.Println(formatMoney(usdValue))
 	case "ub":
 		if price <= 0.00000001 {
 			color.Red("Bitcoin price is too low or zero, cannot divide.")
@@ -722,11 +874,11 @@ func handleConversion(args Args) {
 			color.Red("Bitcoin price is too low or zero, cannot divide.")
 			os.Exit(1)
 		}
-		satoshiValue := (args.conversionVal / price) * 100000000
+		satoshiValue := args.conversionVal * satsPer(price)
 		fmt.Printf("%.0fs\n", satoshiValue)
 	case "su":
-		usdValue := (args.conversionVal / 100000000) * price
-		fmt.Printf("$%s\n", formatUSD(usdValue))
+		usdValue := (args.conversionVal / satsPerBTC) * price
+		fmt.Println(formatMoney(usdValue))
 	}
 }
 
@@ -749,12 +901,10 @@ func clearScreen() {
 	cmd.Run()
 }
 
-// non-Windows stubs live in console_other.go; Windows implementations live in console_windows.go
-
 // (legacy keyboard input helpers removed; Bubble Tea handles input)
 
 func printHelp() {
-	color.Yellow("Bitcoin Monitor (bmon) - Version %s (%s)", version, date)
+	color.Yellow("Bitcoin Monitor (bmon) - Version %s (%s)", version.Version, version.Date)
 	color.White("═══════════════════════════════════════════════════════════════")
 	fmt.Println()
 
@@ -779,8 +929,40 @@ func printHelp() {
 	gray.Println("# K mode (30 min, sparkline + volatility coloring)")
 	white.Print("    ./bmon -kl          ")
 	gray.Println("# K long run (30 min K, then 24 hr golong)")
+	white.Print("    ./bmon -go -above 100000 -below 90000")
+	gray.Println("# Alarm when the price crosses either")
+	white.Print("    ./bmon -exit-on-alarm")
+	gray.Println("# Quit when an alarm fires (for scripts)")
+	white.Print("    ./bmon -golong -log prices.csv")
+	gray.Println("# Append each fetched price to a CSV (.json/.jsonl for JSON lines)")
+	white.Print("    ./bmon --daemon -move 2 -below 90000")
+	gray.Println("# Headless: desktop/webhook notifications on moves and alarms")
+	white.Print("    ./bmon --serve-log /var/log/bmon/prices.csv")
+	gray.Println("# Service mode: daily-rotated price log, SIGHUP reopens, SIGTERM stops")
+	white.Print("    ./bmon --watch-above 100000 --watch-timeout 2h && echo up")
+	gray.Println("# Silent; exit 0 when reached, 2 on timeout")
+	white.Print("    ./bmon -go -big     ")
+	gray.Println("# Big ticker: full-screen block digits for a wall display")
+	white.Print("    ./bmon -adaptive    ")
+	gray.Println("# Golong with polling that speeds up when volatile [Alias -ad]")
+	white.Print("    ./bmon -go -sats    ")
+	gray.Println("# Show the price as sats per dollar")
+	white.Print("    ./bmon -pair BTC ETH")
+	gray.Println("# Both prices and the BTC/ETH ratio with its own baseline and sparkline")
+	white.Print("    ./bmon -golong -sessions")
+	gray.Println("# Add a summary row to bmon_sessions.csv on exit")
+	white.Print("    ./bmon -fiat EUR    ")
+	gray.Println("# Quote prices in another currency (EUR, GBP, JPY, ...)")
 	white.Print("    ./bmon -config      ")
 	gray.Println("# Open configuration menu")
+	white.Print("    ./bmon --version    ")
+	gray.Println("# Print version and exit")
+	white.Print("    ./bmon --check-update")
+	gray.Println("# Check GitHub for a newer release")
+	white.Print("    ./bmon --debug      ")
+	gray.Println("# Write a debug log (API calls, retries, errors)")
+	white.Print("    ./bmon -go --retries 3 --backoff 1 --timeout 5")
+	gray.Println("# Give up on a fetch sooner (defaults 5 attempts, 2s, 10s)")
 	white.Print("    ./bmon -bu 0.5      ")
 	gray.Println("# 0.5 BTC to USD")
 	white.Print("    ./bmon -ub 50000    ")
@@ -789,6 +971,8 @@ func printHelp() {
 	gray.Println("# $100 to satoshis")
 	white.Print("    ./bmon -su 1000000  ")
 	gray.Println("# 1M satoshis to USD")
+	white.Print("    ./bmon -json        ")
+	gray.Println("# Print one JSON line (price, currency, time, source) and exit")
 	fmt.Println()
 
 	color.Green("MONITORING MODES:")
@@ -819,8 +1003,30 @@ func printHelp() {
 	gray.Println("Toggle sound alerts")
 	white.Print("    H - ")
 	gray.Println("Toggle history sparkline")
+	white.Print("    B - ")
+	gray.Println("Cycle baseline: 24h ago, 1h average, trailing high, start")
+	white.Print("    T - ")
+	gray.Println("Toggle big ticker (full-screen block digits)")
+	white.Print("    D - ")
+	gray.Println("Toggle adaptive polling (5s when volatile, 20s when flat)")
+	white.Print("    Z - ")
+	gray.Println("Snooze the last alert (alarm or stale beep), 10 minutes by default")
+	white.Print("    P - ")
+	gray.Println("Toggle status bar (countdown to next fetch, session time left)")
+	white.Print("    $ - ")
+	gray.Println("Toggle sats mode (satoshis per dollar)")
+	white.Print("    C - ")
+	gray.Println("Candle view: 1-minute, then 5-minute candles, then off")
+	white.Print("    W - ")
+	gray.Println("Switch sparkline window (latest prices / last hour)")
 	white.Print("    V - ")
-	gray.Println("Toggle volatility coloring (volatility-colored spinner)")
+	gray.Println("Toggle volatility coloring (Shift+V; volatility-colored spinner)")
+	white.Print("    v - ")
+	gray.Println("Toggle σ and velocity readouts (last 5 minutes)")
+	white.Print("    F - ")
+	gray.Println("Toggle timeframes line (change vs baseline, 1h ago, 24h ago)")
+	white.Print("    A - ")
+	gray.Println("Set a price alarm (>price, <price, - to clear)")
 	fmt.Println()
 
 	color.Magenta("SPINNER COLORS (volatility coloring, go/golong/k modes, sparkline active):")
@@ -849,6 +1055,8 @@ func printHelp() {
 	yellow.Print("    • ")
 	gray.Println("Sound alerts for price movements")
 	yellow.Print("    • ")
+	gray.Println("Price alarms with auto-exit for scripts")
+	yellow.Print("    • ")
 	gray.Println("Historical price sparkline")
 	yellow.Print("    • ")
 	gray.Println("Volatility-colored spinner (volatility coloring)")
@@ -870,18 +1078,14 @@ func printHelp() {
 }
 
 // formatUSD formats a float with thousands separators and two decimals, like 116,802.19
-func formatUSD(v float64) string {
-	p := message.NewPrinter(language.English)
-	return p.Sprintf("%0.2f", v)
-}
-
 // ------------- Bubble Tea TUI -------------
 
 // tea messages
 type tickMsg struct{}
 type priceMsg struct {
-	price float64
-	err   error
+	price  float64
+	source string
+	err    error
 }
 type fetchStartMsg struct{}
 
@@ -906,19 +1110,55 @@ type tuiModel struct {
 	spinner bspinner.Model
 
 	// state
-	mode              string
-	sessionStartTime  time.Time
-	monitorStartPrice float64
-	previousPrice     float64
-	previousColor     string
-	flashUntil        time.Time
-	fetchingNow         bool
-	soundEnabled        bool
-	sparklineEnabled    bool
+	mode                     string
+	sessionStartTime         time.Time
+	monitorStartPrice        float64
+	previousPrice            float64
+	previousColor            string
+	flashUntil               time.Time
+	fetchingNow              bool
+	soundEnabled             bool
+	sparklineEnabled         bool
 	volatilitySpinnerEnabled bool
-	klLongRun           bool
-	history             []float64
-	fetchError          error // Track fetch errors to display on exit
+	klLongRun                bool
+	history                  []float64
+	hourPoints               []pricePoint // last hour of prices, for the hour sparkline window
+	sparkWindow              string
+	sparkWidth               int           // sparkline glyphs, and prices kept in history
+	samples                  []pricePoint  // prices for the candle view, up to candleKeep
+	candleWindow             time.Duration // candle size, 0 when the candle view is off
+	bigTicker                bool
+	satsMode                 bool
+	statusBar                bool
+	nextFetchAt              time.Time // when the scheduled fetch starts, for the status bar
+	adaptive                 bool      // interval follows volatility (adaptive.go)
+	readoutsEnabled          bool
+	timeframesEnabled        bool
+	dayAgoPrice              float64 // for the timeframes line; 0 when unknown
+	dayAgoAt                 time.Time
+	dayAgoPending            bool
+	lastFetchOK              time.Time // last good fetch, for the stale tag
+	staleBeeped              bool
+	baselineKind             string // kind of baseline (see baseline.go)
+	baselineSeq              int
+	baselinePending          bool
+	baselineError            string
+	stats                    sessionStats
+	priceLog                 *priceLog // -log file, nil when not logging
+	alarmAbove               float64
+	alarmBelow               float64
+	alarmUntil               time.Time // alarm flash, distinct from the price flash
+	anomalyAt                time.Time // start of the sudden-move flash
+	anomalyText              string
+	lastAlert                sound   // the alert that sounded last, for Z
+	firedAbove               float64 // alarm prices that last fired, re-armed by Z
+	firedBelow               float64
+	snoozeAlarmUntil         time.Time
+	snoozeStaleUntil         time.Time
+	alarmFired               string // last alarm message, shown until the next one
+	alarmEditing             bool
+	alarmInput               string
+	alarmError               string
 }
 
 func newTUIModel(args Args) tuiModel {
@@ -926,14 +1166,21 @@ func newTUIModel(args Args) tuiModel {
 	sp.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("15")) // white by default
 
 	m := tuiModel{
-		args:             args,
-		spinner:          sp,
-		soundEnabled:        args.sound,
-		sparklineEnabled:    args.sparkline || args.kMode || args.klMode,
+		args:                     args,
+		spinner:                  sp,
+		soundEnabled:             args.sound,
+		sparklineEnabled:         args.sparkline || args.kMode || args.klMode,
 		volatilitySpinnerEnabled: args.volatilitySpinner || args.kMode || args.klMode,
-		klLongRun:           args.klMode,
-		history:             []float64{},
-		previousColor:    "White",
+		klLongRun:                args.klMode,
+		alarmAbove:               args.alarmAbove,
+		alarmBelow:               args.alarmBelow,
+		history:                  []float64{},
+		sparkWindow:              sparkWindowSession,
+		sparkWidth:               sparkPoints,
+		bigTicker:                args.bigTicker,
+		satsMode:                 args.satsMode,
+		adaptive:                 args.adaptive,
+		previousColor:            "White",
 	}
 	// choose start mode (prioritize k/kl, then golong, then go) and set spinner accordingly
 	if args.kMode || args.klMode {
@@ -955,9 +1202,16 @@ func newTUIModel(args Args) tuiModel {
 	if currentBtcPrice > 0 {
 		m.monitorStartPrice = currentBtcPrice
 		m.previousPrice = currentBtcPrice
-		m.history = append(m.history, currentBtcPrice)
+		m.history = seedSparkline(seedHistory, currentBtcPrice)
+		m.hourPoints = append(append([]pricePoint{}, seedHistory...), pricePoint{at: time.Now(), price: currentBtcPrice})
+		m.samples = append([]pricePoint{}, m.hourPoints...)
 	}
 	m.sessionStartTime = time.Now()
+	m.lastFetchOK = time.Now()
+	if m.mode != modeLanding {
+		m.nextFetchAt = time.Now().Add(m.currentInterval()) // Init schedules the first fetch
+		m.stats.start(currentBtcPrice)
+	}
 	return m
 }
 
@@ -982,6 +1236,9 @@ func (m tuiModel) Init() tea.Cmd {
 }
 
 func (m tuiModel) currentInterval() time.Duration {
+	if m.adaptive && m.mode != modeLanding {
+		return m.adaptiveInterval()
+	}
 	switch m.mode {
 	case modeGo:
 		return 5 * time.Second
@@ -1017,8 +1274,8 @@ func tickEvery(d time.Duration) tea.Cmd {
 
 func fetchPriceCmd() tea.Cmd {
 	return func() tea.Msg {
-		p, err := getBtcPrice()
-		return priceMsg{price: p, err: err}
+		p, source, err := getBtcPrice()
+		return priceMsg{price: p, source: source, err: err}
 	}
 }
 
@@ -1038,8 +1295,13 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
+		m.sparkWidth = sparkWidthFor(msg.Width)
+		m.history = lastPrices(m.samples, m.sparkWidth)
 
 	case tea.KeyMsg:
+		if m.alarmEditing && msg.String() != "ctrl+c" {
+			return syncSpinnerStyle(m.handleAlarmKey(msg)), tea.Batch(cmds...)
+		}
 		switch msg.String() {
 		case "ctrl+c", "esc":
 			return syncSpinnerStyle(m), tea.Quit
@@ -1053,7 +1315,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				// Audio feedback: brief beep if sound is enabled
 				if m.soundEnabled {
-					playSound(800, 200)
+					notify.Beep(800, 200)
 				}
 			}
 		case "up":
@@ -1063,7 +1325,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.sparklineEnabled = true
 				m.volatilitySpinnerEnabled = true
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 				// Update spinner for k mode
 				m.spinner.Spinner = bspinner.Spinner{Frames: []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉", "█", "▉", "▊", "▋", "▌", "▍", "▎"}, FPS: 500 * time.Millisecond}
 				cmds = append(cmds, m.spinner.Tick)
@@ -1071,7 +1333,8 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "right":
 			// Right arrow is alias for R
 			if m.mode == modeGo || m.mode == modeGoLong || m.mode == modeK || m.mode == modeInteractive {
-				m.monitorStartPrice = currentBtcPrice
+				m.baselineKind = baselineStart
+				m.resetBaseline()
 				m.sessionStartTime = time.Now()
 			}
 		case "down":
@@ -1086,7 +1349,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.spinner.Spinner = bspinner.Spinner{Frames: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}, FPS: 500 * time.Millisecond}
 				}
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 				cmds = append(cmds, m.spinner.Tick)
 			}
 		case " ":
@@ -1094,8 +1357,9 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case modeLanding:
 				m.mode = modeInteractive
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 				m.previousPrice = currentBtcPrice
+				m.lastFetchOK = time.Now() // not stale while landing
 				cmds = append(cmds, fetchPriceCmd())
 			case modeInteractive:
 				// pause/return to landing
@@ -1111,20 +1375,23 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				// Audio feedback: brief beep if sound is enabled
 				if m.soundEnabled {
-					playSound(800, 200)
+					notify.Beep(800, 200)
 				}
 			}
 		case "g":
 			if m.mode == modeLanding {
 				m.mode = modeGo
+				m.stats.start(currentBtcPrice)
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 				m.previousPrice = currentBtcPrice
+				m.lastFetchOK = time.Now() // not stale while landing
 				cmds = append(cmds, fetchPriceCmd())
 			}
 		case "r":
 			if m.mode == modeGo || m.mode == modeGoLong || m.mode == modeK || m.mode == modeInteractive {
-				m.monitorStartPrice = currentBtcPrice
+				m.baselineKind = baselineStart
+				m.resetBaseline()
 				m.sessionStartTime = time.Now()
 			}
 		case "k", "K":
@@ -1134,7 +1401,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.sparklineEnabled = true
 				m.volatilitySpinnerEnabled = true
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 				// Update spinner for k mode
 				m.spinner.Spinner = bspinner.Spinner{Frames: []string{"▏", "▎", "▍", "▌", "▋", "▊", "▉", "█", "▉", "▊", "▋", "▌", "▍", "▎"}, FPS: 500 * time.Millisecond}
 				cmds = append(cmds, m.spinner.Tick)
@@ -1150,19 +1417,56 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.spinner.Spinner = bspinner.Spinner{Frames: []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}, FPS: 500 * time.Millisecond}
 				}
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 				cmds = append(cmds, m.spinner.Tick)
 			}
 		case "s":
 			m.soundEnabled = !m.soundEnabled
 			if m.soundEnabled {
-				playSound(1200, 350)
+				notify.Beep(1200, 350)
 			} else {
-				playSound(400, 350)
+				notify.Beep(400, 350)
 			}
 		case "h":
 			m.sparklineEnabled = !m.sparklineEnabled
-		case "v", "V":
+		case "z", "Z":
+			m.toggleSnooze()
+		case "b", "B":
+			if m.mode != modeLanding {
+				if cmd := m.cycleBaseline(); cmd != nil {
+					cmds = append(cmds, cmd)
+				}
+			}
+		case "t", "T":
+			m.bigTicker = !m.bigTicker
+		case "$":
+			m.satsMode = !m.satsMode
+		case "p", "P":
+			m.statusBar = !m.statusBar
+		case "d", "D":
+			if m.mode != modeLanding {
+				m.adaptive = !m.adaptive
+			}
+		case "c", "C":
+			if m.mode != modeLanding {
+				m.candleWindow = nextCandleWindow(m.candleWindow)
+			}
+		case "w", "W":
+			if m.sparkWindow == sparkWindowHour {
+				m.sparkWindow = sparkWindowSession
+			} else {
+				m.sparkWindow = sparkWindowHour
+			}
+		case "a", "A":
+			m.alarmEditing = true
+		case "v":
+			m.readoutsEnabled = !m.readoutsEnabled
+		case "f", "F":
+			m.timeframesEnabled = !m.timeframesEnabled
+			if cmd := m.refreshDayAgo(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+		case "V":
 			if m.mode == modeGo || m.mode == modeGoLong || m.mode == modeK || m.mode == modeInteractive {
 				m.volatilitySpinnerEnabled = !m.volatilitySpinnerEnabled
 			}
@@ -1170,10 +1474,14 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.mode == modeGo || m.mode == modeGoLong || m.mode == modeK {
 				m.mode = modeInteractive
 				m.sessionStartTime = time.Now()
-				m.monitorStartPrice = currentBtcPrice
+				m.resetBaseline()
 			}
 		}
 
+	case anomalyFrameMsg:
+		if cmd := m.anomalyFrameStep(); cmd != nil {
+			cmds = append(cmds, cmd)
+		}
 	case tickMsg:
 		// periodic maintenance: duration checks
 		// end-of-session logic
@@ -1187,7 +1495,7 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.klLongRun {
 					m.mode = modeGoLong
 					m.sessionStartTime = time.Now()
-					m.monitorStartPrice = currentBtcPrice
+					m.resetBaseline()
 					m.spinner.Spinner = bspinner.Spinner{Frames: []string{"▚", "▚", "▚", "▚", "▚", "▚", "▞", "▞", "▞", "▞", "▞", "▞"}, FPS: 500 * time.Millisecond}
 					cmds = append(cmds, m.spinner.Tick)
 				} else {
@@ -1197,38 +1505,66 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return syncSpinnerStyle(m), tea.Quit
 			}
 		}
+		m.checkStale()
 		// schedule next UI tick
 		cmds = append(cmds, tickEvery(500*time.Millisecond))
 
+	case baselineMsg:
+		m.applyBaseline(msg)
+
+	case dayAgoMsg:
+		m.applyDayAgo(msg)
+
 	case fetchStartMsg:
 		m.fetchingNow = true
 		cmds = append(cmds, fetchPriceCmd())
 
 	case priceMsg:
 		if msg.err != nil {
-			// After all retries failed, store error and exit
 			m.fetchingNow = false
-			m.fetchError = msg.err
 			clearRetryIndicator()
-			// Exit TUI - error will be displayed after exit
-			return syncSpinnerStyle(m), tea.Quit
+			// All retries failed: keep the last good price on screen, where
+			// the stale tag (stale.go) shows its age, and try again later.
+			slog.Warn("price fetch failed, keeping last price", "err", msg.err, "last_ok", m.lastFetchOK)
+			m.nextFetchAt = time.Now().Add(m.currentInterval())
+			return syncSpinnerStyle(m), tea.Batch(append(cmds, fetchPriceCmdAfter(m.currentInterval()))...)
 		}
 		if msg.price > 0 {
 			newPrice := msg.price
 			// sound cues
 			if m.soundEnabled {
 				if newPrice >= currentBtcPrice+0.01 {
-					playSound(1200, 150)
+					playSound(soundUpTick)
 				} else if newPrice <= currentBtcPrice-0.01 {
-					playSound(400, 150)
+					playSound(soundDownTick)
 				}
 			}
 			currentBtcPrice = newPrice
+			currentPriceSource = msg.source
+			m.lastFetchOK = time.Now()
+			m.stats.add(newPrice)
+			if m.baselineKind == baselineTrailing && newPrice > m.monitorStartPrice {
+				m.monitorStartPrice = newPrice
+			}
+			if m.priceLog != nil {
+				sample := priceSample{Time: time.Now(), Price: newPrice, Change: newPrice - m.monitorStartPrice, Mode: m.mode}
+				if err := m.priceLog.Write(sample); err != nil {
+					// keep monitoring; stop logging rather than fail every fetch
+					slog.Error("price log write failed, logging stopped", "path", m.args.priceLogPath, "err", err)
+					m.priceLog.Close()
+					m.priceLog = nil
+				}
+			}
 			// history
 			m.history = append(m.history, newPrice)
-			if len(m.history) > 14 {
+			if len(m.history) > m.sparkWidth {
 				m.history = m.history[1:]
 			}
+			m.hourPoints = trimHourPoints(append(m.hourPoints, pricePoint{at: time.Now(), price: newPrice}), time.Now())
+			m.samples = addSample(m.samples, pricePoint{at: time.Now(), price: newPrice})
+			if cmd := m.checkAnomaly(m.previousPrice, newPrice); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 			// flash logic
 			priceChange := newPrice - m.monitorStartPrice
 			priceColor := "White"
@@ -1249,7 +1585,17 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			m.previousPrice = newPrice
 			m.previousColor = priceColor
+			if cmd, quit := m.alarmCheck(newPrice); quit {
+				m.fetchingNow = false
+				return syncSpinnerStyle(m), tea.Quit
+			} else if cmd != nil {
+				cmds = append(cmds, cmd)
+			}
+			if cmd := m.refreshDayAgo(); cmd != nil {
+				cmds = append(cmds, cmd)
+			}
 			// schedule next fetch
+			m.nextFetchAt = time.Now().Add(m.currentInterval())
 			cmds = append(cmds, fetchPriceCmdAfter(m.currentInterval()))
 		}
 		m.fetchingNow = false
@@ -1259,10 +1605,21 @@ func (m tuiModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m tuiModel) View() string {
+	if m.bigTicker && m.mode != modeLanding {
+		return m.bigTickerView()
+	}
+	if m.candleWindow > 0 && m.mode != modeLanding {
+		return m.candleChart() + "\n" + m.priceView()
+	}
+	return m.priceView()
+}
+
+// priceView is the screen without the candle view.
+func (m tuiModel) priceView() string {
 	// landing view
 	if m.mode == modeLanding {
 		title := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render("*** BTC Monitor ***") // yellow
-		priceLine := fmt.Sprintf("Bitcoin (USD): $%s", formatUSD(currentBtcPrice))
+		priceLine := fmt.Sprintf("Bitcoin (%s): %s", fiat.Code, m.priceText())
 		controls := lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Render("Start[") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render("Space") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Render("], Go Mode[") +
@@ -1271,7 +1628,10 @@ func (m tuiModel) View() string {
 			lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render("Ctrl+C") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Render("]")
 		prompt := "Press Space to start monitoring..."
-		return strings.Join([]string{title, priceLine, controls, prompt}, "\n")
+		if m.alarmEditing {
+			prompt = m.alarmPrompt()
+		}
+		return strings.Join([]string{title, priceLine + m.sourceTag() + m.adaptiveLabel() + m.staleLabel() + m.baselineLabel() + m.alarmLabel() + m.snoozeLabel() + retryDetailLabel(), controls, prompt}, "\n")
 	}
 
 	// interactive mode view - multi-line like PS version
@@ -1284,24 +1644,28 @@ func (m tuiModel) View() string {
 		changeString := ""
 		if priceChange >= 0.01 {
 			priceColor = lipgloss.Color("2") // green
-			changeString = fmt.Sprintf(" [+$%0.2f]", priceChange)
+			changeString = " [" + m.changeText(priceChange) + "]"
 		} else if priceChange <= -0.01 {
 			priceColor = lipgloss.Color("1") // red
-			changeString = fmt.Sprintf(" [$%0.2f]", priceChange)
+			changeString = " [" + m.changeText(priceChange) + "]"
 		}
 
 		var sparklineOrLabel string
 		if m.sparklineEnabled {
-			sparklineOrLabel = getSparkline(m.history)
+			sparklineOrLabel = getSparkline(m.sparkData(), m.sparkWidth)
 		} else {
-			sparklineOrLabel = "Bitcoin (USD):"
+			sparklineOrLabel = "Bitcoin (" + fiat.Code + "):"
 		}
 
-		priceLine := fmt.Sprintf("%s $%s%s", sparklineOrLabel, formatUSD(currentBtcPrice), changeString)
+		priceLine := fmt.Sprintf("%s %s%s", sparklineOrLabel, m.priceText(), changeString)
 
 		// Apply color and flash effect
 		var styledPriceLine string
-		if time.Now().Before(m.flashUntil) && (priceChange >= 0.01 || priceChange <= -0.01) {
+		if time.Now().Before(m.alarmUntil) {
+			styledPriceLine = lipgloss.NewStyle().Background(lipgloss.Color("11")).Foreground(lipgloss.Color("0")).Render(priceLine)
+		} else if m.anomalyOn() {
+			styledPriceLine = anomalyStyle().Render(priceLine)
+		} else if time.Now().Before(m.flashUntil) && (priceChange >= 0.01 || priceChange <= -0.01) {
 			// Inverted colors for flash
 			styledPriceLine = lipgloss.NewStyle().Background(priceColor).Foreground(lipgloss.Color("0")).Render(priceLine)
 		} else {
@@ -1316,7 +1680,25 @@ func (m tuiModel) View() string {
 			lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render("Ctrl+C") +
 			lipgloss.NewStyle().Foreground(lipgloss.Color("15")).Render("]")
 
-		return strings.Join([]string{title, styledPriceLine, controls}, "\n")
+		lines := []string{title, styledPriceLine + m.sourceTag() + m.adaptiveLabel() + m.staleLabel() + m.baselineLabel() + m.alarmLabel() + m.snoozeLabel() + retryDetailLabel()}
+		if m.readoutsEnabled {
+			lines = append(lines, m.readoutsLine())
+		}
+		if m.timeframesEnabled {
+			lines = append(lines, m.timeframesLine())
+		}
+		if m.statusBar {
+			lines = append(lines, m.statusLine())
+		}
+		lines = append(lines, controls)
+		if m.alarmEditing {
+			lines = append(lines, m.alarmPrompt())
+		} else if m.alarmFired != "" {
+			lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Render(m.alarmFired))
+		} else if m.anomalyText != "" {
+			lines = append(lines, lipgloss.NewStyle().Foreground(anomalyColor).Render(m.anomalyText))
+		}
+		return strings.Join(lines, "\n")
 	}
 
 	// go/golong mode views (single-line)
@@ -1325,27 +1707,31 @@ func (m tuiModel) View() string {
 	changeString := ""
 	if priceChange >= 0.01 {
 		priceColor = "Green"
-		changeString = fmt.Sprintf(" [+$%0.2f]", priceChange)
+		changeString = " [" + m.changeText(priceChange) + "]"
 	} else if priceChange <= -0.01 {
 		priceColor = "Red"
-		changeString = fmt.Sprintf(" [$%0.2f]", priceChange)
+		changeString = " [" + m.changeText(priceChange) + "]"
 	}
 
 	var left string
 	if m.sparklineEnabled {
 		// simple unicode sparkline to match PS feel, relying on VT support
-		left = " " + getSparkline(m.history)
+		left = " " + getSparkline(m.sparkData(), m.sparkWidth)
 	} else {
-		left = " Bitcoin (USD):"
+		left = " Bitcoin (" + fiat.Code + "):"
 	}
 
 	spinnerChar := m.renderSpinnerChar()
 
-	rest := fmt.Sprintf("%s $%s%s", left, formatUSD(currentBtcPrice), changeString)
+	rest := fmt.Sprintf("%s %s%s", left, m.priceText(), changeString)
 
 	// colorize/invert
 	var styledRest string
-	if time.Now().Before(m.flashUntil) && (priceColor == "Green" || priceColor == "Red") {
+	if time.Now().Before(m.alarmUntil) {
+		styledRest = lipgloss.NewStyle().Background(lipgloss.Color("11")).Foreground(lipgloss.Color("0")).Render(rest)
+	} else if m.anomalyOn() {
+		styledRest = anomalyStyle().Render(rest)
+	} else if time.Now().Before(m.flashUntil) && (priceColor == "Green" || priceColor == "Red") {
 		bg := lipgloss.Color("2") // green
 		if priceColor == "Red" {
 			bg = lipgloss.Color("1")
@@ -1362,7 +1748,11 @@ func (m tuiModel) View() string {
 		}
 	}
 
-	line := spinnerChar + styledRest
+	line := spinnerChar + styledRest + m.sourceTag() + m.adaptiveLabel() + m.staleLabel() + m.baselineLabel() + m.alarmLabel() + m.snoozeLabel() + retryDetailLabel()
+	if m.alarmEditing {
+		// the prompt takes the line while it is open
+		line = " " + m.alarmPrompt()
+	}
 	// pad to width
 	if m.width > 0 {
 		pad := m.width - lipgloss.Width(line)
@@ -1370,20 +1760,48 @@ func (m tuiModel) View() string {
 			line += strings.Repeat(" ", pad)
 		}
 	}
+	if m.readoutsEnabled {
+		line += "\n" + m.readoutsLine()
+	}
+	if m.timeframesEnabled {
+		line += "\n" + m.timeframesLine()
+	}
+	if m.statusBar {
+		line += "\n" + m.statusLine()
+	}
 	return line + "\n"
 }
 
 func runTUI(args Args) {
 	m := newTUIModel(args)
+	if args.priceLogPath != "" {
+		pl, err := openPriceLog(args.priceLogPath)
+		if err != nil {
+			slog.Error("price log open failed", "path", args.priceLogPath, "err", err)
+			color.Red("Could not open price log %s: %v", args.priceLogPath, err)
+			os.Exit(1)
+		}
+		defer pl.Close()
+		m.priceLog = pl
+	}
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	finalModelInterface, _ := p.Run()
-	// Type assert to tuiModel to access fetchError field
+	// Type assert to tuiModel to reach the session stats
 	finalModel, ok := finalModelInterface.(tuiModel)
 	// Clear screen on exit
 	clearScreen()
-	// If there was a fetch error, show error message
-	if ok && finalModel.fetchError != nil {
-		color.Red("Failed to fetch price. Check API key or network.")
-		os.Exit(1)
+	if ok {
+		finalModel.stats.print()
+		if args.recordSessions || settings.Settings.SessionCSV {
+			if err := finalModel.stats.recordSession(); err != nil {
+				slog.Error("session not recorded", "err", err)
+				color.Red("Could not record the session: %v", err)
+			}
+		}
+	}
+	// -exit-on-alarm quits as the alarm fires; sound and report it here
+	if ok && args.exitOnAlarm && finalModel.alarmFired != "" {
+		playSound(soundAlarm)
+		color.Yellow(finalModel.alarmFired)
 	}
 }
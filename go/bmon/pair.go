@@ -0,0 +1,250 @@
+package bmon
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+)
+
+// Pair mode (-pair BTC ETH) follows two coins and the ratio of their
+// prices, how many of the second one of the first buys, to track relative
+// strength. The ratio has its own baseline, change coloring and sparkline;
+// R resets the baseline. Both prices come from one LiveCoinWatch coins/map
+// request per update (one credit), or from CoinGecko while LiveCoinWatch is
+// failing. A failed update is shown and retried at the next interval.
+const defaultPairInterval = 20 * time.Second
+
+type pairQuote struct {
+	a, b   float64
+	source string
+}
+
+func (q pairQuote) ratio() float64 {
+	if q.b <= 0 {
+		return 0
+	}
+	return q.a / q.b
+}
+
+type pairMsg struct {
+	quote pairQuote
+	err   error
+}
+
+type pairTickMsg struct{}
+
+// fetchPair returns the prices of coins a and b in the -fiat currency.
+func fetchPair(a, b string) (pairQuote, error) {
+	if err := primarySkipped(); err == nil {
+		q, err := liveCoinWatchPair(a, b)
+		if err == nil {
+			clearRetryIndicator()
+			return q, nil
+		}
+		slog.Warn("pair source failed", "source", "LiveCoinWatch", "err", err)
+		skipPrimary(err)
+	}
+	return coinGeckoPair(a, b)
+}
+
+func liveCoinWatchPair(a, b string) (pairQuote, error) {
+	var entries []struct {
+		Code string  `json:"code"`
+		Rate float64 `json:"rate"`
+	}
+	err := liveCoinWatchClient(priceFetchAttempts).Post(context.Background(), "coins/map", map[string]interface{}{
+		"currency": fiat.Code,
+		"codes":    []string{a, b},
+		"sort":     "rank",
+		"order":    "ascending",
+		"offset":   0,
+		"limit":    0,
+		"meta":     false,
+	}, &entries)
+	if err != nil {
+		return pairQuote{}, err
+	}
+	q := pairQuote{source: "LiveCoinWatch"}
+	for _, e := range entries {
+		switch strings.ToUpper(e.Code) {
+		case a:
+			q.a = e.Rate
+		case b:
+			q.b = e.Rate
+		}
+	}
+	return q, checkPairQuote(q, a, b)
+}
+
+func coinGeckoPair(a, b string) (pairQuote, error) {
+	var markets []struct {
+		Symbol       string  `json:"symbol"`
+		CurrentPrice float64 `json:"current_price"`
+	}
+	url := "https://api.coingecko.com/api/v3/coins/markets?vs_currency=" + strings.ToLower(fiat.Code) +
+		"&order=market_cap_desc&symbols=" + strings.ToLower(a+","+b)
+	if err := getJSON(url, &markets); err != nil {
+		return pairQuote{}, err
+	}
+	q := pairQuote{source: "CoinGecko"}
+	// ordered by market cap, so the first match is the coin people mean
+	for _, m := range markets {
+		switch strings.ToUpper(m.Symbol) {
+		case a:
+			if q.a == 0 {
+				q.a = m.CurrentPrice
+			}
+		case b:
+			if q.b == 0 {
+				q.b = m.CurrentPrice
+			}
+		}
+	}
+	return q, checkPairQuote(q, a, b)
+}
+
+func checkPairQuote(q pairQuote, a, b string) error {
+	if q.a <= 0 {
+		return fmt.Errorf("no price for %s", a)
+	}
+	if q.b <= 0 {
+		return fmt.Errorf("no price for %s", b)
+	}
+	return nil
+}
+
+func fetchPairCmd(a, b string) tea.Cmd {
+	return func() tea.Msg {
+		q, err := fetchPair(a, b)
+		return pairMsg{quote: q, err: err}
+	}
+}
+
+func pairTickAfter(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg { return pairTickMsg{} })
+}
+
+type pairModel struct {
+	a, b       string
+	interval   time.Duration
+	quote      pairQuote
+	baseline   float64
+	history    []float64
+	width      int
+	sparkWidth int
+	err        error // last failed update, cleared by the next good one
+}
+
+func (m pairModel) Init() tea.Cmd {
+	return pairTickAfter(m.interval)
+}
+
+func (m pairModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.sparkWidth = sparkWidthFor(msg.Width)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc":
+			return m, tea.Quit
+		case "r", "R":
+			m.baseline = m.quote.ratio()
+		}
+	case pairTickMsg:
+		return m, fetchPairCmd(m.a, m.b)
+	case pairMsg:
+		if msg.err != nil {
+			slog.Error("pair fetch failed", "err", msg.err)
+			m.err = msg.err
+		} else {
+			m.err = nil
+			m.quote = msg.quote
+			m.history = append(m.history, msg.quote.ratio())
+			if len(m.history) > sparkMaxPoints {
+				m.history = m.history[1:]
+			}
+		}
+		return m, pairTickAfter(m.interval)
+	}
+	return m, nil
+}
+
+func (m pairModel) View() string {
+	ratio := m.quote.ratio()
+	change := ratio - m.baseline
+	style := lipgloss.NewStyle()
+	changeText := ""
+	if m.baseline > 0 && math.Abs(change)/m.baseline >= 0.00005 {
+		style = style.Foreground(lipgloss.Color("2"))
+		if change < 0 {
+			style = style.Foreground(lipgloss.Color("1"))
+		}
+		sign := "+"
+		if change < 0 {
+			sign = ""
+		}
+		changeText = fmt.Sprintf(" [%s%s %+.2f%%]", sign, formatRatio(change), change/m.baseline*100)
+	}
+	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+
+	line := " " + getSparkline(m.history, m.sparkWidth) + " " + style.Render(m.a+"/"+m.b+" "+formatRatio(ratio)+changeText)
+	prices := " " + m.a + " " + formatMoney(m.quote.a) + gray.Render(" · ") + m.b + " " + formatMoney(m.quote.b)
+	if short := priceSourceShort(m.quote.source); short != "" {
+		prices += gray.Render(" [" + short + "]")
+	}
+	lines := []string{line, prices}
+	if m.err != nil {
+		lines = append(lines, lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render(" Update failed, retrying: "+m.err.Error()))
+	}
+	lines = append(lines, gray.Render(" Reset[R] Exit[Ctrl+C]"))
+	return strings.Join(lines, "\n")
+}
+
+// formatRatio writes r with enough decimals to show small moves, e.g.
+// 27.4132 or 0.036481.
+func formatRatio(r float64) string {
+	switch a := math.Abs(r); {
+	case a >= 1000:
+		return fmt.Sprintf("%.2f", r)
+	case a >= 1:
+		return fmt.Sprintf("%.4f", r)
+	default:
+		return fmt.Sprintf("%.6f", r)
+	}
+}
+
+// runPair runs pair mode until Ctrl+C.
+func runPair(args Args) {
+	a, b := strings.ToUpper(args.pairA), strings.ToUpper(args.pairB)
+	interval := args.daemonInterval
+	if interval <= 0 {
+		interval = defaultPairInterval
+	}
+	if interval < minDaemonInterval {
+		interval = minDaemonInterval
+	}
+
+	color.Cyan("Fetching %s and %s...", a, b)
+	q, err := fetchPair(a, b)
+	if err != nil {
+		printCreditsReset(err)
+		color.Red("Could not get %s/%s prices: %v", a, b, err)
+		os.Exit(1)
+	}
+	slog.Info("pair mode started", "a", a, "b", b, "interval", interval, "ratio", q.ratio())
+
+	m := pairModel{a: a, b: b, interval: interval, quote: q, baseline: q.ratio(), history: []float64{q.ratio()}, sparkWidth: sparkPoints}
+	if _, err := tea.NewProgram(m, tea.WithAltScreen()).Run(); err != nil {
+		slog.Error("pair TUI failed", "err", err)
+	}
+	clearScreen()
+}
@@ -0,0 +1,82 @@
+package bmon
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// priceLog appends every fetched price to the -log file, so long sessions
+// leave data to analyze. A .json or .jsonl file gets one JSON object per
+// line; anything else gets CSV with a header row. Each write goes straight
+// to the file, so nothing is lost if bmon is killed.
+type priceLog struct {
+	file  *os.File
+	csv   *csv.Writer
+	jsonl bool
+}
+
+// priceSample is one logged fetch. Change is against the session baseline
+// (the price at start or at the last reset).
+type priceSample struct {
+	Time   time.Time `json:"time"`
+	Price  float64   `json:"price"`
+	Change float64   `json:"change"`
+	Mode   string    `json:"mode"`
+}
+
+var priceLogHeader = []string{"time", "price", "change", "mode"}
+
+// openPriceLog opens path for appending, writing the CSV header when the file
+// is new or empty.
+func openPriceLog(path string) (*priceLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	ext := strings.ToLower(filepath.Ext(path))
+	l := &priceLog{file: f, jsonl: ext == ".json" || ext == ".jsonl"}
+	if !l.jsonl {
+		l.csv = csv.NewWriter(f)
+		if info, err := f.Stat(); err == nil && info.Size() == 0 {
+			if err := l.writeCSV(priceLogHeader); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	return l, nil
+}
+
+func (l *priceLog) writeCSV(record []string) error {
+	l.csv.Write(record)
+	l.csv.Flush()
+	return l.csv.Error()
+}
+
+// Write appends one sample.
+func (l *priceLog) Write(s priceSample) error {
+	if l.jsonl {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(l.file, string(data)+"\n")
+		return err
+	}
+	return l.writeCSV([]string{
+		s.Time.Format(time.RFC3339),
+		strconv.FormatFloat(s.Price, 'f', 2, 64),
+		strconv.FormatFloat(s.Change, 'f', 2, 64),
+		s.Mode,
+	})
+}
+
+func (l *priceLog) Close() error {
+	return l.file.Close()
+}
@@ -0,0 +1,198 @@
+package bmon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fatih/color"
+	"kreftus/shared/lcw"
+)
+
+// Prices come from LiveCoinWatch. When it fails after its retries, or its
+// daily credits run out, the price is taken from CoinGecko and then Coinbase,
+// which need no API key. LiveCoinWatch is skipped for a while after a failure
+// (until midnight UTC when out of credits) so each fetch does not sit
+// through its retries again. The TUI tags a fallback price with its source.
+const liveCoinWatchCooldown = 5 * time.Minute
+
+// errCreditsExhausted is returned by LiveCoinWatch when the daily credits are used up.
+var errCreditsExhausted = lcw.ErrCreditsExhausted
+
+// LiveCoinWatch price fetches wait longer before giving up than one-off
+// calls (the key check and history), since the screen keeps the last price
+// meanwhile. See applyFetchTuning for the flags that change these.
+var (
+	priceFetchAttempts   = 5
+	priceRetryBaseDelay  = 2 * time.Second
+	liveCoinWatchTimeout = 10 * time.Second
+)
+
+// liveCoinWatchClient returns a client for apiKey making up to attempts
+// tries per request.
+func liveCoinWatchClient(attempts int) *lcw.Client {
+	return &lcw.Client{
+		APIKey:    apiKey,
+		Attempts:  attempts,
+		BaseDelay: priceRetryBaseDelay,
+		Timeout:   liveCoinWatchTimeout,
+	}
+}
+
+type priceProvider interface {
+	Name() string
+	Short() string // tag shown next to a fallback price
+	Price(isInitialFetch bool) (float64, error)
+}
+
+type liveCoinWatchProvider struct{}
+
+func (liveCoinWatchProvider) Name() string  { return "LiveCoinWatch" }
+func (liveCoinWatchProvider) Short() string { return "LCW" }
+func (liveCoinWatchProvider) Price(isInitialFetch bool) (float64, error) {
+	return getLiveCoinWatchPrice(isInitialFetch)
+}
+
+type coinGeckoProvider struct{}
+
+func (coinGeckoProvider) Name() string  { return "CoinGecko" }
+func (coinGeckoProvider) Short() string { return "CG" }
+func (coinGeckoProvider) Price(bool) (float64, error) {
+	code := strings.ToLower(fiat.Code)
+	var resp map[string]map[string]float64
+	if err := getJSON("https://api.coingecko.com/api/v3/simple/price?ids=bitcoin&vs_currencies="+code, &resp); err != nil {
+		return 0, err
+	}
+	return resp["bitcoin"][code], nil
+}
+
+type coinbaseProvider struct{}
+
+func (coinbaseProvider) Name() string  { return "Coinbase" }
+func (coinbaseProvider) Short() string { return "CB" }
+func (coinbaseProvider) Price(bool) (float64, error) {
+	var resp struct {
+		Data struct {
+			Amount string `json:"amount"`
+		} `json:"data"`
+	}
+	if err := getJSON("https://api.coinbase.com/v2/prices/BTC-"+fiat.Code+"/spot", &resp); err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(resp.Data.Amount, 64)
+}
+
+// priceProviders lists the sources in the order they are tried.
+var priceProviders = []priceProvider{liveCoinWatchProvider{}, coinGeckoProvider{}, coinbaseProvider{}}
+
+var (
+	primaryMu        sync.Mutex
+	primaryRetryFrom time.Time // LiveCoinWatch is skipped until then
+	primaryErr       error     // why it is skipped
+)
+
+// fetchPrice returns the BTC price and the name of the source it came from.
+// If every source fails it returns the LiveCoinWatch error.
+func fetchPrice(isInitialFetch bool) (float64, string, error) {
+	var firstErr error
+	for i, p := range priceProviders {
+		if i == 0 {
+			if err := primarySkipped(); err != nil {
+				firstErr = err
+				continue
+			}
+		}
+		price, err := p.Price(isInitialFetch)
+		if err == nil && price <= 0 {
+			err = fmt.Errorf("invalid price returned")
+		}
+		if err == nil {
+			if i > 0 {
+				clearRetryIndicator()
+			}
+			return price, p.Name(), nil
+		}
+		slog.Warn("price source failed", "source", p.Name(), "err", err)
+		if i == 0 {
+			firstErr = err
+			skipPrimary(err)
+		}
+	}
+	return 0, "", firstErr
+}
+
+// primarySkipped returns the error that LiveCoinWatch is being skipped for,
+// or nil if it should be tried.
+func primarySkipped() error {
+	primaryMu.Lock()
+	defer primaryMu.Unlock()
+	if time.Now().Before(primaryRetryFrom) {
+		return primaryErr
+	}
+	return nil
+}
+
+func skipPrimary(err error) {
+	until := time.Now().Add(liveCoinWatchCooldown)
+	if errors.Is(err, errCreditsExhausted) {
+		now := time.Now().UTC()
+		until = time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	primaryMu.Lock()
+	primaryRetryFrom, primaryErr = until, err
+	primaryMu.Unlock()
+	slog.Info("falling back from LiveCoinWatch", "until", until)
+}
+
+// sourceTag marks a price taken from a fallback source, e.g. " [CG]".
+func (m tuiModel) sourceTag() string {
+	short := priceSourceShort(currentPriceSource)
+	if short == "" {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(" [" + short + "]")
+}
+
+// priceSourceShort returns the tag for source, or "" for LiveCoinWatch.
+func priceSourceShort(source string) string {
+	for i, p := range priceProviders {
+		if i > 0 && p.Name() == source {
+			return p.Short()
+		}
+	}
+	return ""
+}
+
+// printCreditsReset says when LiveCoinWatch credits renew if err is because
+// they ran out.
+func printCreditsReset(err error) {
+	if errors.Is(err, errCreditsExhausted) {
+		color.Red("API Credits reset in: %s", timeUntilMidnightUTC())
+	}
+}
+
+// getJSON fetches url once and decodes its JSON body into v.
+func getJSON(url string, v interface{}) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, v)
+}
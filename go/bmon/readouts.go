@@ -0,0 +1,77 @@
+package bmon
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The readouts line (v) shows how jumpy and how fast the price is over the
+// last readoutWindow: the standard deviation of the prices and the velocity
+// in price per minute. The velocity turns green or red once it reaches
+// [Display] VelocityThreshold in bmon.ini.
+const readoutWindow = 5 * time.Minute
+
+// windowPoints returns the points within window of the newest one.
+func windowPoints(points []pricePoint, window time.Duration) []pricePoint {
+	if len(points) == 0 {
+		return nil
+	}
+	cut := points[len(points)-1].at.Add(-window)
+	i := len(points)
+	for i > 0 && !points[i-1].at.Before(cut) {
+		i--
+	}
+	return points[i:]
+}
+
+func stdDev(points []pricePoint) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, p := range points {
+		mean += p.price
+	}
+	mean /= float64(len(points))
+	sum := 0.0
+	for _, p := range points {
+		sum += (p.price - mean) * (p.price - mean)
+	}
+	return math.Sqrt(sum / float64(len(points)-1))
+}
+
+// velocity is the change per minute from the first point to the last, and
+// false if they are too close in time to tell.
+func velocity(points []pricePoint) (float64, bool) {
+	if len(points) < 2 {
+		return 0, false
+	}
+	first, last := points[0], points[len(points)-1]
+	minutes := last.at.Sub(first.at).Minutes()
+	if minutes < 1.0/60 {
+		return 0, false
+	}
+	return (last.price - first.price) / minutes, true
+}
+
+func (m tuiModel) readoutsLine() string {
+	points := windowPoints(m.samples, readoutWindow)
+	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	line := gray.Render(fmt.Sprintf(" %s σ ", strings.TrimSuffix(readoutWindow.String(), "0s"))) + formatMoney(stdDev(points))
+	v, ok := velocity(points)
+	if !ok {
+		return line + gray.Render("  velocity —")
+	}
+	text := signedMoney(v) + "/min"
+	switch {
+	case v >= settings.Display.VelocityThreshold:
+		text = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render(text)
+	case v <= -settings.Display.VelocityThreshold:
+		text = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render(text)
+	}
+	return line + gray.Render("  velocity ") + text
+}
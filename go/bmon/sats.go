@@ -0,0 +1,55 @@
+package bmon
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// Sats mode ($, or -sats to start in it) shows the price as satoshis per
+// unit of the quoted currency, the same rate -us and -su convert with, and
+// the change in sats. Sats per dollar fall as BTC rises, so the colors still
+// follow the BTC price: a negative sats change is shown in green.
+const satsPerBTC = 100000000
+
+// satsPer converts a BTC price to satoshis per unit of currency.
+func satsPer(price float64) float64 {
+	if price <= 0 {
+		return 0
+	}
+	return satsPerBTC / price
+}
+
+// formatSats formats whole satoshis with the currency's grouping.
+func formatSats(sats float64) string {
+	return message.NewPrinter(language.Make(fiat.Locale)).Sprintf("%.0f", sats)
+}
+
+// satsUnit is e.g. "sats/$" or "sats/SEK".
+func satsUnit() string {
+	return "sats/" + strings.TrimSpace(fiat.Symbol)
+}
+
+// priceText is the price as shown: money, or sats per unit in sats mode.
+func (m tuiModel) priceText() string {
+	if m.satsMode {
+		return formatSats(satsPer(currentBtcPrice)) + " " + satsUnit()
+	}
+	return formatMoney(currentBtcPrice)
+}
+
+// changeText is the change from the baseline as shown, for a price change
+// of priceChange.
+func (m tuiModel) changeText(priceChange float64) string {
+	if !m.satsMode {
+		return signedMoney(priceChange)
+	}
+	d := satsPer(currentBtcPrice) - satsPer(m.monitorStartPrice)
+	sign := "+"
+	if d < 0 {
+		sign, d = "-", -d
+	}
+	return fmt.Sprintf("%s%s sats", sign, formatSats(d))
+}
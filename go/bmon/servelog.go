@@ -0,0 +1,200 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Service mode (--serve-log <file>) samples the price every -interval
+// seconds into a price log with no terminal at all, for running under
+// systemd, launchd or a Windows service wrapper. The log rotates daily:
+// "prices.csv" is written as "prices-2025-01-02.csv", and files older than
+// -keep days are removed. SIGHUP closes and reopens the current file (for
+// logrotate and friends), and SIGTERM or Ctrl+C stop after the sample in
+// progress is written.
+const (
+	defaultServeInterval = 60 * time.Second
+	defaultServeKeep     = 14
+	serveDayFormat       = "2006-01-02"
+)
+
+// serving keeps the Ctrl+C handler in main from exiting, so runServeLog can
+// close its log first.
+var serving bool
+
+// rotatingLog is a priceLog split into one file per day.
+type rotatingLog struct {
+	base string // path as given; the day goes before its extension
+	keep int    // days of files to keep, 0 for all
+	day  string
+	log  *priceLog
+}
+
+func (r *rotatingLog) pathFor(day string) string {
+	ext := filepath.Ext(r.base)
+	return strings.TrimSuffix(r.base, ext) + "-" + day + ext
+}
+
+// Write appends s to the file for its day, starting a new file at midnight.
+func (r *rotatingLog) Write(s priceSample) error {
+	day := s.Time.Format(serveDayFormat)
+	if r.log == nil || day != r.day {
+		r.Close()
+		l, err := openPriceLog(r.pathFor(day))
+		if err != nil {
+			return err
+		}
+		if r.day != "" && day != r.day {
+			slog.Info("price log rotated", "path", r.pathFor(day))
+		}
+		r.log, r.day = l, day
+		r.prune(s.Time)
+	}
+	return r.log.Write(s)
+}
+
+// Reopen closes the current file so the next write opens it again, picking
+// up a file that was moved away.
+func (r *rotatingLog) Reopen() {
+	if r.log == nil {
+		return
+	}
+	r.Close()
+	slog.Info("price log reopened", "path", r.pathFor(r.day))
+}
+
+func (r *rotatingLog) Close() {
+	if r.log != nil {
+		r.log.Close()
+		r.log = nil
+	}
+}
+
+// prune removes day files older than keep days before now.
+func (r *rotatingLog) prune(now time.Time) {
+	if r.keep <= 0 {
+		return
+	}
+	ext := filepath.Ext(r.base)
+	prefix := strings.TrimSuffix(r.base, ext) + "-"
+	matches, err := filepath.Glob(prefix + "*" + ext)
+	if err != nil {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -r.keep).Format(serveDayFormat)
+	for _, path := range matches {
+		day := strings.TrimSuffix(strings.TrimPrefix(path, prefix), ext)
+		if _, err := time.Parse(serveDayFormat, day); err != nil || day >= cutoff {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			slog.Warn("old price log not removed", "path", path, "err", err)
+		} else {
+			slog.Info("old price log removed", "path", path)
+		}
+	}
+}
+
+// runServeLog samples prices until SIGTERM or Ctrl+C and returns the exit
+// code. It never prompts: without an API key it uses the fallback sources.
+func runServeLog(args Args) int {
+	interval := args.daemonInterval
+	if interval <= 0 {
+		interval = defaultServeInterval
+	}
+	if interval < minDaemonInterval {
+		interval = minDaemonInterval
+	}
+	keep := args.serveKeep
+	if keep == 0 {
+		keep = defaultServeKeep
+	}
+	if keep < 0 {
+		keep = 0
+	}
+
+	if dir := filepath.Dir(args.serveLogPath); dir != "." {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return serveLogError("--serve-log directory %s does not exist", dir)
+		}
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	if ok, err := loadAPIKey(); !ok {
+		slog.Warn("no LiveCoinWatch API key", "err", err)
+		daemonPrintf("No LiveCoinWatch API key; sampling from the fallback sources.")
+	}
+
+	out := &rotatingLog{base: args.serveLogPath, keep: keep}
+	defer out.Close()
+	slog.Info("serve-log started", "path", args.serveLogPath, "interval", interval, "keep", keep)
+	daemonPrintf("Logging BTC every %s to %s.", interval, out.pathFor(time.Now().Format(serveDayFormat)))
+
+	var baseline float64
+	failing := false
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		price, source, err := getBtcPrice()
+		clearRetryIndicator()
+		switch {
+		case err != nil:
+			slog.Error("serve-log price fetch failed", "err", err)
+			if !failing {
+				// report once per outage, not every interval
+				daemonPrintf("Price fetch failed: %v", err)
+				failing = true
+			}
+		default:
+			if failing {
+				daemonPrintf("Price fetch recovered.")
+				failing = false
+			}
+			if source != currentPriceSource {
+				daemonPrintf("Prices now from %s.", source)
+				currentPriceSource = source
+			}
+			currentBtcPrice = price
+			if baseline == 0 {
+				baseline = price
+			}
+			if err := out.Write(priceSample{Time: time.Now(), Price: price, Change: price - baseline, Mode: "serve"}); err != nil {
+				// keep sampling: the disk may come back, and the next write retries the open
+				slog.Error("price log write failed", "path", out.pathFor(out.day), "err", err)
+				daemonPrintf("Price log write failed: %v", err)
+				out.Close()
+			}
+		}
+
+		for waiting := true; waiting; {
+			select {
+			case <-ticker.C:
+				waiting = false
+			case sig := <-sigs:
+				if sig == syscall.SIGHUP {
+					out.Reopen()
+					daemonPrintf("Reopened price log.")
+					continue
+				}
+				slog.Info("serve-log stopped", "signal", sig.String())
+				daemonPrintf("Stopped (%s).", sig)
+				return 0
+			}
+		}
+	}
+}
+
+// serveLogError reports a bad --serve-log setup.
+func serveLogError(format string, a ...interface{}) int {
+	fmt.Fprintf(os.Stderr, "bmon: "+format+"\n", a...)
+	return 1
+}
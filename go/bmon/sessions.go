@@ -0,0 +1,68 @@
+package bmon
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// With -sessions, or [Settings] SessionCSV = true in bmon.ini, each
+// go/golong/k session adds a row to bmon_sessions.csv next to the executable
+// when bmon exits, so long-term monitoring builds a dataset. The layout
+// follows vbtc's ledger.csv: a header row, times in UTC as MMDDYY@HHMMSS and
+// plain numbers with a "." decimal point.
+const (
+	sessionsFileName   = "bmon_sessions.csv"
+	sessionsTimeFormat = "010206@150405"
+)
+
+var sessionsHeader = []string{"Start Time", "End Time", "Minutes", "Start", "End", "High", "Low", "Currency", "Fetches"}
+
+func sessionsPath() string {
+	return filepath.Join(filepath.Dir(bmonIniPath()), sessionsFileName)
+}
+
+// sessionRow is the CSV row for s, ending at end.
+func (s sessionStats) sessionRow(end time.Time) []string {
+	money := func(v float64) string { return fmt.Sprintf("%.*f", fiat.Decimals, v) }
+	return []string{
+		s.started.UTC().Format(sessionsTimeFormat),
+		end.UTC().Format(sessionsTimeFormat),
+		fmt.Sprintf("%.1f", end.Sub(s.started).Minutes()),
+		money(s.startPrice),
+		money(s.lastPrice),
+		money(s.high),
+		money(s.low),
+		fiat.Code,
+		fmt.Sprint(s.fetches),
+	}
+}
+
+// recordSession appends the summary row for s, writing the header first
+// when the file is new.
+func (s sessionStats) recordSession() error {
+	if !s.active {
+		return nil
+	}
+	path := sessionsPath()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", sessionsFileName, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		writer.Write(sessionsHeader)
+	}
+	writer.Write(s.sessionRow(time.Now()))
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", sessionsFileName, err)
+	}
+	slog.Info("session recorded", "path", path)
+	return nil
+}
@@ -0,0 +1,58 @@
+package bmon
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// bmon.ini, next to the executable, holds optional display settings. (It
+// also used to hold the API key, which now lives in the shared keys file.)
+//
+//	[Display]
+//	VelocityThreshold = 50   ; $/min at which the velocity readout is colored
+//	StaleIntervals    = 3    ; update intervals before the price is marked stale
+//	StaleBeep         = false
+//	AnomalyPercent    = 1    ; one-update move that flashes magenta, 0 for off
+//
+//	[Settings]
+//	SessionCSV        = false ; record every session in bmon_sessions.csv
+const defaultVelocityThreshold = 50.0
+
+// settings is loaded from bmon.ini at startup, with defaults for anything
+// missing.
+var settings = defaultSettings()
+
+func defaultSettings() *Config {
+	cfg := &Config{}
+	cfg.Display.VelocityThreshold = defaultVelocityThreshold
+	cfg.Display.StaleIntervals = defaultStaleIntervals
+	cfg.Display.AnomalyPercent = defaultAnomalyPercent
+	cfg.Sounds.SnoozeMinutes = defaultSnoozeMinutes
+	return cfg
+}
+
+func bmonIniPath() string {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "bmon.ini"
+	}
+	return filepath.Join(filepath.Dir(exePath), "bmon.ini")
+}
+
+// loadSettings reads bmon.ini over the defaults; a missing file is fine.
+func loadSettings() {
+	cfg := defaultSettings()
+	if loaded, err := loadConfigInto(bmonIniPath(), cfg); err == nil {
+		cfg = loaded
+	}
+	if cfg.Display.VelocityThreshold <= 0 {
+		cfg.Display.VelocityThreshold = defaultVelocityThreshold
+	}
+	if cfg.Display.StaleIntervals <= 0 {
+		cfg.Display.StaleIntervals = defaultStaleIntervals
+	}
+	if cfg.Sounds.SnoozeMinutes <= 0 {
+		cfg.Sounds.SnoozeMinutes = defaultSnoozeMinutes
+	}
+	settings = cfg
+}
@@ -0,0 +1,89 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Z snoozes the alert that sounded last for [Sounds] SnoozeMinutes (default
+// 10), rather than turning every sound off with S. A snoozed price alarm
+// stays armed, or is armed again if it had just fired: while snoozed it is
+// not checked at all, so it neither sounds, flashes nor logs, and once the
+// snooze ends it sounds (and clears) as usual if the price is still past it.
+// A snoozed stale beep is skipped. Z again ends the snooze.
+const defaultSnoozeMinutes = 10
+
+func snoozeLength() time.Duration {
+	return time.Duration(settings.Sounds.SnoozeMinutes) * time.Minute
+}
+
+// toggleSnooze snoozes m.lastAlert, or ends a snooze in progress.
+func (m *tuiModel) toggleSnooze() {
+	now := time.Now()
+	if now.Before(m.snoozeAlarmUntil) || now.Before(m.snoozeStaleUntil) {
+		m.snoozeAlarmUntil, m.snoozeStaleUntil = time.Time{}, time.Time{}
+		slog.Info("snooze ended")
+		return
+	}
+	switch m.lastAlert {
+	case soundAlarm:
+		m.snoozeAlarmUntil = now.Add(snoozeLength())
+		if m.alarmAbove == 0 {
+			m.alarmAbove = m.firedAbove
+		}
+		if m.alarmBelow == 0 {
+			m.alarmBelow = m.firedBelow
+		}
+		slog.Info("alarm snoozed", "until", m.snoozeAlarmUntil, "above", m.alarmAbove, "below", m.alarmBelow)
+	case soundStale:
+		m.snoozeStaleUntil = now.Add(snoozeLength())
+		slog.Info("stale beep snoozed", "until", m.snoozeStaleUntil)
+	}
+}
+
+func (m tuiModel) alarmSnoozed() bool { return time.Now().Before(m.snoozeAlarmUntil) }
+func (m tuiModel) staleSnoozed() bool { return time.Now().Before(m.snoozeStaleUntil) }
+
+// alarmCheck fires any alarm price reaches, unless alarms are snoozed. It
+// returns the command that sounds the alarm, or nil, and whether
+// -exit-on-alarm should quit now.
+func (m *tuiModel) alarmCheck(price float64) (tea.Cmd, bool) {
+	if m.alarmSnoozed() {
+		return nil, false // stay armed until the snooze ends
+	}
+	above, below := m.alarmAbove, m.alarmBelow
+	fired := m.checkAlarms(price)
+	if fired == "" {
+		return nil, false
+	}
+	m.alarmUntil = time.Now().Add(alarmFlash)
+	m.alarmFired = fired
+	m.lastAlert = soundAlarm
+	if m.alarmAbove == 0 && above > 0 {
+		m.firedAbove = above
+	}
+	if m.alarmBelow == 0 && below > 0 {
+		m.firedBelow = below
+	}
+	if m.args.exitOnAlarm {
+		return nil, true
+	}
+	return alarmToneCmd(), false
+}
+
+// snoozeLabel shows a snooze in progress, e.g. " zZ alarm 9m", or "".
+func (m tuiModel) snoozeLabel() string {
+	until, what := m.snoozeAlarmUntil, "alarm"
+	if m.staleSnoozed() {
+		until, what = m.snoozeStaleUntil, "stale"
+	}
+	left := time.Until(until)
+	if left <= 0 {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(fmt.Sprintf(" zZ %s %dm", what, int(left.Minutes())+1))
+}
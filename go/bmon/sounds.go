@@ -0,0 +1,104 @@
+package bmon
+
+import (
+	"log/slog"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"kreftus/shared/notify"
+)
+
+// Sounds can be changed in the [Sounds] section of bmon.ini. Each sound is a
+// list of frequency:milliseconds tones, or a command to run instead (a wav
+// player, say), for terminals where the beep fallback is harsh or silent:
+//
+//	[Sounds]
+//	UpTick   = 1200:150
+//	DownTick = 400:150
+//	Alarm    = 900:150 1200:150 1500:150
+//	Stale    = 300:500
+//	AlarmCommand = paplay /usr/share/sounds/freedesktop/stereo/alarm-clock-elapsed.oga
+//
+// UpTickCommand, DownTickCommand and StaleCommand work the same way. A
+// command runs through the shell (cmd /c on Windows) without waiting for it.
+// SnoozeMinutes sets how long Z silences an alert (see snooze.go).
+type sound int
+
+const (
+	soundUpTick sound = iota
+	soundDownTick
+	soundAlarm
+	soundStale
+)
+
+type tone struct{ freq, ms int }
+
+var defaultTones = map[sound][]tone{
+	soundUpTick:   {{1200, 150}},
+	soundDownTick: {{400, 150}},
+	soundAlarm:    {{900, 150}, {1200, 150}, {1500, 150}},
+	soundStale:    {{300, 500}},
+}
+
+// soundSetting returns the configured tones and command for s.
+func soundSetting(s sound) (string, string) {
+	c := settings.Sounds
+	switch s {
+	case soundUpTick:
+		return c.UpTick, c.UpTickCommand
+	case soundDownTick:
+		return c.DownTick, c.DownTickCommand
+	case soundAlarm:
+		return c.Alarm, c.AlarmCommand
+	default:
+		return c.Stale, c.StaleCommand
+	}
+}
+
+// parseTones reads "1200:150 400:150" (commas work too), returning nil if
+// any part is not a frequency:milliseconds pair.
+func parseTones(s string) []tone {
+	var tones []tone
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == ' ' || r == ',' || r == '\t' }) {
+		freq, ms, ok := strings.Cut(part, ":")
+		f, err1 := strconv.Atoi(freq)
+		d, err2 := strconv.Atoi(ms)
+		if !ok || err1 != nil || err2 != nil || f <= 0 || d <= 0 {
+			return nil
+		}
+		tones = append(tones, tone{f, d})
+	}
+	return tones
+}
+
+// playSound plays s as configured in bmon.ini, or its default tones.
+func playSound(s sound) {
+	tonesSetting, command := soundSetting(s)
+	if command = strings.TrimSpace(command); command != "" {
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.Command("cmd", "/c", command)
+		} else {
+			cmd = exec.Command("sh", "-c", command)
+		}
+		if err := cmd.Start(); err != nil {
+			slog.Warn("sound command failed", "command", command, "err", err)
+		} else {
+			go cmd.Wait()
+			return
+		}
+	}
+	tones := defaultTones[s]
+	if tonesSetting != "" {
+		if parsed := parseTones(tonesSetting); parsed != nil {
+			tones = parsed
+		} else {
+			slog.Warn("bad tones in bmon.ini, using the default", "value", tonesSetting)
+		}
+	}
+	for _, t := range tones {
+		notify.Beep(t.freq, t.ms)
+	}
+}
@@ -0,0 +1,66 @@
+package bmon
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// While fetches keep failing (say, through a long run of retries) the
+// price on screen stops changing but looks just as current. Once the last
+// good fetch is more than [Display] StaleIntervals update intervals old, a
+// "data 2m old" tag shows next to the price, yellow and then red at twice
+// that. With [Display] StaleBeep = true it also beeps once each time the
+// data goes stale.
+const defaultStaleIntervals = 3
+
+// staleAge returns how old the price is, or 0 while it is fresh.
+func (m tuiModel) staleAge() time.Duration {
+	if m.mode == modeLanding || m.lastFetchOK.IsZero() {
+		return 0
+	}
+	age := time.Since(m.lastFetchOK)
+	if age <= time.Duration(settings.Display.StaleIntervals)*m.currentInterval() {
+		return 0
+	}
+	return age
+}
+
+// staleLabel is the tag for stale data, or "".
+func (m tuiModel) staleLabel() string {
+	age := m.staleAge()
+	if age == 0 {
+		return ""
+	}
+	color := lipgloss.Color("11")
+	if age > 2*time.Duration(settings.Display.StaleIntervals)*m.currentInterval() {
+		color = lipgloss.Color("1")
+	}
+	return lipgloss.NewStyle().Foreground(color).Render(" data " + formatAge(age) + " old")
+}
+
+// checkStale beeps the first time the data goes stale, if StaleBeep is on.
+func (m *tuiModel) checkStale() {
+	if m.staleAge() == 0 {
+		m.staleBeeped = false
+		return
+	}
+	if !m.staleBeeped && settings.Display.StaleBeep {
+		m.lastAlert = soundStale
+		if !m.staleSnoozed() {
+			playSound(soundStale)
+		}
+	}
+	m.staleBeeped = true
+}
+
+func formatAge(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	if d < time.Hour {
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%dm", int(d.Hours()), int(d.Minutes())%60)
+}
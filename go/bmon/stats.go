@@ -0,0 +1,85 @@
+package bmon
+
+import (
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// fetchRetries counts LiveCoinWatch retries for the session summary.
+var fetchRetries atomic.Int64
+
+// sessionStats follows a go/golong/k session for the summary printed on
+// exit. It starts with the first of those modes and carries on through
+// later mode switches until bmon exits.
+type sessionStats struct {
+	active         bool
+	started        time.Time
+	startPrice     float64
+	lastPrice      float64
+	high, low      float64
+	maxDrawdown    float64 // largest fall from a high, in USD
+	maxDrawdownPct float64
+	largestMove    float64 // largest change between two fetches, signed
+	fetches        int
+	retriesAtStart int64
+}
+
+func (s *sessionStats) start(price float64) {
+	if s.active {
+		return
+	}
+	*s = sessionStats{
+		active:         true,
+		started:        time.Now(),
+		startPrice:     price,
+		lastPrice:      price,
+		high:           price,
+		low:            price,
+		retriesAtStart: fetchRetries.Load(),
+	}
+}
+
+func (s *sessionStats) add(price float64) {
+	if !s.active || price <= 0 {
+		return
+	}
+	s.fetches++
+	if move := price - s.lastPrice; math.Abs(move) > math.Abs(s.largestMove) {
+		s.largestMove = move
+	}
+	if price > s.high {
+		s.high = price
+	}
+	if price < s.low {
+		s.low = price
+	}
+	if drawdown := s.high - price; drawdown > s.maxDrawdown {
+		s.maxDrawdown = drawdown
+		s.maxDrawdownPct = drawdown / s.high * 100
+	}
+	s.lastPrice = price
+}
+
+// print writes the summary to the terminal, once the TUI has closed.
+func (s sessionStats) print() {
+	if !s.active {
+		return
+	}
+	color.Yellow("*** Session Summary ***")
+	line := func(label, value string) {
+		fmt.Printf("%-15s %s\n", label, value)
+	}
+	change := s.lastPrice - s.startPrice
+	line("Duration:", time.Since(s.started).Round(time.Second).String())
+	line("Start:", formatMoney(s.startPrice))
+	line("End:", fmt.Sprintf("%s (%s, %+.2f%%)", formatMoney(s.lastPrice), signedMoney(change), change/s.startPrice*100))
+	line("High:", formatMoney(s.high))
+	line("Low:", formatMoney(s.low))
+	line("Max drawdown:", fmt.Sprintf("%s (%.2f%%)", formatMoney(s.maxDrawdown), s.maxDrawdownPct))
+	line("Largest move:", signedMoney(s.largestMove))
+	line("Fetches:", fmt.Sprintf("%d (%d retries)", s.fetches, fetchRetries.Load()-s.retriesAtStart))
+}
@@ -0,0 +1,57 @@
+package bmon
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The status bar (P) shows how long until the next fetch, as seconds and a
+// small bar that fills as it nears, and how much of the session is left, so
+// it is clear whether the price on screen is seconds or minutes old.
+const statusBarCells = 10
+
+func formatClock(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	d = d.Round(time.Second)
+	h, m, s := int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60
+	if h > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	}
+	return fmt.Sprintf("%d:%02d", m, s)
+}
+
+func (m tuiModel) statusLine() string {
+	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	var next string
+	if m.fetchingNow || m.nextFetchAt.IsZero() {
+		next = "fetching…"
+	} else {
+		left := time.Until(m.nextFetchAt)
+		if left < 0 {
+			left = 0
+		}
+		filled := statusBarCells
+		if interval := m.currentInterval(); interval > 0 {
+			filled = int(float64(statusBarCells) * (1 - float64(left)/float64(interval)))
+		}
+		if filled < 0 {
+			filled = 0
+		}
+		if filled > statusBarCells {
+			filled = statusBarCells
+		}
+		next = fmt.Sprintf("next %2ds ", int(left.Round(time.Second).Seconds())) +
+			lipgloss.NewStyle().Foreground(lipgloss.Color("6")).Render(strings.Repeat("▰", filled)) +
+			gray.Render(strings.Repeat("▱", statusBarCells-filled))
+	}
+	line := " " + next
+	if dur := m.sessionDuration(); dur > 0 {
+		line += gray.Render(" · session ") + formatClock(dur-time.Since(m.sessionStartTime)) + gray.Render(" left")
+	}
+	return line
+}
@@ -0,0 +1,83 @@
+package bmon
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The timeframes line (F) shows the change from the baseline, from an hour
+// ago and from 24 hours ago side by side, each colored by its own
+// direction. The hour-ago price is the oldest of the last hour's prices; the
+// day-ago price comes from a history fetch, refreshed every
+// dayAgoRefresh while the line is shown.
+const dayAgoRefresh = 15 * time.Minute
+
+// dayAgoMsg carries the fetched price from 24 hours ago.
+type dayAgoMsg struct {
+	price float64
+	err   error
+}
+
+func fetchDayAgoCmd() tea.Cmd {
+	return func() tea.Msg {
+		price, err := fetchBaseline(baseline24h, time.Now())
+		return dayAgoMsg{price: price, err: err}
+	}
+}
+
+// refreshDayAgo returns the fetch for the day-ago price when the line is
+// shown and the price is missing or old, else nil.
+func (m *tuiModel) refreshDayAgo() tea.Cmd {
+	if !m.timeframesEnabled || m.dayAgoPending || time.Since(m.dayAgoAt) < dayAgoRefresh {
+		return nil
+	}
+	m.dayAgoPending = true
+	return fetchDayAgoCmd()
+}
+
+func (m *tuiModel) applyDayAgo(msg dayAgoMsg) {
+	m.dayAgoPending = false
+	m.dayAgoAt = time.Now()
+	if msg.err != nil {
+		slog.Warn("24h-ago price fetch failed", "err", msg.err)
+		m.dayAgoPrice = 0
+		return
+	}
+	m.dayAgoPrice = msg.price
+}
+
+// timeframeChange renders the change from then to the current price, or
+// "—" without a price to compare with.
+func timeframeChange(label string, then float64) string {
+	gray := lipgloss.NewStyle().Foreground(lipgloss.Color("8"))
+	if then <= 0 {
+		return gray.Render(label+" ") + "—"
+	}
+	change := currentBtcPrice - then
+	text := fmt.Sprintf("%s (%+.2f%%)", signedMoney(change), change/then*100)
+	switch {
+	case change >= 0.01:
+		text = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render(text)
+	case change <= -0.01:
+		text = lipgloss.NewStyle().Foreground(lipgloss.Color("1")).Render(text)
+	}
+	return gray.Render(label+" ") + text
+}
+
+func (m tuiModel) timeframesLine() string {
+	hourAgo := 0.0
+	if len(m.hourPoints) > 0 {
+		hourAgo = m.hourPoints[0].price
+	}
+	sep := lipgloss.NewStyle().Foreground(lipgloss.Color("8")).Render(" · ")
+	return " " + strings.Join([]string{
+		timeframeChange("base", m.monitorStartPrice),
+		timeframeChange("1h", hourAgo),
+		timeframeChange("24h", m.dayAgoPrice),
+	}, sep)
+}
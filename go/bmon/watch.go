@@ -0,0 +1,80 @@
+package bmon
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// Watch mode (--watch-above / --watch-below) prints nothing and waits for
+// the price to reach a level, so scripts can chain on its exit code:
+//
+//	0    the price reached --watch-above or --watch-below
+//	1    bad arguments or no API key
+//	2    --watch-timeout passed first
+//	130  interrupted (Ctrl+C)
+//
+// Fetch failures are logged (with --debug) and retried at the next check
+// until the timeout.
+const (
+	watchExitTriggered   = 0
+	watchExitError       = 1
+	watchExitTimeout     = 2
+	watchExitInterrupted = 130
+
+	defaultWatchInterval = 20 * time.Second
+)
+
+// watching makes Ctrl+C exit quietly with watchExitInterrupted.
+var watching bool
+
+// parseWatchTimeout reads a duration such as "90m" or "2h", or a plain
+// number of minutes.
+func parseWatchTimeout(s string) (time.Duration, bool) {
+	if d, err := time.ParseDuration(s); err == nil && d > 0 {
+		return d, true
+	}
+	if m, err := strconv.ParseFloat(s, 64); err == nil && m > 0 {
+		return time.Duration(m * float64(time.Minute)), true
+	}
+	return 0, false
+}
+
+// runWatch waits for a watch level and returns the exit code.
+func runWatch(args Args) int {
+	interval := args.daemonInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+	if interval < minDaemonInterval {
+		interval = minDaemonInterval
+	}
+	var deadline time.Time
+	if args.watchTimeout > 0 {
+		deadline = time.Now().Add(args.watchTimeout)
+	}
+	slog.Info("watch started", "above", args.watchAbove, "below", args.watchBelow, "interval", interval, "timeout", args.watchTimeout)
+
+	for {
+		price, _, err := fetchPrice(false)
+		clearRetryIndicator()
+		if err != nil {
+			slog.Warn("watch price fetch failed", "err", err)
+		} else if (args.watchAbove > 0 && price >= args.watchAbove) || (args.watchBelow > 0 && price <= args.watchBelow) {
+			slog.Info("watch triggered", "price", price)
+			return watchExitTriggered
+		}
+		wait := interval
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				slog.Info("watch timed out", "timeout", args.watchTimeout)
+				return watchExitTimeout
+			}
+			if left < wait {
+				wait = left
+			}
+		}
+		time.Sleep(wait)
+	}
+}
@@ -21,7 +21,7 @@
     Requires 'windres' for Windows builds, which is part of a MinGW-w64 toolchain.
     On Windows, you can install it via MSYS2 or Chocolatey (choco install mingw).
 
-    The script expects 'gw.go' and 'gw.ico' to be in the same directory.
+    The script expects the gw sources and 'gw.ico' to be in the same directory.
 #>
 
 [CmdletBinding()]
@@ -49,6 +49,12 @@ if (Test-Path $binDir) {
     Remove-Item -Path $binDir -Recurse -Force
 }
 
+# Version stamped into the binary (shown by --version; compared by --check-update).
+# Keep it in step with version.Default in gw.go, which a plain go build uses.
+$Version = "1.0"
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Version=$Version -X kreftus/shared/version.Date=$BuildDate"
+
 Write-Host "Tidying Go modules..."
 go mod tidy
 
@@ -69,27 +75,27 @@ try {
     Write-Host "Building for Windows 32-bit (x86)..."
     Write-Host "  -> Generating 32-bit resources..." -ForegroundColor DarkGray
     $env:GOOS = "windows"; $env:GOARCH = "386"
-    windres -F pe-i386 -i gw.rc -o gw.syso -I .
+    windres -F pe-i386 -i gw.rc -o cmd/gw/gw.syso -I .
     Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray
-    go build -v -ldflags="-s -w" -o ".\bin\win\x86\gw.exe" .
+    go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\win\x86\gw.exe" ./cmd/gw
 
     Write-Host "Building for Windows 64-bit (amd64)..."
     Write-Host "  -> Generating 64-bit resources..." -ForegroundColor DarkGray
     $env:GOOS = "windows"; $env:GOARCH = "amd64"
-    windres -F pe-x86-64 -i gw.rc -o gw.syso -I .
+    windres -F pe-x86-64 -i gw.rc -o cmd/gw/gw.syso -I .
     Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray
-    go build -v -ldflags="-s -w" -o ".\bin\win\x64\gw.exe" .
+    go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\win\x64\gw.exe" ./cmd/gw
 }
 finally {
-    if (Test-Path "gw.syso") { Remove-Item "gw.syso" -Force }
+    if (Test-Path "cmd/gw/gw.syso") { Remove-Item "cmd/gw/gw.syso" -Force }
 }
 
 # --- 4. Linux Builds ---
 Write-Host "Building for Linux 32-bit (x86)..."
-$env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o ".\bin\linux\x86\gw" "gw.go"
+$env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\linux\x86\gw" ./cmd/gw
 
 Write-Host "Building for Linux 64-bit (amd64)..."
-$env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o ".\bin\linux\amd64\gw" "gw.go"
+$env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\linux\amd64\gw" ./cmd/gw
 
 # --- 5. Optional UPX compression (only when -upx is specified) ---
 if ($upx.IsPresent) {
@@ -0,0 +1,48 @@
+package gw
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gw/internal/forecast"
+)
+
+// Exit codes for -check-alerts. Errors (bad location, failed fetch) exit 1
+// like the rest of gw.
+const (
+	exitNoAlerts = 0
+	exitAlerts   = 2 // watches, advisories and statements
+	exitWarning  = 3 // at least one alert is a Warning
+)
+
+// alertsExitCode maps the active alerts to a -check-alerts exit code.
+func alertsExitCode(alerts []forecast.Alert) int {
+	code := exitNoAlerts
+	for _, alert := range alerts {
+		if strings.Contains(strings.ToLower(alert.Event), "warning") {
+			return exitWarning
+		}
+		code = exitAlerts
+	}
+	return code
+}
+
+// checkAlerts fetches the weather once and returns the exit code for its
+// alerts. It prints nothing unless summary (-t) is set, in which case each
+// alert gets one "Event until time" line.
+func checkAlerts(lat, lon float64, provider WeatherProvider, summary bool) int {
+	weather, err := provider.Weather(lat, lon)
+	if err != nil {
+		slog.Error("weather fetch failed", "err", err)
+		fmt.Fprintf(os.Stderr, "Error fetching weather data: %s\n", userMessage(err))
+		return 1
+	}
+	if summary {
+		for _, alert := range weather.Alerts {
+			fmt.Printf("%s until %s\n", alert.Event, formatUnixTimeLocal(alert.End, "Jan 2 3:04 PM"))
+		}
+	}
+	return alertsExitCode(weather.Alerts)
+}
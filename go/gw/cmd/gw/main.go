@@ -0,0 +1,13 @@
+// Command gw is the standalone gw weather report; the program itself lives in
+// package gw so the kreftus launcher can link it in too.
+package main
+
+import (
+	"os"
+
+	"gw"
+)
+
+func main() {
+	os.Exit(gw.Run(os.Args[1:]))
+}
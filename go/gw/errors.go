@@ -0,0 +1,62 @@
+package gw
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"gw/internal/fetch"
+	"kreftus/shared/apikey"
+)
+
+// isOpenWeatherMap reports whether host is one of OpenWeatherMap's, the only
+// APIs gw calls with a key.
+func isOpenWeatherMap(host string) bool {
+	return host == "openweathermap.org" || strings.HasSuffix(host, ".openweathermap.org")
+}
+
+// userMessage turns err into one line of advice for the terminal.
+func userMessage(err error) string {
+	var apiErr *fetch.APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+	owm := isOpenWeatherMap(apiErr.Host)
+	switch apiErr.Kind {
+	case fetch.KindBadAPIKey:
+		if owm {
+			return fmt.Sprintf("%s rejected the API key (HTTP %d). One Call 3.0 needs its own subscription; remove ApiKey under [openweathermap] in %s to enter a new key, or try -provider nws.", apiErr.Host, apiErr.StatusCode, keysFilePath())
+		}
+		return fmt.Sprintf("%s refused the request (HTTP %d). It may be blocking this network for a while; try again later.", apiErr.Host, apiErr.StatusCode)
+	case fetch.KindRateLimited:
+		if owm {
+			return fmt.Sprintf("%s is rate limiting requests. Wait a few minutes and try again (the free plan allows 1000 calls a day).", apiErr.Host)
+		}
+		return fmt.Sprintf("%s is rate limiting requests. Wait a few minutes and try again.", apiErr.Host)
+	case fetch.KindNetwork:
+		return fmt.Sprintf("Could not reach %s. Check your internet connection.", apiErr.Host)
+	case fetch.KindServer:
+		return fmt.Sprintf("%s is having problems (HTTP %d). Try again later.", apiErr.Host, apiErr.StatusCode)
+	case fetch.KindNotFound:
+		return fmt.Sprintf("%s has no data for this location.", apiErr.Host)
+	}
+	return apiErr.Error()
+}
+
+// keysFilePath is where the shared API keys live, for advice about a bad key.
+func keysFilePath() string {
+	if path, err := apikey.Path(); err == nil {
+		return path
+	}
+	return "keys.ini"
+}
+
+// fatal reports err for what (e.g. "fetching weather data") and exits 1.
+// The full error goes to the debug log; the terminal gets userMessage.
+func fatal(what string, err error) {
+	slog.Error("fatal error", "what", what, "err", err)
+	colorAlert.Fprintf(os.Stderr, "Error %s: %s\n", what, userMessage(err))
+	os.Exit(1)
+}
@@ -0,0 +1,143 @@
+package gw
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/ini.v1"
+	"gw/internal/forecast"
+)
+
+// favoritesSection holds saved locations in gw.ini, name = location:
+//
+//	[favorites]
+//	Home = 97219
+//	Mom  = Tucson, AZ
+const favoritesSection = "favorites"
+
+type favorite struct {
+	name, location string
+}
+
+// loadFavorites returns the saved locations in file order. A missing gw.ini
+// has none.
+func loadFavorites(configPath string) ([]favorite, error) {
+	cfg, err := ini.LooseLoad(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config file %s: %w", configPath, err)
+	}
+	var favorites []favorite
+	for _, key := range cfg.Section(favoritesSection).Keys() {
+		if location := strings.TrimSpace(key.String()); location != "" {
+			favorites = append(favorites, favorite{key.Name(), location})
+		}
+	}
+	return favorites, nil
+}
+
+// saveFavorite stores location under name (-save), replacing any location
+// already saved under that name.
+func saveFavorite(configPath, name, location string) error {
+	cfg, err := ini.LooseLoad(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load config file %s: %w", configPath, err)
+	}
+	cfg.Section(favoritesSection).Key(name).SetValue(location)
+	if err := cfg.SaveToIndent(configPath, "  "); err != nil {
+		return fmt.Errorf("failed to save favorite to %s: %w", configPath, err)
+	}
+	return os.Chmod(configPath, defaultPermissions)
+}
+
+// saveFavoriteLocation is saveFavorite for the default gw.ini.
+func saveFavoriteLocation(name, location string) error {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return err
+	}
+	return saveFavorite(configPath, name, location)
+}
+
+// favoriteAlerts is one favorite's result for -alerts-all.
+type favoriteAlerts struct {
+	favorite
+	city, countryOrState string
+	alerts               []forecast.Alert
+	err                  error
+}
+
+// checkFavoriteAlerts geocodes and fetches every favorite concurrently.
+// Results keep the favorites' order.
+func checkFavoriteAlerts(favorites []favorite, provider WeatherProvider) []favoriteAlerts {
+	results := make([]favoriteAlerts, len(favorites))
+	var wg sync.WaitGroup
+	for i, fav := range favorites {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := favoriteAlerts{favorite: fav}
+			var lat, lon float64
+			lat, lon, r.city, r.countryOrState, r.err = provider.Geocode(fav.location)
+			if r.err == nil {
+				var weather *forecast.WeatherData
+				if weather, r.err = provider.Weather(lat, lon); r.err == nil {
+					r.alerts = weather.Alerts
+				}
+			}
+			results[i] = r
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// runAlertsAll prints only the favorites with active alerts (and any that
+// could not be checked) and returns the -check-alerts exit code for all of
+// them together, or 1 if any favorite could not be checked.
+func runAlertsAll(configPath string, provider WeatherProvider, isTerse bool) int {
+	favorites, err := loadFavorites(configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if len(favorites) == 0 {
+		fmt.Fprintln(os.Stderr, `No favorites saved yet. Add one with: gw -save Home 97219`)
+		return 1
+	}
+
+	var all []forecast.Alert
+	failed := 0
+	for _, r := range checkFavoriteAlerts(favorites, provider) {
+		if r.err != nil {
+			slog.Warn("favorite check failed", "name", r.name, "location", r.location, "err", r.err)
+			psColorYellow.Printf("%s (%s): could not be checked: %s\n", r.name, r.location, userMessage(r.err))
+			failed++
+			continue
+		}
+		if len(r.alerts) == 0 {
+			continue
+		}
+		all = append(all, r.alerts...)
+		colorTitle.Printf("*** %s: %s, %s ***\n", r.name, r.city, r.countryOrState)
+		for _, alert := range r.alerts {
+			colorAlert.Printf("%s - %s\n", alert.Event, alert.SenderName)
+			if !isTerse {
+				printWrapped(colorDefault, alert.Description)
+			}
+			colorInfo.Printf("Starts: %s  Ends: %s\n",
+				formatUnixTimeLocal(alert.Start, "Jan 2 3:04 PM MST"), formatUnixTimeLocal(alert.End, "Jan 2 3:04 PM MST"))
+		}
+		fmt.Println()
+	}
+	if checked := len(favorites) - failed; len(all) == 0 && checked > 0 {
+		colorInfo.Printf("No active alerts in %d checked location(s).\n", checked)
+	}
+	if failed > 0 {
+		psColorYellow.Printf("%d of %d saved location(s) could not be checked.\n", failed, len(favorites))
+		return 1
+	}
+	return alertsExitCode(all)
+}
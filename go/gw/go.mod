@@ -6,6 +6,7 @@ require (
 	github.com/fatih/color v1.18.0
 	github.com/shirou/gopsutil/v3 v3.24.5
 	gopkg.in/ini.v1 v1.67.0
+	kreftus/shared v0.0.0-00010101000000-000000000000
 )
 
 require (
@@ -18,5 +19,7 @@ require (
 	github.com/tklauser/go-sysconf v0.3.12 // indirect
 	github.com/tklauser/numcpus v0.6.1 // indirect
 	github.com/yusufpapurcu/wmi v1.2.4 // indirect
-	golang.org/x/sys v0.25.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
 )
+
+replace kreftus/shared => ../shared
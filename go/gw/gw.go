@@ -1,19 +1,17 @@
-package main
+// Package gw is the gw weather report. Run is its entry point for cmd/gw and
+// the kreftus launcher.
+package gw
 
 import (
 	"bufio"
-	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
 	"log"
-	"math"
-	"net/http"
-	"net/url"
+	"log/slog"
 	"os"
 	"os/user"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strings"
 	"sync"
@@ -22,6 +20,15 @@ import (
 	"github.com/fatih/color"
 	"github.com/shirou/gopsutil/v3/process"
 	"gopkg.in/ini.v1"
+	"gw/internal/fetch"
+	"gw/internal/forecast"
+	"gw/internal/format"
+	"gw/internal/render"
+	"kreftus/shared/apikey"
+	"kreftus/shared/console"
+	"kreftus/shared/logging"
+	"kreftus/shared/notify"
+	"kreftus/shared/version"
 )
 
 const (
@@ -30,11 +37,6 @@ const (
 	defaultApiSection  = "openweathermap"
 	defaultApiKeyName  = "apikey"
 	defaultPermissions = 0600 // Read/write for user only for config file
-
-	geoZipURL    = "http://api.openweathermap.org/geo/1.0/zip"
-	geoDirectURL = "http://api.openweathermap.org/geo/1.0/direct"
-	oneCallURL   = "https://api.openweathermap.org/data/3.0/onecall"
-	overviewURL  = "https://api.openweathermap.org/data/3.0/onecall/overview"
 )
 
 var (
@@ -51,93 +53,17 @@ var (
 	psColorCyan   = color.New(color.FgCyan)
 	psColorGreen  = color.New(color.FgGreen)
 	psColorBlue   = color.New(color.FgBlue)
-
-	// Regex for zipcode
-	zipCodeRegex = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
 )
 
-// Geocoding structs
-type GeoZipResponse struct {
-	Zip     string  `json:"zip"`
-	Name    string  `json:"name"`
-	Lat     float64 `json:"lat"`
-	Lon     float64 `json:"lon"`
-	Country string  `json:"country"`
-}
-
-type GeoDirectResponse struct {
-	Name    string  `json:"name"`
-	Lat     float64 `json:"lat"`
-	Lon     float64 `json:"lon"`
-	Country string  `json:"country"`
-	State   string  `json:"state,omitempty"`
-}
-
-// Weather API structs
-type WeatherData struct {
-	Lat     float64         `json:"lat"`
-	Lon     float64         `json:"lon"`
-	Current CurrentWeather  `json:"current"`
-	Hourly  []HourlyWeather `json:"hourly,omitempty"`
-	Daily   []DailyWeather  `json:"daily"`
-	Alerts  []Alert         `json:"alerts,omitempty"`
-}
-
-type CurrentWeather struct {
-	Dt        int64              `json:"dt"`
-	Sunrise   int64              `json:"sunrise"`
-	Sunset    int64              `json:"sunset"`
-	Temp      float64            `json:"temp"`
-	Humidity  int                `json:"humidity"`
-	UVI       float64            `json:"uvi"`
-	WindSpeed float64            `json:"wind_speed"`
-	WindDeg   int                `json:"wind_deg"`
-	WindGust  float64            `json:"wind_gust,omitempty"`
-	Weather   []WeatherCondition `json:"weather"`
-	Rain      *RainSnowInfo      `json:"rain,omitempty"`
-	Snow      *RainSnowInfo      `json:"snow,omitempty"`
-}
-
-type RainSnowInfo struct {
-	OneH float64 `json:"1h,omitempty"`
-}
-
-type WeatherCondition struct {
-	Main string `json:"main"`
-}
-
-type HourlyWeather struct {
-	Dt   int64   `json:"dt"`
-	Temp float64 `json:"temp"`
-}
-
-type DailyWeather struct {
-	Dt        int64              `json:"dt"`
-	Sunrise   int64              `json:"sunrise"` // Daily sunrise/sunset might differ slightly from current
-	Sunset    int64              `json:"sunset"`
-	Moonrise  int64              `json:"moonrise"`
-	Moonset   int64              `json:"moonset"`
-	MoonPhase float64            `json:"moon_phase"`
-	Summary   string             `json:"summary"`
-	Temp      DailyTemp          `json:"temp"`
-	Weather   []WeatherCondition `json:"weather"`
-}
-
-type DailyTemp struct {
-	Min float64 `json:"min"`
-	Max float64 `json:"max"`
-}
-
-type Alert struct {
-	SenderName  string `json:"sender_name"`
-	Event       string `json:"event"`
-	Start       int64  `json:"start"`
-	End         int64  `json:"end"`
-	Description string `json:"description"`
-}
-
-type OverviewData struct {
-	WeatherOverview string `json:"weather_overview"`
+// applyTheme overrides the report colors with any roles defined by the user's
+// theme in the shared themes.ini (alert, title, info, sun, moon, text).
+func applyTheme(theme *console.Theme) {
+	colorAlert = theme.Color("alert", color.FgRed)
+	colorTitle = theme.Color("title", color.FgGreen)
+	colorInfo = theme.Color("info", color.FgHiBlue)
+	colorSun = theme.Color("sun", color.FgHiYellow)
+	colorMoon = theme.Color("moon", color.FgHiBlack)
+	colorDefault = theme.Color("text", color.FgCyan)
 }
 
 func clearScreen() {
@@ -180,19 +106,36 @@ func getConfigPath() (string, error) {
 
 // testApiKey will check if the provided key is valid by making a lightweight API call.
 func testApiKey(apiKey string) bool {
+	return apikey.OpenWeatherMap.Validate(apiKey)
+}
+
+// saveAPIKey writes the key to gw.ini with user-only permissions, keeping
+// the rest of the file (saved favorites). gw.ini only keeps a key when the
+// shared keys.ini cannot be written.
+func saveAPIKey(configPath, apiKey string) error {
+	cfg, err := ini.LooseLoad(configPath)
+	if err != nil {
+		cfg = ini.Empty()
+	}
 	if apiKey == "" {
-		return false
+		cfg.Section(defaultApiSection).DeleteKey(defaultApiKeyName)
+	} else {
+		cfg.Section(defaultApiSection).Key(defaultApiKeyName).SetValue(apiKey)
 	}
-	// Use a simple geocoding request to a known valid location to test the key.
-	testURL := fmt.Sprintf("%s?zip=90210,us&appid=%s", geoZipURL, apiKey)
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(testURL)
-	if err != nil {
-		return false
+
+	dir := filepath.Dir(configPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory for config file %s: %w", dir, err)
+	}
+	if err := cfg.SaveToIndent(configPath, "  "); err != nil {
+		slog.Error("config save failed", "path", configPath, "err", err)
+		return fmt.Errorf("failed to save API key to %s: %w", configPath, err)
 	}
-	defer resp.Body.Close()
-	// A 200 OK response means the key is valid and active.
-	return resp.StatusCode == http.StatusOK
+	slog.Info("config saved", "path", configPath)
+	if err := os.Chmod(configPath, defaultPermissions); err != nil {
+		log.Printf("Warning: could not set permissions for config file %s: %v", configPath, err)
+	}
+	return nil
 }
 
 // showFirstRunSetup handles the interactive prompt for the API key.
@@ -202,61 +145,60 @@ func showFirstRunSetup(configPath string) (string, error) {
 	psColorGreen.Println("Get Free One Call API 3.0 Key: https://openweathermap.org/api")
 
 	reader := bufio.NewReader(os.Stdin)
-	for {
-		color.White("Please enter your API Key:")
-		apiKey, err := reader.ReadString('\n')
-		if err != nil {
-			return "", fmt.Errorf("failed to read API key from input: %w", err)
-		}
-		apiKey = strings.TrimSpace(apiKey)
-
-		if testApiKey(apiKey) {
-			cfg := ini.Empty()
-			cfg.Section(defaultApiSection).Key(defaultApiKeyName).SetValue(apiKey)
-
-			dir := filepath.Dir(configPath)
-			if err := os.MkdirAll(dir, 0755); err != nil {
-				return "", fmt.Errorf("failed to create directory for config file %s: %w", dir, err)
-			}
-			if err := cfg.SaveToIndent(configPath, "  "); err != nil {
-				return "", fmt.Errorf("failed to save API key to %s: %w", configPath, err)
-			}
-			if err := os.Chmod(configPath, defaultPermissions); err != nil {
-				log.Printf("Warning: could not set permissions for config file %s: %v", configPath, err)
-			}
-
-			color.Green("API Key is valid and has been saved to %s", configPath)
-			fmt.Println("Press Enter to continue.")
-			reader.ReadString('\n')
-			return apiKey, nil
-		} else {
-			color.Red("Invalid API Key. Please try again.")
+	apiKey, err := apikey.Prompt(reader, apikey.OpenWeatherMap)
+	if err != nil {
+		return "", err
+	}
+	savedTo, _ := apikey.Path()
+	if apikey.Load(apikey.OpenWeatherMap) != apiKey {
+		// Prompt could not write keys.ini; keep the key in gw.ini instead.
+		if err := saveAPIKey(configPath, apiKey); err != nil {
+			return "", err
 		}
+		savedTo = configPath
 	}
+
+	color.Green("API Key is valid and has been saved to %s", savedTo)
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+	return apiKey, nil
 }
 
+// loadAPIKey returns the OpenWeatherMap key from the shared keys.ini, where
+// every kreftus tool saves it. A key left in gw.ini by older versions is moved
+// there once. Only when neither holds a valid key is the user prompted.
 func loadAPIKey(configPath string) (string, error) {
+	sharedKey := apikey.Load(apikey.OpenWeatherMap)
+	if sharedKey != "" && testApiKey(sharedKey) {
+		return sharedKey, nil
+	}
+
 	cfg, err := ini.Load(configPath)
-	if err != nil {
-		if os.IsNotExist(err) || strings.Contains(err.Error(), "cannot find file") {
-			return showFirstRunSetup(configPath)
-		}
+	if err != nil && !os.IsNotExist(err) && !strings.Contains(err.Error(), "cannot find file") {
 		return "", fmt.Errorf("failed to load config file %s: %w", configPath, err)
 	}
-
-	apiKey := cfg.Section(defaultApiSection).Key(defaultApiKeyName).String()
-	if apiKey == "" || !testApiKey(apiKey) {
-		if apiKey != "" {
-			color.Yellow("Your previously saved API key is no longer valid.")
+	legacyKey := ""
+	if cfg != nil {
+		legacyKey = cfg.Section(defaultApiSection).Key(defaultApiKeyName).String()
+	}
+	if legacyKey != "" && legacyKey != sharedKey && testApiKey(legacyKey) {
+		if err := apikey.Save(apikey.OpenWeatherMap, legacyKey); err != nil {
+			slog.Warn("api key migration failed", "err", err)
+			return legacyKey, nil
 		}
-		return showFirstRunSetup(configPath)
+		slog.Info("api key moved to shared keys file", "from", configPath)
+		return legacyKey, saveAPIKey(configPath, "")
 	}
-	return apiKey, nil
+
+	if sharedKey != "" || legacyKey != "" {
+		color.Yellow("Your previously saved API key is no longer valid.")
+	}
+	return showFirstRunSetup(configPath)
 }
 
 func showHelp() {
-	psColorGreen.Println("Usage: gw [ZipCode | \"City, State\"]") // Changed from goweather
-	psColorCyan.Println(" • Provide a 5-digit zipcode or a City, State (e.g., 'Portland, OR').")
+	psColorGreen.Println("Usage: gw [ZipCode | \"City, State\" | Lat,Lon]") // Changed from goweather
+	psColorCyan.Println(" • Provide a 5-digit zipcode, a City, State (e.g., 'Portland, OR') or coordinates (e.g., 45.5,-122.6).")
 	fmt.Println()
 	psColorBlue.Println("This script retrieves weather info from OpenWeatherMap One Call API 3.0 and outputs:")
 	psColorCyan.Println(" • Location (City, Country/State)")
@@ -266,7 +208,7 @@ func showHelp() {
 	psColorCyan.Println(" • Humidity")
 	psColorCyan.Println(" • Wind (with gust if available; red if wind speed >=16 mph)")
 	psColorCyan.Println(" • UV Index (red if >=6)")
-	psColorCyan.Println(" • Sunrise and Sunset times")
+	psColorCyan.Println(" • Sunrise and Sunset times, daylight length and change, time to the next one")
 	psColorCyan.Println(" • Moonrise and Moonset times")
 	psColorCyan.Println(" • Weather Report")
 	psColorCyan.Println(" • Observation timestamp")
@@ -275,6 +217,28 @@ func showHelp() {
 	psColorCyan.Println("  gw 97219")            // Changed from goweather
 	psColorCyan.Println("  gw \"Portland, OR\"") // Changed from goweather
 	psColorCyan.Println("  gw -h")               // Changed from goweather
+	psColorCyan.Println("  gw --version")
+	psColorCyan.Println("  gw --check-update")
+	psColorCyan.Println("  gw 90210 --debug          (write a debug log)")
+	psColorCyan.Println("  gw -json 97219            (JSON for scripts)")
+	psColorCyan.Println("  gw -plain -t 97219        (no colors or pauses, for status bars)")
+	psColorCyan.Println("  gw -coords 45.5,-122.6    (coordinates in, resolved place and coordinates out)")
+	psColorCyan.Println("  gw -short 97219           (one line: Portland 47°F Rain 12mph NW UV2)")
+	psColorCyan.Println("  gw -map -ascii 97219      (map tile URLs; -ascii draws precipitation, -zoom 7)")
+	psColorCyan.Println("  gw -delta 97219           (today vs tomorrow: 6°F cooler, windier, rain ends)")
+	psColorCyan.Println("  gw -ical week.ics 97219   (sunrise/sunset and alerts as calendar events)")
+	psColorCyan.Println("  gw -week 97219            (feels-like grid: morning/day/evening/night per day)")
+	psColorCyan.Println("  gw -moon 97219            (moon phases for the next 30 days)")
+	psColorCyan.Println("  gw -check-alerts 97219    (exit 0 none, 2 alerts, 3 warning; -t lists them)")
+	psColorCyan.Println("  gw -notify -plain 97219   (desktop toast for alerts, >89°F, <33°F or 16+ mph wind)")
+	psColorCyan.Println("  gw -alarm 97219           (bell and notify.ini notification when alerts are active)")
+	psColorCyan.Println("  gw -log -short 97219      (also append to the location's history CSV)")
+	psColorCyan.Println("  gw -trend -days 30 97219  (summarize the logged history)")
+	psColorCyan.Println("  gw -w 60 97219            (wrap report text at 60 columns)")
+	psColorCyan.Println("  gw -save Mom \"Tucson, AZ\" (save a favorite location)")
+	psColorCyan.Println("  gw -alerts-all            (alerts across all favorites; exit codes as -check-alerts)")
+	psColorCyan.Println("  gw -provider nws 97219    (National Weather Service, no API key, US only)")
+	psColorCyan.Println("  gw -watch 97219           (announce new alerts; -interval 10, -on-alert <cmd>)")
 }
 
 func showWelcomeBanner() {
@@ -297,104 +261,6 @@ func showWelcomeBanner() {
 	fmt.Println()
 }
 
-func makeAPIRequest(url string, target interface{}) error {
-	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
-	}
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", appName+"/1.0") // appName is now "gw"
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to execute request to %s: %w", url, err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API request to %s failed with status %s: %s", url, resp.Status, string(bodyBytes))
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read response body from %s: %w", url, err)
-	}
-	if len(body) == 0 {
-		return fmt.Errorf("empty response from API: %s", url)
-	}
-
-	err = json.Unmarshal(body, target)
-	if err != nil {
-		return fmt.Errorf("failed to unmarshal JSON from %s (body: %s): %w", url, string(body), err)
-	}
-	return nil
-}
-
-func getGeoCoordinates(locationInput, apiKey string) (lat, lon float64, city, countryOrState string, err error) {
-	if zipCodeRegex.MatchString(locationInput) {
-		geoURL := fmt.Sprintf("%s?zip=%s,us&appid=%s", geoZipURL, url.QueryEscape(locationInput), apiKey)
-		var geoResp GeoZipResponse
-		if err = makeAPIRequest(geoURL, &geoResp); err != nil {
-			return 0, 0, "", "", fmt.Errorf("geocoding by zip failed for '%s': %w", locationInput, err)
-		}
-		if geoResp.Name == "" {
-			return 0, 0, "", "", fmt.Errorf("no geocoding results for zipcode '%s'", locationInput)
-		}
-		return geoResp.Lat, geoResp.Lon, geoResp.Name, geoResp.Country, nil
-	} else {
-		// For non-zip inputs, append ",us" if a comma isn't already present.
-		loc := strings.TrimSpace(locationInput)
-		if !strings.Contains(loc, ",") {
-			loc += ",us"
-		}
-		geoURL := fmt.Sprintf("%s?q=%s&limit=1&appid=%s", geoDirectURL, url.QueryEscape(loc), apiKey)
-		var geoRespArr []GeoDirectResponse
-		if err = makeAPIRequest(geoURL, &geoRespArr); err != nil {
-			return 0, 0, "", "", fmt.Errorf("geocoding by city failed for '%s': %w", locationInput, err)
-		}
-		if len(geoRespArr) == 0 {
-			return 0, 0, "", "", fmt.Errorf("no geocoding results found for '%s'", locationInput)
-		}
-		geoResp := geoRespArr[0]
-		resolvedLocation := geoResp.Country
-		if geoResp.State != "" {
-			resolvedLocation = geoResp.State + ", " + geoResp.Country
-		}
-		return geoResp.Lat, geoResp.Lon, geoResp.Name, resolvedLocation, nil
-	}
-}
-
-func getWeatherData(lat, lon float64, apiKey string) (*WeatherData, error) {
-	weatherURL := fmt.Sprintf("%s?lat=%f&lon=%f&appid=%s&units=imperial&lang=en&exclude=minutely",
-		oneCallURL, lat, lon, apiKey)
-	var data WeatherData
-	if err := makeAPIRequest(weatherURL, &data); err != nil {
-		return nil, err
-	}
-	if data.Current.Dt == 0 {
-		return nil, fmt.Errorf("weather API returned incomplete 'current' data")
-	}
-	if len(data.Daily) == 0 {
-		return nil, fmt.Errorf("weather API returned no 'daily' forecast data")
-	}
-	return &data, nil
-}
-
-func getWeatherOverview(lat, lon float64, apiKey string) (*OverviewData, error) {
-	overviewAPIURL := fmt.Sprintf("%s?lat=%f&lon=%f&appid=%s&units=imperial&lang=en",
-		overviewURL, lat, lon, apiKey)
-	var data OverviewData
-	if err := makeAPIRequest(overviewAPIURL, &data); err != nil {
-		return nil, err
-	}
-	if data.WeatherOverview == "" {
-		return nil, fmt.Errorf("weather overview API returned empty 'weather_overview' data")
-	}
-	return &data, nil
-}
-
 func formatUnixTimeLocal(unixTime int64, format string) string {
 	if unixTime == 0 {
 		return "N/A"
@@ -402,175 +268,218 @@ func formatUnixTimeLocal(unixTime int64, format string) string {
 	return time.Unix(unixTime, 0).Local().Format(format)
 }
 
-func getCardinalDirection(deg int) string {
-	val := int(math.Floor((float64(deg) / 22.5) + 0.5))
-	directions := []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
-	return directions[val%16]
-}
+// reportWidth is the column width report text wraps at; see setReportWidth.
+var reportWidth = 80
 
-func getMoonPhaseDescription(phase float64) string {
-	switch {
-	case phase < 0.0625 || phase >= 0.9375:
-		return "New Moon"
-	case phase < 0.1875:
-		return "Waxing Crescent"
-	case phase < 0.3125:
-		return "First Quarter"
-	case phase < 0.4375:
-		return "Waxing Gibbous"
-	case phase < 0.5625:
-		return "Full Moon"
-	case phase < 0.6875:
-		return "Waning Gibbous"
-	case phase < 0.8125:
-		return "Third Quarter"
-	default:
-		return "Waning Crescent"
+// setReportWidth picks the wrap width: the -w override when given, else the
+// terminal width less one column (so a full line does not auto-wrap on
+// Windows consoles), else 80 when the output is not a terminal.
+func setReportWidth(override int) {
+	switch width := console.Width(); {
+	case override > 0:
+		reportWidth = override
+	case width > 0:
+		reportWidth = width - 1
 	}
+	reportWidth = max(reportWidth, 20)
 }
 
-func wrapText(text string, width int) []string {
-	if width <= 0 {
-		return []string{text}
-	}
-	var lines []string
-	words := strings.Fields(text)
-	if len(words) == 0 {
-		return lines
+// printWrapped prints text wrapped at reportWidth in color c.
+func printWrapped(c *color.Color, text string) {
+	for _, line := range format.Wrap(text, reportWidth) {
+		c.Println(line)
 	}
+}
 
-	currentLine := words[0]
-	for _, word := range words[1:] {
-		if len(currentLine)+1+len(word) > width {
-			lines = append(lines, currentLine)
-			currentLine = word
-		} else {
-			currentLine += " " + word
-		}
+// reportRenderer draws views to the terminal in the theme's colors, wrapped
+// at reportWidth.
+func reportRenderer() render.Renderer {
+	return render.Renderer{
+		Out: color.Output,
+		Colors: render.Palette{
+			Alert: colorAlert, Title: colorTitle, Info: colorInfo,
+			Sun: colorSun, Moon: colorMoon, Text: colorDefault, Accent: psColorCyan,
+		},
+		Width:    reportWidth,
+		Location: time.Local,
+		Now:      time.Now,
 	}
-	lines = append(lines, currentLine)
-	return lines
 }
 
-func displayWeather(city, countryOrState string, weather *WeatherData, overview *OverviewData, isTerse bool) {
-	current := weather.Current
-	dailyToday := weather.Daily[0] // Assumes at least one day is present, checked in getWeatherData
+// printCoordinates shows which place a location resolved to (-coords).
+func printCoordinates(city, countryOrState string, lat, lon float64) {
+	colorInfo.Printf("Resolved: %s, %s (%.4f, %.4f)\n", city, countryOrState, lat, lon)
+}
 
-	tempC := colorDefault
-	if current.Temp < 33 || current.Temp > 89 {
-		tempC = colorAlert
+// notifyAlerts sends one notification summarizing the active alerts through
+// the channels configured in the shared notify.ini.
+func notifyAlerts(city string, alerts []forecast.Alert) {
+	events := make([]string, 0, len(alerts))
+	for _, alert := range alerts {
+		events = append(events, alert.Event)
 	}
-
-	windC := colorDefault
-	if current.WindSpeed >= 16 {
-		windC = colorAlert
+	title := fmt.Sprintf("Weather alert for %s", city)
+	if err := notify.Load(appName).Send(title, strings.Join(events, ", ")); err != nil {
+		psColorYellow.Printf("Could not send alert notification: %v\n", err)
 	}
+}
 
-	uvC := colorDefault
-	if current.UVI >= 6 {
-		uvC = colorAlert
-	}
+// fetchReport fetches the detailed weather and, unless isTerse, the overview
+// summary concurrently.
+func fetchReport(provider WeatherProvider, lat, lon float64, isTerse bool) (*forecast.WeatherData, *forecast.OverviewData, error) {
+	var weatherData *forecast.WeatherData
+	var overviewData *forecast.OverviewData
+	var weatherErr, overviewErr error
+	var wg sync.WaitGroup
 
-	tempIndicator := ""
-	if len(weather.Hourly) > 2 { // PowerShell used index 2 for next hour's temp (dt + 2 hours)
-		nextHourTemp := weather.Hourly[2].Temp
-		tempDiff := nextHourTemp - current.Temp
-		if tempDiff >= 0.67 {
-			tempIndicator = "(Rising)"
-		}
-		if tempDiff <= -0.67 {
-			tempIndicator = "(Falling)"
-		}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		weatherData, weatherErr = provider.Weather(lat, lon)
+	}()
+	if !isTerse {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			overviewData, overviewErr = provider.Overview(lat, lon)
+		}()
 	}
+	wg.Wait()
 
-	conditions := current.Weather[0].Main
-	if current.Rain != nil && current.Rain.OneH > 0 {
-		conditions = fmt.Sprintf("%s [%.1f mm/H Rain]", conditions, current.Rain.OneH)
+	if weatherErr != nil {
+		return nil, nil, weatherErr
 	}
-	if current.Snow != nil && current.Snow.OneH > 0 {
-		conditions = fmt.Sprintf("%s [%.1f mm/H Snow]", conditions, current.Snow.OneH)
+	if overviewErr != nil {
+		return nil, nil, fmt.Errorf("fetching the overview: %w", overviewErr)
 	}
+	return weatherData, overviewData, nil
+}
 
-	windDisplay := fmt.Sprintf("%.1f mph %s", current.WindSpeed, getCardinalDirection(current.WindDeg))
-	windLabel := "Wind:"
-	if current.WindGust > 0 {
-		windLabel = "Wind[Gust]:"
-		windDisplay = fmt.Sprintf("%.1f mph [%.1f mph] %s", current.WindSpeed, current.WindGust, getCardinalDirection(current.WindDeg))
+// showAnotherLocation shows the full report for a location entered at the
+// pause-before-exit prompt. Failures are returned instead of exiting so the
+// window stays open for another try. With alarm, active alerts are also sent
+// through notify.ini.
+func showAnotherLocation(provider WeatherProvider, location string, logHistory, alarm bool) error {
+	lat, lon, city, countryOrState, err := provider.Geocode(location)
+	if err != nil {
+		return err
 	}
-
-	colorTitle.Printf("*** %s, %s Current Conditions ***\n", city, countryOrState)
-	colorInfo.Printf("Forecast: %s\n", dailyToday.Summary)
-	colorDefault.Printf("Currently: %s\n", conditions)
-	tempC.Printf("Temp [L/H]: %.0f°F%s [%.0f°F/%.0f°F]\n", current.Temp, tempIndicator, dailyToday.Temp.Min, dailyToday.Temp.Max)
-	colorDefault.Printf("Humidity: %d%%\n", current.Humidity)
-	uvC.Printf("UV Index: %.1f\n", current.UVI)
-	windC.Printf("%s %s\n", windLabel, windDisplay)
-
-	if len(weather.Daily) > 1 {
-		psColorCyan.Printf("Tomorrow: %s\n", weather.Daily[1].Summary)
+	weatherData, overviewData, err := fetchReport(provider, lat, lon, false)
+	if err != nil {
+		return err
 	}
-
-	colorSun.Printf("Sunrise: %s\n", formatUnixTimeLocal(current.Sunrise, "3:04 PM"))
-	colorSun.Printf("Sunset: %s\n", formatUnixTimeLocal(current.Sunset, "3:04 PM"))
-	colorMoon.Printf("Moonrise: %s\n", formatUnixTimeLocal(dailyToday.Moonrise, "3:04 PM"))
-	colorMoon.Printf("Moonset: %s\n", formatUnixTimeLocal(dailyToday.Moonset, "3:04 PM"))
-	colorMoon.Printf("Moon Phase: %s\n", getMoonPhaseDescription(dailyToday.MoonPhase))
-	colorInfo.Printf("Observed: %s\n", formatUnixTimeLocal(current.Dt, "Jan 2, 2006 3:04 PM"))
-
-	if !isTerse && overview != nil {
-		fmt.Println()
-		colorTitle.Printf("*** %s, %s Weather Report ***\n", city, countryOrState)
-		wrappedReport := wrapText(overview.WeatherOverview, 80) // Assuming 80 char width for console
-		for _, line := range wrappedReport {
-			colorDefault.Println(line)
-		}
-		fmt.Println()
-		psColorCyan.Printf("https://forecast.weather.gov/MapClick.php?lat=%f&lon=%f\n", weather.Lat, weather.Lon)
-	}
-
-	if len(weather.Alerts) > 0 {
-		for _, alert := range weather.Alerts {
-			fmt.Println()
-			colorAlert.Printf("*** %s - %s ***\n", alert.Event, alert.SenderName)
-			if !isTerse {
-				wrappedAlertDesc := wrapText(alert.Description, 80)
-				for _, line := range wrappedAlertDesc {
-					colorDefault.Println(line)
-				}
-			}
-			colorInfo.Printf("Starts: %s\n", formatUnixTimeLocal(alert.Start, "Jan 2, 2006 3:04 PM MST"))
-			colorInfo.Printf("Ends: %s\n", formatUnixTimeLocal(alert.End, "Jan 2, 2006 3:04 PM MST"))
+	if logHistory {
+		if path, err := historyPath(city, countryOrState); err == nil {
+			logObservation(path, weatherData)
 		}
 	}
+	clearScreen()
+	reportRenderer().Report(city, countryOrState, weatherData, overviewData, false)
+	if alarm && len(weatherData.Alerts) > 0 {
+		notifyAlerts(city, weatherData.Alerts)
+	}
+	return nil
 }
 
-func main() {
-	clearScreen()
+// Run starts gw with argv, the command line after the program name, and
+// returns the exit code. cmd/gw and the kreftus launcher both call it.
+func Run(argv []string) int {
+	version.Default("1.0")
+	argv = logging.Init(appName, append([]string{appName}, argv...))[1:]
+	defer logging.Close()
+	if version.HandleArgs(appName, argv) {
+		return 0
+	}
+	console.Setup()
+	applyTheme(console.LoadTheme(appName))
 
-	log.SetFlags(0) // No timestamps or prefixes for cleaner error messages from log.Fatal
+	log.SetFlags(0) // No timestamps or prefixes for cleaner warnings from log.Printf
 
+	flags := flag.NewFlagSet(appName, flag.ExitOnError)
 	var isTerse bool
-	helpFlag := flag.Bool("h", false, "Display help information")
-	helpLongFlag := flag.Bool("help", false, "Display help information")
-	flag.BoolVar(&isTerse, "terse", false, "Display a terse, less busy view of the weather.")
-	flag.BoolVar(&isTerse, "t", false, "Alias for -terse.")
-	flag.Parse()
+	helpFlag := flags.Bool("h", false, "Display help information")
+	helpLongFlag := flags.Bool("help", false, "Display help information")
+	flags.BoolVar(&isTerse, "terse", false, "Display a terse, less busy view of the weather.")
+	flags.BoolVar(&isTerse, "t", false, "Alias for -terse.")
+	jsonFlag := flags.Bool("json", false, "Print the weather as JSON for scripts.")
+	plainFlag := flags.Bool("plain", false, "Print the report without colors, screen clearing or pauses.")
+	coordsFlag := flags.Bool("coords", false, "Print the coordinates the location resolved to.")
+	shortFlag := flags.Bool("short", false, "Print one condensed line (add -plain to drop color).")
+	checkAlertsFlag := flags.Bool("check-alerts", false, "Print nothing (-t: one line per alert) and exit 2 for alerts, 3 for warnings.")
+	moonFlag := flags.Bool("moon", false, "Print the moon phases for the next 30 days.")
+	mapFlag := flags.Bool("map", false, "Print weather map tile URLs for the location.")
+	asciiFlag := flags.Bool("ascii", false, "With -map, also draw the precipitation tile as an ASCII map.")
+	mapZoom := flags.Int("zoom", 7, "Map zoom level for -map (3-10).")
+	deltaFlag := flags.Bool("delta", false, "Compare today's and tomorrow's weather.")
+	notifyFlag := flags.Bool("notify", false, "Send a desktop notification for alerts or crossed thresholds.")
+	alarmFlag := flags.Bool("alarm", false, "Ring the bell and notify through notify.ini when alerts are active.")
+	var thresholds notifyThresholds
+	flags.Float64Var(&thresholds.TempAbove, "notify-above", 89, "With -notify, notify when the temperature is above this (°F).")
+	flags.Float64Var(&thresholds.TempBelow, "notify-below", 33, "With -notify, notify when the temperature is below this (°F).")
+	flags.Float64Var(&thresholds.Wind, "notify-wind", 16, "With -notify, notify when the wind reaches this (mph).")
+	logFlag := flags.Bool("log", false, "Append this observation to the location's history CSV.")
+	trendFlag := flags.Bool("trend", false, "Summarize the location's logged history instead of fetching weather.")
+	trendDays := flags.Int("days", 7, "Days of history for -trend.")
+	widthFlag := flags.Int("w", 0, "Wrap report text at this many columns (default: terminal width).")
+	saveName := flags.String("save", "", "Save the location as a favorite under this name.")
+	alertsAllFlag := flags.Bool("alerts-all", false, "Check every saved favorite and show only those with alerts.")
+	icalPath := flags.String("ical", "", "Write sunrise/sunset and alerts for the coming week to this .ics file.")
+	providerName := flags.String("provider", "owm", "Weather source: owm (OpenWeatherMap, NWS fallback for US) or nws (no API key, US only).")
+	weekFlag := flags.Bool("week", false, "Show a week-at-a-glance grid of feels-like temperatures.")
+	watchFlag := flags.Bool("watch", false, "Keep running and announce new weather alerts as they appear.")
+	watchInterval := flags.Int("interval", 10, "Minutes between -watch polls (minimum 2).")
+	alertHook := flags.String("on-alert", "", "Command to run for each new alert in -watch mode.")
+	flags.Parse(argv)
+
+	// Scripted output never clears the screen, prompts or pauses, and leaves
+	// notifications to whatever is reading it.
+	// -short keeps its colors unless -plain is given too.
+	scripted := *jsonFlag || *plainFlag || *shortFlag || *checkAlertsFlag
+	if *jsonFlag || *plainFlag || *checkAlertsFlag {
+		color.NoColor = true
+	}
+	if !scripted {
+		clearScreen()
+	}
+	setReportWidth(*widthFlag)
+	if *shortFlag || *moonFlag || *deltaFlag || *weekFlag || *icalPath != "" {
+		isTerse = true // Neither view shows the overview.
+	}
 
-	if *helpFlag || *helpLongFlag || (isTerse && len(flag.Args()) == 0) {
+	if *helpFlag || *helpLongFlag || (isTerse && len(flags.Args()) == 0 && !scripted && !*alertsAllFlag) {
 		showHelp()
-		return
+		return 0
+	}
+	if scripted && len(flags.Args()) == 0 && !*alertsAllFlag {
+		fmt.Fprintln(os.Stderr, "A location is required with -json, -plain, -short or -check-alerts.")
+		return 1
 	}
 
 	// --- API Key Handling (Moved Up) ---
-	apiKey, err := setup()
+	// The NWS provider needs no key, so it skips setup entirely.
+	var apiKey string
+	var err error
+	if !strings.EqualFold(*providerName, "nws") {
+		if apiKey, err = setup(); err != nil {
+			fatal("setting up the configuration", err)
+		}
+	}
+	provider, err := newProvider(*providerName, apiKey)
 	if err != nil {
-		log.Fatalf("Configuration setup failed: %v", err)
+		fatal("choosing the weather provider", err)
+	}
+	if *alertsAllFlag {
+		configPath, err := getConfigPath()
+		if err != nil {
+			fatal("setting up the configuration", err)
+		}
+		return runAlertsAll(configPath, provider, isTerse)
 	}
 
 	// --- Location Input & Geocoding Loop ---
 	var lat, lon float64
 	var city, countryOrState string
-	args := flag.Args()
+	args := flags.Args()
 	isInteractive := len(args) == 0
 	var locationInput string
 	if !isInteractive {
@@ -582,23 +491,33 @@ func main() {
 			clearScreen()
 			showWelcomeBanner()
 			reader := bufio.NewReader(os.Stdin)
-			fmt.Print("Enter a location (Zip Code or City, State): ")
+			fmt.Print("Enter a location (Zip Code, City, State or Lat,Lon): ")
 			input, err := reader.ReadString('\n')
 			if err != nil {
-				log.Fatalf("Error reading location input: %v", err)
+				fatal("reading location input", err)
 			}
 			locationInput = strings.TrimSpace(input)
 			if locationInput == "" {
-				return // User hit enter on an empty line, exit cleanly.
+				return 0 // User hit enter on an empty line, exit cleanly.
 			}
 		}
 
 		var geoErr error
-		lat, lon, city, countryOrState, geoErr = getGeoCoordinates(locationInput, apiKey)
+		lat, lon, city, countryOrState, geoErr = provider.Geocode(locationInput)
 		if geoErr != nil {
+			// An unreachable or rejecting API is not a bad location; say what happened.
+			var apiErr *fetch.APIError
+			if errors.As(geoErr, &apiErr) && apiErr.Kind != fetch.KindNotFound && apiErr.Kind != fetch.KindBadResponse {
+				fatal("looking up the location", geoErr)
+			}
+			if scripted {
+				slog.Error("geocoding failed", "location", locationInput, "err", geoErr)
+				fmt.Fprintf(os.Stderr, "Location not found: %s\n", locationInput)
+				return 1
+			}
 			color.Red("Location not found, try again")
 			if !isInteractive {
-				os.Exit(1)
+				return 1
 			}
 			locationInput = "" // This will cause the interactive prompt to show again
 			time.Sleep(1 * time.Second)
@@ -607,35 +526,54 @@ func main() {
 		break // Geocoding was successful, exit the loop.
 	}
 
-	// Concurrently fetch detailed weather and the overview summary.
-	var weatherData *WeatherData
-	var overviewData *OverviewData
-	var weatherErr, overviewErr error
-	var wg sync.WaitGroup
-
-	// Fetch detailed weather data in all cases.
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		weatherData, weatherErr = getWeatherData(lat, lon, apiKey)
-	}()
-
-	// Only fetch the overview if not in terse mode.
-	if !isTerse {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			overviewData, overviewErr = getWeatherOverview(lat, lon, apiKey)
-		}()
+	if *saveName != "" {
+		if err := saveFavoriteLocation(*saveName, locationInput); err != nil {
+			fatal("saving the favorite", err)
+		}
+		colorInfo.Printf("Saved favorite %s = %s\n", *saveName, locationInput)
+	}
+	if *trendFlag {
+		if err := printTrend(city, countryOrState, max(*trendDays, 1)); err != nil {
+			fatal("reading history", err)
+		}
+		return 0
+	}
+	var logPath string
+	if *logFlag {
+		if logPath, err = historyPath(city, countryOrState); err != nil {
+			fatal("locating the history file", err)
+		}
+	}
+	if *mapFlag {
+		if apiKey == "" {
+			fatal("drawing maps", errors.New("-map uses OpenWeatherMap tiles and needs its API key; run without -provider nws"))
+		}
+		zoom := min(max(*mapZoom, 3), 10)
+		printMapLinks(city, countryOrState, lat, lon, zoom, apiKey)
+		if *asciiFlag {
+			if err := printASCIIMap(lat, lon, zoom, apiKey); err != nil {
+				fatal("drawing the map", err)
+			}
+		}
+		return 0
+	}
+	if *checkAlertsFlag {
+		return checkAlerts(lat, lon, provider, isTerse)
+	}
+	if *watchFlag {
+		if *coordsFlag {
+			printCoordinates(city, countryOrState, lat, lon)
+		}
+		runWatch(lat, lon, city, countryOrState, provider, *watchInterval, isTerse, *alertHook, logPath)
+		return 0
 	}
 
-	wg.Wait()
-
-	if weatherErr != nil {
-		log.Fatalf("Error fetching weather data: %v", weatherErr)
+	weatherData, overviewData, err := fetchReport(provider, lat, lon, isTerse)
+	if err != nil {
+		fatal("fetching weather data", err)
 	}
-	if !isTerse && overviewErr != nil {
-		log.Fatalf("Error fetching weather overview: %v", overviewErr)
+	if logPath != "" {
+		logObservation(logPath, weatherData)
 	}
 
 	// Clear screen if we prompted for location input before showing weather.
@@ -645,7 +583,44 @@ func main() {
 		clearScreen()
 	}
 
-	displayWeather(city, countryOrState, weatherData, overviewData, isTerse)
+	if *jsonFlag {
+		r := reportRenderer()
+		r.Out = os.Stdout
+		if err := r.JSON(city, countryOrState, weatherData, overviewData); err != nil {
+			fatal("writing JSON", err)
+		}
+		return 0
+	}
+	if *coordsFlag {
+		printCoordinates(city, countryOrState, lat, lon)
+	}
+	switch {
+	case *shortFlag:
+		reportRenderer().Short(city, weatherData)
+	case *moonFlag:
+		printMoonCalendar(city, countryOrState, weatherData)
+	case *deltaFlag:
+		reportRenderer().Delta(city, countryOrState, weatherData)
+	case *weekFlag:
+		reportRenderer().Week(city, countryOrState, weatherData)
+	case *icalPath != "":
+		n, err := writeICal(*icalPath, city, weatherData)
+		if err != nil {
+			fatal("writing the calendar", err)
+		}
+		colorInfo.Printf("Wrote %d events for %s, %s to %s\n", n, city, countryOrState, *icalPath)
+	default:
+		reportRenderer().Report(city, countryOrState, weatherData, overviewData, isTerse)
+		if *alarmFlag && !scripted && !*notifyFlag && len(weatherData.Alerts) > 0 {
+			notifyAlerts(city, weatherData.Alerts)
+		}
+	}
+	if *notifyFlag {
+		sendThresholdNotification(city, weatherData, thresholds)
+	}
+	if scripted {
+		return 0
+	}
 
 	// --- Pause Before Exit Logic ---
 	// Replicate PowerShell script's "pause before exit" logic
@@ -685,9 +660,23 @@ func main() {
 		} // If parentProc can't be determined, default to pausing
 
 		if shouldPause {
-			fmt.Println() // Ensure the prompt is on a new line
-			psColorYellow.Print("Press Enter to exit...")
-			bufio.NewReader(os.Stdin).ReadBytes('\n') // Wait for Enter key
+			// Offer another location so a double-clicked window can check
+			// several places without relaunching; an empty line exits.
+			reader := bufio.NewReader(os.Stdin)
+			for {
+				fmt.Println() // Ensure the prompt is on a new line
+				psColorYellow.Print("Enter another location, or press Enter to exit: ")
+				input, err := reader.ReadString('\n')
+				locationInput = strings.TrimSpace(input)
+				if err != nil || locationInput == "" {
+					return 0
+				}
+				if err := showAnotherLocation(provider, locationInput, *logFlag, *alarmFlag); err != nil {
+					slog.Error("report failed", "location", locationInput, "err", err)
+					colorAlert.Println(userMessage(err))
+				}
+			}
 		}
 	}
+	return 0
 }
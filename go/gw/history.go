@@ -0,0 +1,203 @@
+package gw
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gw/internal/forecast"
+)
+
+const historyDirName = "history"
+
+var historyHeader = []string{"Time", "TempF", "Humidity", "WindMph", "GustMph", "Conditions"}
+
+var slugRegex = regexp.MustCompile(`[^a-z0-9]+`)
+
+// historyPath returns the per-location CSV -log writes, e.g.
+// ~/.config/gw/history/portland-or-us.csv.
+func historyPath(city, countryOrState string) (string, error) {
+	configPath, err := getConfigPath()
+	if err != nil {
+		return "", err
+	}
+	slug := strings.Trim(slugRegex.ReplaceAllString(strings.ToLower(city+" "+countryOrState), "-"), "-")
+	return filepath.Join(filepath.Dir(configPath), historyDirName, slug+".csv"), nil
+}
+
+// appendObservation adds the current conditions to path, writing the header
+// when the file is new.
+func appendObservation(path string, weather *forecast.WeatherData) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file %s: %w", path, err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if info, err := f.Stat(); err == nil && info.Size() == 0 {
+		w.Write(historyHeader)
+	}
+	current := weather.Current
+	w.Write([]string{
+		time.Unix(current.Dt, 0).Format(time.RFC3339),
+		strconv.FormatFloat(current.Temp, 'f', 1, 64),
+		strconv.Itoa(current.Humidity),
+		strconv.FormatFloat(current.WindSpeed, 'f', 1, 64),
+		strconv.FormatFloat(current.WindGust, 'f', 1, 64),
+		current.Conditions(),
+	})
+	w.Flush()
+	return w.Error()
+}
+
+// logObservation appends to path, reporting a failure without stopping
+// the report.
+func logObservation(path string, weather *forecast.WeatherData) {
+	if err := appendObservation(path, weather); err != nil {
+		slog.Warn("history log failed", "path", path, "err", err)
+		fmt.Fprintf(os.Stderr, "Could not log observation: %v\n", err)
+	}
+}
+
+// observation is one row of a history CSV.
+type observation struct {
+	at         time.Time
+	temp, wind float64
+	humidity   int
+	conditions string
+}
+
+// readObservations loads the rows of path recorded at or after since, oldest
+// first. Rows that do not parse are skipped.
+func readObservations(path string, since time.Time) ([]observation, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1
+	var rows []observation
+	for {
+		rec, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if len(rec) < len(historyHeader) {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339, rec[0])
+		if err != nil || at.Before(since) {
+			continue
+		}
+		temp, _ := strconv.ParseFloat(rec[1], 64)
+		humidity, _ := strconv.Atoi(rec[2])
+		wind, _ := strconv.ParseFloat(rec[3], 64)
+		rows = append(rows, observation{at: at.Local(), temp: temp, wind: wind, humidity: humidity, conditions: rec[5]})
+	}
+	sort.SliceStable(rows, func(i, j int) bool { return rows[i].at.Before(rows[j].at) })
+	return rows, nil
+}
+
+// printTrend summarizes the last days of a location's history: overall
+// temperature, humidity and wind, then one row per day.
+func printTrend(city, countryOrState string, days int) error {
+	path, err := historyPath(city, countryOrState)
+	if err != nil {
+		return err
+	}
+	rows, err := readObservations(path, time.Now().AddDate(0, 0, -days))
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no history for %s, %s yet; run gw -log to start one (%s)", city, countryOrState, path)
+	}
+	if err != nil {
+		return err
+	}
+	colorTitle.Printf("*** %s, %s Last %d Days ***\n", city, countryOrState, days)
+	if len(rows) == 0 {
+		colorInfo.Printf("No observations in the last %d days in %s\n", days, path)
+		return nil
+	}
+
+	lo, hi := rows[0], rows[0]
+	var tempSum, windMax float64
+	var humiditySum int
+	conditions := make(map[string]int)
+	for _, o := range rows {
+		if o.temp < lo.temp {
+			lo = o
+		}
+		if o.temp > hi.temp {
+			hi = o
+		}
+		tempSum += o.temp
+		humiditySum += o.humidity
+		windMax = max(windMax, o.wind)
+		conditions[o.conditions]++
+	}
+	n := float64(len(rows))
+	colorInfo.Printf("Observations: %d (%s to %s)\n", len(rows), rows[0].at.Format("Jan 2 3:04 PM"), rows[len(rows)-1].at.Format("Jan 2 3:04 PM"))
+	colorDefault.Printf("Temp: avg %.0f°F, low %.0f°F (%s), high %.0f°F (%s)\n", tempSum/n, lo.temp, lo.at.Format("Jan 2"), hi.temp, hi.at.Format("Jan 2"))
+	colorDefault.Printf("Humidity: avg %.0f%%\n", float64(humiditySum)/n)
+	colorDefault.Printf("Wind: max %.0f mph\n", windMax)
+	colorDefault.Printf("Most common: %s\n", mostCommon(conditions))
+
+	fmt.Println()
+	colorInfo.Printf("%-10s %6s %6s %6s  %s\n", "Day", "Low", "High", "Wind", "Conditions")
+	for _, day := range groupByDay(rows) {
+		dayLo, dayHi, dayWind := day[0].temp, day[0].temp, 0.0
+		dayConditions := make(map[string]int)
+		for _, o := range day {
+			dayLo, dayHi, dayWind = min(dayLo, o.temp), max(dayHi, o.temp), max(dayWind, o.wind)
+			dayConditions[o.conditions]++
+		}
+		colorDefault.Printf("%-10s %5.0f° %5.0f° %6.0f  %s\n", day[0].at.Format("Mon Jan 2"), dayLo, dayHi, dayWind, mostCommon(dayConditions))
+	}
+	return nil
+}
+
+// groupByDay splits rows (oldest first) into local calendar days.
+func groupByDay(rows []observation) [][]observation {
+	var days [][]observation
+	for _, o := range rows {
+		if n := len(days); n > 0 && days[n-1][0].at.Format("2006-01-02") == o.at.Format("2006-01-02") {
+			days[n-1] = append(days[n-1], o)
+			continue
+		}
+		days = append(days, []observation{o})
+	}
+	return days
+}
+
+// mostCommon returns the most frequent key, breaking ties alphabetically.
+func mostCommon(counts map[string]int) string {
+	keys := make([]string, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	best := ""
+	for _, k := range keys {
+		if best == "" || counts[k] > counts[best] {
+			best = k
+		}
+	}
+	return best
+}
@@ -0,0 +1,91 @@
+package gw
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gw/internal/forecast"
+	"gw/internal/format"
+)
+
+const icalTimeFormat = "20060102T150405Z"
+
+// icalEvent is one VEVENT. Sunrise and sunset are one-minute events.
+type icalEvent struct {
+	uid, summary, description string
+	start, end                time.Time
+}
+
+// icalEvents collects sunrise and sunset for each forecast day (a week or
+// so) and every active alert. UIDs are derived from the date and place so
+// importing a newer file updates events instead of duplicating them.
+func icalEvents(city string, weather *forecast.WeatherData) []icalEvent {
+	place := fmt.Sprintf("%.3f,%.3f", weather.Lat, weather.Lon)
+	var events []icalEvent
+	for _, day := range weather.Daily {
+		date := time.Unix(day.Dt, 0).UTC().Format("20060102")
+		for _, sun := range []struct {
+			name string
+			at   int64
+		}{{"Sunrise", day.Sunrise}, {"Sunset", day.Sunset}} {
+			if sun.at == 0 {
+				continue
+			}
+			start := time.Unix(sun.at, 0)
+			events = append(events, icalEvent{
+				uid:         fmt.Sprintf("%s-%s-%s@%s", strings.ToLower(sun.name), date, place, appName),
+				summary:     fmt.Sprintf("%s %s (%s)", sun.name, formatUnixTimeLocal(sun.at, "3:04 PM"), city),
+				description: day.Summary,
+				start:       start,
+				end:         start.Add(time.Minute),
+			})
+		}
+	}
+	for _, alert := range weather.Alerts {
+		end := alert.End
+		if end <= alert.Start {
+			end = alert.Start + 3600
+		}
+		events = append(events, icalEvent{
+			uid:         fmt.Sprintf("alert-%d-%s-%s@%s", alert.Start, strings.ReplaceAll(strings.ToLower(alert.Event), " ", "-"), place, appName),
+			summary:     fmt.Sprintf("⚠ %s (%s)", alert.Event, city),
+			description: alert.SenderName + "\n\n" + alert.Description,
+			start:       time.Unix(alert.Start, 0),
+			end:         time.Unix(end, 0),
+		})
+	}
+	return events
+}
+
+// writeICal writes the events for -ical to path and returns how many.
+func writeICal(path, city string, weather *forecast.WeatherData) (int, error) {
+	events := icalEvents(city, weather)
+	stamp := time.Now().UTC().Format(icalTimeFormat)
+
+	var b strings.Builder
+	b.WriteString(format.ICalFold("BEGIN:VCALENDAR"))
+	b.WriteString(format.ICalFold("VERSION:2.0"))
+	b.WriteString(format.ICalFold("PRODID:-//kreftus//gw//EN"))
+	b.WriteString(format.ICalFold("X-WR-CALNAME:" + format.ICalEscape("Weather for "+city)))
+	for _, e := range events {
+		b.WriteString(format.ICalFold("BEGIN:VEVENT"))
+		b.WriteString(format.ICalFold("UID:" + e.uid))
+		b.WriteString(format.ICalFold("DTSTAMP:" + stamp))
+		b.WriteString(format.ICalFold("DTSTART:" + e.start.UTC().Format(icalTimeFormat)))
+		b.WriteString(format.ICalFold("DTEND:" + e.end.UTC().Format(icalTimeFormat)))
+		b.WriteString(format.ICalFold("SUMMARY:" + format.ICalEscape(e.summary)))
+		if e.description != "" {
+			b.WriteString(format.ICalFold("DESCRIPTION:" + format.ICalEscape(e.description)))
+		}
+		b.WriteString(format.ICalFold("TRANSP:TRANSPARENT"))
+		b.WriteString(format.ICalFold("END:VEVENT"))
+	}
+	b.WriteString(format.ICalFold("END:VCALENDAR"))
+
+	if err := os.WriteFile(path, []byte(b.String()), 0644); err != nil {
+		return 0, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return len(events), nil
+}
@@ -0,0 +1,55 @@
+// Package astro computes the sun and moon values gw fills in for providers
+// that do not report them (NWS): sunrise, sunset and the moon phase.
+package astro
+
+import (
+	"math"
+	"time"
+)
+
+const synodicMonth = 29.530588853 // mean days from new moon to new moon
+
+// referenceNewMoon is the new moon of 2000-01-06 18:14 UTC, the usual epoch
+// for mean lunation arithmetic.
+var referenceNewMoon = time.Date(2000, 1, 6, 18, 14, 0, 0, time.UTC)
+
+// SunTimes computes sunrise and sunset (Unix seconds) for the calendar day
+// of date at lat/lon with the NOAA sunrise equation, good to a minute or
+// two. Both are 0 during polar day or night.
+func SunTimes(date time.Time, lat, lon float64) (sunrise, sunset int64) {
+	const j2000 = 2451545.0
+	rad := math.Pi / 180
+
+	noon := time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, time.UTC)
+	julianDay := float64(noon.Unix())/86400 + 2440587.5
+	n := math.Round(julianDay - j2000 + 0.0008)
+
+	meanSolarNoon := n - lon/360
+	anomaly := math.Mod(357.5291+0.98560028*meanSolarNoon, 360)
+	center := 1.9148*math.Sin(anomaly*rad) + 0.02*math.Sin(2*anomaly*rad) + 0.0003*math.Sin(3*anomaly*rad)
+	eclipticLon := math.Mod(anomaly+center+180+102.9372, 360)
+	transit := j2000 + meanSolarNoon + 0.0053*math.Sin(anomaly*rad) - 0.0069*math.Sin(2*eclipticLon*rad)
+
+	sinDecl := math.Sin(eclipticLon*rad) * math.Sin(23.4397*rad)
+	cosDecl := math.Cos(math.Asin(sinDecl))
+	cosHour := (math.Sin(-0.833*rad) - math.Sin(lat*rad)*sinDecl) / (math.Cos(lat*rad) * cosDecl)
+	if cosHour < -1 || cosHour > 1 {
+		return 0, 0
+	}
+	hourAngle := math.Acos(cosHour) / rad
+
+	toUnix := func(jd float64) int64 { return int64(math.Round((jd - 2440587.5) * 86400)) }
+	return toUnix(transit - hourAngle/360), toUnix(transit + hourAngle/360)
+}
+
+// Lunation returns the moon phase at t as OpenWeatherMap reports it: 0 new,
+// 0.25 first quarter, 0.5 full, 0.75 third quarter. This is the mean phase,
+// so it can be off by up to about half a day.
+func Lunation(t time.Time) float64 {
+	days := t.Sub(referenceNewMoon).Hours() / 24
+	phase := math.Mod(days/synodicMonth, 1)
+	if phase < 0 {
+		phase++
+	}
+	return phase
+}
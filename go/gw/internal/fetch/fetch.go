@@ -0,0 +1,190 @@
+// Package fetch downloads weather for gw. GetJSON is the one HTTP path every
+// API call takes (retries, redacted errors); OWM and NWS implement Fetcher
+// on top of it, each filling the forecast.WeatherData the views read.
+package fetch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"gw/internal/forecast"
+	"kreftus/shared/logging"
+)
+
+// UserAgent identifies gw; NWS and Nominatim ask clients to send one.
+const UserAgent = "gw/1.0 (+https://github.com/Thujone82/kreftus)"
+
+// Fetcher is a source of weather for a point.
+type Fetcher interface {
+	Weather(lat, lon float64) (*forecast.WeatherData, error)
+	Overview(lat, lon float64) (*forecast.OverviewData, error)
+}
+
+// Requests that fail in a way that may pass (no network, 5xx, 429) are tried
+// this many times in all, waiting retryBaseDelay, then twice that, and so on.
+const (
+	requestAttempts = 3
+	retryBaseDelay  = time.Second
+	maxRetryAfter   = 10 * time.Second
+)
+
+// ErrorKind sorts request failures by what the user can do about them.
+type ErrorKind int
+
+const (
+	KindBadResponse ErrorKind = iota // unreadable or unexpected body
+	KindNetwork                      // could not reach the host
+	KindBadAPIKey                    // 401/403
+	KindRateLimited                  // 429
+	KindNotFound                     // 404
+	KindServer                       // 5xx
+	KindHTTP                         // any other status
+)
+
+// APIError is what GetJSON returns for a failed request. URL has the API
+// key redacted, so the error is safe to print.
+type APIError struct {
+	Kind       ErrorKind
+	URL        string
+	Host       string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration // from a 429's Retry-After header
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("request to %s failed: %v", e.URL, e.Err)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("request to %s failed with status %d: %s", e.URL, e.StatusCode, e.Body)
+	}
+	return fmt.Sprintf("request to %s failed", e.URL)
+}
+
+func (e *APIError) Unwrap() error { return e.Err }
+
+// Temporary reports whether trying again could help.
+func (e *APIError) Temporary() bool {
+	return e.Kind == KindNetwork || e.Kind == KindServer || e.Kind == KindRateLimited
+}
+
+// withoutURL drops the *url.Error wrapper from a transport error, since it
+// repeats the unredacted URL (and so the API key).
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+// statusKind classifies an HTTP status code.
+func statusKind(code int) ErrorKind {
+	switch {
+	case code == http.StatusUnauthorized || code == http.StatusForbidden:
+		return KindBadAPIKey
+	case code == http.StatusTooManyRequests:
+		return KindRateLimited
+	case code == http.StatusNotFound:
+		return KindNotFound
+	case code >= 500:
+		return KindServer
+	}
+	return KindHTTP
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds, capped so
+// a long server hint does not hang the report.
+func parseRetryAfter(h string) time.Duration {
+	secs, err := strconv.Atoi(h)
+	if err != nil || secs <= 0 {
+		return 0
+	}
+	return min(time.Duration(secs)*time.Second, maxRetryAfter)
+}
+
+// retryDelay is the wait before attempt (2, 3, ...): exponential backoff
+// with up to 25% jitter, or the server's Retry-After when it asked for one.
+func retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	d := retryBaseDelay << (attempt - 2)
+	return d + time.Duration(rand.Int64N(int64(d/4)+1))
+}
+
+// GetJSON GETs url and decodes its JSON into target, retrying failures that
+// may pass (see APIError.Temporary) with backoff.
+func GetJSON(url string, target interface{}) error {
+	var err error
+	for attempt := 1; attempt <= requestAttempts; attempt++ {
+		if err = getJSONOnce(url, target); err == nil {
+			return nil
+		}
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.Temporary() || attempt == requestAttempts {
+			break
+		}
+		delay := retryDelay(attempt+1, apiErr.RetryAfter)
+		slog.Warn("request failed, retrying", "url", apiErr.URL, "attempt", attempt, "delay", delay, "err", apiErr)
+		time.Sleep(delay)
+	}
+	return err
+}
+
+// getJSONOnce makes one attempt for GetJSON, returning an *APIError for
+// anything that goes wrong after the request is built.
+func getJSONOnce(url string, target interface{}) error {
+	client := &http.Client{Timeout: 15 * time.Second}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	redacted := logging.RedactURL(req.URL)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return &APIError{Kind: KindNetwork, URL: redacted, Host: req.URL.Host, Err: withoutURL(err)}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &APIError{
+			Kind:       statusKind(resp.StatusCode),
+			URL:        redacted,
+			Host:       req.URL.Host,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(bodyBytes)),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &APIError{Kind: KindNetwork, URL: redacted, Host: req.URL.Host, Err: withoutURL(err)}
+	}
+	if len(body) == 0 {
+		return &APIError{Kind: KindBadResponse, URL: redacted, Host: req.URL.Host, Err: errors.New("empty response")}
+	}
+
+	err = json.Unmarshal(body, target)
+	if err != nil {
+		slog.Error("unexpected API response", "url", redacted, "body", string(body), "err", err)
+		return &APIError{Kind: KindBadResponse, URL: redacted, Host: req.URL.Host, Err: err}
+	}
+	return nil
+}
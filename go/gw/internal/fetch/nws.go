@@ -0,0 +1,336 @@
+package fetch
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gw/internal/astro"
+	"gw/internal/forecast"
+)
+
+const (
+	nwsPointsURL = "https://api.weather.gov/points/%.4f,%.4f"
+	nwsAlertsURL = "https://api.weather.gov/alerts/active?point=%.4f,%.4f"
+)
+
+// NWS (api.weather.gov) response shapes, trimmed to what gw reads.
+type nwsPoint struct {
+	Properties struct {
+		Forecast            string `json:"forecast"`
+		ForecastHourly      string `json:"forecastHourly"`
+		ObservationStations string `json:"observationStations"`
+	} `json:"properties"`
+}
+
+type nwsValue struct {
+	Value *float64 `json:"value"`
+}
+
+type nwsPeriod struct {
+	Name             string   `json:"name"`
+	StartTime        string   `json:"startTime"`
+	IsDaytime        bool     `json:"isDaytime"`
+	Temperature      float64  `json:"temperature"`
+	WindSpeed        string   `json:"windSpeed"`
+	ShortForecast    string   `json:"shortForecast"`
+	DetailedForecast string   `json:"detailedForecast"`
+	PrecipChance     nwsValue `json:"probabilityOfPrecipitation"`
+}
+
+type nwsForecast struct {
+	Properties struct {
+		Periods []nwsPeriod `json:"periods"`
+	} `json:"properties"`
+}
+
+type nwsStations struct {
+	Features []struct {
+		ID string `json:"id"`
+	} `json:"features"`
+}
+
+type nwsObservation struct {
+	Properties struct {
+		Timestamp        string   `json:"timestamp"`
+		TextDescription  string   `json:"textDescription"`
+		Temperature      nwsValue `json:"temperature"`      // °C
+		RelativeHumidity nwsValue `json:"relativeHumidity"` // %
+		WindSpeed        nwsValue `json:"windSpeed"`        // km/h
+		WindGust         nwsValue `json:"windGust"`         // km/h
+		WindDirection    nwsValue `json:"windDirection"`    // degrees
+	} `json:"properties"`
+}
+
+type nwsAlerts struct {
+	Features []struct {
+		Properties struct {
+			Event       string `json:"event"`
+			SenderName  string `json:"senderName"`
+			Onset       string `json:"onset"`
+			Effective   string `json:"effective"`
+			Ends        string `json:"ends"`
+			Expires     string `json:"expires"`
+			Description string `json:"description"`
+		} `json:"properties"`
+	} `json:"features"`
+}
+
+// NWS reads the free National Weather Service API (US only, no key). NWS
+// has no UV index, moonrise or moonset, so those stay empty; sun times come
+// from astro.SunTimes and the moon phase from astro.Lunation. The zero
+// value is ready to use.
+type NWS struct {
+	mu    sync.Mutex
+	daily map[string]*nwsForecast // by point, so Overview reuses Weather's fetch
+}
+
+// point fetches the /points metadata and the forecast it links to.
+func (p *NWS) point(lat, lon float64) (*nwsPoint, *nwsForecast, error) {
+	var point nwsPoint
+	if err := GetJSON(fmt.Sprintf(nwsPointsURL, lat, lon), &point); err != nil {
+		return nil, nil, fmt.Errorf("NWS has no data for this location (US locations only): %w", err)
+	}
+	var daily nwsForecast
+	if err := GetJSON(point.Properties.Forecast, &daily); err != nil {
+		return nil, nil, err
+	}
+	if len(daily.Properties.Periods) == 0 {
+		return nil, nil, fmt.Errorf("NWS returned no forecast periods")
+	}
+	p.mu.Lock()
+	if p.daily == nil {
+		p.daily = make(map[string]*nwsForecast)
+	}
+	p.daily[fmt.Sprintf("%.4f,%.4f", lat, lon)] = &daily
+	p.mu.Unlock()
+	return &point, &daily, nil
+}
+
+func (p *NWS) Weather(lat, lon float64) (*forecast.WeatherData, error) {
+	point, daily, err := p.point(lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	var hourly nwsForecast
+	if err := GetJSON(point.Properties.ForecastHourly, &hourly); err != nil {
+		return nil, err
+	}
+	data := &forecast.WeatherData{Lat: lat, Lon: lon, Source: forecast.SourceNWS}
+	if len(hourly.Properties.Periods) > 0 {
+		data.Current.Weather = []forecast.WeatherCondition{{Main: hourly.Properties.Periods[0].ShortForecast}}
+	}
+	for _, h := range hourly.Properties.Periods {
+		if at, err := time.Parse(time.RFC3339, h.StartTime); err == nil {
+			data.Hourly = append(data.Hourly, forecast.HourlyWeather{Dt: at.Unix(), Temp: h.Temperature})
+		}
+	}
+	if err := p.current(point, data); err != nil {
+		return nil, err
+	}
+	if data.Daily = nwsDaily(daily.Properties.Periods, data.Hourly, lat, lon); len(data.Daily) == 0 {
+		return nil, fmt.Errorf("NWS returned no usable forecast periods")
+	}
+	data.Current.Sunrise, data.Current.Sunset = data.Daily[0].Sunrise, data.Daily[0].Sunset
+	if data.Current.Temp > data.Daily[0].Temp.Max {
+		data.Daily[0].Temp.Max = data.Current.Temp
+	}
+
+	var alerts nwsAlerts
+	if err := GetJSON(fmt.Sprintf(nwsAlertsURL, lat, lon), &alerts); err != nil {
+		return nil, err
+	}
+	for _, f := range alerts.Features {
+		a := f.Properties
+		data.Alerts = append(data.Alerts, forecast.Alert{
+			SenderName:  a.SenderName,
+			Event:       a.Event,
+			Start:       parseNWSTime(cmp.Or(a.Onset, a.Effective)),
+			End:         parseNWSTime(cmp.Or(a.Ends, a.Expires)),
+			Description: a.Description,
+		})
+	}
+	return data, nil
+}
+
+// current fills data.Current from the nearest station's latest observation,
+// using the first hourly forecast for anything the station did not report.
+func (p *NWS) current(point *nwsPoint, data *forecast.WeatherData) error {
+	var stations nwsStations
+	if err := GetJSON(point.Properties.ObservationStations, &stations); err != nil {
+		return err
+	}
+	c := &data.Current
+	c.Dt = time.Now().Unix()
+	if len(data.Hourly) > 0 {
+		c.Temp = data.Hourly[0].Temp
+	}
+	if len(c.Weather) == 0 {
+		c.Weather = []forecast.WeatherCondition{{Main: "Unknown"}}
+	}
+	if len(stations.Features) == 0 {
+		return nil
+	}
+	var obs nwsObservation
+	if err := GetJSON(stations.Features[0].ID+"/observations/latest", &obs); err != nil {
+		return err
+	}
+	o := obs.Properties
+	if at, err := time.Parse(time.RFC3339, o.Timestamp); err == nil {
+		c.Dt = at.Unix()
+	}
+	if o.Temperature.Value != nil {
+		c.Temp = *o.Temperature.Value*9/5 + 32
+	}
+	if o.RelativeHumidity.Value != nil {
+		c.Humidity = int(math.Round(*o.RelativeHumidity.Value))
+	}
+	if o.WindSpeed.Value != nil {
+		c.WindSpeed = *o.WindSpeed.Value / 1.609344
+	}
+	if o.WindGust.Value != nil {
+		c.WindGust = *o.WindGust.Value / 1.609344
+	}
+	if o.WindDirection.Value != nil {
+		c.WindDeg = int(*o.WindDirection.Value)
+	}
+	if o.TextDescription != "" {
+		c.Weather = []forecast.WeatherCondition{{Main: o.TextDescription}}
+	}
+	return nil
+}
+
+func (p *NWS) Overview(lat, lon float64) (*forecast.OverviewData, error) {
+	p.mu.Lock()
+	daily := p.daily[fmt.Sprintf("%.4f,%.4f", lat, lon)]
+	p.mu.Unlock()
+	if daily == nil {
+		var err error
+		if _, daily, err = p.point(lat, lon); err != nil {
+			return nil, err
+		}
+	}
+	var parts []string
+	for _, period := range daily.Properties.Periods[:min(2, len(daily.Properties.Periods))] {
+		parts = append(parts, period.Name+": "+period.DetailedForecast)
+	}
+	return &forecast.OverviewData{WeatherOverview: strings.Join(parts, " ")}, nil
+}
+
+// nwsDaily folds the day/night forecast periods into one forecast.DailyWeather per
+// date: the day period gives the high, summary and conditions, the night
+// period the low, and the hourly forecast widens both where it reaches.
+func nwsDaily(periods []nwsPeriod, hourly []forecast.HourlyWeather, lat, lon float64) []forecast.DailyWeather {
+	var days []forecast.DailyWeather
+	index := make(map[string]int)
+	for _, period := range periods {
+		at, err := time.Parse(time.RFC3339, period.StartTime)
+		if err != nil {
+			continue
+		}
+		key := at.Format("2006-01-02")
+		i, ok := index[key]
+		if !ok {
+			noon := time.Date(at.Year(), at.Month(), at.Day(), 12, 0, 0, 0, at.Location())
+			sunrise, sunset := astro.SunTimes(noon, lat, lon)
+			days = append(days, forecast.DailyWeather{
+				Dt:        noon.Unix(),
+				Sunrise:   sunrise,
+				Sunset:    sunset,
+				MoonPhase: astro.Lunation(noon),
+				Temp:      forecast.DailyTemp{Min: period.Temperature, Max: period.Temperature},
+			})
+			i = len(days) - 1
+			index[key] = i
+		}
+		day := &days[i]
+		if period.IsDaytime || day.Summary == "" {
+			day.Summary = period.ShortForecast
+			day.Weather = []forecast.WeatherCondition{{Main: nwsCondition(period.ShortForecast)}}
+			day.WindSpeed = parseNWSWind(period.WindSpeed)
+		}
+		day.Temp.Min = min(day.Temp.Min, period.Temperature)
+		day.Temp.Max = max(day.Temp.Max, period.Temperature)
+		if period.PrecipChance.Value != nil {
+			day.Pop = max(day.Pop, *period.PrecipChance.Value/100)
+		}
+	}
+	// NWS has no feels-like; -week shows the air temperature at 9am, 3pm,
+	// 7pm and 11pm from the hourly forecast, or the low/high past its end.
+	for i := range days {
+		d := &days[i]
+		d.FeelsLike = forecast.DailyFeelsLike{Morn: d.Temp.Min, Day: d.Temp.Max, Eve: d.Temp.Max, Night: d.Temp.Min}
+	}
+	for _, h := range hourly {
+		at := time.Unix(h.Dt, 0)
+		i, ok := index[at.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		d := &days[i]
+		d.Temp.Min = min(d.Temp.Min, h.Temp)
+		d.Temp.Max = max(d.Temp.Max, h.Temp)
+		switch at.Hour() {
+		case 9:
+			d.FeelsLike.Morn = h.Temp
+		case 15:
+			d.FeelsLike.Day = h.Temp
+		case 19:
+			d.FeelsLike.Eve = h.Temp
+		case 23:
+			d.FeelsLike.Night = h.Temp
+		}
+	}
+	return days
+}
+
+// nwsCondition maps an NWS short forecast ("Chance Light Rain") onto the
+// OpenWeatherMap condition groups the rest of gw compares.
+func nwsCondition(short string) string {
+	s := strings.ToLower(short)
+	switch {
+	case strings.Contains(s, "thunder"):
+		return "Thunderstorm"
+	case strings.Contains(s, "snow"), strings.Contains(s, "sleet"), strings.Contains(s, "flurries"):
+		return "Snow"
+	case strings.Contains(s, "drizzle"):
+		return "Drizzle"
+	case strings.Contains(s, "rain"), strings.Contains(s, "showers"):
+		return "Rain"
+	case strings.Contains(s, "fog"):
+		return "Fog"
+	case strings.Contains(s, "haze"), strings.Contains(s, "smoke"):
+		return "Haze"
+	case strings.Contains(s, "cloudy"), strings.Contains(s, "overcast"):
+		return "Clouds"
+	case strings.Contains(s, "sunny"), strings.Contains(s, "clear"):
+		return "Clear"
+	}
+	return short
+}
+
+var windNumberRegex = regexp.MustCompile(`\d+`)
+
+// parseNWSWind reads "5 to 10 mph" as the upper figure, 10.
+func parseNWSWind(s string) float64 {
+	var speed float64
+	for _, n := range windNumberRegex.FindAllString(s, -1) {
+		v, _ := strconv.ParseFloat(n, 64)
+		speed = max(speed, v)
+	}
+	return speed
+}
+
+// parseNWSTime returns the Unix time of an RFC 3339 NWS timestamp, or 0.
+func parseNWSTime(s string) int64 {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,47 @@
+package fetch
+
+import (
+	"fmt"
+
+	"gw/internal/forecast"
+)
+
+const (
+	oneCallURL  = "https://api.openweathermap.org/data/3.0/onecall"
+	overviewURL = "https://api.openweathermap.org/data/3.0/onecall/overview"
+)
+
+// OWM is OpenWeatherMap One Call 3.0, gw's original source.
+type OWM struct {
+	APIKey string
+}
+
+func (o OWM) Weather(lat, lon float64) (*forecast.WeatherData, error) {
+	weatherURL := fmt.Sprintf("%s?lat=%f&lon=%f&appid=%s&units=imperial&lang=en&exclude=minutely",
+		oneCallURL, lat, lon, o.APIKey)
+	var data forecast.WeatherData
+	if err := GetJSON(weatherURL, &data); err != nil {
+		return nil, err
+	}
+	if data.Current.Dt == 0 {
+		return nil, fmt.Errorf("weather API returned incomplete 'current' data")
+	}
+	if len(data.Daily) == 0 {
+		return nil, fmt.Errorf("weather API returned no 'daily' forecast data")
+	}
+	data.Source = forecast.SourceOWM
+	return &data, nil
+}
+
+func (o OWM) Overview(lat, lon float64) (*forecast.OverviewData, error) {
+	overviewAPIURL := fmt.Sprintf("%s?lat=%f&lon=%f&appid=%s&units=imperial&lang=en",
+		overviewURL, lat, lon, o.APIKey)
+	var data forecast.OverviewData
+	if err := GetJSON(overviewAPIURL, &data); err != nil {
+		return nil, err
+	}
+	if data.WeatherOverview == "" {
+		return nil, fmt.Errorf("weather overview API returned empty 'weather_overview' data")
+	}
+	return &data, nil
+}
@@ -0,0 +1,169 @@
+// Package forecast is gw's weather model: the OpenWeatherMap One Call shape
+// every provider fills, and the values derived from it that more than one
+// view shows (temperature trend, conditions, day length).
+package forecast
+
+import (
+	"fmt"
+	"time"
+)
+
+// Sources, for WeatherData.Source.
+const (
+	SourceOWM = "owm"
+	SourceNWS = "nws"
+)
+
+type WeatherData struct {
+	Lat     float64         `json:"lat"`
+	Lon     float64         `json:"lon"`
+	Current CurrentWeather  `json:"current"`
+	Hourly  []HourlyWeather `json:"hourly,omitempty"`
+	Daily   []DailyWeather  `json:"daily"`
+	Alerts  []Alert         `json:"alerts,omitempty"`
+	Source  string          `json:"-"` // SourceOWM or SourceNWS, set by the provider
+}
+
+type CurrentWeather struct {
+	Dt        int64              `json:"dt"`
+	Sunrise   int64              `json:"sunrise"`
+	Sunset    int64              `json:"sunset"`
+	Temp      float64            `json:"temp"`
+	Humidity  int                `json:"humidity"`
+	UVI       float64            `json:"uvi"`
+	WindSpeed float64            `json:"wind_speed"`
+	WindDeg   int                `json:"wind_deg"`
+	WindGust  float64            `json:"wind_gust,omitempty"`
+	Weather   []WeatherCondition `json:"weather"`
+	Rain      *RainSnowInfo      `json:"rain,omitempty"`
+	Snow      *RainSnowInfo      `json:"snow,omitempty"`
+}
+
+type RainSnowInfo struct {
+	OneH float64 `json:"1h,omitempty"`
+}
+
+type WeatherCondition struct {
+	Main string `json:"main"`
+}
+
+type HourlyWeather struct {
+	Dt   int64   `json:"dt"`
+	Temp float64 `json:"temp"`
+}
+
+type DailyWeather struct {
+	Dt        int64              `json:"dt"`
+	Sunrise   int64              `json:"sunrise"` // Daily sunrise/sunset might differ slightly from current
+	Sunset    int64              `json:"sunset"`
+	Moonrise  int64              `json:"moonrise"`
+	Moonset   int64              `json:"moonset"`
+	MoonPhase float64            `json:"moon_phase"`
+	Summary   string             `json:"summary"`
+	Temp      DailyTemp          `json:"temp"`
+	FeelsLike DailyFeelsLike     `json:"feels_like"`
+	WindSpeed float64            `json:"wind_speed"`
+	Pop       float64            `json:"pop"` // Probability of precipitation, 0-1
+	Weather   []WeatherCondition `json:"weather"`
+}
+
+type DailyTemp struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type DailyFeelsLike struct {
+	Morn  float64 `json:"morn"`
+	Day   float64 `json:"day"`
+	Eve   float64 `json:"eve"`
+	Night float64 `json:"night"`
+}
+
+type Alert struct {
+	SenderName  string `json:"sender_name"`
+	Event       string `json:"event"`
+	Start       int64  `json:"start"`
+	End         int64  `json:"end"`
+	Description string `json:"description"`
+}
+
+type OverviewData struct {
+	WeatherOverview string `json:"weather_overview"`
+}
+
+// TemperatureTrend compares the current temperature with the hourly
+// forecast two hours out (as the PowerShell version did), returning
+// "Rising", "Falling" or "".
+func (w *WeatherData) TemperatureTrend() string {
+	if len(w.Hourly) <= 2 {
+		return ""
+	}
+	tempDiff := w.Hourly[2].Temp - w.Current.Temp
+	switch {
+	case tempDiff >= 0.67:
+		return "Rising"
+	case tempDiff <= -0.67:
+		return "Falling"
+	}
+	return ""
+}
+
+// Conditions is the main condition plus any rain or snow rate.
+func (c CurrentWeather) Conditions() string {
+	conditions := c.Weather[0].Main
+	if c.Rain != nil && c.Rain.OneH > 0 {
+		conditions = fmt.Sprintf("%s [%.1f mm/H Rain]", conditions, c.Rain.OneH)
+	}
+	if c.Snow != nil && c.Snow.OneH > 0 {
+		conditions = fmt.Sprintf("%s [%.1f mm/H Snow]", conditions, c.Snow.OneH)
+	}
+	return conditions
+}
+
+// MainCondition is the day's condition group, or "" when the API gave none.
+func (d DailyWeather) MainCondition() string {
+	if len(d.Weather) == 0 {
+		return ""
+	}
+	return d.Weather[0].Main
+}
+
+// DayLength is the time from sunrise to sunset, or 0 when either is missing
+// (polar day or night).
+func (d DailyWeather) DayLength() time.Duration {
+	if d.Sunrise == 0 || d.Sunset == 0 {
+		return 0
+	}
+	return time.Duration(d.Sunset-d.Sunrise) * time.Second
+}
+
+// DaylightChange estimates how much longer today is than yesterday. The API
+// has no yesterday, but day length changes almost linearly over a few days,
+// so tomorrow's change is within seconds of it.
+func (w *WeatherData) DaylightChange() (time.Duration, bool) {
+	if len(w.Daily) < 2 {
+		return 0, false
+	}
+	today, tomorrow := w.Daily[0].DayLength(), w.Daily[1].DayLength()
+	if today == 0 || tomorrow == 0 {
+		return 0, false
+	}
+	return tomorrow - today, true
+}
+
+// NextSunEvent returns "Sunrise" or "Sunset" and how long until it, using
+// tomorrow's sunrise once today's sunset has passed.
+func (w *WeatherData) NextSunEvent(now time.Time) (string, time.Duration, bool) {
+	today := w.Daily[0]
+	switch {
+	case today.Sunrise == 0 || today.Sunset == 0:
+		return "", 0, false
+	case now.Unix() < today.Sunrise:
+		return "Sunrise", time.Unix(today.Sunrise, 0).Sub(now), true
+	case now.Unix() < today.Sunset:
+		return "Sunset", time.Unix(today.Sunset, 0).Sub(now), true
+	case len(w.Daily) > 1 && w.Daily[1].Sunrise != 0:
+		return "Sunrise", time.Unix(w.Daily[1].Sunrise, 0).Sub(now), true
+	}
+	return "", 0, false
+}
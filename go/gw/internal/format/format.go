@@ -0,0 +1,114 @@
+// Package format holds gw's pure text formatting: the helpers that turn
+// numbers from the weather APIs into the strings the report, -json, -moon,
+// -ical and friends print. Nothing here does I/O or touches color, so the
+// output of each function depends only on its arguments.
+package format
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var directions = []string{"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE", "S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW"}
+
+// CardinalDirection names the 16-point compass direction for deg.
+func CardinalDirection(deg int) string {
+	val := int(math.Floor((float64(deg) / 22.5) + 0.5))
+	return directions[val%16]
+}
+
+// MoonPhase describes a lunation fraction (0 new, 0.5 full) as one of the
+// eight named phases.
+func MoonPhase(phase float64) string {
+	switch {
+	case phase < 0.0625 || phase >= 0.9375:
+		return "New Moon"
+	case phase < 0.1875:
+		return "Waxing Crescent"
+	case phase < 0.3125:
+		return "First Quarter"
+	case phase < 0.4375:
+		return "Waxing Gibbous"
+	case phase < 0.5625:
+		return "Full Moon"
+	case phase < 0.6875:
+		return "Waning Gibbous"
+	case phase < 0.8125:
+		return "Third Quarter"
+	default:
+		return "Waning Crescent"
+	}
+}
+
+// Wrap splits text into lines of at most width columns, breaking between
+// words. A width of zero or less returns text unchanged.
+func Wrap(text string, width int) []string {
+	if width <= 0 {
+		return []string{text}
+	}
+	var lines []string
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return lines
+	}
+
+	currentLine := words[0]
+	for _, word := range words[1:] {
+		if len(currentLine)+1+len(word) > width {
+			lines = append(lines, currentLine)
+			currentLine = word
+		} else {
+			currentLine += " " + word
+		}
+	}
+	lines = append(lines, currentLine)
+	return lines
+}
+
+// HoursMinutes renders d as "10h 22m" (or "22m" under an hour).
+func HoursMinutes(d time.Duration) string {
+	d = d.Round(time.Minute)
+	if h := int(d.Hours()); h > 0 {
+		return fmt.Sprintf("%dh %dm", h, int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+// SignedChange renders a signed change such as "+2m 41s" or "-58s".
+func SignedChange(d time.Duration) string {
+	sign := "+"
+	if d < 0 {
+		sign = "-"
+		d = -d
+	}
+	d = d.Round(time.Second)
+	if m := int(d.Minutes()); m > 0 {
+		return fmt.Sprintf("%s%dm %ds", sign, m, int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%s%ds", sign, int(d.Seconds()))
+}
+
+// ICalEscape escapes text values per RFC 5545.
+func ICalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// ICalFold splits a content line into 75-octet pieces, continuing each with
+// CRLF and a space, without breaking a UTF-8 character.
+func ICalFold(line string) string {
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts
+	}
+	b.WriteString(line + "\r\n")
+	return b.String()
+}
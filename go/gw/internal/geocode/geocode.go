@@ -0,0 +1,37 @@
+// Package geocode turns what the user typed (a zip code, "City, State" or
+// "lat,lon") into coordinates and a place name. OWM uses OpenWeatherMap's
+// geocoding API; Nominatim uses OpenStreetMap and needs no key.
+package geocode
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Geocoder resolves a location as typed.
+type Geocoder interface {
+	Geocode(location string) (lat, lon float64, city, countryOrState string, err error)
+}
+
+var (
+	zipCodeRegex = regexp.MustCompile(`^\d{5}(-\d{4})?$`)
+
+	// coordsRegex matches "45.5,-122.6" (spaces around the comma allowed).
+	coordsRegex = regexp.MustCompile(`^\s*(-?\d{1,2}(?:\.\d+)?)\s*,\s*(-?\d{1,3}(?:\.\d+)?)\s*$`)
+)
+
+// ParseCoordinates reports whether input is a latitude,longitude pair and
+// returns it. Pairs out of range are an error rather than a place name.
+func ParseCoordinates(input string) (lat, lon float64, ok bool, err error) {
+	m := coordsRegex.FindStringSubmatch(input)
+	if m == nil {
+		return 0, 0, false, nil
+	}
+	lat, _ = strconv.ParseFloat(m[1], 64)
+	lon, _ = strconv.ParseFloat(m[2], 64)
+	if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+		return 0, 0, true, fmt.Errorf("coordinates out of range: %s", input)
+	}
+	return lat, lon, true, nil
+}
@@ -0,0 +1,96 @@
+package geocode
+
+import (
+	"cmp"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gw/internal/fetch"
+)
+
+const (
+	nominatimURL = "https://nominatim.openstreetmap.org/search"
+	nwsPointsURL = "https://api.weather.gov/points/%.4f,%.4f"
+)
+
+type nominatimPlace struct {
+	Lat     string `json:"lat"`
+	Lon     string `json:"lon"`
+	Address struct {
+		City     string `json:"city"`
+		Town     string `json:"town"`
+		Village  string `json:"village"`
+		Hamlet   string `json:"hamlet"`
+		State    string `json:"state"`
+		Country  string `json:"country_code"`
+		Postcode string `json:"postcode"`
+	} `json:"address"`
+}
+
+// nwsPlace is the part of an NWS /points reply that names the point.
+type nwsPlace struct {
+	Properties struct {
+		RelativeLocation struct {
+			Properties struct {
+				City  string `json:"city"`
+				State string `json:"state"`
+			} `json:"properties"`
+		} `json:"relativeLocation"`
+	} `json:"properties"`
+}
+
+// nominatimMu spaces Nominatim requests a second apart, as its usage policy
+// asks, even when -alerts-all geocodes favorites concurrently.
+var (
+	nominatimMu   sync.Mutex
+	nominatimLast time.Time
+)
+
+// Nominatim geocodes US places with OpenStreetMap Nominatim for the NWS
+// provider, naming "lat,lon" input from the NWS point instead. Neither
+// needs a key.
+type Nominatim struct{}
+
+func (Nominatim) Geocode(location string) (float64, float64, string, string, error) {
+	if lat, lon, ok, err := ParseCoordinates(location); ok {
+		if err != nil {
+			return 0, 0, "", "", err
+		}
+		var point nwsPlace
+		if err := fetch.GetJSON(fmt.Sprintf(nwsPointsURL, lat, lon), &point); err != nil {
+			return 0, 0, "", "", fmt.Errorf("NWS has no data for %s (US locations only): %w", location, err)
+		}
+		rel := point.Properties.RelativeLocation.Properties
+		return lat, lon, rel.City, rel.State + ", US", nil
+	}
+
+	query := url.Values{"format": {"jsonv2"}, "limit": {"1"}, "addressdetails": {"1"}, "countrycodes": {"us"}}
+	if zipCodeRegex.MatchString(location) {
+		query.Set("postalcode", location[:5])
+	} else {
+		query.Set("q", strings.TrimSpace(location))
+	}
+	nominatimMu.Lock()
+	if wait := time.Second - time.Since(nominatimLast); wait > 0 {
+		time.Sleep(wait)
+	}
+	var places []nominatimPlace
+	err := fetch.GetJSON(nominatimURL+"?"+query.Encode(), &places)
+	nominatimLast = time.Now()
+	nominatimMu.Unlock()
+	if err != nil {
+		return 0, 0, "", "", fmt.Errorf("geocoding failed for '%s': %w", location, err)
+	}
+	if len(places) == 0 {
+		return 0, 0, "", "", fmt.Errorf("no geocoding results found for '%s'", location)
+	}
+	place := places[0]
+	lat, _ := strconv.ParseFloat(place.Lat, 64)
+	lon, _ := strconv.ParseFloat(place.Lon, 64)
+	city := cmp.Or(place.Address.City, place.Address.Town, place.Address.Village, place.Address.Hamlet, place.Address.Postcode)
+	return lat, lon, city, place.Address.State + ", " + strings.ToUpper(place.Address.Country), nil
+}
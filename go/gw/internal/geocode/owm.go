@@ -0,0 +1,91 @@
+package geocode
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gw/internal/fetch"
+)
+
+const (
+	geoZipURL     = "http://api.openweathermap.org/geo/1.0/zip"
+	geoDirectURL  = "http://api.openweathermap.org/geo/1.0/direct"
+	geoReverseURL = "http://api.openweathermap.org/geo/1.0/reverse"
+)
+
+type geoZipResponse struct {
+	Zip     string  `json:"zip"`
+	Name    string  `json:"name"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Country string  `json:"country"`
+}
+
+type geoDirectResponse struct {
+	Name    string  `json:"name"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+	Country string  `json:"country"`
+	State   string  `json:"state,omitempty"`
+}
+
+// OWM geocodes with OpenWeatherMap, using the same key as the weather.
+type OWM struct {
+	APIKey string
+}
+
+func (o OWM) Geocode(locationInput string) (lat, lon float64, city, countryOrState string, err error) {
+	if lat, lon, ok, err := ParseCoordinates(locationInput); ok {
+		if err != nil {
+			return 0, 0, "", "", err
+		}
+		city, countryOrState = o.reverse(lat, lon)
+		return lat, lon, city, countryOrState, nil
+	}
+	if zipCodeRegex.MatchString(locationInput) {
+		geoURL := fmt.Sprintf("%s?zip=%s,us&appid=%s", geoZipURL, url.QueryEscape(locationInput), o.APIKey)
+		var geoResp geoZipResponse
+		if err = fetch.GetJSON(geoURL, &geoResp); err != nil {
+			return 0, 0, "", "", fmt.Errorf("geocoding by zip failed for '%s': %w", locationInput, err)
+		}
+		if geoResp.Name == "" {
+			return 0, 0, "", "", fmt.Errorf("no geocoding results for zipcode '%s'", locationInput)
+		}
+		return geoResp.Lat, geoResp.Lon, geoResp.Name, geoResp.Country, nil
+	} else {
+		// For non-zip inputs, append ",us" if a comma isn't already present.
+		loc := strings.TrimSpace(locationInput)
+		if !strings.Contains(loc, ",") {
+			loc += ",us"
+		}
+		geoURL := fmt.Sprintf("%s?q=%s&limit=1&appid=%s", geoDirectURL, url.QueryEscape(loc), o.APIKey)
+		var geoRespArr []geoDirectResponse
+		if err = fetch.GetJSON(geoURL, &geoRespArr); err != nil {
+			return 0, 0, "", "", fmt.Errorf("geocoding by city failed for '%s': %w", locationInput, err)
+		}
+		if len(geoRespArr) == 0 {
+			return 0, 0, "", "", fmt.Errorf("no geocoding results found for '%s'", locationInput)
+		}
+		return geoRespArr[0].Lat, geoRespArr[0].Lon, geoRespArr[0].Name, geoRespArr[0].region(), nil
+	}
+}
+
+// reverse names the place nearest lat/lon, falling back to the coordinates
+// themselves when the lookup fails or finds nothing (at sea).
+func (o OWM) reverse(lat, lon float64) (city, countryOrState string) {
+	geoURL := fmt.Sprintf("%s?lat=%f&lon=%f&limit=1&appid=%s", geoReverseURL, lat, lon, o.APIKey)
+	var geoRespArr []geoDirectResponse
+	if err := fetch.GetJSON(geoURL, &geoRespArr); err != nil || len(geoRespArr) == 0 {
+		return fmt.Sprintf("%.4f", lat), fmt.Sprintf("%.4f", lon)
+	}
+	return geoRespArr[0].Name, geoRespArr[0].region()
+}
+
+// region is "State, Country", or just the country outside states.
+func (g geoDirectResponse) region() string {
+	if g.State != "" {
+		return g.State + ", " + g.Country
+	}
+	return g.Country
+}
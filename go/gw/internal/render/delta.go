@@ -0,0 +1,97 @@
+package render
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gw/internal/forecast"
+)
+
+// Differences below these are reported as "about the same".
+const (
+	deltaTempThreshold = 2.0 // °F
+	deltaWindThreshold = 5.0 // mph
+)
+
+// wetConditions are the condition groups that count as precipitation.
+var wetConditions = map[string]bool{"Rain": true, "Drizzle": true, "Thunderstorm": true, "Snow": true}
+
+// describeDelta phrases the change from today to tomorrow, e.g.
+// "6°F cooler, windier, rain ends".
+func describeDelta(today, tomorrow forecast.DailyWeather) string {
+	var parts []string
+
+	switch diff := tomorrow.Temp.Max - today.Temp.Max; {
+	case diff >= deltaTempThreshold:
+		parts = append(parts, fmt.Sprintf("%.0f°F warmer", diff))
+	case diff <= -deltaTempThreshold:
+		parts = append(parts, fmt.Sprintf("%.0f°F cooler", -diff))
+	default:
+		parts = append(parts, "similar temperatures")
+	}
+
+	switch diff := tomorrow.WindSpeed - today.WindSpeed; {
+	case diff >= deltaWindThreshold:
+		parts = append(parts, "windier")
+	case diff <= -deltaWindThreshold:
+		parts = append(parts, "calmer")
+	}
+
+	from, to := today.MainCondition(), tomorrow.MainCondition()
+	switch {
+	case from == to:
+	case wetConditions[from] && wetConditions[to]:
+		parts = append(parts, fmt.Sprintf("%s instead of %s", strings.ToLower(to), strings.ToLower(from)))
+	case wetConditions[from]:
+		parts = append(parts, strings.ToLower(from)+" ends")
+	case wetConditions[to]:
+		parts = append(parts, strings.ToLower(to)+" arrives")
+	case from == "Clear":
+		parts = append(parts, "clouds move in")
+	case to == "Clear":
+		parts = append(parts, "skies clear")
+	default:
+		parts = append(parts, strings.ToLower(to))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Delta prints today and tomorrow side by side with the change in the last
+// column, then the one-line summary from describeDelta.
+func (r Renderer) Delta(city, countryOrState string, weather *forecast.WeatherData) {
+	c := r.Colors
+	if len(weather.Daily) < 2 {
+		c.Alert.Fprintln(r.Out, "No forecast for tomorrow was returned.")
+		return
+	}
+	today, tomorrow := weather.Daily[0], weather.Daily[1]
+
+	c.Title.Fprintf(r.Out, "*** %s, %s Today vs Tomorrow ***\n", city, countryOrState)
+	c.Info.Fprintf(r.Out, "%-12s %-14s %-14s %s\n", "", "Today", "Tomorrow", "Change")
+	r.deltaRow("High", "%.0f°F", today.Temp.Max, tomorrow.Temp.Max, deltaTempThreshold)
+	r.deltaRow("Low", "%.0f°F", today.Temp.Min, tomorrow.Temp.Min, deltaTempThreshold)
+	r.deltaRow("Wind", "%.0f mph", today.WindSpeed, tomorrow.WindSpeed, deltaWindThreshold)
+	r.deltaRow("Rain chance", "%.0f%%", today.Pop*100, tomorrow.Pop*100, 20)
+	c.Text.Fprintf(r.Out, "%-12s %-14s %-14s\n", "Conditions", today.MainCondition(), tomorrow.MainCondition())
+	fmt.Fprintln(r.Out)
+	r.wrapped(c.Accent, "Tomorrow: "+describeDelta(today, tomorrow)+".")
+	if tomorrow.Summary != "" {
+		r.wrapped(c.Info, tomorrow.Summary)
+	}
+}
+
+// deltaRow prints one comparison row, highlighting changes of at least
+// threshold.
+func (r Renderer) deltaRow(label, format string, today, tomorrow, threshold float64) {
+	diff := tomorrow - today
+	c := r.Colors.Text
+	if math.Abs(diff) >= threshold {
+		c = r.Colors.Sun
+	}
+	change := fmt.Sprintf("%+.0f", diff)
+	if change == "+0" || change == "-0" {
+		change = "0"
+	}
+	c.Fprintf(r.Out, "%-12s %-14s %-14s %s\n", label, fmt.Sprintf(format, today), fmt.Sprintf(format, tomorrow), change)
+}
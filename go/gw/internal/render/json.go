@@ -0,0 +1,128 @@
+package render
+
+import (
+	"encoding/json"
+	"time"
+
+	"gw/internal/forecast"
+	"gw/internal/format"
+)
+
+// jsonReport is what -json prints: the same data as the colored report, with
+// times in RFC 3339 and units fixed to imperial (°F, mph) like the display.
+type jsonReport struct {
+	Location   string      `json:"location"`
+	Region     string      `json:"region"`
+	Lat        float64     `json:"lat"`
+	Lon        float64     `json:"lon"`
+	Units      string      `json:"units"`
+	Source     string      `json:"source"` // provider: owm or nws
+	Observed   string      `json:"observed"`
+	Conditions string      `json:"conditions"`
+	Forecast   string      `json:"forecast"`
+	Tomorrow   string      `json:"tomorrow,omitempty"`
+	Temp       float64     `json:"temp"`
+	TempTrend  string      `json:"temp_trend,omitempty"`
+	Low        float64     `json:"low"`
+	High       float64     `json:"high"`
+	Humidity   int         `json:"humidity"`
+	UVI        float64     `json:"uvi"`
+	WindSpeed  float64     `json:"wind_speed"`
+	WindGust   float64     `json:"wind_gust,omitempty"`
+	WindDir    string      `json:"wind_dir"`
+	RainMMH    float64     `json:"rain_mm_h,omitempty"`
+	SnowMMH    float64     `json:"snow_mm_h,omitempty"`
+	Sunrise    string      `json:"sunrise,omitempty"`
+	Sunset     string      `json:"sunset,omitempty"`
+	Daylight   int         `json:"daylight_min,omitempty"`        // today's day length
+	DayChange  int         `json:"daylight_change_sec,omitempty"` // vs yesterday, see DaylightChange
+	Moonrise   string      `json:"moonrise,omitempty"`
+	Moonset    string      `json:"moonset,omitempty"`
+	MoonPhase  string      `json:"moon_phase"`
+	Overview   string      `json:"overview,omitempty"`
+	URL        string      `json:"url"`
+	Alerts     []jsonAlert `json:"alerts"`
+}
+
+type jsonAlert struct {
+	Event       string `json:"event"`
+	Sender      string `json:"sender"`
+	Start       string `json:"start,omitempty"`
+	End         string `json:"end,omitempty"`
+	Description string `json:"description"`
+}
+
+// rfc3339 formats a Unix time for JSON, leaving 0 (no moonrise, say) empty.
+func (r Renderer) rfc3339(unixTime int64) string {
+	if unixTime == 0 {
+		return ""
+	}
+	return time.Unix(unixTime, 0).In(r.Location).Format(time.RFC3339)
+}
+
+// newJSONReport builds the -json document.
+func (r Renderer) newJSONReport(city, countryOrState string, weather *forecast.WeatherData, overview *forecast.OverviewData) jsonReport {
+	current := weather.Current
+	today := weather.Daily[0]
+	report := jsonReport{
+		Location:   city,
+		Region:     countryOrState,
+		Lat:        weather.Lat,
+		Lon:        weather.Lon,
+		Units:      "imperial",
+		Source:     weather.Source,
+		Observed:   r.rfc3339(current.Dt),
+		Conditions: current.Weather[0].Main,
+		Forecast:   today.Summary,
+		Temp:       current.Temp,
+		TempTrend:  weather.TemperatureTrend(),
+		Low:        today.Temp.Min,
+		High:       today.Temp.Max,
+		Humidity:   current.Humidity,
+		UVI:        current.UVI,
+		WindSpeed:  current.WindSpeed,
+		WindGust:   current.WindGust,
+		WindDir:    format.CardinalDirection(current.WindDeg),
+		Sunrise:    r.rfc3339(current.Sunrise),
+		Sunset:     r.rfc3339(current.Sunset),
+		Moonrise:   r.rfc3339(today.Moonrise),
+		Moonset:    r.rfc3339(today.Moonset),
+		MoonPhase:  format.MoonPhase(today.MoonPhase),
+		URL:        forecastURL(weather),
+		Alerts:     []jsonAlert{},
+	}
+	if current.Rain != nil {
+		report.RainMMH = current.Rain.OneH
+	}
+	if current.Snow != nil {
+		report.SnowMMH = current.Snow.OneH
+	}
+	if len(weather.Daily) > 1 {
+		report.Tomorrow = weather.Daily[1].Summary
+	}
+	report.Daylight = int(today.DayLength().Round(time.Minute).Minutes())
+	if change, ok := weather.DaylightChange(); ok {
+		report.DayChange = int(change.Round(time.Second).Seconds())
+	}
+	if overview != nil {
+		report.Overview = overview.WeatherOverview
+	}
+	for _, alert := range weather.Alerts {
+		report.Alerts = append(report.Alerts, jsonAlert{
+			Event:       alert.Event,
+			Sender:      alert.SenderName,
+			Start:       r.rfc3339(alert.Start),
+			End:         r.rfc3339(alert.End),
+			Description: alert.Description,
+		})
+	}
+	return report
+}
+
+// JSON writes the -json document, indented for readability. overview is
+// nil in terse mode.
+func (r Renderer) JSON(city, countryOrState string, weather *forecast.WeatherData, overview *forecast.OverviewData) error {
+	enc := json.NewEncoder(r.Out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r.newJSONReport(city, countryOrState, weather, overview))
+}
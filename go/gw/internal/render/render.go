@@ -0,0 +1,169 @@
+// Package render draws gw's weather views (the report, -week, -delta,
+// -short and -json) from a forecast.WeatherData. A Renderer writes to any
+// io.Writer with the colors, wrap width, time zone and clock it is given,
+// so the same data always renders the same text.
+package render
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fatih/color"
+	"gw/internal/forecast"
+	"gw/internal/format"
+)
+
+// Palette holds the colors for each role in a view. Alert, Title, Info,
+// Sun, Moon and Text follow the themes.ini roles; Accent is the cyan of the
+// "Tomorrow:" line and the forecast link.
+type Palette struct {
+	Alert, Title, Info, Sun, Moon, Text, Accent *color.Color
+}
+
+// Renderer writes views to Out.
+type Renderer struct {
+	Out      io.Writer
+	Colors   Palette
+	Width    int            // column width text wraps at; 0 does not wrap
+	Location *time.Location // zone times are shown in
+	Now      func() time.Time
+}
+
+// wrapped prints text wrapped at r.Width in color c.
+func (r Renderer) wrapped(c *color.Color, text string) {
+	for _, line := range format.Wrap(text, r.Width) {
+		c.Fprintln(r.Out, line)
+	}
+}
+
+// clock formats a Unix time in r.Location, or "N/A" for 0.
+func (r Renderer) clock(unixTime int64, layout string) string {
+	if unixTime == 0 {
+		return "N/A"
+	}
+	return time.Unix(unixTime, 0).In(r.Location).Format(layout)
+}
+
+// limitColors picks the colors the report and -short use for values past
+// the alert limits: temperature under 33°F or over 89°F, wind from 16 mph,
+// UV index from 6.
+func (r Renderer) limitColors(current forecast.CurrentWeather) (temp, wind, uv *color.Color) {
+	temp, wind, uv = r.Colors.Text, r.Colors.Text, r.Colors.Text
+	if current.Temp < 33 || current.Temp > 89 {
+		temp = r.Colors.Alert
+	}
+	if current.WindSpeed >= 16 {
+		wind = r.Colors.Alert
+	}
+	if current.UVI >= 6 {
+		uv = r.Colors.Alert
+	}
+	return temp, wind, uv
+}
+
+// Report prints the full report: current conditions, sun and moon, the
+// overview (unless terse or nil) and any alerts.
+func (r Renderer) Report(city, countryOrState string, weather *forecast.WeatherData, overview *forecast.OverviewData, isTerse bool) {
+	c := r.Colors
+	current := weather.Current
+	dailyToday := weather.Daily[0] // Assumes at least one day is present, checked by the fetchers
+	tempC, windC, uvC := r.limitColors(current)
+
+	tempIndicator := ""
+	if trend := weather.TemperatureTrend(); trend != "" {
+		tempIndicator = "(" + trend + ")"
+	}
+
+	windDisplay := fmt.Sprintf("%.1f mph %s", current.WindSpeed, format.CardinalDirection(current.WindDeg))
+	windLabel := "Wind:"
+	if current.WindGust > 0 {
+		windLabel = "Wind[Gust]:"
+		windDisplay = fmt.Sprintf("%.1f mph [%.1f mph] %s", current.WindSpeed, current.WindGust, format.CardinalDirection(current.WindDeg))
+	}
+
+	c.Title.Fprintf(r.Out, "*** %s, %s Current Conditions ***\n", city, countryOrState)
+	r.wrapped(c.Info, "Forecast: "+dailyToday.Summary)
+	c.Text.Fprintf(r.Out, "Currently: %s\n", current.Conditions())
+	tempC.Fprintf(r.Out, "Temp [L/H]: %.0f°F%s [%.0f°F/%.0f°F]\n", current.Temp, tempIndicator, dailyToday.Temp.Min, dailyToday.Temp.Max)
+	c.Text.Fprintf(r.Out, "Humidity: %d%%\n", current.Humidity)
+	if weather.Source == forecast.SourceNWS {
+		c.Text.Fprintln(r.Out, "UV Index: N/A")
+	} else {
+		uvC.Fprintf(r.Out, "UV Index: %.1f\n", current.UVI)
+	}
+	windC.Fprintf(r.Out, "%s %s\n", windLabel, windDisplay)
+
+	if len(weather.Daily) > 1 {
+		r.wrapped(c.Accent, "Tomorrow: "+weather.Daily[1].Summary)
+	}
+
+	c.Sun.Fprintf(r.Out, "Sunrise: %s\n", r.clock(current.Sunrise, "3:04 PM"))
+	c.Sun.Fprintf(r.Out, "Sunset: %s\n", r.clock(current.Sunset, "3:04 PM"))
+	if !isTerse {
+		r.daylight(weather)
+	}
+	c.Moon.Fprintf(r.Out, "Moonrise: %s\n", r.clock(dailyToday.Moonrise, "3:04 PM"))
+	c.Moon.Fprintf(r.Out, "Moonset: %s\n", r.clock(dailyToday.Moonset, "3:04 PM"))
+	c.Moon.Fprintf(r.Out, "Moon Phase: %s\n", format.MoonPhase(dailyToday.MoonPhase))
+	c.Info.Fprintf(r.Out, "Observed: %s\n", r.clock(current.Dt, "Jan 2, 2006 3:04 PM"))
+	if weather.Source == forecast.SourceNWS {
+		c.Info.Fprintln(r.Out, "Source: National Weather Service (api.weather.gov)")
+	}
+
+	if !isTerse && overview != nil {
+		fmt.Fprintln(r.Out)
+		c.Title.Fprintf(r.Out, "*** %s, %s Weather Report ***\n", city, countryOrState)
+		r.wrapped(c.Text, overview.WeatherOverview)
+		fmt.Fprintln(r.Out)
+		c.Accent.Fprintln(r.Out, forecastURL(weather))
+	}
+
+	for _, alert := range weather.Alerts {
+		fmt.Fprintln(r.Out)
+		c.Alert.Fprintf(r.Out, "*** %s - %s ***\n", alert.Event, alert.SenderName)
+		if !isTerse {
+			r.wrapped(c.Text, alert.Description)
+		}
+		c.Info.Fprintf(r.Out, "Starts: %s\n", r.clock(alert.Start, "Jan 2, 2006 3:04 PM MST"))
+		c.Info.Fprintf(r.Out, "Ends: %s\n", r.clock(alert.End, "Jan 2, 2006 3:04 PM MST"))
+	}
+}
+
+// forecastURL links the NWS forecast page for the point.
+func forecastURL(weather *forecast.WeatherData) string {
+	return fmt.Sprintf("https://forecast.weather.gov/MapClick.php?lat=%f&lon=%f", weather.Lat, weather.Lon)
+}
+
+// daylight prints the day length, its change vs yesterday and the countdown
+// to the next sunrise or sunset.
+func (r Renderer) daylight(weather *forecast.WeatherData) {
+	if length := weather.Daily[0].DayLength(); length > 0 {
+		if change, ok := weather.DaylightChange(); ok {
+			r.Colors.Sun.Fprintf(r.Out, "Daylight: %s (%s vs yesterday)\n", format.HoursMinutes(length), format.SignedChange(change))
+		} else {
+			r.Colors.Sun.Fprintf(r.Out, "Daylight: %s\n", format.HoursMinutes(length))
+		}
+	}
+	if event, until, ok := weather.NextSunEvent(r.Now()); ok {
+		r.Colors.Sun.Fprintf(r.Out, "%s in: %s\n", event, format.HoursMinutes(until))
+	}
+}
+
+// Short prints the -short line, e.g. "Portland 47°F Rain 12mph NW UV2",
+// using the same alert colors as the full report and ending with any active
+// alert events.
+func (r Renderer) Short(city string, weather *forecast.WeatherData) {
+	current := weather.Current
+	tempC, windC, uvC := r.limitColors(current)
+
+	r.Colors.Title.Fprint(r.Out, city)
+	tempC.Fprintf(r.Out, " %.0f°F", current.Temp)
+	r.Colors.Text.Fprintf(r.Out, " %s", current.Weather[0].Main)
+	windC.Fprintf(r.Out, " %.0fmph %s", current.WindSpeed, format.CardinalDirection(current.WindDeg))
+	uvC.Fprintf(r.Out, " UV%.0f", current.UVI)
+	for _, alert := range weather.Alerts {
+		r.Colors.Alert.Fprintf(r.Out, " ⚠ %s", alert.Event)
+	}
+	fmt.Fprintln(r.Out)
+}
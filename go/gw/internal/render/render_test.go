@@ -0,0 +1,164 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"gw/internal/forecast"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/*.golden from the current output")
+
+func TestMain(m *testing.M) {
+	color.NoColor = true
+	os.Exit(m.Run())
+}
+
+var (
+	pacific = time.FixedZone("PDT", -7*60*60)
+	now     = time.Date(2025, 6, 20, 9, 30, 0, 0, pacific)
+)
+
+// at is the Unix time of hh:mm on the fixture's day plus days.
+func at(days, hh, mm int) int64 {
+	return time.Date(2025, 6, 20+days, hh, mm, 0, 0, pacific).Unix()
+}
+
+// fixture is an OpenWeatherMap report for Portland with a gusty wind, a
+// rising temperature and one alert.
+func fixture() *forecast.WeatherData {
+	return &forecast.WeatherData{
+		Lat: 45.5152, Lon: -122.6784,
+		Source: forecast.SourceOWM,
+		Current: forecast.CurrentWeather{
+			Dt: now.Unix(), Sunrise: at(0, 5, 21), Sunset: at(0, 21, 3),
+			Temp: 91.4, Humidity: 38, UVI: 7.2, WindSpeed: 12.6, WindDeg: 315, WindGust: 21.3,
+			Weather: []forecast.WeatherCondition{{Main: "Clear"}},
+		},
+		Hourly: []forecast.HourlyWeather{{Dt: at(0, 9, 0), Temp: 91}, {Dt: at(0, 10, 0), Temp: 92}, {Dt: at(0, 11, 0), Temp: 93.5}},
+		Daily: []forecast.DailyWeather{
+			{
+				Dt: at(0, 13, 0), Sunrise: at(0, 5, 21), Sunset: at(0, 21, 3), Moonrise: at(0, 1, 12), Moonset: at(0, 15, 40),
+				MoonPhase: 0.82, Summary: "Expect a day of sunshine with record heat in the afternoon and a light breeze from the northwest.",
+				Temp: forecast.DailyTemp{Min: 64, Max: 98}, FeelsLike: forecast.DailyFeelsLike{Morn: 70, Day: 97, Eve: 90, Night: 72},
+				WindSpeed: 9, Pop: 0, Weather: []forecast.WeatherCondition{{Main: "Clear"}},
+			},
+			{
+				Dt: at(1, 13, 0), Sunrise: at(1, 5, 21), Sunset: at(1, 21, 3) + 15, Moonrise: at(1, 1, 40), Moonset: at(1, 16, 55),
+				MoonPhase: 0.86, Summary: "There will be rain until afternoon.",
+				Temp: forecast.DailyTemp{Min: 58, Max: 77}, FeelsLike: forecast.DailyFeelsLike{Morn: 59, Day: 76, Eve: 70, Night: 60},
+				WindSpeed: 15, Pop: 0.8, Weather: []forecast.WeatherCondition{{Main: "Rain"}},
+			},
+			{
+				Dt: at(2, 13, 0), Sunrise: at(2, 5, 22), Sunset: at(2, 21, 3) + 30,
+				MoonPhase: 0.9, Summary: "Partly cloudy.",
+				Temp: forecast.DailyTemp{Min: 29, Max: 61}, FeelsLike: forecast.DailyFeelsLike{Morn: 31, Day: 60, Eve: 55, Night: 30},
+				WindSpeed: 6, Pop: 0.1, Weather: []forecast.WeatherCondition{{Main: "Clouds"}},
+			},
+			{
+				Dt: at(3, 13, 0), Sunrise: at(3, 5, 22), Sunset: at(3, 21, 4),
+				MoonPhase: 0.94, Summary: "Volcanic ash.",
+				Temp: forecast.DailyTemp{Min: 55, Max: 70}, FeelsLike: forecast.DailyFeelsLike{Morn: 56, Day: 69, Eve: 66, Night: 57},
+				Weather: []forecast.WeatherCondition{{Main: "Ash"}},
+			},
+		},
+		Alerts: []forecast.Alert{{
+			SenderName: "NWS Portland OR", Event: "Excessive Heat Warning",
+			Start: at(0, 11, 0), End: at(1, 23, 0),
+			Description: "Dangerously hot conditions with temperatures up to 105 expected. Drink plenty of fluids and stay in an air-conditioned room.",
+		}},
+	}
+}
+
+// nwsFixture is the same place as NWS reports it: no UV index, moonrise,
+// gust or feels-like, a shower in the current conditions and no alerts.
+func nwsFixture() *forecast.WeatherData {
+	w := fixture()
+	w.Source = forecast.SourceNWS
+	w.Current.UVI, w.Current.WindGust = 0, 0
+	w.Current.Weather = []forecast.WeatherCondition{{Main: "Light Rain"}}
+	w.Current.Rain = &forecast.RainSnowInfo{OneH: 0.4}
+	w.Current.Temp = 68
+	w.Hourly = w.Hourly[:1]
+	for i := range w.Daily {
+		w.Daily[i].Moonrise, w.Daily[i].Moonset = 0, 0
+	}
+	w.Alerts = nil
+	return w
+}
+
+var overview = &forecast.OverviewData{WeatherOverview: "Today is hot and sunny with a high near 98. Tonight stays warm. Tomorrow brings a cold front with rain by morning and much cooler air."}
+
+func newRenderer(out *bytes.Buffer) Renderer {
+	plain := color.New()
+	return Renderer{
+		Out:      out,
+		Colors:   Palette{Alert: plain, Title: plain, Info: plain, Sun: plain, Moon: plain, Text: plain, Accent: plain},
+		Width:    60,
+		Location: pacific,
+		Now:      func() time.Time { return now },
+	}
+}
+
+// checkGolden compares got with testdata/name.golden, rewriting the file
+// instead with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s (go test -update rewrites it)\n--- got:\n%s\n--- want:\n%s", path, got, want)
+	}
+}
+
+func TestViews(t *testing.T) {
+	tests := []struct {
+		name string
+		draw func(r Renderer)
+	}{
+		{"report", func(r Renderer) { r.Report("Portland", "OR, US", fixture(), overview, false) }},
+		{"report_terse", func(r Renderer) { r.Report("Portland", "OR, US", fixture(), nil, true) }},
+		{"report_nws", func(r Renderer) { r.Report("Portland", "OR, US", nwsFixture(), overview, false) }},
+		{"week", func(r Renderer) { r.Week("Portland", "OR, US", fixture()) }},
+		{"week_nws", func(r Renderer) { r.Week("Portland", "OR, US", nwsFixture()) }},
+		{"delta", func(r Renderer) { r.Delta("Portland", "OR, US", fixture()) }},
+		{"short", func(r Renderer) { r.Short("Portland", fixture()) }},
+		{"short_nws", func(r Renderer) { r.Short("Portland", nwsFixture()) }},
+		{"json", func(r Renderer) {
+			if err := r.JSON("Portland", "OR, US", fixture(), overview); err != nil {
+				t.Fatal(err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tt.draw(newRenderer(&out))
+			checkGolden(t, tt.name, out.Bytes())
+		})
+	}
+}
+
+func TestDeltaWithoutTomorrow(t *testing.T) {
+	w := fixture()
+	w.Daily = w.Daily[:1]
+	var out bytes.Buffer
+	newRenderer(&out).Delta("Portland", "OR, US", w)
+	if got, want := out.String(), "No forecast for tomorrow was returned.\n"; got != want {
+		t.Errorf("Delta with one day = %q, want %q", got, want)
+	}
+}
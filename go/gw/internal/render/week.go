@@ -0,0 +1,57 @@
+package render
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"gw/internal/forecast"
+)
+
+// weekGlyphs are the -week condition icons. They go last on each row since
+// terminals disagree on how wide emoji are.
+var weekGlyphs = map[string]string{
+	"Clear":        "☀️",
+	"Clouds":       "☁️",
+	"Rain":         "🌧️",
+	"Drizzle":      "🌦️",
+	"Thunderstorm": "⛈️",
+	"Snow":         "❄️",
+	"Mist":         "🌫️",
+	"Fog":          "🌫️",
+	"Haze":         "🌫️",
+	"Smoke":        "🌫️",
+}
+
+// weekTempColor colors a temperature cell like the report's temperature line.
+func (r Renderer) weekTempColor(temp float64) *color.Color {
+	if temp < 33 || temp > 89 {
+		return r.Colors.Alert
+	}
+	return r.Colors.Text
+}
+
+// Week prints the -week grid: one row per forecast day with the feels-like
+// temperature for morning, afternoon, evening and night, the low/high, rain
+// chance and conditions, in under 80 columns.
+func (r Renderer) Week(city, countryOrState string, weather *forecast.WeatherData) {
+	c := r.Colors
+	label := "feels like"
+	if weather.Source == forecast.SourceNWS {
+		label = "temperature" // NWS reports no feels-like
+	}
+	c.Title.Fprintf(r.Out, "*** %s, %s Week at a Glance (%s) ***\n", city, countryOrState, label)
+	c.Info.Fprintf(r.Out, "%-10s %6s%6s%6s%6s  %-9s %4s  %s\n", "", "Morn", "Day", "Eve", "Night", "Low/High", "Rain", "Conditions")
+	for _, day := range weather.Daily {
+		c.Info.Fprintf(r.Out, "%-10s ", r.clock(day.Dt, "Mon Jan 2"))
+		for _, temp := range []float64{day.FeelsLike.Morn, day.FeelsLike.Day, day.FeelsLike.Eve, day.FeelsLike.Night} {
+			r.weekTempColor(temp).Fprintf(r.Out, " %4.0f°", temp)
+		}
+		c.Text.Fprintf(r.Out, "  %-9s %3.0f%%  ", fmt.Sprintf("%.0f/%.0f°", day.Temp.Min, day.Temp.Max), day.Pop*100)
+		condition := day.MainCondition()
+		glyph, ok := weekGlyphs[condition]
+		if !ok {
+			glyph = "·"
+		}
+		c.Text.Fprintf(r.Out, "%s %s\n", glyph, condition)
+	}
+}
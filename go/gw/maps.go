@@ -0,0 +1,121 @@
+package gw
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"gw/internal/fetch"
+	"kreftus/shared/logging"
+)
+
+const (
+	tileURL       = "https://tile.openweathermap.org/map/%s/%d/%d/%d.png?appid=%s"
+	weatherMapURL = "https://openweathermap.org/weathermap?basemap=map&cities=true&layer=%s&lat=%.4f&lon=%.4f&zoom=%d"
+	tileSize      = 256
+)
+
+// mapLayers are the OpenWeatherMap tile layers -map prints.
+var mapLayers = []struct{ name, layer string }{
+	{"Precipitation", "precipitation_new"},
+	{"Clouds", "clouds_new"},
+	{"Temperature", "temp_new"},
+	{"Wind", "wind_new"},
+}
+
+// asciiRamp maps precipitation intensity (tile alpha) to characters, from
+// none to heavy.
+const asciiRamp = " .:-=+*#%@"
+
+// tileFor returns the slippy-map tile holding lat/lon at zoom, and the
+// pixel within that tile.
+func tileFor(lat, lon float64, zoom int) (x, y, px, py int) {
+	n := math.Exp2(float64(zoom))
+	latRad := lat * math.Pi / 180
+	fx := (lon + 180) / 360 * n
+	fy := (1 - math.Log(math.Tan(latRad)+1/math.Cos(latRad))/math.Pi) / 2 * n
+	x, y = int(fx), int(fy)
+	return x, y, int((fx - float64(x)) * tileSize), int((fy - float64(y)) * tileSize)
+}
+
+// printMapLinks prints a browser link and the tile URLs for each layer.
+func printMapLinks(city, countryOrState string, lat, lon float64, zoom int, apiKey string) {
+	x, y, _, _ := tileFor(lat, lon, zoom)
+	colorTitle.Printf("*** %s, %s Weather Maps ***\n", city, countryOrState)
+	colorInfo.Println("Radar (browser):")
+	psColorCyan.Printf("  "+weatherMapURL+"\n", "radar", lat, lon, zoom)
+	colorInfo.Printf("Tiles (zoom %d, x %d, y %d):\n", zoom, x, y)
+	for _, l := range mapLayers {
+		colorDefault.Printf("  %-13s ", l.name+":")
+		psColorCyan.Printf(tileURL+"\n", l.layer, zoom, x, y, apiKey)
+	}
+}
+
+// fetchTile downloads and decodes one map tile.
+func fetchTile(layer string, zoom, x, y int, apiKey string) (image.Image, error) {
+	client := &http.Client{Timeout: 15 * time.Second}
+	req, err := http.NewRequest("GET", fmt.Sprintf(tileURL, layer, zoom, x, y, apiKey), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", fetch.UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch map tile %s: %w", logging.RedactURL(req.URL), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("map tile request failed with status %s", resp.Status)
+	}
+	img, err := png.Decode(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode map tile: %w", err)
+	}
+	return img, nil
+}
+
+// printASCIIMap renders the precipitation tile around the location as a
+// 64x32 character grid. The tile is transparent where it is dry, so each
+// cell takes the strongest alpha in its 4x8 pixel block; + marks the
+// location.
+func printASCIIMap(lat, lon float64, zoom int, apiKey string) error {
+	x, y, px, py := tileFor(lat, lon, zoom)
+	img, err := fetchTile("precipitation_new", zoom, x, y, apiKey)
+	if err != nil {
+		return err
+	}
+	const cols, rows = 64, 32
+	cellW, cellH := tileSize/cols, tileSize/rows
+	bounds := img.Bounds()
+
+	fmt.Println()
+	colorInfo.Printf("Precipitation (zoom %d, + is the location, %q light to heavy):\n", zoom, strings.TrimSpace(asciiRamp))
+	border := "+" + strings.Repeat("-", cols) + "+"
+	colorDefault.Println(border)
+	for r := 0; r < rows; r++ {
+		var line strings.Builder
+		for c := 0; c < cols; c++ {
+			if c == px/cellW && r == py/cellH {
+				line.WriteByte('+')
+				continue
+			}
+			var maxAlpha uint32
+			for dy := 0; dy < cellH; dy++ {
+				for dx := 0; dx < cellW; dx++ {
+					_, _, _, a := img.At(bounds.Min.X+c*cellW+dx, bounds.Min.Y+r*cellH+dy).RGBA()
+					maxAlpha = max(maxAlpha, a)
+				}
+			}
+			line.WriteByte(asciiRamp[int(maxAlpha)*(len(asciiRamp)-1)/0xffff])
+		}
+		colorDefault.Print("|")
+		colorInfo.Print(line.String())
+		colorDefault.Println("|")
+	}
+	colorDefault.Println(border)
+	return nil
+}
@@ -0,0 +1,84 @@
+package gw
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"gw/internal/astro"
+	"gw/internal/forecast"
+	"gw/internal/format"
+)
+
+const moonDays = 30
+
+var moonGlyphs = []string{"🌑", "🌒", "🌓", "🌔", "🌕", "🌖", "🌗", "🌘"}
+
+// principalPhases are the four phases -moon calls out, at their lunation
+// fractions (0 and 1 are both the new moon).
+var principalPhases = []struct {
+	at   float64
+	name string
+}{
+	{0, "New Moon"}, {0.25, "First Quarter"}, {0.5, "Full Moon"}, {0.75, "Third Quarter"}, {1, "New Moon"},
+}
+
+// moonGlyph picks the emoji for phase.
+func moonGlyph(phase float64) string {
+	return moonGlyphs[int(math.Floor(phase*8+0.5))%8]
+}
+
+// principalPhaseBetween names the principal phase reached between the
+// lunation fractions from and to (one day apart), or "".
+func principalPhaseBetween(from, to float64) string {
+	if to < from { // wrapped through the new moon
+		to++
+	}
+	for _, p := range principalPhases {
+		if from < p.at && p.at <= to {
+			return p.name
+		}
+	}
+	return ""
+}
+
+// printMoonCalendar prints the next 30 days of moon phases. Days covered by
+// the forecast use the API's moon_phase, where the principal phases come
+// back as exactly 0, 0.25, 0.5 and 0.75; later days use astro.Lunation.
+func printMoonCalendar(city, countryOrState string, weather *forecast.WeatherData) {
+	fromAPI := make(map[string]float64, len(weather.Daily))
+	for _, day := range weather.Daily {
+		if weather.Source == forecast.SourceNWS {
+			break // NWS phases are Lunation's own; use the crossing check below.
+		}
+		fromAPI[time.Unix(day.Dt, 0).Local().Format("2006-01-02")] = day.MoonPhase
+	}
+
+	colorTitle.Printf("*** %s, %s Moon Phases ***\n", city, countryOrState)
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	for i := 0; i < moonDays; i++ {
+		day := start.AddDate(0, 0, i)
+		phase, ok := fromAPI[day.Format("2006-01-02")]
+		event := ""
+		if ok {
+			for _, p := range principalPhases {
+				if phase == p.at {
+					event = p.name
+				}
+			}
+		} else {
+			phase = astro.Lunation(day.Add(12 * time.Hour))
+			event = principalPhaseBetween(astro.Lunation(day), astro.Lunation(day.AddDate(0, 0, 1)))
+		}
+
+		line := fmt.Sprintf("%s  %s  %-16s", day.Format("Mon Jan 02"), moonGlyph(phase), format.MoonPhase(phase))
+		if event != "" {
+			colorSun.Printf("%s ◀ %s\n", line, event)
+		} else {
+			colorMoon.Println(line)
+		}
+	}
+	colorInfo.Printf("Days after %s are calculated from the mean lunar cycle (±1 day).\n",
+		formatUnixTimeLocal(weather.Daily[len(weather.Daily)-1].Dt, "Jan 2"))
+}
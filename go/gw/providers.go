@@ -0,0 +1,93 @@
+package gw
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gw/internal/fetch"
+	"gw/internal/forecast"
+	"gw/internal/geocode"
+)
+
+// WeatherProvider is a source of weather for gw: a geocoder and a fetcher
+// that go together. Every provider fills the OpenWeatherMap-shaped
+// forecast.WeatherData so the report, -json, -watch and the rest do not
+// care where the data came from.
+type WeatherProvider interface {
+	// Name is the value -provider takes, also used in logs.
+	Name() string
+	geocode.Geocoder
+	fetch.Fetcher
+}
+
+// provider pairs a geocoder with the fetcher it feeds.
+type provider struct {
+	name string
+	geocode.Geocoder
+	fetch.Fetcher
+}
+
+func (p provider) Name() string { return p.name }
+
+// owmProvider is OpenWeatherMap One Call 3.0, gw's original source.
+func owmProvider(apiKey string) provider {
+	return provider{forecast.SourceOWM, geocode.OWM{APIKey: apiKey}, fetch.OWM{APIKey: apiKey}}
+}
+
+// nwsProvider is the National Weather Service (US only, no key), geocoded
+// with OpenStreetMap Nominatim.
+func nwsProvider() provider {
+	return provider{forecast.SourceNWS, geocode.Nominatim{}, &fetch.NWS{}}
+}
+
+// fallbackProvider geocodes with primary and fetches from it, switching to
+// fallback for a fetch that primary fails (a key without a One Call
+// subscription, an outage). NWS only answers for US points, so elsewhere
+// the primary's error is returned.
+type fallbackProvider struct {
+	primary, fallback WeatherProvider
+}
+
+func (p fallbackProvider) Name() string { return p.primary.Name() }
+
+func (p fallbackProvider) Geocode(location string) (float64, float64, string, string, error) {
+	return p.primary.Geocode(location)
+}
+
+func (p fallbackProvider) Weather(lat, lon float64) (*forecast.WeatherData, error) {
+	data, err := p.primary.Weather(lat, lon)
+	if err == nil {
+		return data, nil
+	}
+	slog.Warn("weather fetch failed, trying fallback", "provider", p.primary.Name(), "fallback", p.fallback.Name(), "err", err)
+	if data, fbErr := p.fallback.Weather(lat, lon); fbErr == nil {
+		return data, nil
+	} else {
+		slog.Warn("fallback weather fetch failed", "provider", p.fallback.Name(), "err", fbErr)
+	}
+	return nil, err
+}
+
+func (p fallbackProvider) Overview(lat, lon float64) (*forecast.OverviewData, error) {
+	data, err := p.primary.Overview(lat, lon)
+	if err == nil {
+		return data, nil
+	}
+	if data, fbErr := p.fallback.Overview(lat, lon); fbErr == nil {
+		return data, nil
+	}
+	return nil, err
+}
+
+// newProvider returns the provider for -provider: "owm" (the default, with
+// NWS as fallback) or "nws" alone, which needs no API key.
+func newProvider(name, apiKey string) (WeatherProvider, error) {
+	switch strings.ToLower(name) {
+	case "", "owm", "openweathermap":
+		return fallbackProvider{primary: owmProvider(apiKey), fallback: nwsProvider()}, nil
+	case "nws":
+		return nwsProvider(), nil
+	}
+	return nil, fmt.Errorf("unknown provider %q (use owm or nws)", name)
+}
@@ -0,0 +1,52 @@
+package gw
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"gw/internal/forecast"
+	"kreftus/shared/notify"
+)
+
+// notifyThresholds are the -notify limits. The defaults are the ones the
+// report already colors red.
+type notifyThresholds struct {
+	TempAbove, TempBelow, Wind float64
+}
+
+// thresholdReasons lists what makes the current weather worth a -notify
+// toast: active alerts, then any limit crossed.
+func thresholdReasons(weather *forecast.WeatherData, t notifyThresholds) []string {
+	var reasons []string
+	for _, alert := range weather.Alerts {
+		reasons = append(reasons, alert.Event)
+	}
+	current := weather.Current
+	if current.Temp > t.TempAbove {
+		reasons = append(reasons, fmt.Sprintf("%.0f°F (above %.0f°F)", current.Temp, t.TempAbove))
+	}
+	if current.Temp < t.TempBelow {
+		reasons = append(reasons, fmt.Sprintf("%.0f°F (below %.0f°F)", current.Temp, t.TempBelow))
+	}
+	if current.WindSpeed >= t.Wind {
+		reasons = append(reasons, fmt.Sprintf("wind %.0f mph (%.0f+ mph)", current.WindSpeed, t.Wind))
+	}
+	return reasons
+}
+
+// sendThresholdNotification shows a desktop notification (notify-send,
+// Windows toast or osascript) when thresholdReasons finds anything. Unlike
+// notifyAlerts it does not depend on notify.ini, so -notify works unattended
+// out of the box.
+func sendThresholdNotification(city string, weather *forecast.WeatherData, t notifyThresholds) {
+	reasons := thresholdReasons(weather, t)
+	if len(reasons) == 0 {
+		return
+	}
+	title := fmt.Sprintf("Weather for %s", city)
+	if err := notify.Desktop(appName, title, strings.Join(reasons, ", ")); err != nil {
+		slog.Warn("desktop notification failed", "err", err)
+		psColorYellow.Printf("Could not send desktop notification: %v\n", err)
+	}
+}
@@ -0,0 +1,119 @@
+package gw
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"gw/internal/forecast"
+	"kreftus/shared/notify"
+)
+
+// minWatchInterval keeps -watch inside the 1000 free One Call requests a day.
+const minWatchInterval = 2
+
+// alertKey identifies an alert across polls; the API has no stable ID.
+func alertKey(alert forecast.Alert) string {
+	return fmt.Sprintf("%s|%s|%d", alert.Event, alert.SenderName, alert.Start)
+}
+
+// runWatch polls the weather every interval minutes until interrupted,
+// announcing each alert once when it first appears and noting when it ends.
+// Between polls a single status line is rewritten in place. Each poll is
+// appended to logPath (-log) unless it is empty.
+func runWatch(lat, lon float64, city, countryOrState string, provider WeatherProvider, interval int, isTerse bool, hook, logPath string) {
+	if interval < minWatchInterval {
+		interval = minWatchInterval
+	}
+	colorTitle.Printf("*** Watching %s, %s for weather alerts (every %d min, Ctrl+C to stop) ***\n", city, countryOrState, interval)
+	slog.Info("watch started", "city", city, "interval_min", interval)
+
+	seen := make(map[string]forecast.Alert)
+	for {
+		stamp := time.Now().Format("3:04 PM")
+		weather, err := provider.Weather(lat, lon)
+		if err != nil {
+			slog.Warn("watch poll failed", "err", err)
+			fmt.Print("\r\033[K")
+			psColorYellow.Printf("[%s] Update failed, retrying in %d min: %s", stamp, interval, userMessage(err))
+		} else {
+			if logPath != "" {
+				logObservation(logPath, weather)
+			}
+			current := make(map[string]bool, len(weather.Alerts))
+			var fresh []forecast.Alert
+			for _, alert := range weather.Alerts {
+				key := alertKey(alert)
+				current[key] = true
+				if _, ok := seen[key]; !ok {
+					seen[key] = alert
+					fresh = append(fresh, alert)
+				}
+			}
+			for key, alert := range seen {
+				if !current[key] {
+					delete(seen, key)
+					fmt.Print("\r\033[K")
+					colorInfo.Printf("[%s] Alert ended: %s\n", stamp, alert.Event)
+				}
+			}
+			if len(fresh) > 0 {
+				announceAlerts(stamp, city, fresh, isTerse, hook)
+			}
+			fmt.Print("\r\033[K")
+			colorDefault.Printf("[%s] %.0f°F %s, %d active alert(s)", stamp, weather.Current.Temp, weather.Current.Conditions(), len(seen))
+		}
+		time.Sleep(time.Duration(interval) * time.Minute)
+	}
+}
+
+// announceAlerts prints new alerts prominently, beeps, sends them through
+// notify.ini and runs the -on-alert hook once per alert.
+func announceAlerts(stamp, city string, alerts []forecast.Alert, isTerse bool, hook string) {
+	fmt.Print("\r\033[K")
+	for _, alert := range alerts {
+		fmt.Println()
+		colorAlert.Printf("[%s] *** NEW ALERT: %s - %s ***\n", stamp, alert.Event, alert.SenderName)
+		if !isTerse {
+			printWrapped(colorDefault, alert.Description)
+		}
+		colorInfo.Printf("Starts: %s\n", formatUnixTimeLocal(alert.Start, "Jan 2, 2006 3:04 PM MST"))
+		colorInfo.Printf("Ends: %s\n", formatUnixTimeLocal(alert.End, "Jan 2, 2006 3:04 PM MST"))
+		runAlertHook(hook, city, alert)
+	}
+	fmt.Println()
+	for i := 0; i < 3; i++ {
+		notify.Beep(880, 200)
+	}
+	notifyAlerts(city, alerts)
+}
+
+// runAlertHook runs the -on-alert command through the shell (cmd /c on
+// Windows) without waiting, passing the alert in GW_* environment variables.
+func runAlertHook(hook, city string, alert forecast.Alert) {
+	if hook = strings.TrimSpace(hook); hook == "" {
+		return
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/c", hook)
+	} else {
+		cmd = exec.Command("sh", "-c", hook)
+	}
+	cmd.Env = append(os.Environ(),
+		"GW_LOCATION="+city,
+		"GW_ALERT_EVENT="+alert.Event,
+		"GW_ALERT_SENDER="+alert.SenderName,
+		"GW_ALERT_START="+formatUnixTimeLocal(alert.Start, time.RFC3339),
+		"GW_ALERT_END="+formatUnixTimeLocal(alert.End, time.RFC3339),
+	)
+	if err := cmd.Start(); err != nil {
+		slog.Warn("alert hook failed", "command", hook, "err", err)
+		return
+	}
+	go cmd.Wait()
+}
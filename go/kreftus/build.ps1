@@ -0,0 +1,92 @@
+<#
+.SYNOPSIS
+    Builds the 'kreftus' toolkit launcher for Windows and Linux.
+
+.DESCRIPTION
+    Mirrors the structure used in your other Go projects.
+    - Cleans previous bin output
+    - go mod tidy
+    - Builds for Windows (x86, x64) and Linux (x86, amd64)
+    - Places artifacts under ./bin/...
+
+    Every tool is linked in from ../<tool> through its Run entry point, so
+    one kreftus binary per platform holds the whole toolkit.
+#>
+
+[CmdletBinding()]
+param(
+    [switch]$upx
+)
+
+$ErrorActionPreference = "Stop"
+Set-Location $PSScriptRoot
+
+Write-Host "Starting build process for kreftus..." -ForegroundColor Cyan
+
+# 1) Cleanup bin
+if (Test-Path "./bin") {
+    Write-Host "Cleaning old bin directory..." -ForegroundColor DarkGray
+    Remove-Item -Path "./bin" -Recurse -Force
+}
+
+# Only the build date is stamped. The linked-in tools each set their own
+# version through version.Default, which a stamped Version would override;
+# kreftus's own number is the version.Default call in main.go.
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Date=$BuildDate"
+
+# 2) Tidy modules
+Write-Host "Tidying Go modules..." -ForegroundColor Cyan
+go mod tidy
+
+# 3) Create output directories
+Write-Host "Preparing output directories..." -ForegroundColor Cyan
+$platforms = @("win/x86", "win/x64", "linux/x86", "linux/amd64")
+foreach ($p in $platforms) {
+    New-Item -Path "./bin/$p" -ItemType Directory -Force | Out-Null
+}
+
+$ldflags = "-s -w $VersionFlags"
+
+# 4) Launcher builds
+try {
+    Write-Host "Building for Windows (x86)..." -ForegroundColor Cyan
+    $env:GOOS = "windows"; $env:GOARCH = "386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x86/kreftus.exe" .
+    Write-Host "Building for Windows (x64)..." -ForegroundColor Cyan
+    $env:GOOS = "windows"; $env:GOARCH = "amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x64/kreftus.exe" .
+    Write-Host "Building for Linux (x86)..." -ForegroundColor Cyan
+    $env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/x86/kreftus" .
+    Write-Host "Building for Linux (amd64)..." -ForegroundColor Cyan
+    $env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/amd64/kreftus" .
+}
+finally {
+    Remove-Item Env:\GOOS -ErrorAction SilentlyContinue
+    Remove-Item Env:\GOARCH -ErrorAction SilentlyContinue
+}
+
+# 5) Optional UPX compression (only when -upx is specified)
+if ($upx.IsPresent) {
+    $upxCmd = Get-Command upx -ErrorAction SilentlyContinue
+    if ($upxCmd -and $upxCmd.Path) {
+        Write-Host "Compressing launcher binaries with UPX (--best --lzma)..." -ForegroundColor Yellow
+        $binaries = @(
+            "./bin/win/x86/kreftus.exe",
+            "./bin/win/x64/kreftus.exe",
+            "./bin/linux/x86/kreftus",
+            "./bin/linux/amd64/kreftus"
+        )
+        foreach ($bin in $binaries) {
+            if (Test-Path $bin) {
+                Write-Host "  -> upx $bin" -ForegroundColor DarkGray
+                & $upxCmd.Path --best --lzma $bin
+            }
+        }
+        Write-Host "UPX compression completed." -ForegroundColor Green
+    } else {
+        Write-Host "-upx specified but UPX not found in PATH. Skipping compression." -ForegroundColor DarkGray
+    }
+} else {
+    Write-Host "UPX compression disabled by default. Pass -upx to enable." -ForegroundColor DarkGray
+}
+
+Write-Host "Build process completed successfully!" -ForegroundColor Green
@@ -0,0 +1,136 @@
+// Command kreftus bundles the kreftus Go tools into one program. Each tool is
+// linked in through its package's Run entry point, so "kreftus gw -t 90210"
+// runs gw in this process with the arguments passed through unchanged.
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"bmon"
+	"github.com/fatih/color"
+	"gw"
+	"kreftus/shared/configdir"
+	"kreftus/shared/version"
+	"larry"
+	"mind"
+	"rc"
+	"vbtc"
+)
+
+// tool is one of the programs linked into kreftus.
+type tool struct {
+	name        string
+	description string
+	run         func(args []string) int
+}
+
+var tools = []tool{
+	{"vbtc", "Virtual Bitcoin trading simulator", vbtc.Run},
+	{"bmon", "Bitcoin price monitor", bmon.Run},
+	{"gw", "Weather report for a zip code or city", gw.Run},
+	{"rc", "Run a command continuously on a schedule", rc.Run},
+	{"larry", "Terminal road-crossing game", larry.Run},
+	{"mind", "Mastermind code-breaking game", mind.Run},
+}
+
+func main() {
+	// Busybox-style: when kreftus is installed (or linked) under a tool's
+	// name, run that tool directly.
+	if t, ok := findTool(invokedName()); ok {
+		os.Exit(t.run(os.Args[1:]))
+	}
+	if len(os.Args) > 1 {
+		if t, ok := findTool(strings.ToLower(os.Args[1])); ok {
+			os.Exit(t.run(os.Args[2:]))
+		}
+	}
+
+	// version.Version is shared with the tools, which each set their own
+	// release number, so kreftus sets its own only once no tool is running.
+	version.Default("1.0")
+	if version.HandleArgs("kreftus", os.Args[1:]) {
+		return
+	}
+
+	if len(os.Args) < 2 {
+		printUsage()
+		return
+	}
+
+	switch strings.ToLower(os.Args[1]) {
+	case "-h", "-help", "--help", "help":
+		printUsage()
+	case "list":
+		printTools()
+	case "config":
+		printConfig()
+	default:
+		color.Red("Unknown command: %s", os.Args[1])
+		fmt.Println()
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+// invokedName returns the executable name without directory or .exe suffix.
+func invokedName() string {
+	name := strings.ToLower(filepath.Base(os.Args[0]))
+	return strings.TrimSuffix(name, ".exe")
+}
+
+func findTool(name string) (tool, bool) {
+	for _, t := range tools {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return tool{}, false
+}
+
+func printUsage() {
+	color.Yellow("kreftus - Toolkit Launcher - Version %s", version.Version)
+	fmt.Println()
+	color.Cyan("USAGE")
+	fmt.Println("  kreftus <tool> [arguments...]")
+	fmt.Println("  kreftus list | config | --version | --check-update")
+	fmt.Println()
+	color.Cyan("TOOLS")
+	printTools()
+	fmt.Println()
+	color.Cyan("NOTES")
+	fmt.Println("  Every tool is built into kreftus; the arguments after the tool name are passed")
+	fmt.Println("  through unchanged, e.g. 'kreftus gw -t 90210'.")
+	fmt.Println("  Copying or linking kreftus as 'gw' (or any tool name) runs that tool.")
+	fmt.Println("  All tools share API keys, notification and theme settings; run 'kreftus config' to see where.")
+}
+
+// printTools lists every tool linked into kreftus.
+func printTools() {
+	for _, t := range tools {
+		fmt.Printf("  %-6s %s\n", t.name, t.description)
+	}
+}
+
+// printConfig shows the shared config directory and which common files exist.
+func printConfig() {
+	dir, err := configdir.Dir()
+	if err != nil {
+		color.Red("%v", err)
+		os.Exit(1)
+	}
+	color.Cyan("Shared config directory: %s", dir)
+	for _, f := range []struct{ name, purpose string }{
+		{"keys.ini", "API keys (LiveCoinWatch, OpenWeatherMap)"},
+		{"notify.ini", "Notification channels (bell, desktop, webhook)"},
+		{"themes.ini", "Color themes"},
+	} {
+		state := color.New(color.FgHiBlack).Sprint("(not created)")
+		if _, err := os.Stat(filepath.Join(dir, f.name)); err == nil {
+			state = color.New(color.FgGreen).Sprint("(present)")
+		}
+		fmt.Printf("  %-11s %-48s %s\n", f.name, f.purpose, state)
+	}
+}
@@ -26,6 +26,12 @@ if (Test-Path "./bin") {
     Remove-Item -Path "./bin" -Recurse -Force
 }
 
+# Version stamped into the binary (shown by --version; compared by --check-update).
+# Keep it in step with version.Default in main.go, which a plain go build uses.
+$Version = "1.1"
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Version=$Version -X kreftus/shared/version.Date=$BuildDate"
+
 # 2) Tidy modules
 Write-Host "Tidying Go modules..." -ForegroundColor Cyan
 go mod tidy
@@ -37,7 +43,7 @@ New-Item -Path "./bin/win/x64" -ItemType Directory -Force | Out-Null
 New-Item -Path "./bin/linux/x86" -ItemType Directory -Force | Out-Null
 New-Item -Path "./bin/linux/amd64" -ItemType Directory -Force | Out-Null
 
-$ldflags = "-s -w"
+$ldflags = "-s -w $VersionFlags"
 
 try {
     # 4) Windows builds (console app)
@@ -49,31 +55,31 @@ try {
     if ($hasIcon) {
         Write-Host "Building for Windows (x86) with icon..." -ForegroundColor Cyan
         $env:GOOS = "windows"; $env:GOARCH = "386"
-        windres -F pe-i386 -I $PSScriptRoot -i $rcFile -o larry.syso
+        windres -F pe-i386 -I $PSScriptRoot -i $rcFile -o cmd/larry/larry.syso
         Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray
-        go build -v -ldflags "$ldflags" -o "./bin/win/x86/larry.exe" .
+        go build -v -ldflags "$ldflags" -o "./bin/win/x86/larry.exe" ./cmd/larry
 
         Write-Host "Building for Windows (x64) with icon..." -ForegroundColor Cyan
         $env:GOOS = "windows"; $env:GOARCH = "amd64"
-        windres -F pe-x86-64 -I $PSScriptRoot -i $rcFile -o larry.syso
+        windres -F pe-x86-64 -I $PSScriptRoot -i $rcFile -o cmd/larry/larry.syso
         Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray
-        go build -v -ldflags "$ldflags" -o "./bin/win/x64/larry.exe" .
+        go build -v -ldflags "$ldflags" -o "./bin/win/x64/larry.exe" ./cmd/larry
 
-        if (Test-Path "larry.syso") { Remove-Item "larry.syso" -Force }
+        if (Test-Path "cmd/larry/larry.syso") { Remove-Item "cmd/larry/larry.syso" -Force }
     } else {
         Write-Host "Building for Windows (x86)..." -ForegroundColor Cyan
-        $env:GOOS = "windows"; $env:GOARCH = "386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x86/larry.exe" .
+        $env:GOOS = "windows"; $env:GOARCH = "386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x86/larry.exe" ./cmd/larry
 
         Write-Host "Building for Windows (x64)..." -ForegroundColor Cyan
-        $env:GOOS = "windows"; $env:GOARCH = "amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x64/larry.exe" .
+        $env:GOOS = "windows"; $env:GOARCH = "amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x64/larry.exe" ./cmd/larry
     }
 
     # 5) Linux builds
     Write-Host "Building for Linux (x86)..." -ForegroundColor Cyan
-    $env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/x86/larry" .
+    $env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/x86/larry" ./cmd/larry
 
     Write-Host "Building for Linux (amd64)..." -ForegroundColor Cyan
-    $env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/amd64/larry" .
+    $env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/amd64/larry" ./cmd/larry
 }
 finally {
     # Clean env overrides
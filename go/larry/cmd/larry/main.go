@@ -0,0 +1,13 @@
+// Command larry is the standalone Go Larry game; the program itself lives in
+// package larry so the kreftus launcher can link it in too.
+package main
+
+import (
+	"os"
+
+	"larry"
+)
+
+func main() {
+	os.Exit(larry.Run(os.Args[1:]))
+}
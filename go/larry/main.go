@@ -1,8 +1,11 @@
-package main
+// Package larry is the Go Larry road-crossing game. Run is its entry point for
+// cmd/larry and the kreftus launcher.
+package larry
 
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"math"
 	"math/rand/v2"
 	"os"
@@ -12,18 +15,36 @@ import (
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"kreftus/shared/console"
+	"kreftus/shared/logging"
+	"kreftus/shared/version"
 )
 
 type lane struct {
 	y           int
 	speedTicks  int
 	dirRight    bool
-	cars        []int // leftmost x for each vehicle in this lane
+	cars        []int // leftmost x for each vehicle (or log/turtle group) in this lane
 	width       int
 	tickCounter int
 	length      int         // vehicle length in cells
 	glyph       []rune      // glyphs to render per cell (same length as length)
 	color       tcell.Color // per-lane vehicle color
+	river       bool        // logs/turtles Larry rides; open water drowns him
+}
+
+// covers reports whether column x is under one of the lane's vehicles or
+// floating objects.
+func (ln *lane) covers(x int) bool {
+	if x < 0 || x >= ln.width {
+		return false
+	}
+	for _, cx := range ln.cars {
+		if x >= cx && x < cx+ln.length {
+			return true
+		}
+	}
+	return false
 }
 
 type theme struct {
@@ -45,6 +66,22 @@ const (
 	startScores = 1
 )
 
+// Each crossing gets baseCrossingTime plus timePerRow for every playfield
+// row, so tall terminals are not unfair. Unused time scores
+// timeBonusPerSecond × level on reaching a home slot.
+const (
+	baseCrossingTime   = 20 * time.Second
+	timePerRow         = 500 * time.Millisecond
+	timeBonusPerSecond = 10
+)
+
+// Home slots on the goal row. Each must be filled to clear a level; the
+// hedge between them is deadly.
+const (
+	homeSlots = 5
+	homeWidth = 3 // cells per slot
+)
+
 type game struct {
 	screen tcell.Screen
 	width  int
@@ -62,14 +99,18 @@ type game struct {
 	safeTopY         int
 	safeBottomY      int
 	safeRow          []bool
+	riverRow         []bool
+	homeFilled       [homeSlots]bool
 	rng              *rand.Rand
 	theme            theme
 	paused           bool
 	events           chan tcell.Event
 	acceptInputAfter time.Time
-	// Per-level score decay
-	scoreTimerActive   bool
-	nextScoreDecrement time.Time
+	// Per-crossing countdown; zero until Larry's first move of the crossing
+	crossingEnds time.Time
+	pausedLeft   time.Duration // time left when paused
+	timerBarX    int           // HUD column of the timer bar, set by updateHUD
+	timerBarW    int           // 0 when the HUD has no room for it
 	// HUD throttling
 	hudLine           string
 	lastRenderedScore int
@@ -92,10 +133,23 @@ type scoreEntry struct {
 	Date  string `json:"date,omitempty"`
 }
 
-func main() {
+// Run starts larry with args, the command line after the program name, and
+// returns the exit code. cmd/larry and the kreftus launcher both call it.
+func Run(args []string) int {
+	version.Default("1.1")
+	args = logging.Init("larry", append([]string{"larry"}, args...))[1:]
+	defer logging.Close()
+	if version.HandleArgs("larry", args) {
+		return 0
+	}
+	console.Setup()
+	userTheme = console.LoadTheme("larry")
+
 	// Set up panic recovery to ensure cleanup
 	defer func() {
 		if r := recover(); r != nil {
+			slog.Error("panic", "err", r)
+			logging.Close()
 			// Reset terminal colors to default using ANSI escape codes
 			fmt.Print("\033[0m")
 			// Also reset cursor visibility
@@ -163,10 +217,10 @@ func main() {
 				g.resize()
 			case *tcell.EventKey:
 				if g.handleQuit(e) {
-					return
+					return 0
 				}
 				if g.handleInput(e) {
-					return
+					return 0
 				}
 			}
 		case <-tick.C:
@@ -174,7 +228,7 @@ func main() {
 			g.render()
 		case <-sigChan:
 			// Handle Ctrl+C and other termination signals
-			return
+			return 0
 		}
 	}
 }
@@ -192,6 +246,7 @@ func (g *game) resize() {
 	g.frogX = g.width / 2
 	g.frogY = g.safeBottomY
 	g.highestY = g.frogY
+	g.crossingEnds = time.Time{}
 	g.createLanes()
 }
 
@@ -199,6 +254,28 @@ func (g *game) respawnAtStart() {
 	g.frogX = g.width / 2
 	g.frogY = g.safeBottomY
 	g.highestY = g.frogY
+	// the next crossing's clock starts with his first move
+	g.crossingEnds = time.Time{}
+}
+
+// crossingTime is the countdown for one crossing at the current height.
+func (g *game) crossingTime() time.Duration {
+	return baseCrossingTime + time.Duration(max(0, g.safeBottomY-g.safeTopY))*timePerRow
+}
+
+// timeLeft is what remains of the current crossing's countdown; the full
+// time before the first move.
+func (g *game) timeLeft() time.Duration {
+	switch {
+	case g.crossingEnds.IsZero():
+		return g.crossingTime()
+	case g.paused:
+		return g.pausedLeft
+	}
+	if left := time.Until(g.crossingEnds); left > 0 {
+		return left
+	}
+	return 0
 }
 
 func (g *game) initLevel(level int) {
@@ -217,8 +294,9 @@ func (g *game) initLevel(level int) {
 	g.frogY = g.safeBottomY
 	g.highestY = g.frogY
 	g.theme = themeForLevel(level)
-	// score decay starts only after first action each level
-	g.scoreTimerActive = false
+	g.homeFilled = [homeSlots]bool{}
+	// the crossing clock starts only after the first move
+	g.crossingEnds = time.Time{}
 	g.updateHUD()
 	g.createLanes()
 }
@@ -242,8 +320,9 @@ func (g *game) nextLevel() {
 	// Reward: extra life each cleared level
 	g.lives++
 	g.theme = themeForLevel(g.level)
-	// reset decay timer for new level
-	g.scoreTimerActive = false
+	g.homeFilled = [homeSlots]bool{}
+	// reset the crossing clock for the new level
+	g.crossingEnds = time.Time{}
 	g.updateHUD()
 	g.createLanes()
 }
@@ -255,6 +334,7 @@ func (g *game) createLanes() {
 	}
 	g.lanes = g.lanes[:0]
 	g.safeRow = make([]bool, h)
+	g.riverRow = make([]bool, h)
 	// shoulders are always safe
 	if h > 0 {
 		g.safeRow[0] = true
@@ -262,38 +342,48 @@ func (g *game) createLanes() {
 	if h > 1 {
 		g.safeRow[h-1] = true
 	}
-	// Generate roads: 4-6 lanes in one direction, then a safe gap of 1-3 rows, then flip direction.
+	// Adjust density and speed by level
+	var densityFactor, speedFactor float64
+	if g.level <= 5 {
+		// Original progression for levels 1-5
+		densityFactor = 0.5 + 0.05*float64(max(0, g.level-1)) // 0.5 at L1, +5% each level
+		speedFactor = 0.67 + 0.05*float64(max(0, g.level-1))  // ~33% slower at L1, +5% each level
+	} else {
+		// New progression after level 5
+		// Speed increases each level after 5
+		speedFactor = 0.92 + 0.08*float64(g.level-5) // Start at 0.92, +8% each level after 5
+		// Density only increases every 5 levels after level 5 (at levels 10, 15, 20, etc.)
+		densityIncreases := (g.level - 5) / 5
+		densityFactor = 0.75 + 0.1*float64(densityIncreases) // Start at 0.75, +10% every 5 levels
+	}
+
+	// Apply caps
+	if densityFactor > 2.0 {
+		densityFactor = 2.0
+	}
+	if speedFactor > 2.0 {
+		speedFactor = 2.0
+	}
+
+	// Generate alternating river and road sections, each followed by a safe gap of 1-3 rows.
+	// As in the arcade, the section just below the goal is a river. Roads are 4-6 lanes in
+	// one direction, flipping direction from one road to the next.
 	// Playfield between safeTopY and safeBottomY; HUD is at row 0.
 	y := g.safeTopY + 1
 	dirRight := g.rng.IntN(2) == 0
+	river := true
 	for y < h-1 {
+		if river {
+			y = g.addRiver(y, speedFactor)
+			river = false
+			continue
+		}
+		river = true
 		// Road segment
 		lanesThisRoad := 4 + g.rng.IntN(3) // 4..6
 		if lanesThisRoad > 8 {
 			lanesThisRoad = 8
 		}
-		// Adjust density and speed by level
-		var densityFactor, speedFactor float64
-		if g.level <= 5 {
-			// Original progression for levels 1-5
-			densityFactor = 0.5 + 0.05*float64(max(0, g.level-1)) // 0.5 at L1, +5% each level
-			speedFactor = 0.67 + 0.05*float64(max(0, g.level-1))  // ~33% slower at L1, +5% each level
-		} else {
-			// New progression after level 5
-			// Speed increases each level after 5
-			speedFactor = 0.92 + 0.08*float64(g.level-5) // Start at 0.92, +8% each level after 5
-			// Density only increases every 5 levels after level 5 (at levels 10, 15, 20, etc.)
-			densityIncreases := (g.level - 5) / 5
-			densityFactor = 0.75 + 0.1*float64(densityIncreases) // Start at 0.75, +10% every 5 levels
-		}
-
-		// Apply caps
-		if densityFactor > 2.0 {
-			densityFactor = 2.0
-		}
-		if speedFactor > 2.0 {
-			speedFactor = 2.0
-		}
 
 		for li := 0; li < lanesThisRoad && y < h-1; li++ {
 			// Vehicle class selection per lane
@@ -363,6 +453,62 @@ func (g *game) createLanes() {
 	}
 }
 
+// addRiver lays down 3-5 river lanes from row y, each carrying logs or
+// turtles, and returns the row after the safe bank below them. Lanes
+// alternate direction. Gaps widen with the level but stay narrower than
+// the objects so every lane remains crossable.
+func (g *game) addRiver(y int, speedFactor float64) int {
+	w, h := g.width, g.height
+	lanesThisRiver := 3 + g.rng.IntN(3) // 3..5
+	dirRight := g.rng.IntN(2) == 0
+	for li := 0; li < lanesThisRiver && y < h-1; li++ {
+		var minSpd, maxSpd int
+		var color tcell.Color
+		var glyph []rune
+		if g.rng.IntN(3) == 0 { // turtles: short and quicker
+			minSpd, maxSpd = 2, 3
+			color = g.theme.goal
+			glyph = []rune{'o', 'o', 'o'}
+		} else { // logs: 4-7 cells
+			minSpd, maxSpd = 1, 2
+			color = g.theme.log
+			glyph = []rune(strings.Repeat("=", 4+g.rng.IntN(4)))
+		}
+		length := len(glyph)
+		desired := minSpd + g.rng.IntN(maxSpd-minSpd+1)
+		baseTicks := max(1, 7-desired)
+		speed := int(math.Round(float64(baseTicks) / speedFactor))
+		if speed < 1 {
+			speed = 1
+		}
+
+		baseGap := min(2+g.level/2, length-1)
+		num := max(1, int(float64(w)/(float64(length+baseGap))))
+		positions := make([]int, 0, num)
+		pos := g.rng.IntN(max(1, w))
+		for k := 0; k < num; k++ {
+			positions = append(positions, pos%max(1, w))
+			pos += length + baseGap + g.rng.IntN(2)
+		}
+		g.lanes = append(g.lanes, lane{y: y, speedTicks: speed, dirRight: dirRight, cars: positions, width: w, length: length, glyph: glyph, color: color, river: true})
+		if y >= 0 && y < h {
+			g.safeRow[y] = false
+			g.riverRow[y] = true
+		}
+		y++
+		dirRight = !dirRight
+	}
+	// Safe bank 1-3 lines
+	gap := 1 + g.rng.IntN(3)
+	for gi := 0; gi < gap && y < g.safeBottomY; gi++ {
+		if y >= 0 && y < h {
+			g.safeRow[y] = true
+		}
+		y++
+	}
+	return y
+}
+
 func (g *game) handleInput(e *tcell.EventKey) bool {
 	// Handle start screen
 	if g.showStartScreen {
@@ -403,11 +549,12 @@ func (g *game) handleInput(e *tcell.EventKey) bool {
 		if g.paused {
 			// resuming
 			g.paused = false
-			if g.scoreTimerActive {
-				g.nextScoreDecrement = time.Now().Add(time.Second)
+			if !g.crossingEnds.IsZero() {
+				g.crossingEnds = time.Now().Add(g.pausedLeft)
 			}
 		} else {
-			// pausing
+			// pausing: freeze the crossing clock
+			g.pausedLeft = g.timeLeft()
 			g.paused = true
 		}
 		return false
@@ -460,9 +607,8 @@ func (g *game) handleInput(e *tcell.EventKey) bool {
 		}
 	}
 	g.clampFrog()
-	if moved && !g.scoreTimerActive {
-		g.scoreTimerActive = true
-		g.nextScoreDecrement = time.Now().Add(time.Second)
+	if moved && g.crossingEnds.IsZero() {
+		g.crossingEnds = time.Now().Add(g.crossingTime())
 	}
 	return false
 }
@@ -560,6 +706,15 @@ func (g *game) update() {
 		ln.tickCounter++
 		if ln.tickCounter >= ln.speedTicks {
 			ln.tickCounter = 0
+			// Larry rides the log or turtles he is standing on; carried off
+			// screen he drowns below.
+			if ln.river && ln.y == g.frogY && ln.covers(g.frogX) {
+				if ln.dirRight {
+					g.frogX++
+				} else {
+					g.frogX--
+				}
+			}
 			for j := range ln.cars {
 				if ln.dirRight {
 					ln.cars[j] = (ln.cars[j] + 1) % max(1, ln.width)
@@ -573,48 +728,97 @@ func (g *game) update() {
 	// Collision detection with lanes (ignore safe rows)
 	isSafe := g.frogY >= 0 && g.frogY < len(g.safeRow) && g.safeRow[g.frogY]
 	if !isSafe {
-		for _, ln := range g.lanes {
-			if ln.y == g.frogY {
-				for _, cx := range ln.cars {
-					if g.frogX >= cx && g.frogX < cx+ln.length {
-						// Hit! Lose a life
-						g.lives--
-						if g.lives <= 0 {
-							// Delay accepting input until overlay is up
-							g.acceptInputAfter = time.Now().Add(1250 * time.Millisecond) // 1050ms flash + 200ms buffer
-							g.gameOverSequence()
-						} else {
-							// Respawn at start row and show brief message
-							g.respawnAtStart()
-							// Drain any pending input before showing overlay
-							g.flushInput()
-							g.acceptInputAfter = time.Now().Add(900 * time.Millisecond) // 700ms flash + 200ms buffer
-							g.youDiedFlash()
-						}
-						break
-					}
+		for i := range g.lanes {
+			ln := &g.lanes[i]
+			if ln.y != g.frogY {
+				continue
+			}
+			if ln.river {
+				if !ln.covers(g.frogX) {
+					g.loseLife("Splash!") // open water
 				}
-				break
+			} else if ln.covers(g.frogX) {
+				g.loseLife("You Died!") // hit by a vehicle
 			}
+			break
 		}
 	}
 
-	// Reached goal at top safe row
+	// Reached the goal row
 	if g.frogY == g.safeTopY {
-		g.score += 100 * g.level
-		if g.score > g.topScore {
-			g.topScore = g.score
+		g.reachHome()
+	}
+
+	// Out of time for this crossing
+	if !g.crossingEnds.IsZero() && time.Now().After(g.crossingEnds) {
+		g.loseLife("Time's Up!")
+	}
+}
+
+// homeAt returns the home slot covering column x, or -1 for the hedge.
+// Slots are spread evenly across the goal row.
+func (g *game) homeAt(x int) int {
+	for i := 0; i < homeSlots; i++ {
+		center := (i + 1) * g.width / (homeSlots + 1)
+		if x >= center-homeWidth/2 && x <= center+homeWidth/2 {
+			return i
 		}
+	}
+	return -1
+}
+
+// reachHome handles Larry reaching the goal row. An empty slot scores and
+// sends him back for the next crossing; filling the last one clears the
+// level with a bonus. An occupied slot or the hedge costs a life.
+func (g *game) reachHome() {
+	slot := g.homeAt(g.frogX)
+	switch {
+	case slot < 0:
+		g.loseLife("Bonk!")
+		return
+	case g.homeFilled[slot]:
+		g.loseLife("Occupied!")
+		return
+	}
+	g.homeFilled[slot] = true
+	g.score += 100 * g.level
+	g.score += int(g.timeLeft()/time.Second) * timeBonusPerSecond * g.level
+	allHome := true
+	for _, filled := range g.homeFilled {
+		allHome = allHome && filled
+	}
+	if allHome {
+		g.score += 500 * g.level
+	}
+	if g.score > g.topScore {
+		g.topScore = g.score
+	}
+	if allHome {
 		g.nextLevel()
+		return
 	}
+	g.respawnAtStart()
+	g.flushInput()
+	g.acceptInputAfter = time.Now().Add(200 * time.Millisecond)
+	g.updateHUD()
+}
 
-	// Per-second score decay while level is active
-	if g.scoreTimerActive && time.Now().After(g.nextScoreDecrement) {
-		if g.score > 0 {
-			g.score--
-		}
-		g.nextScoreDecrement = time.Now().Add(time.Second)
+// loseLife takes a life, flashing msg, and respawns Larry at the start, or
+// runs the game over sequence on the last life.
+func (g *game) loseLife(msg string) {
+	g.lives--
+	if g.lives <= 0 {
+		// Delay accepting input until overlay is up
+		g.acceptInputAfter = time.Now().Add(1250 * time.Millisecond) // 1050ms flash + 200ms buffer
+		g.gameOverSequence()
+		return
 	}
+	// Respawn at start row and show brief message
+	g.respawnAtStart()
+	// Drain any pending input before showing overlay
+	g.flushInput()
+	g.acceptInputAfter = time.Now().Add(900 * time.Millisecond) // 700ms flash + 200ms buffer
+	g.youDiedFlash(msg)
 }
 
 func (g *game) render() {
@@ -633,13 +837,13 @@ func (g *game) render() {
 	for y := 0; y < h; y++ {
 		var bg tcell.Color
 		if y == g.safeTopY {
-			bg = g.theme.goal
+			bg = g.theme.safe // hedge; the home slots are drawn below
 		} else if y == g.safeBottomY || (y >= 0 && y < len(g.safeRow) && g.safeRow[y]) {
 			bg = g.theme.safe
-		} else if y%2 == 0 {
-			bg = g.theme.road
-		} else {
+		} else if y >= 0 && y < len(g.riverRow) && g.riverRow[y] {
 			bg = g.theme.river
+		} else {
+			bg = g.theme.road
 		}
 		st := tcell.StyleDefault.Background(bg)
 		for x := 0; x < w; x++ {
@@ -647,9 +851,26 @@ func (g *game) render() {
 		}
 	}
 
-	// Draw lanes' vehicles with length and glyphs
+	// Home slots on the goal row, with a Larry in each filled one
+	for x := 0; x < w; x++ {
+		if slot := g.homeAt(x); slot >= 0 {
+			st := tcell.StyleDefault.Background(g.theme.goal)
+			ch := ' '
+			if g.homeFilled[slot] && x == (slot+1)*w/(homeSlots+1) {
+				ch = '@'
+				st = st.Foreground(g.theme.frog).Bold(true)
+			}
+			s.SetContent(x, g.safeTopY, ch, nil, st)
+		}
+	}
+
+	// Draw lanes' vehicles, logs and turtles with length and glyphs
 	for _, ln := range g.lanes {
 		st := tcell.StyleDefault.Foreground(ln.color)
+		if ln.river {
+			// Solid blocks on the water make the footholds easy to read
+			st = tcell.StyleDefault.Foreground(g.theme.river).Background(ln.color)
+		}
 		for _, left := range ln.cars {
 			for dx := 0; dx < ln.length; dx++ {
 				x := left + dx
@@ -673,6 +894,7 @@ func (g *game) render() {
 	hudStyle := tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(g.theme.frog).Bold(true)
 	drawText(s, 0, 0, spaces(w), hudStyle)
 	drawText(s, 0, 0, g.hudLine, hudStyle)
+	g.drawTimerBar(hudStyle)
 
 	// Draw Larry as a green '@' for wide-compat terminals
 	frogStyle := tcell.StyleDefault.Foreground(g.theme.frog).Bold(true)
@@ -758,6 +980,7 @@ func (g *game) resetGame() {
 	g.lastRenderedScore = -1
 	g.level = 1
 	g.theme = themeForLevel(g.level)
+	g.homeFilled = [homeSlots]bool{}
 	g.createLanes()
 	g.frogX = g.width / 2
 	g.frogY = g.safeBottomY
@@ -767,8 +990,8 @@ func (g *game) resetGame() {
 	g.startView = startMenu
 	g.menuIndex = 0
 	g.acceptInputAfter = time.Now().Add(200 * time.Millisecond)
-	// fresh start: no decay until first move
-	g.scoreTimerActive = false
+	// fresh start: no clock until first move
+	g.crossingEnds = time.Time{}
 	g.updateHUD()
 }
 
@@ -791,7 +1014,7 @@ func (g *game) saveHighScores() {
 	_ = os.WriteFile("larry.scores.json", data, 0644)
 }
 
-func (g *game) youDiedFlash() {
+func (g *game) youDiedFlash(msg string) {
 	st := tcell.StyleDefault.Background(tcell.ColorDarkRed)
 	for i := 0; i < 2; i++ {
 		for y := 0; y < g.height; y++ {
@@ -799,7 +1022,7 @@ func (g *game) youDiedFlash() {
 				g.screen.SetContent(x, y, ' ', nil, st)
 			}
 		}
-		drawCentered(g.screen, g.width/2, g.height/2, "You Died!", tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorDarkRed).Bold(true))
+		drawCentered(g.screen, g.width/2, g.height/2, msg, tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorDarkRed).Bold(true))
 		g.screen.Show()
 		time.Sleep(350 * time.Millisecond)
 	}
@@ -858,10 +1081,25 @@ func spaces(n int) string {
 func (g *game) updateHUD() {
 	// Build the HUD string
 	w := g.width
-	left := fmt.Sprintf("Score:%d  Level:%d  Lives:%d", g.score, g.level, g.lives)
+	homes := 0
+	for _, filled := range g.homeFilled {
+		if filled {
+			homes++
+		}
+	}
+	left := fmt.Sprintf("Score:%d  Level:%d  Lives:%d  Home:%d/%d", g.score, g.level, g.lives, homes, homeSlots)
 	help := "  (Space:Pause Esc:Quit)"
 	right := fmt.Sprintf("Top:%d  Best:%d", g.topScore, g.historyTop)
-	if len(left)+len(help)+len(right)+1 <= w {
+	// Timer bar goes right after the counters: "  Time:[....]"
+	const timerLabel = "  Time:"
+	g.timerBarW = min(20, w-len(left)-len(timerLabel)-len(right)-3)
+	if g.timerBarW >= 5 {
+		g.timerBarX = len(left) + len(timerLabel)
+		left += timerLabel + spaces(g.timerBarW)
+	} else {
+		g.timerBarW = 0
+	}
+	if len(left)+len(help)+1+len(right) < w {
 		left += help
 	}
 	hudLine := left
@@ -875,6 +1113,28 @@ func (g *game) updateHUD() {
 	g.hudLine = hudLine
 }
 
+// drawTimerBar draws the crossing countdown into the HUD slot reserved by
+// updateHUD, turning red in the last quarter.
+func (g *game) drawTimerBar(hudStyle tcell.Style) {
+	if g.timerBarW <= 0 {
+		return
+	}
+	total := g.crossingTime()
+	left := g.timeLeft()
+	filled := int(math.Ceil(float64(g.timerBarW) * float64(left) / float64(total)))
+	st := hudStyle
+	if left < total/4 {
+		st = st.Foreground(tcell.ColorMaroon)
+	}
+	for i := 0; i < g.timerBarW; i++ {
+		ch := '·'
+		if i < filled {
+			ch = '█'
+		}
+		g.screen.SetContent(g.timerBarX+i, 0, ch, nil, st)
+	}
+}
+
 func (g *game) drawPauseOverlay() {
 	w, h := g.width, g.height
 	if w <= 0 || h <= 0 {
@@ -1003,7 +1263,33 @@ func themeForLevel(level int) theme {
 		{bg: tcell.ColorBlack, fg: tcell.ColorSilver, road: tcell.ColorGray, river: tcell.ColorDarkSlateBlue, safe: tcell.ColorDarkGreen, frog: tcell.ColorGreenYellow, carSmall: tcell.ColorKhaki, carRegular: tcell.ColorGoldenrod, carSemi: tcell.ColorSaddleBrown, log: tcell.ColorTan, goal: tcell.ColorCadetBlue},
 		{bg: tcell.ColorBlack, fg: tcell.ColorWhite, road: tcell.ColorGray, river: tcell.ColorRoyalBlue, safe: tcell.ColorDarkOliveGreen, frog: tcell.ColorSpringGreen, carSmall: tcell.ColorLightGreen, carRegular: tcell.ColorSeaGreen, carSemi: tcell.ColorDarkGreen, log: tcell.ColorSandyBrown, goal: tcell.ColorSteelBlue},
 	}
-	return palettes[(level-1)%len(palettes)]
+	return applyUserTheme(palettes[(level-1)%len(palettes)])
+}
+
+// userTheme holds color overrides from the shared themes.ini. Roles match the
+// theme fields (bg, fg, road, river, safe, frog, carSmall, carRegular, carSemi,
+// log, goal) and apply on every level.
+var userTheme *console.Theme
+
+func applyUserTheme(t theme) theme {
+	override := func(role string, c *tcell.Color) {
+		if spec, ok := userTheme.Lookup(role); ok {
+			r, g, b := spec.RGB()
+			*c = tcell.NewRGBColor(int32(r), int32(g), int32(b))
+		}
+	}
+	override("bg", &t.bg)
+	override("fg", &t.fg)
+	override("road", &t.road)
+	override("river", &t.river)
+	override("safe", &t.safe)
+	override("frog", &t.frog)
+	override("carSmall", &t.carSmall)
+	override("carRegular", &t.carRegular)
+	override("carSemi", &t.carSemi)
+	override("log", &t.log)
+	override("goal", &t.goal)
+	return t
 }
 
 func max(a, b int) int {
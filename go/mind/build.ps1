@@ -26,6 +26,12 @@ if (Test-Path "./bin") {
     Remove-Item -Path "./bin" -Recurse -Force
 }
 
+# Version stamped into the binary (shown by --version; compared by --check-update).
+# Keep it in step with version.Default in main.go, which a plain go build uses.
+$Version = "1.0"
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Version=$Version -X kreftus/shared/version.Date=$BuildDate"
+
 # 2) Tidy modules
 Write-Host "Tidying Go modules..." -ForegroundColor Cyan
 go mod tidy
@@ -37,7 +43,7 @@ New-Item -Path "./bin/win/x64" -ItemType Directory -Force | Out-Null
 New-Item -Path "./bin/linux/x86" -ItemType Directory -Force | Out-Null
 New-Item -Path "./bin/linux/amd64" -ItemType Directory -Force | Out-Null
 
-$ldflags = "-s -w"
+$ldflags = "-s -w $VersionFlags"
 
 # 4) Windows builds (console app) — with icon if windres and mind.rc/mind_icon.ico are present
 $rcFile = Join-Path $PSScriptRoot 'mind.rc'
@@ -48,34 +54,34 @@ try {
     if ($hasIcon) {
         Write-Host "Building for Windows (x86) with icon..." -ForegroundColor Cyan
         $env:GOOS = "windows"; $env:GOARCH = "386"
-        windres -F pe-i386 -I $PSScriptRoot -i $rcFile -o mind.syso
+        windres -F pe-i386 -I $PSScriptRoot -i $rcFile -o cmd/mind/mind.syso
         Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray
-        go build -v -ldflags "$ldflags" -o "./bin/win/x86/mind.exe" .
+        go build -v -ldflags "$ldflags" -o "./bin/win/x86/mind.exe" ./cmd/mind
 
         Write-Host "Building for Windows (x64) with icon..." -ForegroundColor Cyan
         $env:GOOS = "windows"; $env:GOARCH = "amd64"
-        windres -F pe-x86-64 -I $PSScriptRoot -i $rcFile -o mind.syso
+        windres -F pe-x86-64 -I $PSScriptRoot -i $rcFile -o cmd/mind/mind.syso
         Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray
-        go build -v -ldflags "$ldflags" -o "./bin/win/x64/mind.exe" .
+        go build -v -ldflags "$ldflags" -o "./bin/win/x64/mind.exe" ./cmd/mind
 
-        if (Test-Path "mind.syso") { Remove-Item "mind.syso" -Force }
+        if (Test-Path "cmd/mind/mind.syso") { Remove-Item "cmd/mind/mind.syso" -Force }
     } else {
         Write-Host "Building for Windows (x86)..." -ForegroundColor Cyan
-        $env:GOOS = "windows"; $env:GOARCH = "386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x86/mind.exe" .
+        $env:GOOS = "windows"; $env:GOARCH = "386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x86/mind.exe" ./cmd/mind
 
         Write-Host "Building for Windows (x64)..." -ForegroundColor Cyan
-        $env:GOOS = "windows"; $env:GOARCH = "amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x64/mind.exe" .
+        $env:GOOS = "windows"; $env:GOARCH = "amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/win/x64/mind.exe" ./cmd/mind
     }
 
     # 5) Linux builds
     Write-Host "Building for Linux (x86)..." -ForegroundColor Cyan
-    $env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/x86/mind" .
+    $env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/x86/mind" ./cmd/mind
 
     Write-Host "Building for Linux (amd64)..." -ForegroundColor Cyan
-    $env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/amd64/mind" .
+    $env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags "$ldflags" -o "./bin/linux/amd64/mind" ./cmd/mind
 }
 finally {
-    if (Test-Path "mind.syso") { Remove-Item "mind.syso" -Force }
+    if (Test-Path "cmd/mind/mind.syso") { Remove-Item "cmd/mind/mind.syso" -Force }
     Remove-Item Env:\GOOS -ErrorAction SilentlyContinue
     Remove-Item Env:\GOARCH -ErrorAction SilentlyContinue
 }
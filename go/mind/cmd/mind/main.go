@@ -0,0 +1,13 @@
+// Command mind is the standalone Mastermind game; the program itself lives in
+// package mind so the kreftus launcher can link it in too.
+package main
+
+import (
+	"os"
+
+	"mind"
+)
+
+func main() {
+	os.Exit(mind.Run(os.Args[1:]))
+}
@@ -4,6 +4,20 @@ go 1.24.0
 
 toolchain go1.24.6
 
-require golang.org/x/term v0.39.0
+require (
+	golang.org/x/term v0.39.0
+	kreftus/shared v0.0.0-00010101000000-000000000000
+)
 
-require golang.org/x/sys v0.40.0 // indirect
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/fatih/color v1.17.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/sys v0.40.0 // indirect
+	gopkg.in/ini.v1 v1.67.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace kreftus/shared => ../shared
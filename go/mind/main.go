@@ -1,4 +1,6 @@
-package main
+// Package mind is the Mastermind code-breaking game. Run is its entry point
+// for cmd/mind and the kreftus launcher.
+package mind
 
 import (
 	"bufio"
@@ -12,6 +14,9 @@ import (
 	"time"
 
 	"golang.org/x/term"
+	"kreftus/shared/console"
+	"kreftus/shared/logging"
+	"kreftus/shared/version"
 )
 
 const (
@@ -25,8 +30,8 @@ const colors = "RGBCMY"
 
 const peg = "⬤"
 
-// ANSI color codes
-const (
+// ANSI color codes (overridable by the user's theme, empty when NO_COLOR is set)
+var (
 	ansiReset   = "\033[0m"
 	ansiRed     = "\033[31m"
 	ansiGreen   = "\033[32m"
@@ -45,13 +50,46 @@ var ansiByColor = map[byte]string{
 	'Y': ansiYellow,
 }
 
+// Feedback pegs; replaced by distinct glyphs when color is disabled.
+var (
+	exactPeg   = peg
+	partialPeg = peg
+)
+
+// applyTheme sets the peg colors from the user's theme in the shared themes.ini
+// (roles red, green, blue, cyan, magenta, yellow). With NO_COLOR, pegs are drawn
+// as their color letters and feedback as ● (right slot) and ○ (wrong slot).
+func applyTheme(theme *console.Theme) {
+	ansiReset = console.Reset()
+	ansiRed = theme.ANSI("red", ansiRed)
+	ansiGreen = theme.ANSI("green", ansiGreen)
+	ansiYellow = theme.ANSI("yellow", ansiYellow)
+	ansiBlue = theme.ANSI("blue", ansiBlue)
+	ansiMagenta = theme.ANSI("magenta", ansiMagenta)
+	ansiCyan = theme.ANSI("cyan", ansiCyan)
+	ansiByColor = map[byte]string{
+		'R': ansiRed,
+		'G': ansiGreen,
+		'B': ansiBlue,
+		'C': ansiCyan,
+		'M': ansiMagenta,
+		'Y': ansiYellow,
+	}
+	if console.NoColor() {
+		exactPeg = "●"
+		partialPeg = "○"
+	}
+}
+
 // termRestoreOnce and termRestoreFunc allow Ctrl+C and ESC to restore the terminal before exiting.
 var (
 	termRestoreOnce sync.Once
 	termRestoreFunc func()
 )
 
-func main() {
+// Run starts mind with args, the command line after the program name, and
+// returns the exit code. cmd/mind and the kreftus launcher both call it.
+func Run(args []string) int {
 	// Allow Ctrl+C to exit cleanly (restore terminal if in raw mode)
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt)
@@ -63,8 +101,19 @@ func main() {
 		os.Exit(0)
 	}()
 
-	setCode := flag.String("set", "", "4-peg code for another player to guess (e.g. r22m)")
-	flag.Parse()
+	version.Default("1.0")
+	args = logging.Init("mind", append([]string{"mind"}, args...))[1:]
+	defer logging.Close()
+	if version.HandleArgs("mind", args) {
+		return 0
+	}
+
+	flags := flag.NewFlagSet("mind", flag.ExitOnError)
+	setCode := flags.String("set", "", "4-peg code for another player to guess (e.g. r22m)")
+	flags.Parse(args)
+
+	console.Setup()
+	applyTheme(console.LoadTheme("mind"))
 
 	// Set terminal window title (ANSI OSC 0 ; title BEL)
 	fmt.Print("\033]0;Mastermind - Crack the code!\007")
@@ -78,7 +127,7 @@ func main() {
 		secret, err = parseSetCode(*setCode)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, err)
-			os.Exit(1)
+			return 1
 		}
 	} else {
 		secret = generateSecret()
@@ -91,7 +140,7 @@ func main() {
 		guess, err := readGuess(reader, turn)
 		if err != nil {
 			fmt.Fprintln(os.Stderr, "Error reading input:", err)
-			os.Exit(1)
+			return 1
 		}
 
 		fmt.Println() // newline after "Turn NN/12: ⬤⬤⬤⬤"
@@ -103,7 +152,7 @@ func main() {
 		if rightPlace == codeLength {
 			fmt.Printf("\nYou win! You cracked the code in %s.\n", formatPlaytime(time.Since(startTime)))
 			waitForAnyKey(reader)
-			return
+			return 0
 		}
 
 		if turn == maxTurns {
@@ -111,9 +160,10 @@ func main() {
 			printColoredPegs(secret)
 			fmt.Printf(" (%s)\n", formatPlaytime(time.Since(startTime)))
 			waitForAnyKey(reader)
-			return
+			return 0
 		}
 	}
+	return 0
 }
 
 // waitForAnyKey waits for a keypress (or Enter if not a TTY) before the program exits after win/lose.
@@ -145,8 +195,8 @@ func showStartScreen(reader *bufio.Reader) {
 	fmt.Println("          C=" + ansiCyan + "Cyan" + ansiReset + ", M=" + ansiMagenta + "Magenta" + ansiReset + ", Y=" + ansiYellow + "Yellow" + ansiReset)
 	fmt.Println("  Enter 4 letters (e.g. RGBC). You have 12 turns.")
 	fmt.Println()
-	fmt.Println("  Feedback: " + ansiGreen + peg + ansiReset + " = right color, right slot")
-	fmt.Println("            " + ansiYellow + peg + ansiReset + " = right color, wrong slot")
+	fmt.Println("  Feedback: " + ansiGreen + exactPeg + ansiReset + " = right color, right slot")
+	fmt.Println("            " + ansiYellow + partialPeg + ansiReset + " = right color, wrong slot")
 	fmt.Println()
 	fmt.Print("        Press " + ansiGreen + "ENTER" + ansiReset + " to START ")
 	_, _ = reader.ReadString('\n')
@@ -198,6 +248,11 @@ func coloredPegsString(code []byte) string {
 	var b strings.Builder
 	for _, c := range code {
 		if ac, ok := ansiByColor[c]; ok {
+			if ac == "" {
+				// No color: the letter is the only way to tell pegs apart
+				b.WriteByte(c)
+				continue
+			}
 			b.WriteString(ac)
 			b.WriteString(peg)
 			b.WriteString(ansiReset)
@@ -208,10 +263,10 @@ func coloredPegsString(code []byte) string {
 
 func printFeedback(rightPlace, rightColor int) {
 	for i := 0; i < rightPlace; i++ {
-		fmt.Print(ansiGreen + peg + ansiReset)
+		fmt.Print(ansiGreen + exactPeg + ansiReset)
 	}
 	for i := 0; i < rightColor; i++ {
-		fmt.Print(ansiYellow + peg + ansiReset)
+		fmt.Print(ansiYellow + partialPeg + ansiReset)
 	}
 }
 
@@ -42,6 +42,12 @@ if (Test-Path $binDir) {
     Remove-Item -Path $binDir -Recurse -Force
 }
 
+# Version stamped into the binary (shown by --version; compared by --check-update).
+# Keep it in step with version.Default in main.go, which a plain go build uses.
+$Version = "1.4"
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Version=$Version -X kreftus/shared/version.Date=$BuildDate"
+
 Write-Host "Tidying Go modules..."
 go mod tidy
 
@@ -62,27 +68,27 @@ try {
     Write-Host "Building for Windows 32-bit (x86)..."
     Write-Host "  -> Generating 32-bit resources..." -ForegroundColor DarkGray
     $env:GOOS = "windows"; $env:GOARCH = "386"
-    windres -F pe-i386 -i rc.rc -o rc.syso -I .
+    windres -F pe-i386 -i rc.rc -o cmd/rc/rc.syso -I .
     Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray
-    go build -v -ldflags="-s -w" -o ".\bin\win\x86\rc.exe" .
+    go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\win\x86\rc.exe" ./cmd/rc
 
     Write-Host "Building for Windows 64-bit (amd64)..."
     Write-Host "  -> Generating 64-bit resources..." -ForegroundColor DarkGray
     $env:GOOS = "windows"; $env:GOARCH = "amd64"
-    windres -F pe-x86-64 -i rc.rc -o rc.syso -I .
+    windres -F pe-x86-64 -i rc.rc -o cmd/rc/rc.syso -I .
     Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray
-    go build -v -ldflags="-s -w" -o ".\bin\win\x64\rc.exe" .
+    go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\win\x64\rc.exe" ./cmd/rc
 }
 finally {
-    if (Test-Path "rc.syso") { Remove-Item "rc.syso" -Force }
+    if (Test-Path "cmd/rc/rc.syso") { Remove-Item "cmd/rc/rc.syso" -Force }
 }
 
 # --- 4. Linux Builds ---
 Write-Host "Building for Linux 32-bit (x86)..."
-$env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o ".\bin\linux\x86\rc" .
+$env:GOOS = "linux"; $env:GOARCH = "386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\linux\x86\rc" ./cmd/rc
 
 Write-Host "Building for Linux 64-bit (amd64)..."
-$env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o ".\bin\linux\amd64\rc" .
+$env:GOOS = "linux"; $env:GOARCH = "amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o ".\bin\linux\amd64\rc" ./cmd/rc
 
 # --- 5. Optional UPX compression (only when -upx is specified) ---
 if ($upx.IsPresent) {
@@ -0,0 +1,13 @@
+// Command rc is the standalone rc command repeater; the program itself lives
+// in package rc so the kreftus launcher can link it in too.
+package main
+
+import (
+	"os"
+
+	"rc"
+)
+
+func main() {
+	os.Exit(rc.Run(os.Args[1:]))
+}
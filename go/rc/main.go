@@ -1,8 +1,11 @@
-package main
+// Package rc runs a command again and again on a schedule. Run is its entry
+// point for cmd/rc and the kreftus launcher.
+package rc
 
 import (
 	"bufio"
 	"fmt"
+	"log/slog"
 	"math"
 	"os"
 	"os/exec"
@@ -12,6 +15,9 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"kreftus/shared/logging"
+	"kreftus/shared/notify"
+	"kreftus/shared/version"
 )
 
 const replaceMarker = "^*"
@@ -250,9 +256,10 @@ func clearScreen() {
 	}
 }
 
-// executeCommand runs the given command string in the appropriate shell for the OS.
+// executeCommand runs the given command string in the appropriate shell for
+// the OS and returns its error, such as a non-zero exit status.
 // It pipes the command's stdout and stderr to the application's stdout and stderr.
-func executeCommand(command string) {
+func executeCommand(command string) error {
 	var cmd *exec.Cmd
 	if runtime.GOOS == "windows" {
 		cmd = exec.Command("cmd", "/C", command)
@@ -262,8 +269,41 @@ func executeCommand(command string) {
 	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	start := time.Now()
 	if err := cmd.Run(); err != nil {
+		slog.Warn("command failed", "command", command, "duration", time.Since(start), "err", err)
 		color.Yellow("Command failed: %v", err)
+		return err
+	}
+	slog.Debug("command finished", "command", command, "duration", time.Since(start))
+	return nil
+}
+
+// failureNoticeInterval is the least time between two notifications about
+// failed runs, so a command failing every few seconds does not flood the
+// notify.ini channels.
+const failureNoticeInterval = 15 * time.Minute
+
+// failureNotifier sends a notification when a run fails, at most once per
+// failureNoticeInterval, counting the failures held back in between.
+type failureNotifier struct {
+	command  string
+	lastSent time.Time
+	held     int
+}
+
+func (f *failureNotifier) failed(err error, silent bool) {
+	if !f.lastSent.IsZero() && time.Since(f.lastSent) < failureNoticeInterval {
+		f.held++
+		return
+	}
+	message := err.Error()
+	if f.held > 0 {
+		message += fmt.Sprintf(" (%d more failure(s) since the last notice)", f.held)
+	}
+	f.lastSent, f.held = time.Now(), 0
+	if err := notify.Load("rc").Send(fmt.Sprintf("\"%s\" failed", f.command), message); err != nil && !silent {
+		color.Yellow("Could not send failure notification: %v", err)
 	}
 }
 
@@ -337,6 +377,14 @@ func printUsage() {
 	fmt.Println("    Optional. Exit when accumulated successful run time reaches this cap. Period format. Requires -expect.")
 	fmt.Println()
 
+	color.Cyan("  --version, --check-update")
+	fmt.Println("    Optional. Print the version, or check GitHub for a newer release, and exit.")
+	fmt.Println()
+
+	color.Cyan("  --debug, --log-file <path>")
+	fmt.Println("    Optional. Log each run, failures and exit reason to a rotating log file.")
+	fmt.Println()
+
 	color.Yellow("EXAMPLES")
 	color.Green("    rc \"go run main.go\" 1")
 	fmt.Println("    Runs 'go run main.go' every 1 minute.")
@@ -390,7 +438,15 @@ func warnDuplicateFlag(seen map[string]bool, label string) bool {
 	return false
 }
 
-func main() {
+// Run starts rc with args, the command line after the program name, and
+// returns the exit code. cmd/rc and the kreftus launcher both call it.
+func Run(args []string) int {
+	version.Default("1.4")
+	args = logging.Init("rc", append([]string{"rc"}, args...))[1:]
+	defer logging.Close()
+	if version.HandleArgs("rc", args) {
+		return 0
+	}
 	// Manual argument parsing is used to allow flags to be placed anywhere in the command.
 	// The standard `flag` package stops parsing at the first non-flag argument.
 	var commandStr string
@@ -417,7 +473,6 @@ func main() {
 
 	seenFlags := make(map[string]bool)
 
-	args := os.Args[1:]
 	skipValue := func(i int) int {
 		if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
 			return 2
@@ -538,7 +593,7 @@ func main() {
 				continue
 			}
 			printUsage()
-			os.Exit(0)
+			return 0
 		default:
 			nonFlagArgs = append(nonFlagArgs, arg)
 		}
@@ -624,7 +679,7 @@ func main() {
 	// Exit if no command was provided either by argument or interactively.
 	if commandStr == "" {
 		fmt.Println("No command provided. Exiting.")
-		return
+		return 0
 	}
 
 	// Parse period string to get duration and display string
@@ -722,6 +777,7 @@ func main() {
 	actualExecutionCount := 0
 	var pendingExitMsg string
 	var pendingExitGreen bool
+	failures := &failureNotifier{command: commandStr}
 	for {
 		executionCount++
 		loopStartTime := time.Now()
@@ -745,7 +801,9 @@ func main() {
 				}
 				color.White(executeMessage)
 			}
-			executeCommand(commandStr)
+			if err := executeCommand(commandStr); err != nil {
+				failures.failed(err, silent)
+			}
 			commandEndTime := time.Now()
 			commandDuration = commandEndTime.Sub(loopStartTime)
 			hasCommandDuration = true
@@ -825,6 +883,9 @@ func main() {
 		}
 	}
 
+	if pendingExitMsg != "" {
+		slog.Info("exiting", "reason", pendingExitMsg, "executions", actualExecutionCount)
+	}
 	if pendingExitMsg != "" && !silent {
 		printExpectSummary(expect, executionCount, skip, silent)
 		if pendingExitGreen {
@@ -833,4 +894,11 @@ func main() {
 			color.Red("\n%s", pendingExitMsg)
 		}
 	}
+	// Failure exits are usually unattended; surface them through the shared notification channels.
+	if pendingExitMsg != "" && !pendingExitGreen {
+		if err := notify.Load("rc").Send(fmt.Sprintf("\"%s\" failed", commandStr), pendingExitMsg); err != nil && !silent {
+			color.Yellow("Could not send failure notification: %v", err)
+		}
+	}
+	return 0
 }
@@ -0,0 +1,137 @@
+// Package apikey stores provider API keys in one shared keys.ini so a key
+// entered once (for example in vbtc) is picked up by every other tool that
+// talks to the same provider (for example bmon).
+package apikey
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"kreftus/shared/configdir"
+	"kreftus/shared/lcw"
+)
+
+const (
+	keysFileName = "keys.ini"
+	keyName      = "ApiKey"
+)
+
+// ErrCancelled is returned by Prompt when the user submits an empty line.
+var ErrCancelled = errors.New("setup cancelled by user")
+
+// Provider describes an API whose key is shared between tools.
+type Provider struct {
+	Section   string            // Section name in keys.ini
+	Name      string            // Human-readable provider name used in prompts
+	SignupURL string            // Where to get a free key
+	Validate  func(string) bool // Returns true if the key is accepted by the provider
+}
+
+// LiveCoinWatch is used by vbtc and bmon for Bitcoin prices.
+var LiveCoinWatch = Provider{
+	Section:   "livecoinwatch",
+	Name:      "LiveCoinWatch",
+	SignupURL: "https://www.livecoinwatch.com/tools/api",
+	Validate:  validateLiveCoinWatch,
+}
+
+// OpenWeatherMap is used by gw for the One Call API 3.0.
+var OpenWeatherMap = Provider{
+	Section:   "openweathermap",
+	Name:      "OpenWeatherMap",
+	SignupURL: "https://openweathermap.org/api",
+	Validate:  validateOpenWeatherMap,
+}
+
+// Path returns the location of the shared keys file, creating its directory if needed.
+// It lives in the user config dir (e.g. ~/.config/kreftus/keys.ini or %AppData%\kreftus\keys.ini).
+func Path() (string, error) {
+	return configdir.File(keysFileName)
+}
+
+// Load returns the stored key for p, or "" if none is saved.
+func Load(p Provider) string {
+	path, err := Path()
+	if err != nil {
+		return ""
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Section(p.Section).Key(keyName).String())
+}
+
+// Save writes key for p into the shared keys file, preserving other providers' keys.
+func Save(p Provider, key string) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		cfg = ini.Empty()
+	}
+	cfg.Section(p.Section).Key(keyName).SetValue(key)
+	if err := cfg.SaveTo(path); err != nil {
+		return fmt.Errorf("failed to save API key to %s: %w", path, err)
+	}
+	// Keys are credentials; keep the file readable by the owner only.
+	_ = os.Chmod(path, 0600)
+	return nil
+}
+
+// Prompt asks for a key until a valid one is entered, saves it to the shared
+// keys file and returns it. An empty line returns ErrCancelled.
+func Prompt(reader *bufio.Reader, p Provider) (string, error) {
+	color.Green("Get a free key at: %s", p.SignupURL)
+	for {
+		fmt.Printf("Please enter your %s API Key (or press Enter to exit): ", p.Name)
+		input, err := reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input == "" {
+			if err != nil {
+				return "", fmt.Errorf("failed to read API key from input: %w", err)
+			}
+			return "", ErrCancelled
+		}
+		if p.Validate == nil || p.Validate(input) {
+			if err := Save(p, input); err != nil {
+				color.Yellow("API Key is valid, but could not be shared with other tools: %v", err)
+			}
+			return input, nil
+		}
+		color.Red("Invalid API Key. Please try again.")
+	}
+}
+
+func validateLiveCoinWatch(key string) bool {
+	if key == "" {
+		return false
+	}
+	client := &lcw.Client{APIKey: key, Attempts: 1}
+	var resp struct{}
+	return client.Post(context.Background(), "coins/single", map[string]interface{}{"currency": "USD", "code": "BTC", "meta": false}, &resp) == nil
+}
+
+func validateOpenWeatherMap(key string) bool {
+	if key == "" {
+		return false
+	}
+	// A geocoding lookup of a known zip code is the cheapest authenticated call.
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get("http://api.openweathermap.org/geo/1.0/zip?zip=90210,us&appid=" + key)
+	if err != nil {
+		return false
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
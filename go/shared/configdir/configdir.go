@@ -0,0 +1,40 @@
+// Package configdir locates the directory shared by all kreftus tools for
+// cross-tool settings such as API keys and notification preferences.
+package configdir
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+const name = "kreftus"
+
+// Dir returns the shared config directory, creating it if needed.
+// It lives in the user config dir (e.g. ~/.config/kreftus or %AppData%\kreftus),
+// falling back to ~/.kreftus when no config dir is available.
+func Dir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		home, homeErr := os.UserHomeDir()
+		if homeErr != nil {
+			return "", fmt.Errorf("failed to get user config directory (%v) and home directory (%w)", err, homeErr)
+		}
+		dir = filepath.Join(home, "."+name)
+	} else {
+		dir = filepath.Join(dir, name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create config directory %s: %w", dir, err)
+	}
+	return dir, nil
+}
+
+// File returns the path of fileName inside the shared config directory.
+func File(fileName string) (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, fileName), nil
+}
@@ -0,0 +1,50 @@
+// Package console gives the kreftus tools one place for terminal setup and
+// capability checks: Windows console configuration, NO_COLOR handling,
+// truecolor detection and user color themes.
+package console
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// NoColor reports whether color output should be suppressed, following the
+// NO_COLOR convention (https://no-color.org) and TERM=dumb.
+func NoColor() bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return true
+	}
+	return os.Getenv("TERM") == "dumb"
+}
+
+// TrueColor reports whether the terminal is known to render 24-bit color.
+func TrueColor() bool {
+	if NoColor() {
+		return false
+	}
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return true
+	}
+	// Windows Terminal and the common GUI terminals support truecolor without advertising it.
+	if runtime.GOOS == "windows" && os.Getenv("WT_SESSION") != "" {
+		return true
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "vscode", "WezTerm", "Hyper":
+		return true
+	}
+	return strings.Contains(os.Getenv("TERM"), "truecolor") || strings.Contains(os.Getenv("TERM"), "24bit")
+}
+
+// Width returns the terminal's column count: $COLUMNS when set, otherwise
+// what the console reports for stdout. It returns 0 when stdout is not a
+// terminal (piped or redirected) and COLUMNS is unset.
+func Width() int {
+	if n, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && n > 0 {
+		return n
+	}
+	return consoleWidth()
+}
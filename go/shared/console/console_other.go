@@ -0,0 +1,25 @@
+//go:build !windows
+
+package console
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Setup is a no-op outside Windows; Unix terminals speak UTF-8 and ANSI already.
+func Setup() {}
+
+// PreferUnicodeFont is a no-op outside Windows.
+func PreferUnicodeFont() {}
+
+// consoleWidth asks the terminal on stdout for its size (TIOCGWINSZ).
+func consoleWidth() int {
+	var ws struct{ Row, Col, X, Y uint16 }
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.Col)
+}
@@ -1,6 +1,6 @@
 //go:build windows
 
-package main
+package console
 
 import (
 	"os"
@@ -9,9 +9,9 @@ import (
 	"unsafe"
 )
 
-// configureWindowsConsole ensures the Windows console uses UTF-8 code page
-// and enables Virtual Terminal (ANSI) processing for proper Unicode rendering.
-func configureWindowsConsole() {
+// Setup ensures the Windows console uses the UTF-8 code page and enables
+// Virtual Terminal (ANSI) processing for proper Unicode and color rendering.
+func Setup() {
 	// Quietly set UTF-8 code page for legacy console APIs
 	_ = exec.Command("cmd", "/c", "chcp 65001 >nul").Run()
 
@@ -27,40 +27,38 @@ func configureWindowsConsole() {
 		ENABLE_VTP_OUTPUT        uint32 = 0x0004
 		ENABLE_PROCESSED_OUTPUT  uint32 = 0x0001
 		DISABLE_NEWLINE_AUTO_RET uint32 = 0x0008
-		ENABLE_VTP_INPUT         uint32 = 0x0200
 	)
 
 	// Set UTF-8 code pages
 	_, _, _ = setConsoleOutputCP.Call(uintptr(CP_UTF8))
 	_, _, _ = setConsoleCP.Call(uintptr(CP_UTF8))
 
-	// Enable VT on stdout
-	outHandle, _, _ := getStdHandle.Call(uintptr(^uint32(11) + 1)) // STD_OUTPUT_HANDLE=-11
-	if outHandle != 0 {
+	// Enable VT on stdout and stderr (STD_OUTPUT_HANDLE=-11, STD_ERROR_HANDLE=-12)
+	for _, std := range []uint32{11, 12} {
+		handle, _, _ := getStdHandle.Call(uintptr(^std + 1))
+		if handle == 0 {
+			continue
+		}
 		var mode uint32
-		_, _, _ = getConsoleMode.Call(outHandle, uintptr(unsafe.Pointer(&mode)))
+		_, _, _ = getConsoleMode.Call(handle, uintptr(unsafe.Pointer(&mode)))
 		mode |= ENABLE_VTP_OUTPUT | ENABLE_PROCESSED_OUTPUT | DISABLE_NEWLINE_AUTO_RET
-		_, _, _ = setConsoleMode.Call(outHandle, uintptr(mode))
-	}
-	// Enable VT on stderr
-	errHandle, _, _ := getStdHandle.Call(uintptr(^uint32(12) + 1)) // STD_ERROR_HANDLE=-12
-	if errHandle != 0 {
-		var mode uint32
-		_, _, _ = getConsoleMode.Call(errHandle, uintptr(unsafe.Pointer(&mode)))
-		mode |= ENABLE_VTP_OUTPUT | ENABLE_PROCESSED_OUTPUT | DISABLE_NEWLINE_AUTO_RET
-		_, _, _ = setConsoleMode.Call(errHandle, uintptr(mode))
+		_, _, _ = setConsoleMode.Call(handle, uintptr(mode))
 	}
+}
 
-	// Prefer not to override Windows Terminal font
-	if os.Getenv("WT_SESSION") == "" {
-		// Try Cascadia Mono first, then Consolas
-		setConsoleFontWindows("Cascadia Mono", 16)
-		setConsoleFontWindows("Consolas", 16)
+// PreferUnicodeFont switches classic conhost windows to a TrueType font that
+// can render block and braille glyphs. Windows Terminal is left untouched.
+func PreferUnicodeFont() {
+	if os.Getenv("WT_SESSION") != "" {
+		return
 	}
+	// Try Cascadia Mono first, then Consolas
+	setConsoleFont("Cascadia Mono", 16)
+	setConsoleFont("Consolas", 16)
 }
 
-// setConsoleFontWindows attempts to switch to a Unicode-capable TrueType font.
-func setConsoleFontWindows(face string, height int16) {
+// setConsoleFont attempts to switch to a Unicode-capable TrueType font.
+func setConsoleFont(face string, height int16) {
 	kernel32 := syscall.NewLazyDLL("kernel32.dll")
 	getStdHandle := kernel32.NewProc("GetStdHandle")
 	getCurrentConsoleFontEx := kernel32.NewProc("GetCurrentConsoleFontEx")
@@ -104,3 +102,30 @@ func setConsoleFontWindows(face string, height int16) {
 	}
 	_, _, _ = setCurrentConsoleFontEx.Call(h, 0, uintptr(unsafe.Pointer(&info)))
 }
+
+// consoleWidth reads the visible window width of the stdout console.
+func consoleWidth() int {
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	getStdHandle := kernel32.NewProc("GetStdHandle")
+	getConsoleScreenBufferInfo := kernel32.NewProc("GetConsoleScreenBufferInfo")
+
+	type coord struct{ X, Y int16 }
+	type smallRect struct{ Left, Top, Right, Bottom int16 }
+	type consoleScreenBufferInfo struct {
+		Size              coord
+		CursorPosition    coord
+		Attributes        uint16
+		Window            smallRect
+		MaximumWindowSize coord
+	}
+
+	h, _, _ := getStdHandle.Call(uintptr(^uint32(11) + 1)) // STD_OUTPUT_HANDLE
+	if h == 0 {
+		return 0
+	}
+	var info consoleScreenBufferInfo
+	if ok, _, _ := getConsoleScreenBufferInfo.Call(h, uintptr(unsafe.Pointer(&info))); ok == 0 {
+		return 0
+	}
+	return int(info.Window.Right-info.Window.Left) + 1
+}
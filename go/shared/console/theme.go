@@ -0,0 +1,194 @@
+package console
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"kreftus/shared/configdir"
+)
+
+const (
+	themesFileName  = "themes.ini"
+	settingsSection = "settings"
+)
+
+// Spec is a parsed theme color: one of the 16 ANSI colors or an RGB value.
+type Spec struct {
+	Index   int // ANSI color index 0-15, or -1 for an RGB color
+	R, G, B uint8
+}
+
+// ansiNames maps the color names accepted in themes.ini to ANSI indexes.
+// The "hi" prefix selects the bright variant, matching fatih/color naming.
+var ansiNames = map[string]int{
+	"black": 0, "red": 1, "green": 2, "yellow": 3, "blue": 4, "magenta": 5, "cyan": 6, "white": 7,
+	"hiblack": 8, "hired": 9, "higreen": 10, "hiyellow": 11, "hiblue": 12, "himagenta": 13, "hicyan": 14, "hiwhite": 15,
+	"gray": 8, "grey": 8, "darkgray": 8,
+}
+
+// ansiRGB holds the xterm default RGB values of the 16 ANSI colors, used to
+// downsample RGB theme colors on terminals without truecolor.
+var ansiRGB = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// Theme maps role names (e.g. "alert", "title") to colors. Each tool documents
+// the roles it reads; roles a theme does not define keep the tool's defaults.
+type Theme struct {
+	Name   string
+	colors map[string]Spec
+}
+
+// ThemesPath returns the location of the shared themes.ini.
+func ThemesPath() (string, error) {
+	return configdir.File(themesFileName)
+}
+
+// LoadTheme returns the theme selected for tool. The KREFTUS_THEME environment
+// variable wins; otherwise [settings] in themes.ini is consulted for a key
+// named after the tool, then the global Theme key. A missing file or the
+// name "default" yields an empty theme, so every tool keeps its built-in colors.
+//
+//	[settings]
+//	Theme = solarized
+//	larry = neon
+//
+//	[solarized]
+//	title = #268bd2
+//	alert = hired
+func LoadTheme(tool string) *Theme {
+	t := &Theme{Name: "default", colors: map[string]Spec{}}
+	path, err := ThemesPath()
+	if err != nil {
+		return t
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return t
+	}
+	name := strings.TrimSpace(os.Getenv("KREFTUS_THEME"))
+	if name == "" {
+		settings := cfg.Section(settingsSection)
+		name = strings.TrimSpace(settings.Key(tool).String())
+		if name == "" {
+			name = strings.TrimSpace(settings.Key("Theme").String())
+		}
+	}
+	if name == "" || strings.EqualFold(name, "default") || !cfg.HasSection(name) {
+		return t
+	}
+	t.Name = name
+	for _, key := range cfg.Section(name).Keys() {
+		if spec, err := ParseSpec(key.String()); err == nil {
+			t.colors[strings.ToLower(key.Name())] = spec
+		}
+	}
+	return t
+}
+
+// ParseSpec parses a color name ("red", "hicyan") or hex value ("#ff8800").
+func ParseSpec(s string) (Spec, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if idx, ok := ansiNames[s]; ok {
+		return Spec{Index: idx}, nil
+	}
+	hex := strings.TrimPrefix(s, "#")
+	if len(hex) != 6 {
+		return Spec{}, fmt.Errorf("invalid color %q: use a name like \"red\" or a hex value like \"#ff8800\"", s)
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return Spec{}, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return Spec{Index: -1, R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v)}, nil
+}
+
+// RGB returns the color's RGB value, resolving ANSI colors to xterm defaults.
+func (s Spec) RGB() (r, g, b uint8) {
+	if s.Index >= 0 {
+		c := ansiRGB[s.Index]
+		return c[0], c[1], c[2]
+	}
+	return s.R, s.G, s.B
+}
+
+// ansiIndex returns the ANSI index, downsampling RGB colors to the nearest one.
+func (s Spec) ansiIndex() int {
+	if s.Index >= 0 {
+		return s.Index
+	}
+	best, bestDist := 0, -1
+	for i, c := range ansiRGB {
+		dr, dg, db := int(s.R)-int(c[0]), int(s.G)-int(c[1]), int(s.B)-int(c[2])
+		dist := dr*dr + dg*dg + db*db
+		if bestDist < 0 || dist < bestDist {
+			best, bestDist = i, dist
+		}
+	}
+	return best
+}
+
+// sgr returns the SGR parameters that select s as the foreground color.
+func (s Spec) sgr() []int {
+	if s.Index < 0 && TrueColor() {
+		return []int{38, 2, int(s.R), int(s.G), int(s.B)}
+	}
+	idx := s.ansiIndex()
+	if idx < 8 {
+		return []int{30 + idx}
+	}
+	return []int{90 + idx - 8}
+}
+
+// Lookup returns the theme's color for role, if the theme defines one.
+func (t *Theme) Lookup(role string) (Spec, bool) {
+	if t == nil {
+		return Spec{}, false
+	}
+	spec, ok := t.colors[strings.ToLower(role)]
+	return spec, ok
+}
+
+// Color returns a fatih/color printer for role, or one built from fallback
+// when the theme does not define the role.
+func (t *Theme) Color(role string, fallback ...color.Attribute) *color.Color {
+	spec, ok := t.Lookup(role)
+	if !ok {
+		return color.New(fallback...)
+	}
+	var attrs []color.Attribute
+	for _, p := range spec.sgr() {
+		attrs = append(attrs, color.Attribute(p))
+	}
+	return color.New(attrs...)
+}
+
+// ANSI returns the escape sequence for role, or fallback when the theme does
+// not define it. It returns "" when color is disabled.
+func (t *Theme) ANSI(role, fallback string) string {
+	if NoColor() {
+		return ""
+	}
+	spec, ok := t.Lookup(role)
+	if !ok {
+		return fallback
+	}
+	parts := make([]string, 0, 5)
+	for _, p := range spec.sgr() {
+		parts = append(parts, strconv.Itoa(p))
+	}
+	return "\033[" + strings.Join(parts, ";") + "m"
+}
+
+// Reset returns the escape sequence that resets colors, or "" when color is disabled.
+func Reset() string {
+	if NoColor() {
+		return ""
+	}
+	return "\033[0m"
+}
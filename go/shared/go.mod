@@ -0,0 +1,15 @@
+module kreftus/shared
+
+go 1.23.0
+
+require (
+	github.com/fatih/color v1.17.0
+	gopkg.in/ini.v1 v1.67.0
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	github.com/stretchr/testify v1.10.0 // indirect
+	golang.org/x/sys v0.34.0 // indirect
+)
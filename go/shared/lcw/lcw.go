@@ -0,0 +1,173 @@
+// Package lcw is the LiveCoinWatch API client shared by bmon and vbtc. It
+// sends requests with the API key, gives each attempt its own timeout, and
+// retries network errors, server errors and rate limiting with exponential
+// backoff and jitter, so a brief hiccup does not reach the user.
+package lcw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// BaseURL is the LiveCoinWatch API root.
+const BaseURL = "https://api.livecoinwatch.com"
+
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultAttempts  = 3
+	defaultBaseDelay = 1 * time.Second
+
+	creditsExhaustedText = "No more daily credits remaining"
+)
+
+// ErrCreditsExhausted is returned when the key's daily credits are used up;
+// they renew at midnight UTC.
+var ErrCreditsExhausted = errors.New("no more daily API credits")
+
+// StatusError is a response other than 200 OK.
+type StatusError struct {
+	Endpoint   string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.Endpoint, e.StatusCode)
+}
+
+// KeyRejected reports whether the status means the API key was refused.
+func (e *StatusError) KeyRejected() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+// Poster sends one LiveCoinWatch request and decodes the response into out.
+// *Client implements it; tests and demo modes can substitute their own.
+type Poster interface {
+	Post(ctx context.Context, endpoint string, payload, out interface{}) error
+}
+
+// Doer sends an HTTP request. *http.Client implements it.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client holds the key and retry settings. The zero value of each setting
+// means its default.
+type Client struct {
+	APIKey    string
+	BaseURL   string        // default BaseURL
+	Timeout   time.Duration // per attempt, default 10s
+	Attempts  int           // including the first, default 3
+	BaseDelay time.Duration // wait before the second attempt, doubling after; default 1s
+	HTTP      Doer          // default an *http.Client with Timeout
+
+	// OnAttempt is called before every request, e.g. to count API budget.
+	OnAttempt func(endpoint string)
+	// OnRetry is called before waiting to retry; attempt is the one that failed.
+	OnRetry func(endpoint string, attempt int, wait time.Duration, err error)
+}
+
+// Post sends payload as JSON to endpoint (e.g. "coins/single") and decodes
+// the response into out. A response other than 200 OK is a *StatusError, or
+// ErrCreditsExhausted when the daily credits are used up.
+func (c *Client) Post(ctx context.Context, endpoint string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request for %s: %w", endpoint, err)
+	}
+	resp, err := c.Do(ctx, endpoint, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", endpoint, err)
+	}
+	if resp.StatusCode == http.StatusForbidden && strings.Contains(string(data), creditsExhaustedText) {
+		return ErrCreditsExhausted
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{Endpoint: endpoint, StatusCode: resp.StatusCode}
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to unmarshal response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// Do POSTs body to endpoint, retrying failed requests, server errors and
+// rate limiting. The last response is returned whatever its status; API key
+// errors are never retried. The caller closes the response body.
+func (c *Client) Do(ctx context.Context, endpoint string, body []byte) (*http.Response, error) {
+	if c.APIKey == "" {
+		return nil, fmt.Errorf("API key is empty")
+	}
+	base := c.BaseURL
+	if base == "" {
+		base = BaseURL
+	}
+	attempts := c.Attempts
+	if attempts <= 0 {
+		attempts = defaultAttempts
+	}
+	delay := c.BaseDelay
+	if delay <= 0 {
+		delay = defaultBaseDelay
+	}
+	doer := c.HTTP
+	if doer == nil {
+		timeout := c.Timeout
+		if timeout <= 0 {
+			timeout = defaultTimeout
+		}
+		doer = &http.Client{Timeout: timeout}
+	}
+
+	for attempt := 1; ; attempt++ {
+		// Request bodies are one-shot, so build a new request each attempt
+		req, err := http.NewRequestWithContext(ctx, "POST", base+"/"+endpoint, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("x-api-key", c.APIKey)
+
+		if c.OnAttempt != nil {
+			c.OnAttempt(endpoint)
+		}
+		resp, err := doer.Do(req)
+		retry := err != nil || resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+		if !retry || attempt >= attempts || ctx.Err() != nil {
+			if err != nil {
+				slog.Warn("request failed", "endpoint", endpoint, "attempts", attempt, "err", err)
+				err = fmt.Errorf("%s failed after %d attempts: %w", endpoint, attempt, err)
+			}
+			return resp, err
+		}
+
+		wait := time.Duration(math.Pow(2, float64(attempt-1)))*delay +
+			time.Duration(time.Now().UnixNano()%1000)*time.Millisecond
+		if err == nil {
+			err = &StatusError{Endpoint: endpoint, StatusCode: resp.StatusCode}
+			resp.Body.Close()
+		}
+		slog.Warn("request failed, retrying", "endpoint", endpoint, "attempt", attempt, "err", err, "wait", wait)
+		if c.OnRetry != nil {
+			c.OnRetry(endpoint, attempt, wait, err)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
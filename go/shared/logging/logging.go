@@ -0,0 +1,189 @@
+// Package logging gives every kreftus tool the same opt-in diagnostic log.
+// Passing --debug or --log-file routes log/slog output to a size-rotated file
+// and records each outgoing HTTP request, so a field-reported bug can be
+// diagnosed from the log instead of a screenshot. Logging never writes to the
+// terminal, so TUIs are unaffected.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"kreftus/shared/configdir"
+)
+
+const (
+	maxLogSize = 5 << 20 // rotate after 5 MB
+	maxBackups = 3       // keep tool.log.1 .. tool.log.3
+)
+
+// closer is the open log file, if logging is enabled.
+var closer io.Closer
+
+// Init strips --debug and --log-file from args (os.Args style, program name
+// first), configures the default slog logger and returns the remaining args.
+//
+//	--debug             debug-level log to the default file
+//	--log-file <path>   info-level log to path (debug level with --debug)
+//	--log-file=<path>
+//
+// The default file is <config dir>/kreftus/logs/<tool>.log. Setting
+// KREFTUS_DEBUG=1 is equivalent to --debug. Without either flag the logger
+// discards everything.
+func Init(tool string, args []string) []string {
+	debug := os.Getenv("KREFTUS_DEBUG") != ""
+	logFile := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i == 0 {
+			rest = append(rest, arg)
+			continue
+		}
+		switch {
+		case arg == "--debug" || arg == "-debug":
+			debug = true
+		case arg == "--log-file" || arg == "-log-file":
+			if i+1 < len(args) {
+				logFile = args[i+1]
+				i++
+			}
+		case strings.HasPrefix(arg, "--log-file=") || strings.HasPrefix(arg, "-log-file="):
+			logFile = arg[strings.Index(arg, "=")+1:]
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	if !debug && logFile == "" {
+		slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+		return rest
+	}
+
+	if logFile == "" {
+		dir, err := configdir.Dir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Logging disabled: %v\n", err)
+			return rest
+		}
+		logFile = filepath.Join(dir, "logs", tool+".log")
+	}
+	w, err := openRotating(logFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Logging disabled: %v\n", err)
+		return rest
+	}
+	closer = w
+
+	level := slog.LevelInfo
+	if debug {
+		level = slog.LevelDebug
+	}
+	handler := slog.NewTextHandler(w, &slog.HandlerOptions{Level: level, ReplaceAttr: redactAttr})
+	slog.SetDefault(slog.New(handler).With("tool", tool, "pid", os.Getpid()))
+
+	// Every client without its own Transport goes through DefaultTransport,
+	// so wrapping it logs all API calls without touching each call site.
+	http.DefaultTransport = &loggingTransport{base: http.DefaultTransport}
+
+	slog.Info("session start", "args", strings.Join(redactArgs(rest[1:]), " "), "log", logFile)
+	return rest
+}
+
+// Close flushes and closes the log file. Safe to call when logging is disabled.
+func Close() {
+	if closer != nil {
+		slog.Info("session end")
+		closer.Close()
+		closer = nil
+	}
+}
+
+// Enabled reports whether a log file is being written.
+func Enabled() bool {
+	return closer != nil
+}
+
+// loggingTransport records method, redacted URL, status and latency of each request.
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	attrs := []any{"method", req.Method, "url", RedactURL(req.URL), "duration", time.Since(start).Round(time.Millisecond)}
+	if err != nil {
+		slog.Error("http request failed", append(attrs, "err", err)...)
+		return resp, err
+	}
+	level := slog.LevelDebug
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	slog.Log(context.Background(), level, "http request", append(attrs, "status", resp.StatusCode)...)
+	return resp, nil
+}
+
+// secretParams are query parameters that carry API keys.
+var secretParams = []string{"appid", "apikey", "api_key", "key", "token"}
+
+// RedactURL returns u as a string with API key query parameters masked.
+func RedactURL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	c := *u
+	q := c.Query()
+	changed := false
+	for _, p := range secretParams {
+		if q.Has(p) {
+			q.Set(p, "REDACTED")
+			changed = true
+		}
+	}
+	if changed {
+		c.RawQuery = q.Encode()
+	}
+	return c.String()
+}
+
+// secretPattern matches key=value query fragments embedded in error strings
+// (e.g. a failed request URL), so keys never reach the log file.
+var secretPattern = regexp.MustCompile(`(?i)\b(appid|apikey|api_key|key|token)=[^&\s"]+`)
+
+// redactAttr masks API keys inside string and error attribute values.
+func redactAttr(_ []string, a slog.Attr) slog.Attr {
+	switch v := a.Value.Any().(type) {
+	case string:
+		if secretPattern.MatchString(v) {
+			a.Value = slog.StringValue(secretPattern.ReplaceAllString(v, "$1=REDACTED"))
+		}
+	case error:
+		if msg := v.Error(); secretPattern.MatchString(msg) {
+			a.Value = slog.StringValue(secretPattern.ReplaceAllString(msg, "$1=REDACTED"))
+		}
+	}
+	return a
+}
+
+// redactArgs masks values that look like API keys passed on the command line.
+func redactArgs(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		if i > 0 && strings.Contains(strings.ToLower(args[i-1]), "key") {
+			a = "REDACTED"
+		}
+		out[i] = a
+	}
+	return out
+}
@@ -0,0 +1,199 @@
+// Package notify delivers alerts from the kreftus tools through one set of
+// channels: the terminal bell, a desktop toast and a webhook POST. Which
+// channels are used is read from the shared notify.ini so a user configures
+// them once for every tool.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+	"kreftus/shared/configdir"
+)
+
+const (
+	configFileName = "notify.ini"
+	globalSection  = "notify"
+)
+
+// Notifier sends a notification through each enabled channel.
+type Notifier struct {
+	App     string // Tool name shown as the notification source
+	Bell    bool   // Ring the terminal bell
+	Desktop bool   // Show a desktop toast
+	Webhook string // URL to POST a JSON payload to ("" = disabled)
+}
+
+// Path returns the location of the shared notify.ini.
+func Path() (string, error) {
+	return configdir.File(configFileName)
+}
+
+// Load returns the notifier settings for app. Values in the [notify] section
+// apply to every tool; a section named after the tool overrides them.
+// Without a config file only the terminal bell is enabled.
+//
+//	[notify]
+//	Bell    = true
+//	Desktop = true
+//	Webhook = https://discord.com/api/webhooks/...
+//
+//	[gw]
+//	Bell = false
+func Load(app string) *Notifier {
+	n := &Notifier{App: app, Bell: true}
+	path, err := Path()
+	if err != nil {
+		return n
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return n
+	}
+	for _, name := range []string{globalSection, app} {
+		if !cfg.HasSection(name) {
+			continue
+		}
+		sec := cfg.Section(name)
+		if sec.HasKey("Bell") {
+			n.Bell = sec.Key("Bell").MustBool(n.Bell)
+		}
+		if sec.HasKey("Desktop") {
+			n.Desktop = sec.Key("Desktop").MustBool(n.Desktop)
+		}
+		if sec.HasKey("Webhook") {
+			n.Webhook = strings.TrimSpace(sec.Key("Webhook").String())
+		}
+	}
+	return n
+}
+
+// Send delivers title and message through every enabled channel. A failing
+// channel does not stop the others; all failures are returned together.
+func (n *Notifier) Send(title, message string) error {
+	if n == nil {
+		return nil
+	}
+	var errs []error
+	if n.Bell {
+		Bell()
+	}
+	if n.Desktop {
+		if err := Desktop(n.App, title, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.Webhook != "" {
+		if err := Webhook(n.Webhook, n.App, title, message); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Bell rings the terminal bell.
+func Bell() {
+	fmt.Fprint(os.Stdout, "\a")
+}
+
+// Beep plays a tone of the given frequency (Hz) and duration (ms). Windows
+// consoles can play the exact tone; elsewhere it falls back to the terminal bell.
+func Beep(frequency, duration int) {
+	if runtime.GOOS == "windows" {
+		exec.Command("powershell", "-NoProfile", "-c", fmt.Sprintf("[console]::beep(%d, %d)", frequency, duration)).Run()
+		return
+	}
+	Bell()
+}
+
+// Desktop shows a desktop notification using the platform's native mechanism:
+// a PowerShell toast on Windows, osascript on macOS and notify-send elsewhere.
+func Desktop(app, title, message string) error {
+	heading := title
+	if app != "" {
+		heading = app + ": " + title
+	}
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("powershell", "-NoProfile", "-NonInteractive", "-c", windowsToastScript(heading, message))
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(message), appleScriptString(heading))
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		cmd = exec.Command("notify-send", heading, message)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("desktop notification failed: %w", err)
+	}
+	return nil
+}
+
+// Webhook POSTs a JSON payload to url. The message is sent as both "text"
+// (Slack, Mattermost) and "content" (Discord) so common chat webhooks accept
+// it unmodified.
+func Webhook(url, app, title, message string) error {
+	text := title
+	if message != "" {
+		text = title + "\n" + message
+	}
+	if app != "" {
+		text = "[" + app + "] " + text
+	}
+	payload, err := json.Marshal(map[string]string{
+		"app":     app,
+		"title":   title,
+		"message": message,
+		"time":    time.Now().UTC().Format(time.RFC3339),
+		"text":    text,
+		"content": text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %s", resp.Status)
+	}
+	return nil
+}
+
+// windowsToastScript builds a PowerShell script that shows a toast through the
+// WinRT notification API, using PowerShell's own AppUserModelID so no app
+// registration is needed.
+func windowsToastScript(title, message string) string {
+	const appID = `{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe`
+	return fmt.Sprintf(`[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$t = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$x = $t.GetElementsByTagName('text')
+$x.Item(0).AppendChild($t.CreateTextNode(%s)) > $null
+$x.Item(1).AppendChild($t.CreateTextNode(%s)) > $null
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier('%s').Show([Windows.UI.Notifications.ToastNotification]::new($t))`,
+		powerShellString(title), powerShellString(message), appID)
+}
+
+// powerShellString quotes s as a single-quoted PowerShell literal.
+func powerShellString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// appleScriptString quotes s as an AppleScript string literal.
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,165 @@
+// Package version holds the build version of each tool and implements the
+// common --version flag and the opt-in --check-update release lookup.
+//
+// Each tool's main calls Default with its release number, so a plain
+// `go build` reports it too. build.ps1 stamps the same number and the build
+// date via:
+//
+//	-ldflags "-X kreftus/shared/version.Version=1.6 -X kreftus/shared/version.Date=2025-08-07@1430"
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Set at build time through -ldflags -X, or by Default.
+var (
+	Version = ""
+	Date    = ""
+)
+
+// Default sets Version to v unless the build stamped one.
+func Default(v string) {
+	if Version == "" {
+		Version = v
+	}
+}
+
+const (
+	releasesURL = "https://api.github.com/repos/Thujone82/kreftus/releases?per_page=100"
+	projectURL  = "https://github.com/Thujone82/kreftus"
+)
+
+// Release is the subset of the GitHub release payload used for update checks.
+type Release struct {
+	TagName    string `json:"tag_name"`
+	HTMLURL    string `json:"html_url"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+}
+
+// String returns e.g. "bmon 1.6 (2025-08-07@1430)".
+func String(tool string) string {
+	if Date == "" {
+		return fmt.Sprintf("%s %s", tool, Version)
+	}
+	return fmt.Sprintf("%s %s (%s)", tool, Version, Date)
+}
+
+// HandleArgs prints the version for --version/-version and runs the update
+// check for --check-update/-check-update. Like the flag package it only looks
+// at the flags before the first positional argument or "--", so the same word
+// given as an argument (or to a launched tool) is left alone. It returns true
+// when one of these flags was handled and the caller should exit.
+func HandleArgs(tool string, args []string) bool {
+	for _, arg := range args {
+		if arg == "--" || !strings.HasPrefix(arg, "-") || arg == "-" {
+			return false
+		}
+		switch arg {
+		case "--version", "-version":
+			fmt.Println(String(tool))
+			return true
+		case "--check-update", "-check-update":
+			fmt.Println(String(tool))
+			if err := PrintUpdateCheck(tool); err != nil {
+				fmt.Fprintf(os.Stderr, "Update check failed: %v\n", err)
+				os.Exit(1)
+			}
+			return true
+		}
+	}
+	return false
+}
+
+// Latest fetches the newest GitHub release of tool. The kreftus repository
+// releases every tool, each tagged with its name (e.g. "bmon-1.6"), so the
+// release list is searched for the highest version with that prefix.
+func Latest(tool string) (*Release, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	req, err := http.NewRequest("GET", releasesURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach GitHub: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub returned status %s", resp.Status)
+	}
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, fmt.Errorf("failed to decode release info: %w", err)
+	}
+	var latest *Release
+	for i, rel := range releases {
+		if rel.Draft || rel.Prerelease || !strings.HasPrefix(rel.TagName, tool+"-") {
+			continue
+		}
+		if latest == nil || Newer(tagVersion(tool, rel.TagName), tagVersion(tool, latest.TagName)) {
+			latest = &releases[i]
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("no %s release found", tool)
+	}
+	return latest, nil
+}
+
+// tagVersion returns the version in a release tag, e.g. "1.6" for "bmon-v1.6".
+func tagVersion(tool, tag string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(tag, tool+"-"), "v")
+}
+
+// PrintUpdateCheck compares the running version against the latest release
+// of tool and prints upgrade instructions when a newer one exists.
+func PrintUpdateCheck(tool string) error {
+	rel, err := Latest(tool)
+	if err != nil {
+		return err
+	}
+	if Version == "" || Newer(tagVersion(tool, rel.TagName), Version) {
+		fmt.Printf("A newer release is available: %s\n", rel.TagName)
+		url := rel.HTMLURL
+		if url == "" {
+			url = projectURL + "/releases"
+		}
+		fmt.Printf("Download it from %s\n", url)
+		if exe, err := os.Executable(); err == nil {
+			fmt.Printf("Then replace %s with the new %s binary.\n", exe, filepath.Base(exe))
+		}
+		return nil
+	}
+	fmt.Println("You are running the latest version.")
+	return nil
+}
+
+// Newer reports whether dotted version a is greater than b (e.g. "1.10" > "1.9").
+// Non-numeric components compare as zero.
+func Newer(a, b string) bool {
+	pa := strings.Split(a, ".")
+	pb := strings.Split(b, ".")
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var na, nb int
+		if i < len(pa) {
+			na, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			nb, _ = strconv.Atoi(pb[i])
+		}
+		if na != nb {
+			return na > nb
+		}
+	}
+	return false
+}
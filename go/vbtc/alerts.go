@@ -0,0 +1,344 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"kreftus/shared/notify"
+)
+
+// Price alerts live in the [Alerts] section of vbtc.ini, one key per alert:
+//
+//	[Alerts]
+//	NextID = 3
+//	Beep   = true
+//	1      = Above,100000.00,Armed
+//	2      = Below,90000.00,Fired
+//
+// An alert fires once when a refresh or trade sees the BTC rate cross its
+// price, then stays Fired until the rate moves back across and re-arms it.
+const alertsSection = "Alerts"
+
+type priceAlert struct {
+	ID    int
+	Side  string // "Above" or "Below"
+	Price float64
+	Armed bool
+}
+
+func loadAlerts(f *ini.File) []priceAlert {
+	var alerts []priceAlert
+	if f == nil || !f.HasSection(alertsSection) {
+		return alerts
+	}
+	for _, key := range f.Section(alertsSection).Keys() {
+		id, err := strconv.Atoi(key.Name())
+		if err != nil {
+			continue // NextID, Beep and anything unrecognized
+		}
+		fields := strings.Split(key.String(), ",")
+		if len(fields) < 2 || (fields[0] != "Above" && fields[0] != "Below") {
+			slog.Warn("ignoring malformed alert", "id", key.Name(), "value", key.String())
+			continue
+		}
+		price, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil || price <= 0 {
+			slog.Warn("ignoring malformed alert", "id", key.Name(), "value", key.String())
+			continue
+		}
+		alerts = append(alerts, priceAlert{ID: id, Side: fields[0], Price: price, Armed: len(fields) < 3 || fields[2] != "Fired"})
+	}
+	sort.Slice(alerts, func(i, j int) bool { return alerts[i].ID < alerts[j].ID })
+	return alerts
+}
+
+func (a priceAlert) iniValue() string {
+	state := "Armed"
+	if !a.Armed {
+		state = "Fired"
+	}
+	return fmt.Sprintf("%s,%.2f,%s", a.Side, a.Price, state)
+}
+
+// describe returns e.g. "BTC above $100,000.00".
+func (a priceAlert) describe() string {
+	return fmt.Sprintf("BTC %s %s", strings.ToLower(a.Side), formatMoney(a.Price))
+}
+
+// reached reports whether rate is on the alert's side of its price.
+func (a priceAlert) reached(rate float64) bool {
+	if a.Side == "Above" {
+		return rate > a.Price
+	}
+	return rate < a.Price
+}
+
+// saveAlert adds or replaces an alert in f. New alerts (ID 0) get the next ID.
+func saveAlert(f *ini.File, alert priceAlert) priceAlert {
+	section := f.Section(alertsSection)
+	if alert.ID == 0 {
+		nextID, _ := section.Key("NextID").Int()
+		if nextID < 1 {
+			nextID = 1
+		}
+		for _, existing := range loadAlerts(f) {
+			if existing.ID >= nextID {
+				nextID = existing.ID + 1
+			}
+		}
+		alert.ID = nextID
+		section.Key("NextID").SetValue(strconv.Itoa(nextID + 1))
+	}
+	section.Key(strconv.Itoa(alert.ID)).SetValue(alert.iniValue())
+	return alert
+}
+
+func alertBeepEnabled(f *ini.File) bool {
+	if f == nil || !f.HasSection(alertsSection) || !f.Section(alertsSection).HasKey("Beep") {
+		return true
+	}
+	return f.Section(alertsSection).Key("Beep").MustBool(true)
+}
+
+// checkPriceAlerts shows a banner for each armed alert the current rate has
+// crossed, and re-arms fired alerts the rate has moved back across.
+func checkPriceAlerts(reader *bufio.Reader) {
+	if apiData == nil || apiData.Rate <= 0 || apiData.ApiError != "" {
+		return
+	}
+	unlock, err := lockPortfolio()
+	if err != nil {
+		slog.Warn("alert check skipped", "err", err)
+		return
+	}
+	defer unlock()
+	alertCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		slog.Error("alert check: could not read portfolio", "path", iniFilePath, "err", err)
+		return
+	}
+	rate := apiData.Rate
+	var fired []priceAlert
+	changed := false
+	for _, alert := range loadAlerts(alertCfg) {
+		reached := alert.reached(rate)
+		if reached == alert.Armed {
+			alert.Armed = !reached
+			saveAlert(alertCfg, alert)
+			changed = true
+			if reached {
+				fired = append(fired, alert)
+			}
+		}
+	}
+	if !changed {
+		return
+	}
+	if err := writeIni(alertCfg); err != nil {
+		slog.Error("alert state save failed", "path", iniFilePath, "err", err)
+	} else {
+		cfg = alertCfg
+	}
+	unlock()
+	if len(fired) == 0 {
+		return
+	}
+
+	fmt.Println()
+	for _, alert := range fired {
+		slog.Info("price alert fired", "id", alert.ID, "alert", alert.describe(), "rate", rate)
+		banner := color.New(color.BgGreen, color.FgBlack, color.Bold)
+		if alert.Side == "Below" {
+			banner = color.New(color.BgRed, color.FgWhite, color.Bold)
+		}
+		banner.Printf(" *** ALERT #%d: %s - now %s *** ", alert.ID, alert.describe(), formatMoney(rate))
+		fmt.Println()
+		title, message := fmt.Sprintf("Alert #%d", alert.ID), fmt.Sprintf("%s - now %s", alert.describe(), formatMoney(rate))
+		sendWebhook(title, message)
+		notifyAlert(title, message)
+	}
+	if fired[0].Side == "Above" {
+		playSound(soundAlertUp)
+	} else {
+		playSound(soundAlertDown)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// notifyAlert sends a fired alert through the desktop and webhook channels
+// set in the shared notify.ini. The bell is left to playSound, which plays
+// vbtc's own alert tones under the Beep setting, and a notify.ini webhook
+// that is also vbtc's own is not posted twice.
+func notifyAlert(title, message string) {
+	n := notify.Load("vbtc")
+	n.Bell = false
+	if n.Webhook == webhookURL() {
+		n.Webhook = ""
+	}
+	if err := n.Send(title, message); err != nil {
+		slog.Warn("alert notification failed", "title", title, "err", err)
+	}
+}
+
+// showAlertsScreen lists price alerts and handles the alert subcommands:
+//
+//	alert                       list alerts
+//	alert > 100000              alert when BTC rises above 100000
+//	alert < 90000               alert when BTC falls below 90000
+//	alert remove <id|all>
+//	alert beep on|off
+func showAlertsScreen(reader *bufio.Reader, args []string) {
+	for {
+		clearScreen()
+		color.Yellow("*** Price Alerts ***")
+		if apiData != nil && apiData.Rate > 0 {
+			writeAlignedLine(currencyLabel("Bitcoin"), formatMoney(apiData.Rate), color.New(color.FgWhite))
+		}
+		beep := "On"
+		if !alertBeepEnabled(cfg) {
+			beep = "Off"
+		}
+		writeAlignedLine("Beep:", beep, color.New(color.FgWhite))
+		fmt.Println()
+
+		alerts := loadAlerts(cfg)
+		if len(alerts) == 0 {
+			fmt.Println("No price alerts.")
+		} else {
+			header := fmt.Sprintf("%-4s  %-5s  %14s  %-6s", "ID", "When", "Price", "State")
+			fmt.Println(header)
+			fmt.Println(strings.Repeat("-", len(header)))
+			for _, alert := range alerts {
+				rowColor := colorPositive
+				if alert.Side == "Below" {
+					rowColor = colorNegative
+				}
+				state := "Armed"
+				if !alert.Armed {
+					state = "Fired"
+					rowColor = color.New(color.FgHiBlack)
+				}
+				rowColor.Printf("%-4d  %-5s  %14s  %-6s\n", alert.ID, alert.Side, formatMoney(alert.Price), state)
+			}
+		}
+		fmt.Println()
+
+		if len(args) == 0 {
+			color.New(color.FgHiBlack).Println("> <price> | < <price> | remove <id|all> | beep on|off")
+			fmt.Print("Alert command (Enter to return): ")
+			input, _ := reader.ReadString('\n')
+			args = strings.Fields(strings.TrimSpace(input))
+			if len(args) == 0 {
+				return
+			}
+		}
+
+		message, ok := runAlertCommand(args)
+		if ok {
+			color.Green(message)
+		} else {
+			color.Red(message)
+		}
+		args = nil
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}
+}
+
+// runAlertCommand applies one alert subcommand to vbtc.ini and returns a
+// message for the user and whether it succeeded.
+func runAlertCommand(args []string) (string, bool) {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err.Error(), false
+	}
+	defer unlock()
+	alertCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		return fmt.Sprintf("Could not read portfolio file '%s': %v", iniFilePath, err), false
+	}
+
+	var message string
+	switch strings.ToLower(args[0]) {
+	case "remove", "r", "delete", "d", "cancel", "c":
+		if len(args) < 2 {
+			return "Usage: remove <id|all>", false
+		}
+		if strings.ToLower(args[1]) == "all" {
+			for _, alert := range loadAlerts(alertCfg) {
+				alertCfg.Section(alertsSection).DeleteKey(strconv.Itoa(alert.ID))
+			}
+			message = "All price alerts removed."
+		} else {
+			id, err := strconv.Atoi(strings.TrimPrefix(args[1], "#"))
+			if err != nil || !alertCfg.Section(alertsSection).HasKey(strconv.Itoa(id)) {
+				return fmt.Sprintf("No price alert #%s.", args[1]), false
+			}
+			alertCfg.Section(alertsSection).DeleteKey(strconv.Itoa(id))
+			message = fmt.Sprintf("Alert #%d removed.", id)
+		}
+		slog.Info("price alert removed", "arg", args[1])
+	case "beep", "b":
+		if len(args) < 2 || (strings.ToLower(args[1]) != "on" && strings.ToLower(args[1]) != "off") {
+			return "Usage: beep on|off", false
+		}
+		on := strings.ToLower(args[1]) == "on"
+		alertCfg.Section(alertsSection).Key("Beep").SetValue(strconv.FormatBool(on))
+		message = "Alert beep turned " + strings.ToLower(args[1]) + "."
+	default:
+		alert, errMsg := parseAlert(strings.Join(args, ""))
+		if errMsg != "" {
+			return errMsg, false
+		}
+		// An alert set on the far side of the current price waits for the next crossing.
+		if apiData != nil && apiData.Rate > 0 && alert.reached(apiData.Rate) {
+			alert.Armed = false
+		}
+		alert = saveAlert(alertCfg, alert)
+		message = fmt.Sprintf("Alert #%d set: %s", alert.ID, alert.describe())
+		if !alert.Armed {
+			message += " (already there; fires on the next crossing)"
+		}
+		slog.Info("price alert set", "id", alert.ID, "alert", alert.describe())
+	}
+
+	if err := writeIni(alertCfg); err != nil {
+		slog.Error("alert save failed", "path", iniFilePath, "err", err)
+		return fmt.Sprintf("Could not save vbtc.ini: %v", err), false
+	}
+	cfg = alertCfg
+	return message, true
+}
+
+// parseAlert reads ">100000", "<90,000", "above 100000" or "btc below $90000"
+// (with the words run together, as runAlertCommand joins its arguments).
+func parseAlert(input string) (priceAlert, string) {
+	input = strings.TrimPrefix(strings.ToLower(input), "btc")
+	var alert priceAlert
+	switch {
+	case strings.HasPrefix(input, ">"):
+		alert.Side, input = "Above", strings.TrimLeft(input, ">=")
+	case strings.HasPrefix(input, "<"):
+		alert.Side, input = "Below", strings.TrimLeft(input, "<=")
+	case strings.HasPrefix(input, "above"):
+		alert.Side, input = "Above", strings.TrimPrefix(input, "above")
+	case strings.HasPrefix(input, "below"):
+		alert.Side, input = "Below", strings.TrimPrefix(input, "below")
+	default:
+		return priceAlert{}, "Usage: alert > <price> or alert < <price>"
+	}
+	price, err := strconv.ParseFloat(strings.TrimPrefix(strings.ReplaceAll(input, ",", ""), "$"), 64)
+	if err != nil || price <= 0 {
+		return priceAlert{}, "Invalid alert price."
+	}
+	alert.Price = price
+	alert.Armed = true
+	return alert, ""
+}
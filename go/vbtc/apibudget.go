@@ -0,0 +1,148 @@
+package vbtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// LiveCoinWatch's free tier allows a fixed number of requests a day (UTC).
+// Each request is counted per endpoint in vbtc.apiusage.json next to
+// vbtc.ini, shared by every session, and the main screen shows what is left
+// of [Settings] ApiDailyLimit. Near the limit, the 24h history is refreshed
+// less often; once it is used up, the history is not refreshed at all, so
+// the remaining requests go to prices.
+const (
+	defaultApiDailyLimit = 10000
+	apiBudgetLowPercent  = 90 // history refreshes slow down from here
+	lowBudgetHistoryAge  = time.Hour
+)
+
+type apiUsage struct {
+	Day   string         `json:"day"` // UTC date the counts are for
+	Calls map[string]int `json:"calls"`
+}
+
+func apiUsagePath() string {
+	return filepath.Join(filepath.Dir(iniFilePath), "vbtc.apiusage.json")
+}
+
+func apiDailyLimit() int {
+	if cfg == nil {
+		return defaultApiDailyLimit
+	}
+	return cfg.Section("Settings").Key("ApiDailyLimit").MustInt(defaultApiDailyLimit)
+}
+
+// loadApiUsage returns today's counts, empty when the file is missing,
+// unreadable or from an earlier day.
+func loadApiUsage() apiUsage {
+	today := time.Now().UTC().Format("2006-01-02")
+	usage := apiUsage{Day: today, Calls: map[string]int{}}
+	data, err := os.ReadFile(apiUsagePath())
+	if err != nil {
+		return usage
+	}
+	var saved apiUsage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		slog.Warn("ignoring unreadable API usage file", "path", apiUsagePath(), "err", err)
+		return usage
+	}
+	if saved.Day != today || saved.Calls == nil {
+		return usage
+	}
+	return saved
+}
+
+func (u apiUsage) total() int {
+	total := 0
+	for _, n := range u.Calls {
+		total += n
+	}
+	return total
+}
+
+// recordApiCall counts a LiveCoinWatch request to endpoint. Replays make no
+// requests and are never counted.
+func recordApiCall(endpoint string) {
+	if replaySource != nil || iniFilePath == "" {
+		return
+	}
+	unlock, err := lockPortfolio()
+	if err != nil {
+		slog.Debug("API call not counted", "endpoint", endpoint, "err", err)
+		return
+	}
+	defer unlock()
+	usage := loadApiUsage()
+	usage.Calls[endpoint]++
+	data, err := json.Marshal(usage)
+	if err != nil {
+		return
+	}
+	err = writeFileAtomic(apiUsagePath(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		slog.Warn("API usage write failed", "path", apiUsagePath(), "err", err)
+	}
+}
+
+// apiBudgetLeft returns the requests left today and the daily limit; the
+// limit is 0 when the budget is not tracked.
+func apiBudgetLeft() (left, limit int) {
+	limit = apiDailyLimit()
+	if limit <= 0 {
+		return 0, 0
+	}
+	return max(limit-loadApiUsage().total(), 0), limit
+}
+
+// historyRefreshAllowed reports whether a stale 24h history may be fetched
+// again from provider, given when the current one was fetched. It always is
+// when there is none yet and the budget is not used up.
+func historyRefreshAllowed(provider string, fetched time.Time) bool {
+	left, limit := apiBudgetLeft()
+	if provider != defaultProvider || limit == 0 {
+		return true
+	}
+	if left == 0 {
+		slog.Info("history refresh skipped: API budget used up", "limit", limit)
+		return false
+	}
+	if fetched.IsZero() || left > limit*(100-apiBudgetLowPercent)/100 {
+		return true
+	}
+	if time.Since(fetched) < lowBudgetHistoryAge {
+		slog.Debug("history refresh throttled: API budget low", "left", left, "limit", limit)
+		return false
+	}
+	return true
+}
+
+// showApiBudget prints the requests left today when LiveCoinWatch supplied
+// the data: yellow once history refreshes slow down, red when none are left.
+func showApiBudget() {
+	if replaySource != nil || apiData == nil || (apiData.Provider != "" && apiData.Provider != defaultProvider) {
+		return
+	}
+	left, limit := apiBudgetLeft()
+	if limit == 0 {
+		return
+	}
+	budgetColor := color.New(color.FgWhite)
+	switch {
+	case left == 0:
+		budgetColor = colorNegative
+	case left <= limit*(100-apiBudgetLowPercent)/100:
+		budgetColor = colorTitle
+	}
+	writeAlignedLine("API Budget:", fmt.Sprintf("%s of %s left today", formatFloat(float64(left), 0), formatFloat(float64(limit), 0)), budgetColor)
+}
@@ -0,0 +1,257 @@
+package vbtc
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// "vbtc backtest" runs a moving-average strategy against past prices and
+// reports how it would have done: buy a percentage of cash when the price is
+// below its SMA (by at least BuyBelow percent), sell a percentage of the BTC
+// held when it is above (by at least SellAbove percent). Trades are priced
+// with the configured spread and fees, starting from startingCapital in cash.
+const (
+	maxBacktestDays = 30
+	backtestDateFmt = "2006-01-02 15:04"
+)
+
+type backtestStrategy struct {
+	SmaHours    float64
+	BuyBelow    float64 // percent under the SMA
+	SellAbove   float64 // percent over the SMA
+	BuyPercent  float64 // of cash
+	SellPercent float64 // of BTC held
+	Cooldown    time.Duration
+}
+
+type backtestTrade struct {
+	Time  time.Time
+	Side  string
+	Quote tradeQuote
+	Sma   float64
+}
+
+type backtestResult struct {
+	Trades      []backtestTrade
+	StartValue  float64
+	EndValue    float64
+	HoldValue   float64 // all cash in BTC at the first price
+	Fees        float64
+	MaxDrawdown float64 // percent from the highest value
+	Cash, BTC   float64
+}
+
+func (s backtestStrategy) String() string {
+	return fmt.Sprintf("Buy %g%% of cash below %s; sell %g%% of BTC above %s",
+		s.BuyPercent, smaLevel(s.SmaHours, -s.BuyBelow), s.SellPercent, smaLevel(s.SmaHours, s.SellAbove))
+}
+
+// smaLevel describes a price percent away from the SMA, e.g. "1H SMA +2%".
+func smaLevel(hours, percent float64) string {
+	level := fmt.Sprintf("%gH SMA", hours)
+	if percent != 0 {
+		level += fmt.Sprintf(" %+g%%", percent)
+	}
+	return level
+}
+
+// runBacktest simulates strategy over points, sorted by date.
+func runBacktest(points []HistoryPoint, strategy backtestStrategy) backtestResult {
+	result := backtestResult{StartValue: startingCapital, Cash: startingCapital}
+	if len(points) > 0 && points[0].Rate > 0 {
+		result.HoldValue = startingCapital / points[0].Rate * points[len(points)-1].Rate
+	}
+	window := time.Duration(strategy.SmaHours * float64(time.Hour)).Milliseconds()
+	var sum float64
+	left := 0
+	var lastTrade int64
+	peak := startingCapital
+	for i, p := range points {
+		sum += p.Rate
+		for points[left].Date <= p.Date-window {
+			sum -= points[left].Rate
+			left++
+		}
+		value := result.Cash + result.BTC*p.Rate
+		peak = max(peak, value)
+		if peak > 0 {
+			result.MaxDrawdown = max(result.MaxDrawdown, (peak-value)/peak*100)
+		}
+		// Wait for a full SMA window, and for the cooldown after each trade.
+		if p.Date-points[0].Date < window || (lastTrade > 0 && p.Date-lastTrade < strategy.Cooldown.Milliseconds()) {
+			continue
+		}
+		sma := sum / float64(i-left+1)
+
+		var side string
+		var amount float64
+		switch {
+		case p.Rate < sma*(1-strategy.BuyBelow/100) && result.Cash >= 0.01:
+			side, amount = "Buy", math.Floor(result.Cash*strategy.BuyPercent)/100
+		case p.Rate > sma*(1+strategy.SellAbove/100) && result.BTC > 0:
+			side, amount = "Sell", math.Floor(result.BTC*strategy.SellPercent*1e6)/1e8
+		default:
+			continue
+		}
+		quote, err := quoteTrade(side, amount, p.Rate)
+		if err != nil || quote.Coin <= 0 {
+			continue
+		}
+		if side == "Buy" {
+			result.Cash -= quote.USD
+			result.BTC += quote.Coin
+		} else {
+			result.Cash += quote.USD
+			result.BTC -= quote.Coin
+		}
+		result.Fees += quote.Fee
+		lastTrade = p.Date
+		result.Trades = append(result.Trades, backtestTrade{Time: time.UnixMilli(p.Date), Side: side, Quote: quote, Sma: sma})
+	}
+	if len(points) > 0 {
+		result.EndValue = result.Cash + result.BTC*points[len(points)-1].Rate
+	}
+	return result
+}
+
+// downloadBacktestPrices fetches the last days of BTC prices from the
+// configured provider, one day per request so every provider returns
+// fine-grained points.
+func downloadBacktestPrices(days int) ([]HistoryPoint, error) {
+	provider := newProvider(selectedProviderName(), liveCoinWatchKey, currencyCode())
+	end := time.Now().UTC()
+	var points []HistoryPoint
+	for day := days; day >= 1; day-- {
+		fmt.Fprintf(os.Stderr, "Downloading day %d of %d from %s...\n", days-day+1, days, provider.Name())
+		history, err := provider.History(end.Add(-time.Duration(day)*24*time.Hour), end.Add(-time.Duration(day-1)*24*time.Hour))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range history.History {
+			if len(points) == 0 || p.Date > points[len(points)-1].Date {
+				points = append(points, p)
+			}
+		}
+	}
+	return points, nil
+}
+
+// runBacktestCLI handles "vbtc backtest [options]" and returns the exit code.
+func runBacktestCLI(args []string) int {
+	flags := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	file := flags.String("file", "", "")
+	days := flags.Int("days", 1, "")
+	strategy := backtestStrategy{}
+	flags.Float64Var(&strategy.SmaHours, "sma-hours", 1, "")
+	flags.Float64Var(&strategy.BuyBelow, "buy-below", 0, "")
+	flags.Float64Var(&strategy.SellAbove, "sell-above", 2, "")
+	flags.Float64Var(&strategy.BuyPercent, "buy-percent", 10, "")
+	flags.Float64Var(&strategy.SellPercent, "sell-percent", 10, "")
+	cooldown := flags.Int("cooldown", 60, "")
+	listTrades := flags.Bool("trades", false, "")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		fmt.Fprintln(os.Stderr, "Usage: vbtc backtest [--file <prices>] [--days <n>] [--sma-hours <h>] [--buy-below <%>] [--sell-above <%>]")
+		fmt.Fprintln(os.Stderr, "                     [--buy-percent <%>] [--sell-percent <%>] [--cooldown <minutes>] [--trades]")
+		return 2
+	}
+	switch {
+	case *days < 1 || *days > maxBacktestDays:
+		color.Red("Backtest: --days must be 1-%d.", maxBacktestDays)
+		return 2
+	case strategy.SmaHours <= 0 || strategy.SmaHours > 24:
+		color.Red("Backtest: --sma-hours must be more than 0 and at most 24.")
+		return 2
+	case strategy.BuyPercent <= 0 || strategy.BuyPercent > 100 || strategy.SellPercent <= 0 || strategy.SellPercent > 100:
+		color.Red("Backtest: --buy-percent and --sell-percent must be more than 0 and at most 100.")
+		return 2
+	case strategy.BuyBelow < 0 || strategy.BuyBelow >= 100 || strategy.SellAbove < 0 || *cooldown < 0:
+		color.Red("Backtest: --buy-below must be 0-99; --sell-above and --cooldown cannot be negative.")
+		return 2
+	}
+	strategy.Cooldown = time.Duration(*cooldown) * time.Minute
+
+	// Spread, fees, currency and provider come from vbtc.ini when there is one.
+	var err error
+	if cfg, err = ini.Load(iniFilePath); err != nil {
+		cfg = ini.Empty()
+	}
+	loadLiveCoinWatchKey()
+	var points []HistoryPoint
+	if *file != "" {
+		points, err = loadReplayFile(*file)
+	} else {
+		points, err = downloadBacktestPrices(*days)
+	}
+	if err != nil {
+		color.Red("Backtest: %v", err)
+		return 1
+	}
+	if len(points) < 2 {
+		color.Red("Backtest: fewer than two prices to test against.")
+		return 1
+	}
+
+	result := runBacktest(points, strategy)
+	first, last := time.UnixMilli(points[0].Date), time.UnixMilli(points[len(points)-1].Date)
+	color.Yellow("*** Backtest ***")
+	fmt.Println()
+	writeAlignedLine("Prices:", fmt.Sprintf("%d from %s to %s", len(points), first.Local().Format(backtestDateFmt), last.Local().Format(backtestDateFmt)), color.New(color.FgWhite))
+	writeAlignedLine("Strategy:", strategy.String(), color.New(color.FgWhite))
+	if strategy.Cooldown > 0 {
+		writeAlignedLine("Cooldown:", fmt.Sprintf("%d minutes between trades", *cooldown), color.New(color.FgWhite))
+	}
+	fmt.Println()
+
+	if *listTrades && len(result.Trades) > 0 {
+		colorInfo.Printf("%-16s  %-4s  %14s  %14s  %12s  %14s\n", "Time", "TX", "Rate", currencyCode(), "BTC", "SMA")
+		for _, t := range result.Trades {
+			rowColor := colorPositive
+			if t.Side == "Sell" {
+				rowColor = colorNegative
+			}
+			rowColor.Printf("%-16s  %-4s  %14s  %14s  %12.8f  %14s\n", t.Time.Local().Format(backtestDateFmt), t.Side,
+				formatMoney(t.Quote.Rate), formatMoney(t.Quote.USD), t.Quote.Coin, formatMoney(t.Sma))
+		}
+		fmt.Println()
+	}
+
+	buys := 0
+	for _, t := range result.Trades {
+		if t.Side == "Buy" {
+			buys++
+		}
+	}
+	pnl := result.EndValue - result.StartValue
+	pnlColor := color.New(color.FgWhite)
+	if pnl > 0 {
+		pnlColor = colorPositive
+	} else if pnl < 0 {
+		pnlColor = colorNegative
+	}
+	writeAlignedLine("Trades:", fmt.Sprintf("%d (%d buys, %d sells)", len(result.Trades), buys, len(result.Trades)-buys), color.New(color.FgWhite))
+	if result.Fees > 0 {
+		writeAlignedLine("Fees Paid:", formatMoney(result.Fees), color.New(color.FgWhite))
+	}
+	writeAlignedLine("Start Value:", formatMoney(result.StartValue), color.New(color.FgWhite))
+	writeAlignedLine("End Value:", fmt.Sprintf("%s (%s + %.8f BTC)", formatMoney(result.EndValue), formatMoney(result.Cash), result.BTC), pnlColor)
+	writeAlignedLineWithBrackets("Profit/Loss:", signOf(pnl)+formatMoney(math.Abs(pnl)), fmt.Sprintf("%+.2f%%", pnl/result.StartValue*100), pnlColor, 0)
+	holdColor := color.New(color.FgWhite)
+	if result.HoldValue > result.EndValue {
+		holdColor = colorNegative // the strategy did worse than holding
+	}
+	writeAlignedLineWithBrackets("Buy & Hold:", formatMoney(result.HoldValue), fmt.Sprintf("%+.2f%%", (result.HoldValue-result.StartValue)/result.StartValue*100), holdColor, 0)
+	drawdownColor := color.New(color.FgWhite)
+	if result.MaxDrawdown > 0 {
+		drawdownColor = colorNegative
+	}
+	writeAlignedLine("Max Drawdown:", fmt.Sprintf("%.2f%%", result.MaxDrawdown), drawdownColor)
+	return 0
+}
@@ -9,7 +9,11 @@ Set-Location $PSScriptRoot
 $useUpx = $args -contains '-upx'
 
 # Define the version number in one place for easy updates.
+# It is also stamped into the binary (shown by --version; compared by --check-update).
+# Keep it in step with version.Default in main.go, which a plain go build uses.
 $Version = "1.6"
+$BuildDate = Get-Date -Format "yyyy-MM-dd@HHmm"
+$VersionFlags = "-X kreftus/shared/version.Version=$Version -X kreftus/shared/version.Date=$BuildDate"
 
 # --- Build Helper Tool ---
 # Capture the host's Go environment settings to ensure our helper tool is always
@@ -58,30 +62,31 @@ $rcFilePath = Join-Path $PSScriptRoot 'vbtc.rc'
 
 # Use a try/finally block to ensure the Windows-specific .syso file is always cleaned up.
 try {
-    windres -I $PSScriptRoot -o vbtc.syso $rcFilePath
+    # The .syso goes next to cmd/vbtc, the main package, so the linker picks it up.
+    windres -I $PSScriptRoot -o cmd/vbtc/vbtc.syso $rcFilePath
     Write-Host "  - Compiling executable (x64)..."
     # Explicitly set the OS and Architecture for the Windows build to avoid environment issues.
-    $env:GOOS="windows"; $env:GOARCH="amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o bin/pc/x64/vbtc.exe
+    $env:GOOS="windows"; $env:GOARCH="amd64"; Write-Host "  -> go build (windows/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o bin/pc/x64/vbtc.exe ./cmd/vbtc
     Write-Host "  - Compiling executable (x86)..."
-    $env:GOOS="windows"; $env:GOARCH="386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o bin/pc/x86/vbtc.exe
+    $env:GOOS="windows"; $env:GOARCH="386"; Write-Host "  -> go build (windows/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o bin/pc/x86/vbtc.exe ./cmd/vbtc
 }
 finally {
-    if (Test-Path "vbtc.syso") {
-        Remove-Item vbtc.syso -Force
+    if (Test-Path "cmd/vbtc/vbtc.syso") {
+        Remove-Item cmd/vbtc/vbtc.syso -Force
     }
 }
 
 Write-Host "Building for macOS (Apple Silicon)..." -ForegroundColor Cyan
-$env:GOOS="darwin"; $env:GOARCH="arm64"; Write-Host "  -> go build (darwin/arm64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o bin/mac/arm64/vbtc
+$env:GOOS="darwin"; $env:GOARCH="arm64"; Write-Host "  -> go build (darwin/arm64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o bin/mac/arm64/vbtc ./cmd/vbtc
 
 Write-Host "Building for macOS (Intel)..." -ForegroundColor Cyan
-$env:GOOS="darwin"; $env:GOARCH="amd64"; Write-Host "  -> go build (darwin/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o bin/mac/amd64/vbtc
+$env:GOOS="darwin"; $env:GOARCH="amd64"; Write-Host "  -> go build (darwin/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o bin/mac/amd64/vbtc ./cmd/vbtc
 
 Write-Host "Building for Linux (x64)..." -ForegroundColor Cyan
-$env:GOOS="linux"; $env:GOARCH="amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o bin/linux/amd64/vbtc
+$env:GOOS="linux"; $env:GOARCH="amd64"; Write-Host "  -> go build (linux/amd64) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o bin/linux/amd64/vbtc ./cmd/vbtc
 
 Write-Host "Building for Linux (x86)..." -ForegroundColor Cyan
-$env:GOOS="linux"; $env:GOARCH="386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w" -o bin/linux/x86/vbtc
+$env:GOOS="linux"; $env:GOARCH="386"; Write-Host "  -> go build (linux/386) with -v" -ForegroundColor DarkGray; go build -v -ldflags="-s -w $VersionFlags" -o bin/linux/x86/vbtc ./cmd/vbtc
 
 # --- Optional UPX compression for all binaries (only when -upx is specified) ---
 if ($useUpx) {
@@ -0,0 +1,13 @@
+// Command vbtc is the standalone vBTC trading simulator; the program itself
+// lives in package vbtc so the kreftus launcher can link it in too.
+package main
+
+import (
+	"os"
+
+	"vbtc"
+)
+
+func main() {
+	os.Exit(vbtc.Run(os.Args[1:]))
+}
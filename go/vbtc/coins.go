@@ -0,0 +1,255 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// Coins besides BTC are opt-in: [Settings] Coins lists the symbols to track
+// (e.g. "ETH,LTC"). Each one is held in [Portfolio] as Player<SYM> and
+// PlayerInvested<SYM>; BTC keeps the original PlayerBTC/PlayerInvested keys so
+// existing vbtc.ini files work unchanged.
+
+// trackedCoins returns the extra coins listed in [Settings] Coins, upper-cased,
+// de-duplicated and without BTC.
+func trackedCoins() []string {
+	if cfg == nil {
+		return nil
+	}
+	var coins []string
+	seen := map[string]bool{"BTC": true}
+	for _, c := range strings.Split(cfg.Section("Settings").Key("Coins").String(), ",") {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c == "" || seen[c] {
+			continue
+		}
+		seen[c] = true
+		coins = append(coins, c)
+	}
+	return coins
+}
+
+func isTrackedCoin(coin string) bool {
+	if coin == "BTC" {
+		return true
+	}
+	for _, c := range trackedCoins() {
+		if c == coin {
+			return true
+		}
+	}
+	return false
+}
+
+// isCoinSymbol reports whether s looks like a coin symbol rather than an amount
+// (amounts always contain a digit, e.g. 10, 50p, 100000s).
+func isCoinSymbol(s string) bool {
+	if s == "" || len(s) > 10 {
+		return false
+	}
+	for _, r := range s {
+		if !((r >= 'A' && r <= 'Z') || (r >= 'a' && r <= 'z')) {
+			return false
+		}
+	}
+	return true
+}
+
+func coinBalanceKey(coin string) string {
+	return "Player" + coin
+}
+
+func coinInvestedKey(coin string) string {
+	if coin == "BTC" {
+		return "PlayerInvested"
+	}
+	return "PlayerInvested" + coin
+}
+
+// coinName is used in screen titles ("*** Buy Bitcoin ***", "*** Buy ETH ***").
+func coinName(coin string) string {
+	if coin == "BTC" {
+		return "Bitcoin"
+	}
+	return coin
+}
+
+// coinRate returns the last fetched USD rate for coin, or 0 if unknown.
+func coinRate(coin string) float64 {
+	return marketPrices{apiData}.Rate(coin)
+}
+
+// marketPrices is the engine.PriceSource for a set of market data.
+type marketPrices struct {
+	data *ApiDataResponse
+}
+
+func (p marketPrices) Rate(coin string) float64 {
+	if p.data == nil {
+		return 0
+	}
+	if coin == "BTC" {
+		return p.data.Rate
+	}
+	return p.data.CoinRates[coin]
+}
+
+// parseTradeArgs splits "buy [amount] [coin]" arguments (either order) into a
+// coin symbol, defaulting to BTC, and an amount string.
+func parseTradeArgs(args []string) (coin, amount string, err error) {
+	coin = "BTC"
+	for _, arg := range args {
+		if isCoinSymbol(arg) {
+			coin = strings.ToUpper(arg)
+			continue
+		}
+		if amount != "" {
+			return "", "", fmt.Errorf("unexpected argument '%s'", arg)
+		}
+		amount = arg
+	}
+	if !isTrackedCoin(coin) {
+		return "", "", fmt.Errorf("%s is not tracked; add it under Config > Tracked Coins", coin)
+	}
+	return coin, amount, nil
+}
+
+type coinMapEntry struct {
+	Code string  `json:"code"`
+	Rate float64 `json:"rate"`
+}
+
+// fetchCoinRates returns rates in currency for codes from a single
+// LiveCoinWatch coins/map request.
+func fetchCoinRates(apiKey, currency string, codes []string) (map[string]float64, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("API key is empty")
+	}
+	var entries []coinMapEntry
+	payload := map[string]interface{}{"currency": currency, "codes": codes, "sort": "rank", "order": "ascending", "offset": 0, "limit": 0, "meta": false}
+	if err := postLiveCoinWatch("coins/map", apiKey, payload, &entries, "coin rates"); err != nil {
+		return nil, err
+	}
+	rates := make(map[string]float64, len(entries))
+	for _, e := range entries {
+		if e.Rate > 0 {
+			rates[strings.ToUpper(e.Code)] = e.Rate
+		}
+	}
+	return rates, nil
+}
+
+// updateCoinRates fills newData.CoinRates for the tracked coins from provider,
+// keeping the previous rates for any coin the fetch could not price.
+func updateCoinRates(provider PriceProvider, newData *ApiDataResponse) {
+	coins := trackedCoins()
+	if len(coins) == 0 || newData == nil {
+		return
+	}
+	newData.CoinRates = make(map[string]float64, len(coins))
+	if apiData != nil {
+		for coin, rate := range apiData.CoinRates {
+			newData.CoinRates[coin] = rate
+		}
+	}
+	rates, err := provider.Rates(coins)
+	if err != nil {
+		slog.Warn("coin rates fetch failed", "coins", strings.Join(coins, ","), "err", err)
+		fmt.Printf("Warning: could not fetch rates for %s. Using last known rates.\n", strings.Join(coins, ", "))
+		return
+	}
+	for coin, rate := range rates {
+		newData.CoinRates[coin] = rate
+	}
+}
+
+// showHeldCoins prints one Portfolio line per tracked coin with a balance.
+func showHeldCoins() {
+	for _, coin := range trackedCoins() {
+		balance, _ := cfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+		if balance <= 0 {
+			continue
+		}
+		valueDisplay := ""
+		if rate := coinRate(coin); rate > 0 {
+			valueDisplay = fmt.Sprintf(" (%s)", formatMoney(balance*rate))
+		}
+		writeAlignedLine(coin+":", fmt.Sprintf("%.8f%s", balance, valueDisplay), color.New(color.FgWhite))
+	}
+}
+
+// invokeTrackedCoinsConfig edits [Settings] Coins. A coin with a balance
+// cannot be removed, so its holdings never drop out of the portfolio value.
+func invokeTrackedCoinsConfig(reader *bufio.Reader) {
+	current := trackedCoins()
+	display := strings.Join(current, ",")
+	if display == "" {
+		display = "(BTC only)"
+	}
+	colorInfo.Printf("Tracked coins: %s\n", display)
+	fmt.Print("Enter coin symbols to track besides BTC, comma separated (e.g. ETH,LTC), or '-' for none: ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	var coins []string
+	seen := map[string]bool{"BTC": true}
+	if input != "-" {
+		for _, c := range strings.Split(input, ",") {
+			c = strings.ToUpper(strings.TrimSpace(c))
+			if c == "" || seen[c] {
+				continue
+			}
+			if !isCoinSymbol(c) {
+				color.Red("Invalid coin symbol '%s'. No changes made.", c)
+				fmt.Println("Press Enter to continue.")
+				reader.ReadString('\n')
+				return
+			}
+			seen[c] = true
+			coins = append(coins, c)
+		}
+	}
+	for _, c := range current {
+		if seen[c] {
+			continue
+		}
+		if balance, _ := cfg.Section("Portfolio").Key(coinBalanceKey(c)).Float64(); balance > 0 {
+			coins = append(coins, c)
+			color.Yellow("%s is still held (%.8f) and stays tracked until sold.", c, balance)
+		}
+	}
+	sort.Strings(coins)
+
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("Coins").SetValue(strings.Join(coins, ","))
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		apiData = updateApiData(true)
+		var unknown []string
+		for _, c := range coins {
+			if coinRate(c) <= 0 {
+				unknown = append(unknown, c)
+			}
+		}
+		if len(unknown) > 0 {
+			color.Yellow("No price found for %s; check the symbol.", strings.Join(unknown, ", "))
+		}
+		color.Green("Tracked coins updated.")
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
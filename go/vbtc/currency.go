@@ -0,0 +1,202 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// The portfolio is kept in one base currency, [Portfolio] Currency (USD by
+// default). Prices are requested from the providers in that currency and every
+// cash amount in vbtc.ini and ledger.csv is in it, so the USD column and
+// PlayerUSD key keep their names whatever the currency is.
+const defaultCurrency = "USD"
+
+type currencyInfo struct {
+	Code     string
+	Symbol   string
+	Decimals int // digits shown after the decimal point
+}
+
+var currencies = []currencyInfo{
+	{"USD", "$", 2},
+	{"EUR", "€", 2},
+	{"GBP", "£", 2},
+	{"JPY", "¥", 0},
+	{"CAD", "C$", 2},
+	{"AUD", "A$", 2},
+	{"CHF", "CHF ", 2},
+}
+
+func lookupCurrency(code string) (currencyInfo, bool) {
+	for _, c := range currencies {
+		if strings.EqualFold(c.Code, strings.TrimSpace(code)) {
+			return c, true
+		}
+	}
+	return currencyInfo{}, false
+}
+
+// baseCurrency returns the configured currency, or USD if it is unset or unknown.
+func baseCurrency() currencyInfo {
+	if cfg != nil {
+		if c, ok := lookupCurrency(cfg.Section("Portfolio").Key("Currency").String()); ok {
+			return c
+		}
+	}
+	c, _ := lookupCurrency(defaultCurrency)
+	return c
+}
+
+// currencyCode is the base currency's ISO code, e.g. "EUR".
+func currencyCode() string {
+	return baseCurrency().Code
+}
+
+// formatMoney formats amount in the base currency, e.g. "$1,234.56" or "¥123,457".
+func formatMoney(amount float64) string {
+	c := baseCurrency()
+	return c.Symbol + formatFloat(amount, c.Decimals)
+}
+
+// formatMoneyWhole formats amount in the base currency without decimals, for volumes.
+func formatMoneyWhole(amount float64) string {
+	return baseCurrency().Symbol + formatFloat(amount, 0)
+}
+
+// currencyLabel appends the base currency to a screen label, e.g. "Bitcoin (EUR):".
+func currencyLabel(name string) string {
+	return fmt.Sprintf("%s (%s):", name, currencyCode())
+}
+
+// exchangeRate returns how many units of to one unit of from is worth, from
+// the BTC price in both currencies.
+func exchangeRate(from, to string) (float64, error) {
+	apiKey := liveCoinWatchKey
+	var lastErr error
+	for _, name := range providerNames {
+		if name == defaultProvider && apiKey == "" {
+			continue
+		}
+		fromData, err := newProvider(name, apiKey, from).Current()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		toData, err := newProvider(name, apiKey, to).Current()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if fromData.Rate > 0 && toData.Rate > 0 {
+			return toData.Rate / fromData.Rate, nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no provider returned a price")
+	}
+	return 0, lastErr
+}
+
+// invokeCurrencyConfig edits [Portfolio] Currency. Cash and invested amounts
+// are converted at the current exchange rate; limit orders, stop-loss and
+// take-profit prices and alerts are removed because their prices were set in
+// the old currency.
+func invokeCurrencyConfig(reader *bufio.Reader) {
+	current := currencyCode()
+	colorInfo.Printf("Base currency: %s\n", current)
+	if replaySource != nil {
+		color.Yellow("Replay and demo prices have no currency; the currency cannot be changed in these modes.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	for i, c := range currencies {
+		fmt.Printf("  %d. %s (%s)\n", i+1, c.Code, strings.TrimSpace(c.Symbol))
+	}
+	fmt.Printf("Choose a currency (1-%d), or press Enter to keep %s: ", len(currencies), current)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(currencies) {
+		color.Red("Invalid choice. No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	code := currencies[choice-1].Code
+	if code == current {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	fmt.Printf("Fetching the %s/%s exchange rate...\n", current, code)
+	rate, err := exchangeRate(current, code)
+	if err != nil {
+		slog.Error("exchange rate fetch failed", "from", current, "to", code, "err", err)
+		color.Red("Could not get the exchange rate: %v", err)
+		color.Red("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	fmt.Printf("1 %s = %s %s\n", current, strconv.FormatFloat(rate, 'f', -1, 64), code)
+	color.Yellow("Cash and invested amounts will be converted. Limit orders, stop-loss/take-profit prices and alerts will be removed.")
+	color.Yellow("Ledger rows keep the currency they were traded in; archive the ledger first to keep its statistics in one currency.")
+	fmt.Printf("Switch to %s? (y/n): ", code)
+	confirm, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	err = updateIni(func(f *ini.File) {
+		portfolio := f.Section("Portfolio")
+		keys := []string{"PlayerUSD", "PlayerInvested"}
+		for _, coin := range trackedCoins() {
+			keys = append(keys, coinInvestedKey(coin))
+		}
+		for _, key := range keys {
+			if !portfolio.HasKey(key) {
+				continue
+			}
+			value, _ := portfolio.Key(key).Float64()
+			portfolio.Key(key).SetValue(fmt.Sprintf("%.2f", value*rate))
+		}
+		portfolio.Key("Currency").SetValue(code)
+		f.DeleteSection(ordersSection)
+		f.DeleteSection(triggersSection)
+		for _, key := range f.Section(alertsSection).Keys() {
+			if _, err := strconv.Atoi(key.Name()); err == nil {
+				f.Section(alertsSection).DeleteKey(key.Name()) // keep NextID and Beep
+			}
+		}
+		f.DeleteSection(undoSection)
+	})
+	if err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("base currency changed", "from", current, "to", code, "rate", rate)
+		priceWatch.configure()
+		apiData = updateApiData(false) // the 24h history is in the old currency too
+		color.Green("Base currency set to %s.", code)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,46 @@
+package vbtc
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Demo mode ("vbtc --demo") lets new users try the simulator before getting a
+// LiveCoinWatch key. It runs as a replay of a generated random walk that moves
+// in real time, starting from today's date with two days of made-up history so
+// the 24h stats and charts have something to show. Like a replay it keeps its
+// own portfolio and ledger, in demoDir.
+const (
+	demoDir        = "demo"
+	demoStartPrice = 60000.0
+	demoStep       = 5 * time.Minute
+	demoHistory    = 48 * time.Hour
+	demoLength     = 30 * 24 * time.Hour // after which the last price holds
+	demoVolatility = 0.002               // standard deviation of each step's return
+)
+
+// parseDemoArgs reports whether --demo was given.
+func parseDemoArgs(args []string) bool {
+	for _, arg := range args {
+		if arg == "-demo" || arg == "--demo" {
+			return true
+		}
+	}
+	return false
+}
+
+// newDemoProvider returns a replay of a random walk around demoStartPrice that
+// reaches the present at now and continues for demoLength.
+func newDemoProvider(now time.Time, seed int64) *replayProvider {
+	rng := rand.New(rand.NewSource(seed))
+	first := now.Add(-demoHistory).Truncate(demoStep)
+	steps := int((demoHistory + demoLength) / demoStep)
+	points := make([]HistoryPoint, 0, steps+1)
+	rate := demoStartPrice
+	for i := 0; i <= steps; i++ {
+		points = append(points, HistoryPoint{Date: first.Add(time.Duration(i) * demoStep).UnixMilli(), Rate: math.Round(rate*100) / 100})
+		rate *= math.Exp(rng.NormFloat64() * demoVolatility)
+	}
+	return &replayProvider{points: points, speed: 1, start: now.UTC(), startReal: now, demo: true}
+}
@@ -0,0 +1,207 @@
+package vbtc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// exportRow is a ledger entry with its cost basis from replayLedger. For a
+// buy CostBasis is the cash spent; for a sell it is the cost of the coin sold
+// and RealizedGain is the proceeds minus that cost.
+type exportRow struct {
+	LedgerEntry
+	CostBasis    float64
+	RealizedGain float64
+}
+
+const exportDateLayout = "2006-01-02"
+
+// runExportCommand handles "export json|ofx|qif [from] [to]" with dates as YYYY-MM-DD.
+func runExportCommand(reader *bufio.Reader, args []string) {
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+	if len(args) == 0 || len(args) > 3 {
+		color.Red("Usage: export json|ofx|qif [from YYYY-MM-DD] [to YYYY-MM-DD]")
+		return
+	}
+	format := strings.ToLower(args[0])
+	if format != "json" && format != "ofx" && format != "qif" {
+		color.Red("Unknown export format '%s'. Use json, ofx or qif.", args[0])
+		return
+	}
+	var from, to time.Time
+	var err error
+	if len(args) > 1 {
+		if from, err = time.ParseInLocation(exportDateLayout, args[1], time.Local); err != nil {
+			color.Red("Invalid start date '%s'. Use YYYY-MM-DD.", args[1])
+			return
+		}
+	}
+	if len(args) > 2 {
+		if to, err = time.ParseInLocation(exportDateLayout, args[2], time.Local); err != nil {
+			color.Red("Invalid end date '%s'. Use YYYY-MM-DD.", args[2])
+			return
+		}
+		to = to.AddDate(0, 0, 1) // inclusive of the whole end day
+		if !to.After(from) {
+			color.Red("The end date is before the start date.")
+			return
+		}
+	}
+
+	// Replay the full history so rows inside the range carry the cost of earlier purchases.
+	entries, err := readAllLedgerEntries()
+	if err != nil {
+		color.Red("Error reading ledger: %v", err)
+		return
+	}
+	allRows, _ := replayLedger(entries, loadCostBasisMethod())
+	var rows []exportRow
+	for _, row := range allRows {
+		if isRevertedTX(row.TX) || row.DateTime.IsZero() || (!from.IsZero() && row.DateTime.Before(from)) || (!to.IsZero() && !row.DateTime.Before(to)) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		color.Yellow("No ledger transactions in that range.")
+		return
+	}
+
+	var data []byte
+	switch format {
+	case "json":
+		data, err = exportJSON(rows)
+	case "ofx":
+		data = exportOFX(rows)
+	case "qif":
+		data = exportQIF(rows)
+	}
+	if err != nil {
+		color.Red("Export failed: %v", err)
+		return
+	}
+
+	ledgerAbs, _ := filepath.Abs(ledgerFilePath)
+	exportPath := filepath.Join(filepath.Dir(ledgerAbs), fmt.Sprintf("vBTC - Export_%s.%s", time.Now().Format("010206@150405"), format))
+	if err := os.WriteFile(exportPath, data, 0644); err != nil {
+		slog.Error("ledger export failed", "path", exportPath, "err", err)
+		color.Red("Could not write export file: %v", err)
+		return
+	}
+	slog.Info("ledger exported", "path", exportPath, "format", format, "rows", len(rows))
+	color.Green("Exported %d transactions to %s", len(rows), exportPath)
+}
+
+type jsonExportRow struct {
+	Type         string  `json:"type"`
+	Side         string  `json:"side"`
+	Coin         string  `json:"coin"`
+	Time         string  `json:"time"`
+	USD          float64 `json:"usd"`
+	Amount       float64 `json:"amount"`
+	Price        float64 `json:"price"`
+	Fee          float64 `json:"fee"`
+	Balance      float64 `json:"balance"`
+	CostBasis    float64 `json:"costBasis"`
+	RealizedGain float64 `json:"realizedGain"`
+	Note         string  `json:"note,omitempty"`
+	Tags         string  `json:"tags,omitempty"`
+}
+
+func exportJSON(rows []exportRow) ([]byte, error) {
+	out := make([]jsonExportRow, 0, len(rows))
+	for _, row := range rows {
+		out = append(out, jsonExportRow{
+			Type:         row.TX,
+			Side:         ledgerSide(row.TX),
+			Coin:         row.Coin,
+			Time:         row.DateTime.Format(time.RFC3339),
+			USD:          row.USD,
+			Amount:       row.BTC,
+			Price:        row.BTCPrice,
+			Fee:          row.Fee,
+			Balance:      row.UserBTC,
+			CostBasis:    row.CostBasis,
+			RealizedGain: math.Round(row.RealizedGain*100) / 100,
+			Note:         row.Note,
+			Tags:         row.Tags,
+		})
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// exportOFX writes an OFX 2 investment statement with one BUYOTHER/SELLOTHER
+// per row. Cost basis and realized gain go in the memo, which OFX has no
+// field for.
+func exportOFX(rows []exportRow) []byte {
+	const ofxTime = "20060102150405"
+	var b strings.Builder
+	b.WriteString("<?xml version=\"1.0\" encoding=\"UTF-8\" standalone=\"no\"?>\n")
+	b.WriteString("<?OFX OFXHEADER=\"200\" VERSION=\"211\" SECURITY=\"NONE\" OLDFILEUID=\"NONE\" NEWFILEUID=\"NONE\"?>\n")
+	b.WriteString("<OFX>\n<INVSTMTMSGSRSV1>\n<INVSTMTTRNRS>\n<TRNUID>0</TRNUID>\n")
+	b.WriteString("<STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>\n")
+	fmt.Fprintf(&b, "<INVSTMTRS>\n<DTASOF>%s</DTASOF>\n<CURDEF>%s</CURDEF>\n", time.Now().UTC().Format(ofxTime), currencyCode())
+	b.WriteString("<INVACCTFROM><BROKERID>vbtc</BROKERID><ACCTID>vbtc</ACCTID></INVACCTFROM>\n")
+	fmt.Fprintf(&b, "<INVTRANLIST>\n<DTSTART>%s</DTSTART>\n<DTEND>%s</DTEND>\n", rows[0].DateTime.Format(ofxTime), rows[len(rows)-1].DateTime.Format(ofxTime))
+
+	coins := make(map[string]bool)
+	for i, row := range rows {
+		coins[row.Coin] = true
+		tag, inner, total := "BUYOTHER", "INVBUY", -row.USD
+		memo := fmt.Sprintf("%s; cost basis %.2f", row.TX, row.CostBasis)
+		if ledgerSide(row.TX) == "Sell" {
+			tag, inner, total = "SELLOTHER", "INVSELL", row.USD
+			memo += fmt.Sprintf("; realized gain %.2f", row.RealizedGain)
+		}
+		fmt.Fprintf(&b, "<%s>\n<%s>\n<INVTRAN><FITID>%s-%d</FITID><DTTRADE>%s</DTTRADE><MEMO>%s</MEMO></INVTRAN>\n",
+			tag, inner, row.DateTime.Format(ofxTime), i+1, row.DateTime.Format(ofxTime), memo)
+		fmt.Fprintf(&b, "<SECID><UNIQUEID>%s</UNIQUEID><UNIQUEIDTYPE>TICKER</UNIQUEIDTYPE></SECID>\n", row.Coin)
+		fmt.Fprintf(&b, "<UNITS>%.8f</UNITS>\n<UNITPRICE>%.2f</UNITPRICE>\n<FEES>%.2f</FEES>\n<TOTAL>%.2f</TOTAL>\n", row.BTC, row.BTCPrice, row.Fee, total)
+		fmt.Fprintf(&b, "<SUBACCTSEC>CASH</SUBACCTSEC>\n<SUBACCTFUND>CASH</SUBACCTFUND>\n</%s>\n</%s>\n", inner, tag)
+	}
+	b.WriteString("</INVTRANLIST>\n</INVSTMTRS>\n</INVSTMTTRNRS>\n</INVSTMTMSGSRSV1>\n")
+
+	b.WriteString("<SECLISTMSGSRSV1>\n<SECLIST>\n")
+	for coin := range coins {
+		fmt.Fprintf(&b, "<OTHERINFO><SECINFO><SECID><UNIQUEID>%s</UNIQUEID><UNIQUEIDTYPE>TICKER</UNIQUEIDTYPE></SECID><SECNAME>%s</SECNAME><TICKER>%s</TICKER></SECINFO></OTHERINFO>\n", coin, coinName(coin), coin)
+	}
+	b.WriteString("</SECLIST>\n</SECLISTMSGSRSV1>\n</OFX>\n")
+	return []byte(b.String())
+}
+
+// exportQIF writes a Quicken investment (!Type:Invst) file.
+func exportQIF(rows []exportRow) []byte {
+	var b strings.Builder
+	b.WriteString("!Type:Invst\n")
+	for _, row := range rows {
+		action := "Buy"
+		memo := fmt.Sprintf("%s; cost basis %.2f", row.TX, row.CostBasis)
+		if ledgerSide(row.TX) == "Sell" {
+			action = "Sell"
+			memo += fmt.Sprintf("; realized gain %.2f", row.RealizedGain)
+		}
+		if note := row.NoteText(); note != "" {
+			memo += "; " + note
+		}
+		local := row.DateTime.Local()
+		fmt.Fprintf(&b, "D%s\n", local.Format("01/02'2006"))
+		fmt.Fprintf(&b, "N%s\nY%s\nI%.2f\nQ%.8f\nT%.2f\n", action, row.Coin, row.BTCPrice, row.BTC, row.USD)
+		if row.Fee > 0 {
+			fmt.Fprintf(&b, "O%.2f\n", row.Fee)
+		}
+		fmt.Fprintf(&b, "M%s\n^\n", memo)
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,126 @@
+package vbtc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"vbtc/internal/engine"
+)
+
+// Trading fees are set in [Settings] as FeePercent (percent of the trade's USD
+// value) and FeeFlat (USD per trade); either may be 0. Fees always come out
+// of the USD side: a buy of $100 with a $1 fee buys $99 of coin, a sell worth
+// $100 with a $1 fee credits $99.
+
+// tradeQuote is the result of executing a trade of a given size at a given rate.
+type tradeQuote = engine.Quote
+
+func loadFeeModel() (percent, flat float64) {
+	if cfg == nil {
+		return 0, 0
+	}
+	percent, _ = cfg.Section("Settings").Key("FeePercent").Float64()
+	flat, _ = cfg.Section("Settings").Key("FeeFlat").Float64()
+	return math.Max(percent, 0), math.Max(flat, 0)
+}
+
+// tradeCosts returns the configured fees and the current spread.
+func tradeCosts() engine.Costs {
+	percent, flat := loadFeeModel()
+	return engine.Costs{FeePercent: percent, FeeFlat: flat, SpreadPercent: currentSpread()}
+}
+
+// quoteTrade prices a trade at the mid-market rate, applying the spread and
+// fees. For buys amount is the USD to spend (fee included); for sells it is
+// the coin amount.
+func quoteTrade(side string, amount, rate float64) (tradeQuote, error) {
+	q, err := tradeCosts().Quote(side, amount, rate)
+	var feeErr *engine.FeeTooHighError
+	if errors.As(err, &feeErr) {
+		if side == "Buy" {
+			return q, fmt.Errorf("the %s fee exceeds the %s trade", formatMoney(feeErr.Fee), formatMoney(feeErr.Value))
+		}
+		return q, fmt.Errorf("the %s fee exceeds the %s sale", formatMoney(feeErr.Fee), formatMoney(feeErr.Value))
+	}
+	return q, err
+}
+
+// confirmTradePrompt is the question shown on the trade confirmation screen.
+func confirmTradePrompt(txType, coin string, q tradeQuote) string {
+	if txType == "Buy" {
+		if q.Fee > 0 {
+			return fmt.Sprintf("Purchase %.8f %s for %s (incl. %s fee)? ", q.Coin, coin, formatMoney(q.USD), formatMoney(q.Fee))
+		}
+		return fmt.Sprintf("Purchase %.8f %s for %s? ", q.Coin, coin, formatMoney(q.USD))
+	}
+	if q.Fee > 0 {
+		return fmt.Sprintf("Sell %.8f %s for %s (after %s fee)? ", q.Coin, coin, formatMoney(q.USD), formatMoney(q.Fee))
+	}
+	return fmt.Sprintf("Sell %.8f %s for %s? ", q.Coin, coin, formatMoney(q.USD))
+}
+
+func feeModelDisplay() string {
+	percent, flat := loadFeeModel()
+	switch {
+	case percent == 0 && flat == 0:
+		return "none"
+	case flat == 0:
+		return fmt.Sprintf("%s%%", strconv.FormatFloat(percent, 'f', -1, 64))
+	case percent == 0:
+		return fmt.Sprintf("%s per trade", formatMoney(flat))
+	}
+	return fmt.Sprintf("%s%% + %s per trade", strconv.FormatFloat(percent, 'f', -1, 64), formatMoney(flat))
+}
+
+// invokeFeeConfig edits FeePercent and FeeFlat. Enter keeps a value.
+func invokeFeeConfig(reader *bufio.Reader) {
+	percent, flat := loadFeeModel()
+	colorInfo.Printf("Current fees: %s\n", feeModelDisplay())
+
+	newPercent, ok := promptSettingValue(reader, fmt.Sprintf("Percentage fee per trade [%s]: ", strconv.FormatFloat(percent, 'f', -1, 64)), percent, 100)
+	if !ok {
+		return
+	}
+	newFlat, ok := promptSettingValue(reader, fmt.Sprintf("Flat fee per trade in %s [%.2f]: ", currencyCode(), flat), flat, math.MaxFloat64)
+	if !ok {
+		return
+	}
+
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("FeePercent").SetValue(strconv.FormatFloat(newPercent, 'f', -1, 64))
+		f.Section("Settings").Key("FeeFlat").SetValue(fmt.Sprintf("%.2f", newFlat))
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("fees updated", "percent", newPercent, "flat", newFlat)
+		color.Green("Fees set to %s.", feeModelDisplay())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// promptSettingValue reads a non-negative number up to max; empty input keeps current.
+func promptSettingValue(reader *bufio.Reader, prompt string, current, max float64) (float64, bool) {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(input), "$"), "%"))
+	if input == "" {
+		return current, true
+	}
+	val, err := strconv.ParseFloat(input, 64)
+	if err != nil || val < 0 || val > max {
+		color.Red("Invalid value. No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return 0, false
+	}
+	return val, true
+}
@@ -0,0 +1,195 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// Risk limits are optional checks on buys and sells typed at the prompt, set
+// in [Settings]: MaxTradeUSD (largest single trade, in the base currency),
+// MaxTradePercent (largest trade as a percent of the portfolio value) and
+// MaxTradesPerDay. 0 turns a limit off. With RiskLimits = warn a trade over a
+// limit asks for confirmation first; with block it is refused. Limit orders
+// and stop-loss/take-profit sales are not checked.
+const (
+	riskWarn  = "warn"
+	riskBlock = "block"
+)
+
+type riskLimits struct {
+	MaxTradeUSD     float64
+	MaxTradePercent float64
+	MaxTradesPerDay int
+	Mode            string // riskWarn or riskBlock
+}
+
+func loadRiskLimits() riskLimits {
+	limits := riskLimits{Mode: riskWarn}
+	if cfg == nil {
+		return limits
+	}
+	settings := cfg.Section("Settings")
+	limits.MaxTradeUSD = math.Max(settings.Key("MaxTradeUSD").MustFloat64(0), 0)
+	limits.MaxTradePercent = math.Max(settings.Key("MaxTradePercent").MustFloat64(0), 0)
+	limits.MaxTradesPerDay = max(settings.Key("MaxTradesPerDay").MustInt(0), 0)
+	if strings.EqualFold(settings.Key("RiskLimits").String(), riskBlock) {
+		limits.Mode = riskBlock
+	}
+	return limits
+}
+
+func (l riskLimits) enabled() bool {
+	return l.MaxTradeUSD > 0 || l.MaxTradePercent > 0 || l.MaxTradesPerDay > 0
+}
+
+func riskLimitsDisplay() string {
+	limits := loadRiskLimits()
+	if !limits.enabled() {
+		return "off"
+	}
+	var parts []string
+	if limits.MaxTradeUSD > 0 {
+		parts = append(parts, formatMoneyWhole(limits.MaxTradeUSD))
+	}
+	if limits.MaxTradePercent > 0 {
+		parts = append(parts, strconv.FormatFloat(limits.MaxTradePercent, 'f', -1, 64)+"%")
+	}
+	if limits.MaxTradesPerDay > 0 {
+		parts = append(parts, fmt.Sprintf("%d/day", limits.MaxTradesPerDay))
+	}
+	return strings.Join(parts, ", ") + ", " + limits.Mode
+}
+
+// tradesToday counts the buys and sells made today, across all sessions.
+// Automatic trades and undone trades do not count.
+func tradesToday() int {
+	entries, err := readAndParseLedger()
+	if err != nil {
+		slog.Warn("ledger read failed for the daily trade limit", "err", err)
+		return 0
+	}
+	year, month, day := time.Now().Date()
+	count := 0
+	for _, entry := range entries {
+		y, m, d := entry.DateTime.Local().Date()
+		if (entry.TX == "Buy" || entry.TX == "Sell") && y == year && m == month && d == day {
+			count++
+		}
+	}
+	return count
+}
+
+// riskLimitBreaches describes each limit a trade worth value (in the base
+// currency) would break.
+func riskLimitBreaches(limits riskLimits, value, portfolioValue float64) []string {
+	var breaches []string
+	if limits.MaxTradeUSD > 0 && value > limits.MaxTradeUSD {
+		breaches = append(breaches, fmt.Sprintf("This trade is worth %s; your limit per trade is %s.", formatMoney(value), formatMoney(limits.MaxTradeUSD)))
+	}
+	if limits.MaxTradePercent > 0 && portfolioValue > 0 {
+		if share := value / portfolioValue * 100; share > limits.MaxTradePercent {
+			breaches = append(breaches, fmt.Sprintf("This trade is %.1f%% of your portfolio; your limit is %s%%.", share, strconv.FormatFloat(limits.MaxTradePercent, 'f', -1, 64)))
+		}
+	}
+	if limits.MaxTradesPerDay > 0 {
+		if count := tradesToday(); count >= limits.MaxTradesPerDay {
+			breaches = append(breaches, fmt.Sprintf("You have made %d trades today; your limit is %d.", count, limits.MaxTradesPerDay))
+		}
+	}
+	return breaches
+}
+
+// checkRiskLimits checks a trade of amount (cash for a buy, coin for a sell)
+// against the risk limits and returns whether it may go ahead.
+func checkRiskLimits(reader *bufio.Reader, txType, coin string, amount float64) bool {
+	limits := loadRiskLimits()
+	if !limits.enabled() {
+		return true
+	}
+	value := amount
+	if txType == "Sell" {
+		value = amount * coinRate(coin)
+	}
+	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	breaches := riskLimitBreaches(limits, value, getPortfolioValue(playerUSD, playerBTC, apiData))
+	if len(breaches) == 0 {
+		return true
+	}
+	slog.Info("risk limit reached", "tx", txType, "coin", coin, "value", value, "mode", limits.Mode)
+	sessionLog.Info("risk limit reached", "tx", txType, "coin", coin, "value", value, "mode", limits.Mode)
+
+	if limits.Mode == riskBlock {
+		for _, breach := range breaches {
+			color.Red(breach)
+		}
+		color.Red("Trade blocked by your risk limits (Config > Risk Limits).")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return false
+	}
+	for _, breach := range breaches {
+		color.Yellow(breach)
+	}
+	fmt.Print("Trade anyway? (y/n): ")
+	input, _ := reader.ReadString('\n')
+	return strings.EqualFold(strings.TrimSpace(input), "y")
+}
+
+// invokeRiskLimitsConfig edits the risk limits. Enter keeps a value.
+func invokeRiskLimitsConfig(reader *bufio.Reader) {
+	limits := loadRiskLimits()
+	colorInfo.Printf("Current risk limits: %s\n", riskLimitsDisplay())
+	fmt.Println("0 turns a limit off.")
+
+	maxUSD, ok := promptSettingValue(reader, fmt.Sprintf("Largest single trade in %s [%.2f]: ", currencyCode(), limits.MaxTradeUSD), limits.MaxTradeUSD, math.MaxFloat64)
+	if !ok {
+		return
+	}
+	maxPercent, ok := promptSettingValue(reader, fmt.Sprintf("Largest trade as a percent of your portfolio [%s]: ", strconv.FormatFloat(limits.MaxTradePercent, 'f', -1, 64)), limits.MaxTradePercent, 100)
+	if !ok {
+		return
+	}
+	maxTrades, ok := promptSettingValue(reader, fmt.Sprintf("Most trades per day [%d]: ", limits.MaxTradesPerDay), float64(limits.MaxTradesPerDay), math.MaxInt32)
+	if !ok {
+		return
+	}
+	fmt.Printf("When a trade breaks a limit: (W)arn and ask, or (B)lock it [%s]: ", limits.Mode)
+	input, _ := reader.ReadString('\n')
+	mode := limits.Mode
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "":
+	case "w", riskWarn:
+		mode = riskWarn
+	case "b", riskBlock:
+		mode = riskBlock
+	default:
+		color.Red("Invalid value. No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("MaxTradeUSD").SetValue(fmt.Sprintf("%.2f", maxUSD))
+		f.Section("Settings").Key("MaxTradePercent").SetValue(strconv.FormatFloat(maxPercent, 'f', -1, 64))
+		f.Section("Settings").Key("MaxTradesPerDay").SetValue(strconv.Itoa(int(maxTrades)))
+		f.Section("Settings").Key("RiskLimits").SetValue(mode)
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("risk limits updated", "max_usd", maxUSD, "max_percent", maxPercent, "max_per_day", int(maxTrades), "mode", mode)
+		color.Green("Risk limits set to %s.", riskLimitsDisplay())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
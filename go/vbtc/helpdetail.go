@@ -0,0 +1,298 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// commandDetail is the extended help "help <command>" shows.
+type commandDetail struct {
+	Usage    []string
+	Summary  string
+	Examples [][2]string // command, what it does
+	Notes    []string
+}
+
+var commandDetails = map[string]commandDetail{
+	"buy": {
+		Usage:   []string{"buy [amount] [coin]"},
+		Summary: "Buy Bitcoin (or a tracked coin) with an amount of cash in your base currency. Without an amount you are asked for one.",
+		Examples: [][2]string{
+			{"buy 100", "Buy $100 of BTC"},
+			{"b 1,250.50", "Commas are ignored"},
+			{"buy (1000-250)/2", "Any amount may be an arithmetic expression"},
+			{"buy 50p", "Spend 50% of your cash"},
+			{"buy 100/3p", "Spend a third of your cash (percentages may be expressions)"},
+			{"buy 25 eth", "Buy $25 of ETH (tracked coins only)"},
+		},
+		Notes: []string{
+			"The offer is good for 2 minutes: Y accepts, R fetches a new price, N or Esc cancels",
+			"Fees and spread are included in the amount you spend, so less than the full amount buys coin",
+			"Satoshi amounts ('s') are for selling only",
+			"The trade is cancelled if another session changed your portfolio while the offer was open",
+		},
+	},
+	"sell": {
+		Usage:   []string{"sell [amount] [coin]"},
+		Summary: "Sell an amount of BTC (or a tracked coin). Without an amount you are asked for one.",
+		Examples: [][2]string{
+			{"sell 0.5", "Sell 0.5 BTC"},
+			{"sell 50000s", "Sell 50,000 satoshis (0.0005 BTC)"},
+			{"sell 0.05*3", "Expressions work for coin and satoshi amounts too"},
+			{"sell 100p", "Sell all of your BTC"},
+			{"s 25p ltc", "Sell a quarter of your LTC"},
+		},
+		Notes: []string{
+			"Amounts are in the coin, not in cash; use a percentage to sell a share of the balance",
+			"Percentages are rounded down to the satoshi so you never sell more than you hold",
+			"Fees and spread come out of the proceeds",
+		},
+	},
+	"quote": {
+		Usage:   []string{"quote [buy|sell] <amount> [coin]"},
+		Summary: "Show what a buy or sell would cost or yield right now, spread and fees included, without trading.",
+		Examples: [][2]string{
+			{"quote 100", "How much BTC $100 buys"},
+			{"quote sell 0.5", "What selling 0.5 BTC pays"},
+			{"quote 50000s", "Satoshi amounts are quoted as sells"},
+			{"quote sell 50p eth", "What selling half your ETH pays"},
+		},
+		Notes: []string{
+			"Amounts use the same syntax as buy and sell, including percentages and expressions",
+			"The quote uses the price on the main screen; refresh first for the latest",
+		},
+	},
+	"rebalance": {
+		Usage:   []string{"rebalance <percent>"},
+		Summary: "Show the buy or sell that makes BTC a target share of your cash plus BTC, and offer to open the trade screen with it.",
+		Examples: [][2]string{
+			{"rebalance 60", "Aim for 60% BTC / 40% cash"},
+			{"rebalance 0", "Plan selling all your BTC"},
+		},
+		Notes: []string{
+			"Other tracked coins are left out of the split",
+			"The plan uses the mid-market rate; fees and spread leave the result just short of the target",
+			"The trade goes through the usual confirmation screen and risk limits",
+		},
+	},
+	"undo": {
+		Usage:   []string{"undo"},
+		Summary: "Reverse your last buy or sell if it was made within the grace period (60 seconds by default).",
+		Notes: []string{
+			"Set the grace period with UndoSeconds in the [Settings] section of vbtc.ini; 0 turns undo off",
+			"The ledger row is kept and renamed 'Reverted Buy' or 'Reverted Sell'",
+			"Refused if anything changed the portfolio since, such as an order fill or another session",
+		},
+	},
+	"ledger": {
+		Usage:   []string{"ledger [filters] [search words]"},
+		Summary: "Show your transactions a page at a time, with summary statistics. Filters and searches also cover archived ledgers.",
+		Examples: [][2]string{
+			{"ledger type:sell", "Only sells, including order fills and stop-loss/take-profit sales"},
+			{"ledger from:2024-01-01 to:2024-06-30", "Trades in the first half of 2024"},
+			{"ledger min:500", "Trades of at least $500"},
+			{"ledger #strategy1", "Trades tagged #strategy1"},
+			{"ledger sort:newest", "Newest first"},
+		},
+		Notes: []string{
+			"On the ledger screen: N / P page, O flips the order, F edits the filters, / searches, C clears",
+			"Use _ for spaces in a TX type, e.g. type:stop_loss",
+		},
+	},
+	"orders": {
+		Usage: []string{
+			"orders",
+			"orders buy <amount> <price>",
+			"orders sell <btc> <price>",
+			"orders edit <id> <amount> <price>",
+			"orders cancel <id|all>",
+		},
+		Summary: "Place limit orders that trade automatically when the price reaches them.",
+		Examples: [][2]string{
+			{"orders buy 100 60000", "Buy $100 of BTC once the price is at or below $60,000"},
+			{"orders sell 50p 90000", "Sell half your BTC at or above $90,000"},
+			{"orders cancel all", "Cancel every pending order"},
+		},
+		Notes: []string{
+			"Orders are checked at startup, after refresh and after every trade",
+			"Funds are not reserved; an order your balance no longer covers is cancelled when it triggers",
+		},
+	},
+	"triggers": {
+		Usage:   []string{"triggers", "triggers stop <price|off>", "triggers take <price|off>", "triggers off"},
+		Summary: "Set a stop-loss and take-profit price for your whole BTC position.",
+		Examples: [][2]string{
+			{"triggers stop 55000", "Offer to sell everything if BTC falls to $55,000"},
+			{"triggers take 120000", "Offer to sell everything if BTC rises to $120,000"},
+		},
+		Notes: []string{
+			"A stop-loss must be below the current price and a take-profit above it",
+			"When one is reached you get 30 seconds to keep the position; otherwise it is sold",
+		},
+	},
+	"alert": {
+		Usage:   []string{"alert", "alert > <price>", "alert < <price>", "alert remove <id|all>", "alert beep on|off"},
+		Summary: "Get a banner (and a beep) when BTC crosses a price. Alerts never trade.",
+		Examples: [][2]string{
+			{"alert > 100000", "Alert when BTC rises above $100,000"},
+			{"alert below 90,000", "Alert when BTC falls below $90,000"},
+			{"alert remove 2", "Remove alert 2"},
+		},
+		Notes: []string{
+			"Each alert fires once per crossing and re-arms when the price moves back",
+		},
+	},
+	"pnl": {
+		Usage:   []string{"pnl [fifo|average]"},
+		Summary: "Show realized and unrealized gains per coin from the ledger and its archives.",
+		Examples: [][2]string{
+			{"pnl", "Use the saved cost basis method"},
+			{"pnl average", "Switch to average cost and save it"},
+		},
+	},
+	"stats": {
+		Usage:   []string{"stats"},
+		Summary: "Show win rate, trade sizes, largest gain and loss, max drawdown, holding times and monthly P/L.",
+	},
+	"history": {
+		Usage:   []string{"history"},
+		Summary: "Chart your portfolio value day by day from snapshots.csv.",
+		Notes: []string{
+			"A snapshot is saved each day you run vBTC, when the session starts and when you exit",
+		},
+	},
+	"leaderboard": {
+		Usage:   []string{"leaderboard"},
+		Summary: "Show the rankings on the shared leaderboard. Join one under Config > Leaderboard; your score is posted when you exit.",
+	},
+	"status": {
+		Usage:   []string{"status --json"},
+		Summary: "Print the market data and your portfolio as JSON.",
+		Notes: []string{
+			"'vbtc status --json' on the command line prints the same and exits, for scripts",
+		},
+	},
+	"export": {
+		Usage:   []string{"export <json|ofx|qif> [from] [to]"},
+		Summary: "Write the ledger, with cost basis and realized gains, to a file next to ledger.csv.",
+		Examples: [][2]string{
+			{"export json", "Everything, as JSON"},
+			{"x qif 2024-01-01 2024-12-31", "2024 only, for Quicken"},
+		},
+	},
+	"import": {
+		Usage:   []string{"import <file>"},
+		Summary: "Add the buys and sells from a Coinbase transaction history or Kraken trades CSV to the ledger and your balances, at the exchange's dates and prices.",
+		Examples: [][2]string{
+			{"import ~/Downloads/coinbase.csv", "Preview, then confirm with YES"},
+		},
+		Notes: []string{
+			"Transfers, rewards, untracked coins and trades in other currencies are skipped",
+			"Trades already imported are recognized and skipped, so a newer export can be imported again",
+			"The import is refused if a buy would need more cash than the portfolio has",
+		},
+	},
+	"refresh": {
+		Usage:   []string{"refresh"},
+		Summary: "Fetch fresh market data and reload vbtc.ini, picking up changes from other sessions.",
+	},
+	"config": {
+		Usage:   []string{"config"},
+		Summary: "Open the configuration menu: API key, portfolio reset, ledger archive and merge, tracked coins, fees, spread, price watcher, provider, audio, currency, indicators, leaderboard, number format, risk limits, session log and webhook.",
+	},
+	"help": {
+		Usage:   []string{"help [command]"},
+		Summary: "Show the list of commands, or the details of one.",
+		Examples: [][2]string{
+			{"help sell", "Details and examples for sell"},
+		},
+	},
+	"exit": {
+		Usage:   []string{"exit"},
+		Summary: "Show the portfolio and session summary and quit.",
+	},
+}
+
+// matchCommands returns the commands input names: the command a shortcut
+// stands for, or every command it is a prefix of.
+func matchCommands(commands map[string]string, input string) []string {
+	if long, ok := commands[input]; ok {
+		// Shortcuts win over prefixes, so "e" stays exit alongside export.
+		return []string{long}
+	}
+	var matched []string
+	seen := map[string]bool{}
+	for _, long := range commands {
+		if strings.HasPrefix(long, input) && !seen[long] {
+			seen[long] = true
+			matched = append(matched, long)
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+// showCommandHelp prints the extended help for the command name refers to.
+func showCommandHelp(reader *bufio.Reader, commands map[string]string, name string) {
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+	matched := matchCommands(commands, strings.ToLower(name))
+	if len(matched) > 1 {
+		color.Yellow("Ambiguous command. Did you mean: %s?", strings.Join(matched, ", "))
+		return
+	}
+	detail, ok := commandDetails[strings.Join(matched, "")]
+	if !ok {
+		color.Red("No help for '%s'. Type 'help' for a list of commands.", name)
+		return
+	}
+	command := matched[0]
+	var shortcuts []string
+	for short, long := range commands {
+		if long == command && short != command {
+			shortcuts = append(shortcuts, short)
+		}
+	}
+	sort.Strings(shortcuts)
+
+	clearScreen()
+	color.Yellow("*** Help: %s ***", command)
+	fmt.Println()
+	colorInfo.Println("USAGE:")
+	for _, usage := range detail.Usage {
+		color.New(color.FgWhite).Printf("    %s\n", usage)
+	}
+	if len(shortcuts) > 0 {
+		color.New(color.FgHiBlack).Printf("    Shortcut: %s\n", strings.Join(shortcuts, ", "))
+	}
+	fmt.Println()
+	fmt.Println(detail.Summary)
+	fmt.Println()
+
+	if len(detail.Examples) > 0 {
+		width := 0
+		for _, example := range detail.Examples {
+			width = max(width, len(example[0]))
+		}
+		colorInfo.Println("EXAMPLES:")
+		for _, example := range detail.Examples {
+			color.New(color.FgWhite).Printf("    %-*s  ", width, example[0])
+			color.New(color.FgHiBlack).Println(example[1])
+		}
+		fmt.Println()
+	}
+	if len(detail.Notes) > 0 {
+		colorPositive.Println("NOTES:")
+		for _, note := range detail.Notes {
+			colorTitle.Print("    • ")
+			color.New(color.FgHiBlack).Println(note)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,74 @@
+package vbtc
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The last 24h history response is kept in vbtc.history.json next to vbtc.ini,
+// so restarting vbtc (or a second session) within historyCacheMaxAge reuses it
+// instead of spending another history request. Replays never use the cache.
+const historyCacheMaxAge = 15 * time.Minute
+
+type historyCache struct {
+	Fetched  time.Time      `json:"fetched"`
+	Provider string         `json:"provider"`
+	Currency string         `json:"currency"`
+	History  []HistoryPoint `json:"history"`
+}
+
+func historyCachePath() string {
+	return filepath.Join(filepath.Dir(iniFilePath), "vbtc.history.json")
+}
+
+// loadHistoryCache returns the cached history and when it was fetched, or nil
+// if there is none, it is older than historyCacheMaxAge, it is in another
+// currency, or rate is outside its range (the 24h high or low has moved).
+func loadHistoryCache(rate float64) (*HistoryResponse, time.Time) {
+	if replaySource != nil {
+		return nil, time.Time{}
+	}
+	data, err := os.ReadFile(historyCachePath())
+	if err != nil {
+		return nil, time.Time{}
+	}
+	var cache historyCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		slog.Warn("ignoring unreadable history cache", "path", historyCachePath(), "err", err)
+		return nil, time.Time{}
+	}
+	if len(cache.History) == 0 || cache.Currency != currencyCode() || time.Since(cache.Fetched) > historyCacheMaxAge {
+		return nil, time.Time{}
+	}
+	low, high := cache.History[0].Rate, cache.History[0].Rate
+	for _, p := range cache.History {
+		low, high = min(low, p.Rate), max(high, p.Rate)
+	}
+	if rate > high || rate < low {
+		return nil, time.Time{}
+	}
+	slog.Debug("using cached history", "fetched", cache.Fetched, "points", len(cache.History))
+	return &HistoryResponse{History: cache.History}, cache.Fetched
+}
+
+// saveHistoryCache writes history, fetched from provider just now, to the cache.
+func saveHistoryCache(provider string, history *HistoryResponse) {
+	if replaySource != nil || history == nil {
+		return
+	}
+	data, err := json.Marshal(historyCache{Fetched: time.Now().UTC(), Provider: provider, Currency: currencyCode(), History: history.History})
+	if err != nil {
+		return
+	}
+	err = writeFileAtomic(historyCachePath(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		slog.Warn("history cache write failed", "path", historyCachePath(), "err", err)
+	}
+}
@@ -0,0 +1,498 @@
+package vbtc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+
+	"vbtc/internal/engine"
+)
+
+// "import <file>" reads a trade history exported from Coinbase or Kraken and
+// replays its buys and sells into vbtc: each becomes a ledger row dated when
+// the exchange made it, and the portfolio balances move as if the trade had
+// been made here. Transfers, rewards, conversions, coins that are not tracked
+// and trades priced in another currency are skipped and counted.
+
+// Ledger TX values written for imported trades. Their notes hold the
+// exchange and its transaction id, tagged #import.
+const (
+	txImportBuy  = "Import Buy"
+	txImportSell = "Import Sell"
+)
+
+// importedTrade is one buy or sell read from an exchange export. USD is the
+// cash moved in the base currency, fee included on buys and deducted on
+// sells, as in the ledger.
+type importedTrade struct {
+	Time   time.Time
+	Side   string // "Buy" or "Sell"
+	Coin   string
+	Amount float64
+	Rate   float64
+	USD    float64
+	Fee    float64
+	Ref    string // exchange and transaction id, kept as the ledger note
+}
+
+func (t importedTrade) tx() string {
+	if t.Side == "Sell" {
+		return txImportSell
+	}
+	return txImportBuy
+}
+
+// importSkips counts the rows an import leaves out, by reason.
+type importSkips map[string]int
+
+func (s importSkips) String() string {
+	reasons := make([]string, 0, len(s))
+	for reason := range s {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	parts := make([]string, len(reasons))
+	for i, reason := range reasons {
+		parts[i] = fmt.Sprintf("%d %s", s[reason], reason)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseExchangeCSV recognizes a Coinbase transaction history or a Kraken
+// trades export by its header row and returns the exchange name and its
+// trades in currency, oldest first.
+func parseExchangeCSV(data []byte, currency string) (string, []importedTrade, importSkips, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("not a readable CSV file: %w", err)
+	}
+	// Coinbase puts a few lines of account details above the header.
+	for i, record := range records {
+		cols := make(map[string]int, len(record))
+		for j, name := range record {
+			cols[strings.ToLower(strings.TrimSpace(name))] = j
+		}
+		_, hasTxid := cols["txid"]
+		_, hasPair := cols["pair"]
+		_, hasTimestamp := cols["timestamp"]
+		_, hasType := cols["transaction type"]
+		switch {
+		case hasTxid && hasPair:
+			trades, skips := parseKrakenTrades(records[i+1:], cols, currency)
+			return "Kraken", trades, skips, nil
+		case hasTimestamp && hasType:
+			trades, skips := parseCoinbaseTrades(records[i+1:], cols, currency)
+			return "Coinbase", trades, skips, nil
+		}
+	}
+	return "", nil, nil, errors.New("not a Coinbase transaction history or Kraken trades export (no recognized header row)")
+}
+
+// csvField returns the first of names present in cols, trimmed, or "".
+func csvField(record []string, cols map[string]int, names ...string) string {
+	for _, name := range names {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+	}
+	return ""
+}
+
+// parseExportNumber reads a number as exchanges write it, ignoring currency
+// symbols and thousands separators ("$1,234.56", "-0.0125"). The sign is dropped.
+func parseExportNumber(s string) float64 {
+	s = strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' {
+			return r
+		}
+		return -1
+	}, s)
+	value, _ := strconv.ParseFloat(s, 64)
+	return value
+}
+
+// parseExportTime reads the timestamp layouts Coinbase and Kraken use, in UTC.
+func parseExportTime(s string) (time.Time, bool) {
+	for _, layout := range []string{"2006-01-02 15:04:05 UTC", time.RFC3339, "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// cashMoved is the ledger USD for a trade: what a buy cost with its fee, or
+// what a sell brought in after it.
+func cashMoved(side string, subtotal, fee float64) float64 {
+	if side == "Buy" {
+		return subtotal + fee
+	}
+	return subtotal - fee
+}
+
+// addImportedTrade checks t against the currency and tracked coins and adds
+// it to trades, or counts why it was skipped.
+func addImportedTrade(trades []importedTrade, skips importSkips, t importedTrade, tradeCurrency, currency string) []importedTrade {
+	switch {
+	case !strings.EqualFold(tradeCurrency, currency):
+		skips["in other currencies"]++
+	case !isTrackedCoin(t.Coin):
+		skips[fmt.Sprintf("%s (not a tracked coin)", t.Coin)]++
+	case t.Amount <= 0 || t.USD <= 0:
+		skips["with no amount"]++
+	default:
+		trades = append(trades, t)
+	}
+	return trades
+}
+
+// parseCoinbaseTrades reads the rows after the header of a Coinbase
+// transaction history. Both the current layout (ID, Price Currency, Price at
+// Transaction) and the older one (Spot Price Currency, Spot Price at
+// Transaction) are understood.
+func parseCoinbaseTrades(records [][]string, cols map[string]int, currency string) ([]importedTrade, importSkips) {
+	var trades []importedTrade
+	skips := importSkips{}
+	for _, record := range records {
+		kind := strings.ToLower(csvField(record, cols, "transaction type"))
+		if kind == "" {
+			continue
+		}
+		var side string
+		switch {
+		case strings.HasSuffix(kind, "buy"):
+			side = "Buy"
+		case strings.HasSuffix(kind, "sell"):
+			side = "Sell"
+		default:
+			skips["transfers and other transactions"]++
+			continue
+		}
+		when, ok := parseExportTime(csvField(record, cols, "timestamp"))
+		if !ok {
+			skips["with an unreadable date"]++
+			continue
+		}
+		fee := parseExportNumber(csvField(record, cols, "fees and/or spread", "fees"))
+		usd := parseExportNumber(csvField(record, cols, "total (inclusive of fees and/or spread)", "total (inclusive of fees)"))
+		if usd == 0 {
+			usd = cashMoved(side, parseExportNumber(csvField(record, cols, "subtotal")), fee)
+		}
+		t := importedTrade{
+			Time:   when,
+			Side:   side,
+			Coin:   strings.ToUpper(csvField(record, cols, "asset")),
+			Amount: parseExportNumber(csvField(record, cols, "quantity transacted")),
+			Rate:   parseExportNumber(csvField(record, cols, "price at transaction", "spot price at transaction")),
+			USD:    usd,
+			Fee:    fee,
+		}
+		if id := csvField(record, cols, "id"); id != "" {
+			t.Ref = "coinbase " + id
+		}
+		trades = addImportedTrade(trades, skips, t, csvField(record, cols, "price currency", "spot price currency"), currency)
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Time.Before(trades[j].Time) })
+	return trades, skips
+}
+
+// krakenQuotes are the quote currencies splitKrakenPair looks for, longest
+// first so USDT is not read as USD.
+var krakenQuotes = []string{"ZUSD", "ZEUR", "ZGBP", "ZCAD", "ZJPY", "USDT", "USDC", "USD", "EUR", "GBP", "CAD", "AUD", "JPY", "CHF"}
+
+// krakenAsset maps Kraken's asset codes to the usual symbols: "XXBT" and
+// "XBT" are BTC, "XXDG" is DOGE, and legacy four-letter codes drop their
+// X or Z prefix ("XETH", "ZUSD").
+func krakenAsset(code string) string {
+	code = strings.ToUpper(code)
+	if len(code) == 4 && (code[0] == 'X' || code[0] == 'Z') {
+		code = code[1:]
+	}
+	switch code {
+	case "XBT":
+		return "BTC"
+	case "XDG":
+		return "DOGE"
+	}
+	return code
+}
+
+// splitKrakenPair splits a pair such as "XXBTZUSD", "XBTUSD" or "ETHEUR"
+// into its base asset and quote currency.
+func splitKrakenPair(pair string) (base, quote string) {
+	pair = strings.ToUpper(strings.ReplaceAll(pair, "/", ""))
+	for _, q := range krakenQuotes {
+		if strings.HasSuffix(pair, q) && len(pair) > len(q) {
+			return krakenAsset(strings.TrimSuffix(pair, q)), krakenAsset(q)
+		}
+	}
+	return krakenAsset(pair), ""
+}
+
+// parseKrakenTrades reads the rows after the header of a Kraken trades.csv
+// (txid, pair, time, type, price, cost, fee, vol).
+func parseKrakenTrades(records [][]string, cols map[string]int, currency string) ([]importedTrade, importSkips) {
+	var trades []importedTrade
+	skips := importSkips{}
+	for _, record := range records {
+		txid := csvField(record, cols, "txid")
+		if txid == "" {
+			continue
+		}
+		var side string
+		switch strings.ToLower(csvField(record, cols, "type")) {
+		case "buy":
+			side = "Buy"
+		case "sell":
+			side = "Sell"
+		default:
+			skips["transfers and other transactions"]++
+			continue
+		}
+		when, ok := parseExportTime(csvField(record, cols, "time"))
+		if !ok {
+			skips["with an unreadable date"]++
+			continue
+		}
+		base, quote := splitKrakenPair(csvField(record, cols, "pair"))
+		fee := parseExportNumber(csvField(record, cols, "fee"))
+		t := importedTrade{
+			Time:   when,
+			Side:   side,
+			Coin:   base,
+			Amount: parseExportNumber(csvField(record, cols, "vol")),
+			Rate:   parseExportNumber(csvField(record, cols, "price")),
+			USD:    cashMoved(side, parseExportNumber(csvField(record, cols, "cost")), fee),
+			Fee:    fee,
+			Ref:    "kraken " + txid,
+		}
+		trades = addImportedTrade(trades, skips, t, quote, currency)
+	}
+	sort.SliceStable(trades, func(i, j int) bool { return trades[i].Time.Before(trades[j].Time) })
+	return trades, skips
+}
+
+// alreadyImported reports whether the ledger has t: the same exchange
+// reference, or for exports without ids the same side, coin, amount and second.
+func alreadyImported(entries []LedgerEntry, t importedTrade) bool {
+	for _, e := range entries {
+		if t.Ref != "" {
+			if e.Note == t.Ref {
+				return true
+			}
+			continue
+		}
+		if e.TX == t.tx() && e.Coin == t.Coin && math.Abs(e.BTC-t.Amount) < 1e-8 && e.DateTime.Equal(t.Time.Truncate(time.Second)) {
+			return true
+		}
+	}
+	return false
+}
+
+// runImportCommand handles "import <file>": it reads the export, shows what
+// would change and, once confirmed, writes the ledger rows and balances.
+func runImportCommand(reader *bufio.Reader, args []string) {
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+	path := strings.Trim(strings.Join(args, " "), `"'`)
+	if path == "" {
+		color.Red("Usage: import <file> (a Coinbase transaction history or Kraken trades CSV)")
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		color.Red("Could not read %s: %v", path, err)
+		return
+	}
+	exchange, parsed, skips, err := parseExchangeCSV(data, currencyCode())
+	if err != nil {
+		color.Red("%s: %v", path, err)
+		return
+	}
+	entries, err := readAllLedgerEntries()
+	if err != nil {
+		color.Red("Error reading ledger: %v", err)
+		return
+	}
+	var trades []importedTrade
+	for _, t := range parsed {
+		if alreadyImported(entries, t) {
+			skips["already in the ledger"]++
+			continue
+		}
+		trades = append(trades, t)
+	}
+
+	clearScreen()
+	color.Yellow("*** Import from %s ***", exchange)
+	if len(skips) > 0 {
+		color.New(color.FgHiBlack).Printf("Skipped: %s\n", skips)
+	}
+	if len(trades) == 0 {
+		color.Yellow("No new %s trades to import.", currencyCode())
+		return
+	}
+	// Summarize per coin, in the order the coins first appear.
+	var coins []string
+	byCoin := map[string][]importedTrade{}
+	for _, t := range trades {
+		if _, ok := byCoin[t.Coin]; !ok {
+			coins = append(coins, t.Coin)
+		}
+		byCoin[t.Coin] = append(byCoin[t.Coin], t)
+	}
+	fmt.Printf("%d trades from %s to %s:\n", len(trades),
+		trades[0].Time.Local().Format("01/02/06"), trades[len(trades)-1].Time.Local().Format("01/02/06"))
+	for _, coin := range coins {
+		var buys, sells int
+		var net, spent, received float64
+		for _, t := range byCoin[coin] {
+			if t.Side == "Buy" {
+				buys++
+				net += t.Amount
+				spent += t.USD
+			} else {
+				sells++
+				net -= t.Amount
+				received += t.USD
+			}
+		}
+		fmt.Printf("  %-5s %d buys (%s), %d sells (%s), net %s %s\n", coin, buys, formatMoney(spent), sells, formatMoney(received), formatCoinAmount(net), coin)
+	}
+	if _, err := simulateImport(cfg, trades); err != nil {
+		color.Red("%v", err)
+		color.Red("Nothing was imported.")
+		return
+	}
+	fmt.Println()
+	colorNegative.Print("Add these trades to your ledger and balances? Type 'YES' to confirm: ")
+	confirm, _ := reader.ReadString('\n')
+	if strings.TrimSpace(confirm) != "YES" {
+		fmt.Println("Import cancelled.")
+		return
+	}
+
+	imported, err := importTrades(coins, byCoin, trades)
+	if err != nil {
+		slog.Error("import failed", "file", path, "imported", imported, "err", err)
+		color.Red("Import stopped after %d trades: %v", imported, err)
+		return
+	}
+	slog.Info("trades imported", "exchange", exchange, "file", path, "trades", imported)
+	sessionLog.Info("import", "exchange", exchange, "file", path, "trades", imported)
+	color.Green("Imported %d trades from %s.", imported, exchange)
+	recordSnapshot()
+}
+
+// simulateImport replays trades, oldest first, on the balances in f and
+// returns the resulting cash. A trade that would take cash or a coin below
+// zero is an error: vbtc has no deposits, so the export must fit the
+// simulated account.
+func simulateImport(f *ini.File, trades []importedTrade) (float64, error) {
+	portfolio := f.Section("Portfolio")
+	cash, _ := portfolio.Key("PlayerUSD").Float64()
+	held := map[string]float64{}
+	for _, t := range trades {
+		if _, ok := held[t.Coin]; !ok {
+			held[t.Coin], _ = portfolio.Key(coinBalanceKey(t.Coin)).Float64()
+		}
+		if t.Side == "Buy" {
+			cash -= t.USD
+			held[t.Coin] += t.Amount
+		} else {
+			cash += t.USD
+			held[t.Coin] -= t.Amount
+		}
+		switch {
+		case cash < -0.005:
+			return cash, fmt.Errorf("The %s buy of %s %s on %s needs %s more cash than the portfolio has.",
+				formatMoney(t.USD), formatCoinAmount(t.Amount), t.Coin, t.Time.Local().Format("01/02/06"), formatMoney(-cash))
+		case held[t.Coin] < -1e-9:
+			return cash, fmt.Errorf("The sale of %s %s on %s is more %s than the portfolio holds; import the earlier trades first.",
+				formatCoinAmount(t.Amount), t.Coin, t.Time.Local().Format("01/02/06"), t.Coin)
+		}
+	}
+	return cash, nil
+}
+
+// importTrades writes the trades of each coin as one journaled trade, like
+// a refresh that fills several orders, and returns how many were written.
+// Ledger rows keep the exchange's time, moved on by a second where one is
+// already taken so every row stays distinct.
+func importTrades(coins []string, byCoin map[string][]importedTrade, all []importedTrade) (int, error) {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+	// Re-read under the lock so a trade made by another session is accounted for.
+	importCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		return 0, fmt.Errorf("could not read %s: %w", iniFilePath, err)
+	}
+	if _, err := simulateImport(importCfg, all); err != nil {
+		return 0, err
+	}
+	entries, err := readAllLedgerEntries()
+	if err != nil {
+		return 0, err
+	}
+	taken := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		taken[e.Time] = true
+	}
+
+	imported := 0
+	for _, coin := range coins {
+		before := portfolioBalances(importCfg, coin)
+		var rows []pendingRow
+		for _, t := range byCoin[coin] {
+			when := t.Time.Truncate(time.Second)
+			for taken[when.Format(engine.LedgerTimeFormat)] {
+				when = when.Add(time.Second)
+			}
+			taken[when.Format(engine.LedgerTimeFormat)] = true
+			userCoin, err := applyTradeToPortfolio(importCfg, coin, t.Side, t.USD, t.Amount)
+			if err != nil {
+				return imported, fmt.Errorf("%s trade at %s: %w", coin, t.Time.Format(engine.LedgerTimeFormat), err)
+			}
+			rows = append(rows, pendingRow{TX: t.tx(), USD: t.USD, Amount: t.Amount, Rate: t.Rate, UserCoin: userCoin, Fee: t.Fee, Note: strings.TrimSpace(t.Ref + " #import"), Time: when})
+		}
+		if err := beginTrade(pendingTrade{Coin: coin, Before: before, After: portfolioBalances(importCfg, coin), Rows: rows}); err != nil {
+			return imported, err
+		}
+		if err := writeIni(importCfg); err != nil {
+			endTrade()
+			return imported, fmt.Errorf("could not save vbtc.ini: %w", err)
+		}
+		cfg = importCfg
+		for _, row := range rows {
+			ledger := engine.Ledger{Store: csvLedgerStore{}, Clock: fixedClock(row.Time)}
+			note, tags := splitNoteTags(row.Note)
+			if _, err := ledger.Append(LedgerEntry{TX: row.TX, USD: row.USD, BTC: row.Amount, BTCPrice: row.Rate, UserBTC: row.UserCoin, Coin: coin, Fee: row.Fee, Note: note, Tags: tags}); err != nil {
+				// The journal keeps the rest; the next trade or start adds them.
+				return imported, fmt.Errorf("balances saved, but ledger.csv could not be written (vBTC will add the rows next time it starts): %w", err)
+			}
+			imported++
+		}
+		endTrade()
+	}
+	return imported, nil
+}
@@ -0,0 +1,382 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// [Settings] Indicators chooses the indicators on the main screen, in order,
+// as a comma separated list of:
+//
+//	SMA<n>H  simple moving average over the last n hours
+//	EMA<n>H  exponential moving average with an n hour period
+//	BB<n>H   Bollinger bands (mean ± 2 standard deviations) over n hours
+//	RSI      RSI(14)
+//	MACD     MACD(12, 26, 9)
+//	VOL<n>H  high-low range over n hours as a percent of the low; VOL24H is
+//	         the 24h volatility line with its velocity
+//
+// n is 1-24, since only 24 hours of history is fetched. Volatility lines are
+// shown after the 24h high and low, the others under the price.
+const defaultIndicators = "SMA1H,RSI,MACD,VOL24H"
+
+type indicator struct {
+	Kind  string // SMA, EMA, BB, RSI, MACD or VOL
+	Hours int    // window for SMA, EMA, BB and VOL
+}
+
+func (ind indicator) String() string {
+	if ind.Hours > 0 {
+		return fmt.Sprintf("%s%dH", ind.Kind, ind.Hours)
+	}
+	return ind.Kind
+}
+
+// parseIndicators parses an Indicators setting, e.g. "SMA1H,EMA4H,BB2H".
+func parseIndicators(value string) ([]indicator, error) {
+	var indicators []indicator
+	seen := map[string]bool{}
+	for _, token := range strings.Split(value, ",") {
+		token = strings.ToUpper(strings.TrimSpace(token))
+		if token == "" {
+			continue
+		}
+		var ind indicator
+		switch {
+		case token == "RSI" || token == "MACD":
+			ind = indicator{Kind: token}
+		default:
+			for _, kind := range []string{"SMA", "EMA", "BB", "VOL"} {
+				rest, ok := strings.CutPrefix(token, kind)
+				if !ok || !strings.HasSuffix(rest, "H") {
+					continue
+				}
+				hours, err := strconv.Atoi(strings.TrimSuffix(rest, "H"))
+				if err != nil || hours < 1 || hours > 24 {
+					return nil, fmt.Errorf("'%s' needs a window of 1-24 hours, e.g. %s4H", token, kind)
+				}
+				ind = indicator{Kind: kind, Hours: hours}
+			}
+			if ind.Kind == "" {
+				return nil, fmt.Errorf("unknown indicator '%s'", token)
+			}
+		}
+		if !seen[ind.String()] {
+			seen[ind.String()] = true
+			indicators = append(indicators, ind)
+		}
+	}
+	return indicators, nil
+}
+
+// loadIndicators returns the configured indicators, or the default set if the
+// setting is missing or invalid.
+func loadIndicators() []indicator {
+	value := defaultIndicators
+	if cfg != nil && cfg.Section("Settings").HasKey("Indicators") {
+		value = cfg.Section("Settings").Key("Indicators").String()
+	}
+	indicators, err := parseIndicators(value)
+	if err != nil {
+		slog.Warn("invalid Indicators setting, using the default", "value", value, "err", err)
+		indicators, _ = parseIndicators(defaultIndicators)
+	}
+	return indicators
+}
+
+func indicatorsDisplay() string {
+	var tokens []string
+	for _, ind := range loadIndicators() {
+		tokens = append(tokens, ind.String())
+	}
+	if len(tokens) == 0 {
+		return "none"
+	}
+	return strings.Join(tokens, ",")
+}
+
+// historyWindow returns the rates of the 24h history in the last hours before
+// its newest point, oldest first.
+func historyWindow(hours int) []float64 {
+	if apiData == nil || len(apiData.History) == 0 {
+		return nil
+	}
+	cutoff := apiData.History[len(apiData.History)-1].Date - (time.Duration(hours) * time.Hour).Milliseconds()
+	var rates []float64
+	for _, p := range apiData.History {
+		if p.Date > cutoff {
+			rates = append(rates, p.Rate)
+		}
+	}
+	return rates
+}
+
+// priceColor is green when the price is above level and red when below.
+func priceColor(level float64) *color.Color {
+	if apiData.Rate > level {
+		return colorPositive
+	} else if apiData.Rate < level {
+		return colorNegative
+	}
+	return color.New(color.FgWhite)
+}
+
+// RSI and MACD are computed from the 24h history points (about 5 minutes
+// apart) with the usual periods: RSI(14) and MACD(12, 26, 9).
+const (
+	rsiPeriod        = 14
+	rsiOverbought    = 70.0
+	rsiOversold      = 30.0
+	macdFastPeriod   = 12
+	macdSlowPeriod   = 26
+	macdSignalPeriod = 9
+)
+
+// computeRSI returns Wilder's RSI over period for rates (oldest first), or
+// false if there are not enough points.
+func computeRSI(rates []float64, period int) (float64, bool) {
+	if len(rates) <= period {
+		return 0, false
+	}
+	var avgGain, avgLoss float64
+	for i := 1; i <= period; i++ {
+		change := rates[i] - rates[i-1]
+		if change > 0 {
+			avgGain += change
+		} else {
+			avgLoss -= change
+		}
+	}
+	avgGain /= float64(period)
+	avgLoss /= float64(period)
+	for i := period + 1; i < len(rates); i++ {
+		gain, loss := 0.0, 0.0
+		if change := rates[i] - rates[i-1]; change > 0 {
+			gain = change
+		} else {
+			loss = -change
+		}
+		avgGain = (avgGain*float64(period-1) + gain) / float64(period)
+		avgLoss = (avgLoss*float64(period-1) + loss) / float64(period)
+	}
+	if avgLoss == 0 {
+		if avgGain == 0 {
+			return 50, true
+		}
+		return 100, true
+	}
+	return 100 - 100/(1+avgGain/avgLoss), true
+}
+
+// ema returns the exponential moving average series of values over period,
+// seeded with the first value.
+func ema(values []float64, period int) []float64 {
+	if len(values) == 0 {
+		return nil
+	}
+	k := 2 / float64(period+1)
+	out := make([]float64, len(values))
+	out[0] = values[0]
+	for i := 1; i < len(values); i++ {
+		out[i] = values[i]*k + out[i-1]*(1-k)
+	}
+	return out
+}
+
+// computeMACD returns the latest MACD line and signal line for rates (oldest
+// first), or false if there are not enough points for the slow EMA and signal.
+func computeMACD(rates []float64) (macd, signal float64, ok bool) {
+	if len(rates) < macdSlowPeriod+macdSignalPeriod {
+		return 0, 0, false
+	}
+	fast := ema(rates, macdFastPeriod)
+	slow := ema(rates, macdSlowPeriod)
+	line := make([]float64, len(rates))
+	for i := range rates {
+		line[i] = fast[i] - slow[i]
+	}
+	// Skip the slow EMA's warm-up before smoothing the signal line.
+	signalLine := ema(line[macdSlowPeriod-1:], macdSignalPeriod)
+	return line[len(line)-1], signalLine[len(signalLine)-1], true
+}
+
+// setIndicators fills the RSI and MACD fields of data from the 24h history
+// points, sorted by date.
+func setIndicators(data *ApiDataResponse, history []HistoryPoint) {
+	rates := make([]float64, len(history))
+	for i, p := range history {
+		rates[i] = p.Rate
+	}
+	data.Rsi14, data.HasRsi = computeRSI(rates, rsiPeriod)
+	data.Macd, data.MacdSignal, data.HasMacd = computeMACD(rates)
+}
+
+// showTrendIndicators prints the configured indicators other than volatility
+// under the price. Averages are green when the price is above them and red
+// when below; RSI is red when overbought and green when oversold; MACD is
+// green above its signal line and red below it, with the histogram in
+// brackets; Bollinger bands are red above the upper band and green below the
+// lower one.
+func showTrendIndicators(indicators []indicator) {
+	for _, ind := range indicators {
+		switch ind.Kind {
+		case "SMA":
+			sma := apiData.Sma1h
+			if ind.Hours != 1 || sma <= 0 {
+				sma = 0
+				if rates := historyWindow(ind.Hours); len(rates) > 0 {
+					for _, r := range rates {
+						sma += r
+					}
+					sma /= float64(len(rates))
+				}
+			}
+			if sma > 0 {
+				writeAlignedLine(fmt.Sprintf("%dH SMA:", ind.Hours), formatMoney(sma), priceColor(sma))
+			}
+		case "EMA":
+			rates := historyWindow(24)
+			period := len(historyWindow(ind.Hours))
+			if len(rates) > 0 && period > 0 {
+				value := ema(rates, period)[len(rates)-1]
+				writeAlignedLine(fmt.Sprintf("%dH EMA:", ind.Hours), formatMoney(value), priceColor(value))
+			}
+		case "BB":
+			rates := historyWindow(ind.Hours)
+			if len(rates) < 2 {
+				continue
+			}
+			var mean, variance float64
+			for _, r := range rates {
+				mean += r
+			}
+			mean /= float64(len(rates))
+			for _, r := range rates {
+				variance += (r - mean) * (r - mean)
+			}
+			deviation := math.Sqrt(variance / float64(len(rates)))
+			lower, upper := mean-2*deviation, mean+2*deviation
+			bandColor := color.New(color.FgWhite)
+			if apiData.Rate > upper {
+				bandColor = colorNegative
+			} else if apiData.Rate < lower {
+				bandColor = colorPositive
+			}
+			writeAlignedLine(fmt.Sprintf("%dH BB:", ind.Hours), formatMoney(lower)+" - "+formatMoney(upper), bandColor)
+		case "RSI":
+			if !apiData.HasRsi {
+				continue
+			}
+			rsiColor := color.New(color.FgWhite)
+			label := ""
+			if apiData.Rsi14 >= rsiOverbought {
+				rsiColor = colorNegative
+				label = " (overbought)"
+			} else if apiData.Rsi14 <= rsiOversold {
+				rsiColor = colorPositive
+				label = " (oversold)"
+			}
+			writeAlignedLine(fmt.Sprintf("RSI (%d):", rsiPeriod), fmt.Sprintf("%.1f%s", apiData.Rsi14, label), rsiColor)
+		case "MACD":
+			if !apiData.HasMacd {
+				continue
+			}
+			histogram := apiData.Macd - apiData.MacdSignal
+			macdColor := color.New(color.FgWhite)
+			if histogram > 0 {
+				macdColor = colorPositive
+			} else if histogram < 0 {
+				macdColor = colorNegative
+			}
+			writeAlignedLineWithBrackets("MACD:", fmt.Sprintf("%.2f", apiData.Macd), fmt.Sprintf("%+.2f", histogram), macdColor, 0)
+		}
+	}
+}
+
+// showVolatilityIndicators prints the configured volatility windows. Windows
+// shorter than 24 hours are green when more volatile than the 24h average for
+// a window of that length and red when less.
+func showVolatilityIndicators(indicators []indicator) {
+	for _, ind := range indicators {
+		if ind.Kind != "VOL" {
+			continue
+		}
+		if ind.Hours == 24 {
+			showVolatility24h()
+			continue
+		}
+		rates := historyWindow(ind.Hours)
+		if len(rates) < 2 {
+			continue
+		}
+		low, high := rates[0], rates[0]
+		for _, r := range rates {
+			low, high = min(low, r), max(high, r)
+		}
+		if low <= 0 {
+			continue
+		}
+		volatility := (high - low) / low * 100
+		volColor := color.New(color.FgWhite)
+		if expected := apiData.Volatility24h * float64(ind.Hours) / 24; expected > 0 {
+			if volatility > expected {
+				volColor = colorPositive
+			} else if volatility < expected {
+				volColor = colorNegative
+			}
+		}
+		writeAlignedLine(fmt.Sprintf("%dH Volatility:", ind.Hours), fmt.Sprintf("%.2f%%", volatility), volColor)
+	}
+}
+
+// invokeIndicatorsConfig edits [Settings] Indicators.
+func invokeIndicatorsConfig(reader *bufio.Reader) {
+	colorInfo.Printf("Indicators: %s\n", indicatorsDisplay())
+	fmt.Println("Available: SMA<n>H, EMA<n>H, BB<n>H, VOL<n>H (n = 1-24 hours), RSI, MACD")
+	fmt.Printf("Enter indicators in display order, comma separated (e.g. SMA1H,EMA4H,BB2H,RSI,VOL24H), '-' for none or 'default' for %s: ", defaultIndicators)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	switch strings.ToLower(input) {
+	case "-":
+		input = ""
+	case "default":
+		input = defaultIndicators
+	}
+	indicators, err := parseIndicators(input)
+	if err != nil {
+		color.Red("Invalid indicators: %v. No changes made.", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	tokens := make([]string, len(indicators))
+	for i, ind := range indicators {
+		tokens[i] = ind.String()
+	}
+	value := strings.Join(tokens, ",")
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("Indicators").SetValue(value)
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("indicators configured", "indicators", value)
+		color.Green("Indicators set to %s.", indicatorsDisplay())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,213 @@
+package vbtc
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// badLedgerName is where malformed ledger.csv rows are moved, next to the ledger.
+const badLedgerName = "ledger.bad.csv"
+
+// badLedgerLine is a ledger.csv line that does not parse as a trade.
+type badLedgerLine struct {
+	number int
+	text   string
+	reason string
+}
+
+// writeFileAtomic fills a temporary file beside path and renames it over path,
+// so a crash or full disk mid-write leaves the old file intact.
+func writeFileAtomic(path string, write func(w io.Writer) error) error {
+	tempFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tempName := tempFile.Name()
+	if err := write(tempFile); err != nil {
+		tempFile.Close()
+		os.Remove(tempName)
+		return err
+	}
+	if err := tempFile.Sync(); err != nil {
+		tempFile.Close()
+		os.Remove(tempName)
+		return err
+	}
+	if err := tempFile.Close(); err != nil {
+		os.Remove(tempName)
+		return err
+	}
+	os.Chmod(tempName, 0644) // CreateTemp makes the file private
+	if err := os.Rename(tempName, path); err != nil {
+		os.Remove(tempName)
+		return err
+	}
+	return nil
+}
+
+// scanLedger splits ledger.csv into its header, the rows that parse and the
+// lines that do not. A missing ledger has no rows.
+func scanLedger() (header []string, good [][]string, bad []badLedgerLine, err error) {
+	data, err := os.ReadFile(ledgerFilePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil, nil
+		}
+		return nil, nil, nil, err
+	}
+	// Notes are typed on one line, so every ledger row is one line of the file.
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		reader := csv.NewReader(strings.NewReader(line))
+		reader.FieldsPerRecord = -1
+		record, readErr := reader.Read()
+		if header == nil && readErr == nil && len(record) > 0 && record[0] == ledgerHeader[0] {
+			header = record
+			continue
+		}
+		reason := ""
+		if readErr != nil {
+			reason = "not valid CSV"
+		} else {
+			reason = checkLedgerRecord(record)
+		}
+		if reason != "" {
+			bad = append(bad, badLedgerLine{number: i + 1, text: line, reason: reason})
+			continue
+		}
+		good = append(good, record)
+	}
+	if header == nil {
+		header = ledgerHeader
+	}
+	return header, good, bad, nil
+}
+
+// checkLedgerRecord returns why record is not a usable trade, or "" if it is.
+func checkLedgerRecord(record []string) string {
+	if len(record) < 6 {
+		return fmt.Sprintf("%d fields, expected at least 6", len(record))
+	}
+	if strings.TrimSpace(record[0]) == "" {
+		return "TX is empty"
+	}
+	for i := 1; i <= 4; i++ {
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(record[i], ",", ""), 64); err != nil {
+			return fmt.Sprintf("%s '%s' is not a number", ledgerHeader[i], record[i])
+		}
+	}
+	if _, err := time.ParseInLocation("010206@150405", record[5], time.UTC); err != nil {
+		return fmt.Sprintf("Time '%s' is not MMddyy@HHmmss", record[5])
+	}
+	if len(record) > 7 && strings.TrimSpace(record[7]) != "" {
+		if _, err := strconv.ParseFloat(strings.ReplaceAll(record[7], ",", ""), 64); err != nil {
+			return fmt.Sprintf("Fee '%s' is not a number", record[7])
+		}
+	}
+	return ""
+}
+
+// checkLedgerIntegrity looks for malformed rows in ledger.csv at startup and
+// offers to move them to ledger.bad.csv, since the ledger views would
+// otherwise skip them or read their amounts as zero.
+func checkLedgerIntegrity(reader *bufio.Reader) {
+	_, good, bad, err := scanLedger()
+	if err != nil {
+		slog.Error("ledger integrity check failed", "path", ledgerFilePath, "err", err)
+		color.Red("Could not read ledger.csv: %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	if len(bad) == 0 {
+		return
+	}
+	slog.Warn("malformed ledger rows", "path", ledgerFilePath, "rows", len(bad))
+
+	clearScreen()
+	color.Yellow("*** Ledger Check ***")
+	fmt.Printf("\nledger.csv has %d malformed row(s):\n\n", len(bad))
+	const shown = 10
+	for i, line := range bad {
+		if i == shown {
+			fmt.Printf("  ...and %d more\n", len(bad)-shown)
+			break
+		}
+		fmt.Printf("  Line %d: %s\n", line.number, line.reason)
+	}
+	fmt.Printf("\nMove them to %s and keep the other %d transaction(s)? (y/n): ", badLedgerName, len(good))
+	confirm, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		color.Yellow("Ledger left unchanged. Malformed rows are skipped or read as zero.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	if err := quarantineLedgerLines(); err != nil {
+		slog.Error("ledger quarantine failed", "path", ledgerFilePath, "err", err)
+		color.Red("Could not quarantine the malformed rows: %v", err)
+		color.Red("Ledger left unchanged.")
+	} else {
+		color.Green("Moved %d row(s) to %s.", len(bad), badLedgerName)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// quarantineLedgerLines appends the malformed lines to ledger.bad.csv as they
+// appeared in ledger.csv, then rewrites ledger.csv without them. The ledger is
+// scanned again under the lock so a trade from another session is not lost.
+func quarantineLedgerLines() error {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	header, good, bad, err := scanLedger()
+	if err != nil {
+		return err
+	}
+	if len(bad) == 0 {
+		return nil
+	}
+
+	ledgerAbs, _ := filepath.Abs(ledgerFilePath)
+	badPath := filepath.Join(filepath.Dir(ledgerAbs), badLedgerName)
+	file, err := os.OpenFile(badPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, _ := file.Stat()
+	writer := bufio.NewWriter(file)
+	if info.Size() == 0 {
+		csvWriter := csv.NewWriter(writer)
+		csvWriter.Write(header)
+		csvWriter.Flush()
+	}
+	for _, line := range bad {
+		writer.WriteString(line.text + "\n")
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	slog.Info("ledger rows quarantined", "path", badPath, "rows", len(bad))
+	return writeLedgerRaw(header, good)
+}
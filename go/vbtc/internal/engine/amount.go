@@ -0,0 +1,91 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Knetic/govaluate"
+)
+
+// ErrSatoshiBuy is returned for a buy amount given in satoshis.
+var ErrSatoshiBuy = errors.New("satoshi amounts ('s') are for selling")
+
+// ParseAmount reads a trade amount: a number or arithmetic expression
+// ("(1000-250)/2"), a percentage of maxAmount with a 'p' suffix ("100/3p"), or
+// for sells satoshis with an 's' suffix ("50000*3s"). Commas are ignored.
+// Percentages are rounded down to the cent for buys and to the satoshi for
+// sells, so they never come to more than maxAmount.
+func ParseAmount(input string, maxAmount float64, side string) (float64, error) {
+	input = strings.TrimSpace(input)
+	input = strings.ReplaceAll(input, ",", "") // Allow commas
+
+	// Percentage
+	if strings.HasSuffix(input, "p") {
+		percentVal, err := EvaluateAmount(strings.TrimSuffix(input, "p"))
+		if err != nil {
+			return 0, err
+		}
+		if percentVal <= 0 || percentVal > 100 {
+			return 0, fmt.Errorf("a percentage must be more than 0 and at most 100, not %s", strconv.FormatFloat(percentVal, 'f', -1, 64))
+		}
+		calculatedAmount := (maxAmount * percentVal) / 100
+		if side == "Sell" {
+			return floorTo(calculatedAmount, 1e8), nil // Truncate for BTC
+		}
+		return floorTo(calculatedAmount, 100), nil // Truncate for USD
+	}
+
+	// Satoshis
+	if strings.HasSuffix(input, "s") {
+		if side == "Buy" {
+			return 0, ErrSatoshiBuy
+		}
+		satoshiVal, err := EvaluateAmount(strings.TrimSuffix(input, "s"))
+		if err != nil {
+			return 0, err
+		}
+		return math.Floor(satoshiVal) / 1e8, nil // Whole satoshis
+	}
+
+	// Plain number or expression
+	return EvaluateAmount(input)
+}
+
+// floorTo rounds v down to a multiple of 1/scale, ignoring float noise just
+// under it: 0.12345678*1e8 is 12345677.999..., which must not lose a satoshi.
+func floorTo(v, scale float64) float64 {
+	return math.Floor(v*scale+1e-6) / scale
+}
+
+// EvaluateAmount evaluates a number or an arithmetic expression such as
+// "0.05*3" with govaluate.
+func EvaluateAmount(input string) (float64, error) {
+	if input == "" {
+		return 0, fmt.Errorf("no amount given")
+	}
+	if amount, err := strconv.ParseFloat(input, 64); err == nil {
+		return amount, nil
+	}
+	expression, err := govaluate.NewEvaluableExpression(input)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a valid expression (%v)", input, err)
+	}
+	if len(expression.Vars()) > 0 {
+		return 0, fmt.Errorf("'%s' is not a number or arithmetic expression", input)
+	}
+	result, err := expression.Evaluate(nil)
+	if err != nil {
+		return 0, fmt.Errorf("could not evaluate '%s' (%v)", input, err)
+	}
+	amount, ok := result.(float64)
+	if !ok {
+		return 0, fmt.Errorf("'%s' does not evaluate to a number", input)
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("'%s' does not evaluate to a finite number (division by zero?)", input)
+	}
+	return math.Round(amount*1e10) / 1e10, nil // drop float noise such as 0.15000000000000002
+}
@@ -0,0 +1,82 @@
+package engine
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		max     float64
+		side    string
+		want    float64
+		wantErr bool
+	}{
+		{"plain number", "250", 1000, "Buy", 250, false},
+		{"commas", "1,250.50", 5000, "Buy", 1250.5, false},
+		{"expression", "(1000-250)/2", 1000, "Buy", 375, false},
+		{"float noise dropped", "0.05*3", 1, "Sell", 0.15, false},
+		{"percent of cash", "50p", 1000, "Buy", 500, false},
+		{"percent rounds down to the cent", "100/3p", 1000, "Buy", 333.33, false},
+		{"percent rounds down to the satoshi", "100/3p", 0.1, "Sell", 0.03333333, false},
+		{"whole balance", "100p", 0.12345678, "Sell", 0.12345678, false},
+		{"percent over 100", "101p", 1000, "Buy", 0, true},
+		{"zero percent", "0p", 1000, "Buy", 0, true},
+		{"satoshis", "50000s", 1, "Sell", 0.0005, false},
+		{"satoshi expression", "50000*3s", 1, "Sell", 0.0015, false},
+		{"fractional satoshis dropped", "1234.9s", 1, "Sell", 0.00001234, false},
+		{"empty", "", 1000, "Buy", 0, true},
+		{"empty percent", "p", 1000, "Buy", 0, true},
+		{"division by zero", "1/0", 1000, "Buy", 0, true},
+		{"variables", "all", 1000, "Buy", 0, true},
+		{"not an expression", "5+", 1000, "Buy", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.input, tt.max, tt.side)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAmount(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAmountSatoshiBuy(t *testing.T) {
+	if _, err := ParseAmount("50000s", 1000, "Buy"); !errors.Is(err, ErrSatoshiBuy) {
+		t.Errorf("ParseAmount satoshi buy error = %v, want ErrSatoshiBuy", err)
+	}
+}
+
+func TestEvaluateAmount(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{"42", 42, false},
+		{"-5", -5, false},
+		{"2*(3+4)", 14, false},
+		{"0.1+0.2", 0.3, false},
+		{"10/4", 2.5, false},
+		{"1/0", 0, true},
+		{"0/0", 0, true},
+		{"", 0, true},
+		{"x*2", 0, true},
+		{"'a'", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := EvaluateAmount(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("EvaluateAmount(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("EvaluateAmount(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
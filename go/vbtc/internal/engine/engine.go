@@ -0,0 +1,32 @@
+// Package engine holds vBTC's trading math: reading trade amounts, pricing
+// trades with fees and spread, moving balances and cost basis, valuing a
+// portfolio, and converting ledger rows. It does no terminal I/O and reads no
+// configuration. The clock, market prices and ledger storage are passed in,
+// so every calculation can be run without a terminal, network or vbtc.ini.
+package engine
+
+import "time"
+
+// Clock tells the engine the time, for dating ledger rows.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock.
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// PriceSource returns the market rate of a coin in the base currency, or 0
+// when it is not known.
+type PriceSource interface {
+	Rate(coin string) float64
+}
+
+// Storage holds the ledger rows.
+type Storage interface {
+	// ReadLedger returns every row, header included; none if there is no ledger yet.
+	ReadLedger() ([][]string, error)
+	// AppendLedger adds row at the end, writing LedgerHeader first to an empty ledger.
+	AppendLedger(row []string) error
+}
@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LedgerTimeFormat is the layout of the ledger's Time column, in UTC.
+const LedgerTimeFormat = "010206@150405"
+
+// LedgerHeader names the ledger columns. The BTC, BTC(USD) and User BTC
+// columns hold the traded coin's amount, rate and balance; rows written
+// before multi-coin support, fees or notes stop after Time, Coin or Fee.
+var LedgerHeader = []string{"TX", "USD", "BTC", "BTC(USD)", "User BTC", "Time", "Coin", "Fee", "Note", "Tags"}
+
+// LedgerEntry is one ledger row.
+type LedgerEntry struct {
+	TX       string
+	USD      float64
+	BTC      float64
+	BTCPrice float64
+	UserBTC  float64
+	Time     string
+	DateTime time.Time
+	Coin     string // BTC for ledgers written before multi-coin support
+	Fee      float64
+	Note     string
+	Tags     string // space-separated, e.g. "#dip #strategy1"
+}
+
+// ErrShortRow is returned for a ledger row with fewer than six columns.
+var ErrShortRow = errors.New("short ledger row")
+
+// ParseLedgerRecord converts a ledger row to an entry. A row whose Time does
+// not parse is still returned, with a zero DateTime, along with the error.
+func ParseLedgerRecord(record []string) (LedgerEntry, error) {
+	if len(record) < 6 {
+		return LedgerEntry{}, ErrShortRow
+	}
+	usd, _ := strconv.ParseFloat(strings.ReplaceAll(record[1], ",", ""), 64)
+	btc, _ := strconv.ParseFloat(strings.ReplaceAll(record[2], ",", ""), 64)
+	btcPrice, _ := strconv.ParseFloat(strings.ReplaceAll(record[3], ",", ""), 64)
+	userBTC, _ := strconv.ParseFloat(strings.ReplaceAll(record[4], ",", ""), 64)
+	coin := "BTC"
+	if len(record) > 6 && strings.TrimSpace(record[6]) != "" {
+		coin = strings.ToUpper(strings.TrimSpace(record[6]))
+	}
+	var fee float64
+	if len(record) > 7 {
+		fee, _ = strconv.ParseFloat(strings.ReplaceAll(record[7], ",", ""), 64)
+	}
+	entry := LedgerEntry{
+		TX: record[0], USD: usd, BTC: btc,
+		BTCPrice: btcPrice, UserBTC: userBTC, Time: record[5], Coin: coin, Fee: fee,
+	}
+	if len(record) > 8 {
+		entry.Note = record[8]
+	}
+	if len(record) > 9 {
+		entry.Tags = record[9]
+	}
+	dateTime, err := time.ParseInLocation(LedgerTimeFormat, record[5], time.UTC)
+	if err != nil {
+		return entry, fmt.Errorf("could not parse timestamp '%s': %w", record[5], err)
+	}
+	entry.DateTime = dateTime
+	return entry, nil
+}
+
+// Record returns the entry as a ledger row.
+func (e LedgerEntry) Record() []string {
+	return []string{
+		e.TX,
+		fmt.Sprintf("%.2f", e.USD),
+		fmt.Sprintf("%.8f", e.BTC),
+		fmt.Sprintf("%.2f", e.BTCPrice),
+		fmt.Sprintf("%.8f", e.UserBTC),
+		e.Time,
+		e.Coin,
+		fmt.Sprintf("%.2f", e.Fee),
+		e.Note,
+		e.Tags,
+	}
+}
+
+// NoteText is the note and tags as shown in the ledger table.
+func (e LedgerEntry) NoteText() string {
+	return strings.TrimSpace(e.Note + " " + e.Tags)
+}
+
+// MatchesSearch reports whether every word of query appears in the entry's
+// note, tags, TX or coin (case-insensitive). "#tag" only matches tags.
+func (e LedgerEntry) MatchesSearch(query string) bool {
+	text := strings.ToLower(strings.Join([]string{e.Note, e.TX, e.Coin}, " "))
+	tags := " " + e.Tags + " "
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		if strings.HasPrefix(word, "#") {
+			if !strings.Contains(tags, " "+word+" ") {
+				return false
+			}
+		} else if !strings.Contains(text, word) && !strings.Contains(tags, word) {
+			return false
+		}
+	}
+	return true
+}
+
+// Ledger reads and appends trades through a Storage.
+type Ledger struct {
+	Store Storage
+	Clock Clock
+}
+
+// Append dates entry with the ledger's clock, writes it and returns it as written.
+func (l Ledger) Append(entry LedgerEntry) (LedgerEntry, error) {
+	now := l.Clock.Now().UTC()
+	entry.Time = now.Format(LedgerTimeFormat)
+	entry.DateTime = now.Truncate(time.Second)
+	if err := l.Store.AppendLedger(entry.Record()); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+// Entries returns every row after the header that parses. Rows with an
+// unreadable Time are kept with a zero DateTime; short rows are skipped.
+func (l Ledger) Entries() ([]LedgerEntry, error) {
+	records, err := l.Store.ReadLedger()
+	if err != nil || len(records) <= 1 {
+		return nil, err
+	}
+	var entries []LedgerEntry
+	for _, record := range records[1:] {
+		entry, err := ParseLedgerRecord(record)
+		if errors.Is(err, ErrShortRow) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
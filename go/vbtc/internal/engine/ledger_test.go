@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseLedgerRecord(t *testing.T) {
+	tradeTime := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name    string
+		record  []string
+		want    LedgerEntry
+		wantErr bool
+	}{
+		{
+			name:   "six columns from before multi-coin support",
+			record: []string{"Buy", "1,000.00", "0.01000000", "100000.00", "0.01000000", "010225@150405"},
+			want: LedgerEntry{TX: "Buy", USD: 1000, BTC: 0.01, BTCPrice: 100000, UserBTC: 0.01,
+				Time: "010225@150405", DateTime: tradeTime, Coin: "BTC"},
+		},
+		{
+			name:   "seven columns with a coin",
+			record: []string{"Sell", "300.00", "0.10000000", "3000.00", "0.00000000", "010225@150405", " eth "},
+			want: LedgerEntry{TX: "Sell", USD: 300, BTC: 0.1, BTCPrice: 3000,
+				Time: "010225@150405", DateTime: tradeTime, Coin: "ETH"},
+		},
+		{
+			name:   "eight columns with a fee",
+			record: []string{"Buy", "500.00", "0.00500000", "99000.00", "0.00500000", "010225@150405", "", "2.50"},
+			want: LedgerEntry{TX: "Buy", USD: 500, BTC: 0.005, BTCPrice: 99000, UserBTC: 0.005,
+				Time: "010225@150405", DateTime: tradeTime, Coin: "BTC", Fee: 2.5},
+		},
+		{
+			name:   "ten columns with a note and tags",
+			record: []string{"Buy", "10.00", "0.00010000", "100000.00", "0.00010000", "010225@150405", "BTC", "0.00", "dip", "#dca"},
+			want: LedgerEntry{TX: "Buy", USD: 10, BTC: 0.0001, BTCPrice: 100000, UserBTC: 0.0001,
+				Time: "010225@150405", DateTime: tradeTime, Coin: "BTC", Note: "dip", Tags: "#dca"},
+		},
+		{
+			name:    "unreadable time keeps the row",
+			record:  []string{"Buy", "10.00", "0.00010000", "100000.00", "0.00010000", "yesterday"},
+			want:    LedgerEntry{TX: "Buy", USD: 10, BTC: 0.0001, BTCPrice: 100000, UserBTC: 0.0001, Time: "yesterday", Coin: "BTC"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLedgerRecord(tt.record)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLedgerRecord error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLedgerRecord = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseLedgerRecord([]string{"Buy", "1", "1", "1", "1"}); !errors.Is(err, ErrShortRow) {
+		t.Errorf("five columns error = %v, want ErrShortRow", err)
+	}
+}
+
+// memoryStorage is a Storage held in memory.
+type memoryStorage struct {
+	rows [][]string
+	err  error
+}
+
+func (s *memoryStorage) ReadLedger() ([][]string, error) {
+	return s.rows, s.err
+}
+
+func (s *memoryStorage) AppendLedger(row []string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.rows) == 0 {
+		s.rows = append(s.rows, LedgerHeader)
+	}
+	s.rows = append(s.rows, row)
+	return nil
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestLedgerAppendAndEntries(t *testing.T) {
+	local := time.FixedZone("UTC-5", -5*60*60)
+	clock := fixedClock(time.Date(2025, 3, 4, 7, 8, 9, 500, local))
+	store := &memoryStorage{}
+	ledger := Ledger{Store: store, Clock: clock}
+
+	written, err := ledger.Append(LedgerEntry{TX: "Buy", USD: 100, BTC: 0.001, BTCPrice: 100000, UserBTC: 0.001, Coin: "BTC", Note: "first"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written.Time != "030425@120809" {
+		t.Errorf("Append dated the row %q, want 030425@120809 (UTC)", written.Time)
+	}
+	if _, err := ledger.Append(LedgerEntry{TX: "Sell", USD: 110, BTC: 0.001, BTCPrice: 110000, Coin: "BTC"}); err != nil {
+		t.Fatal(err)
+	}
+	store.rows = append(store.rows, []string{"Buy", "short"})
+
+	if len(store.rows) != 4 || !reflect.DeepEqual(store.rows[0], LedgerHeader) {
+		t.Fatalf("storage rows = %v, want the header, two trades and the short row", store.rows)
+	}
+	entries, err := ledger.Entries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Entries returned %d rows, want 2 (short rows skipped)", len(entries))
+	}
+	if !reflect.DeepEqual(entries[0], written) {
+		t.Errorf("Entries()[0] = %+v, want the appended %+v", entries[0], written)
+	}
+	if entries[1].TX != "Sell" || entries[1].USD != 110 || entries[1].UserBTC != 0 {
+		t.Errorf("Entries()[1] = %+v, want the sell", entries[1])
+	}
+}
+
+func TestLedgerEmptyAndFailingStorage(t *testing.T) {
+	clock := fixedClock(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	entries, err := Ledger{Store: &memoryStorage{}, Clock: clock}.Entries()
+	if err != nil || entries != nil {
+		t.Errorf("Entries on an empty ledger = %v, %v; want none", entries, err)
+	}
+
+	failing := errors.New("disk full")
+	ledger := Ledger{Store: &memoryStorage{err: failing}, Clock: clock}
+	if _, err := ledger.Append(LedgerEntry{TX: "Buy"}); !errors.Is(err, failing) {
+		t.Errorf("Append error = %v, want %v", err, failing)
+	}
+	if _, err := ledger.Entries(); !errors.Is(err, failing) {
+		t.Errorf("Entries error = %v, want %v", err, failing)
+	}
+}
@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNonPositiveTrade is returned by Apply for a trade that does not move a
+// positive amount of both cash and coin.
+var ErrNonPositiveTrade = errors.New("trade amounts must be more than zero")
+
+// Position is the cash balance with one coin's balance and the capital
+// invested in it (its cost basis).
+type Position struct {
+	Cash     float64
+	Coin     float64
+	Invested float64
+}
+
+// Apply returns the position after a trade that moved usd in cash and coin
+// of the coin. A buy adds its cost to Invested; a sell reduces Invested in
+// proportion to the coin sold, and clears it when the balance is sold out.
+// Amounts of zero or less are rejected with ErrNonPositiveTrade.
+func (p Position) Apply(side string, usd, coin float64) (Position, error) {
+	if usd <= 0 || coin <= 0 {
+		return p, fmt.Errorf("%w: %s of %v for %v", ErrNonPositiveTrade, side, coin, usd)
+	}
+	after := p
+	if side == "Buy" {
+		after.Cash = p.Cash - usd
+		after.Coin = p.Coin + coin
+		after.Invested = p.Invested + usd
+		return after, nil
+	}
+	after.Cash = p.Cash + usd
+	after.Coin = p.Coin - coin
+	switch {
+	case after.Coin < 1e-9: // Tolerance for float comparison
+		after.Coin = 0
+		after.Invested = 0
+	case p.Coin > 0:
+		after.Invested = p.Invested * (after.Coin / p.Coin)
+	default:
+		after.Invested = 0
+	}
+	return after, nil
+}
+
+// Value returns cash plus every holding at its market rate. Holdings with no
+// known rate count as nothing.
+func Value(cash float64, holdings map[string]float64, prices PriceSource) float64 {
+	value := cash
+	if prices == nil {
+		return value
+	}
+	for coin, balance := range holdings {
+		value += balance * prices.Rate(coin)
+	}
+	return value
+}
@@ -0,0 +1,106 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestPositionApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		start Position
+		side  string
+		usd   float64
+		coin  float64
+		want  Position
+	}{
+		{
+			name:  "buy",
+			start: Position{Cash: 1000},
+			side:  "Buy", usd: 400, coin: 0.01,
+			want: Position{Cash: 600, Coin: 0.01, Invested: 400},
+		},
+		{
+			name:  "buy adds to the cost basis",
+			start: Position{Cash: 600, Coin: 0.01, Invested: 400},
+			side:  "Buy", usd: 100, coin: 0.002,
+			want: Position{Cash: 500, Coin: 0.012, Invested: 500},
+		},
+		{
+			name:  "partial sell reduces invested in proportion",
+			start: Position{Cash: 500, Coin: 0.02, Invested: 800},
+			side:  "Sell", usd: 450, coin: 0.005,
+			want: Position{Cash: 950, Coin: 0.015, Invested: 600},
+		},
+		{
+			name:  "sell out clears invested",
+			start: Position{Cash: 0, Coin: 0.1, Invested: 5000},
+			side:  "Sell", usd: 6000, coin: 0.1,
+			want: Position{Cash: 6000},
+		},
+		{
+			name:  "float dust left by a sell out counts as sold out",
+			start: Position{Cash: 0, Coin: 0.3, Invested: 900},
+			side:  "Sell", usd: 1200, coin: 0.1 + 0.2,
+			want: Position{Cash: 1200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.start.Apply(tt.side, tt.usd, tt.coin)
+			if err != nil {
+				t.Fatalf("Apply(%s, %v, %v): %v", tt.side, tt.usd, tt.coin, err)
+			}
+			if !closePosition(got, tt.want) {
+				t.Errorf("Apply(%s, %v, %v) = %+v, want %+v", tt.side, tt.usd, tt.coin, got, tt.want)
+			}
+		})
+	}
+}
+
+func closePosition(a, b Position) bool {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	return near(a.Cash, b.Cash) && near(a.Coin, b.Coin) && near(a.Invested, b.Invested)
+}
+
+type fixedPrices map[string]float64
+
+func (p fixedPrices) Rate(coin string) float64 { return p[coin] }
+
+func TestPositionApplyRejectsNonPositive(t *testing.T) {
+	start := Position{Cash: 100, Invested: 50}
+	tests := []struct {
+		name      string
+		side      string
+		usd, coin float64
+	}{
+		{"sell with a negative coin amount", "Sell", 10, -0.001},
+		{"buy with a negative coin amount", "Buy", 10, -0.001},
+		{"zero coin", "Buy", 10, 0},
+		{"zero cash", "Sell", 0, 0.001},
+		{"negative cash", "Buy", -10, 0.001},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := start.Apply(tt.side, tt.usd, tt.coin)
+			if !errors.Is(err, ErrNonPositiveTrade) {
+				t.Errorf("Apply(%s, %v, %v) error = %v, want ErrNonPositiveTrade", tt.side, tt.usd, tt.coin, err)
+			}
+			if got != start {
+				t.Errorf("Apply(%s, %v, %v) = %+v, want the position unchanged", tt.side, tt.usd, tt.coin, got)
+			}
+		})
+	}
+}
+
+func TestValue(t *testing.T) {
+	prices := fixedPrices{"BTC": 60000, "ETH": 3000}
+	holdings := map[string]float64{"BTC": 0.5, "ETH": 2, "DOGE": 1000}
+	if got := Value(100, holdings, prices); got != 36100 {
+		t.Errorf("Value = %v, want 36100", got)
+	}
+	if got := Value(100, holdings, nil); got != 100 {
+		t.Errorf("Value without prices = %v, want 100", got)
+	}
+}
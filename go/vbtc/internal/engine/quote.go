@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Quote is the result of executing a trade of a given size at a given rate.
+type Quote struct {
+	USD  float64 // cash moved: spent on a buy (fee included), received on a sell (fee deducted)
+	Coin float64 // coin bought or sold
+	Fee  float64 // in the base currency
+	Rate float64 // execution rate
+}
+
+// Costs are what a trade pays beyond the market rate. Fees always come out
+// of the cash side: a buy of $100 with a $1 fee buys $99 of coin, a sell
+// worth $100 with a $1 fee credits $99.
+type Costs struct {
+	FeePercent    float64 // percent of the trade's value
+	FeeFlat       float64 // per trade
+	SpreadPercent float64 // full bid/ask width; buys pay half above the rate, sells get half below
+}
+
+// ErrNoRate is returned when a trade is quoted without a market rate.
+var ErrNoRate = errors.New("no market rate available")
+
+// FeeTooHighError is returned when the fee would take the whole trade.
+type FeeTooHighError struct {
+	Side  string
+	Fee   float64
+	Value float64 // the trade's value before the fee
+}
+
+func (e *FeeTooHighError) Error() string {
+	if e.Side == "Buy" {
+		return fmt.Sprintf("the %.2f fee exceeds the %.2f trade", e.Fee, e.Value)
+	}
+	return fmt.Sprintf("the %.2f fee exceeds the %.2f sale", e.Fee, e.Value)
+}
+
+// Fee returns the fee on a trade worth value, rounded to the cent.
+func (c Costs) Fee(value float64) float64 {
+	percent, flat := math.Max(c.FeePercent, 0), math.Max(c.FeeFlat, 0)
+	if percent == 0 && flat == 0 {
+		return 0
+	}
+	return math.Round((value*percent/100+flat)*100) / 100
+}
+
+// ExecutionRate returns the price a trade on side actually gets at mid-market rate.
+func (c Costs) ExecutionRate(side string, rate float64) float64 {
+	half := math.Max(c.SpreadPercent, 0) / 200
+	if side == "Buy" {
+		return rate * (1 + half)
+	}
+	return rate * (1 - half)
+}
+
+// Quote prices a trade at the mid-market rate, applying the spread and fees.
+// For buys amount is the cash to spend (fee included); for sells it is the
+// coin amount. Coin is rounded down to the satoshi and sale proceeds to the
+// cent.
+func (c Costs) Quote(side string, amount, rate float64) (Quote, error) {
+	if rate <= 0 {
+		return Quote{}, ErrNoRate
+	}
+	q := Quote{Rate: c.ExecutionRate(side, rate)}
+	if side == "Buy" {
+		q.USD = amount
+		q.Fee = c.Fee(amount)
+		if q.Fee >= amount {
+			return Quote{}, &FeeTooHighError{Side: side, Fee: q.Fee, Value: amount}
+		}
+		q.Coin = math.Floor(((amount-q.Fee)/q.Rate)*1e8) / 1e8
+		return q, nil
+	}
+	q.Coin = amount
+	gross := math.Floor((amount*q.Rate)*100) / 100
+	q.Fee = c.Fee(gross)
+	if q.Fee >= gross {
+		return Quote{}, &FeeTooHighError{Side: side, Fee: q.Fee, Value: gross}
+	}
+	q.USD = gross - q.Fee
+	return q, nil
+}
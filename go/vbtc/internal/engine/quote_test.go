@@ -0,0 +1,79 @@
+package engine
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCostsFee(t *testing.T) {
+	tests := []struct {
+		name  string
+		costs Costs
+		value float64
+		want  float64
+	}{
+		{"no fees", Costs{}, 1000, 0},
+		{"percent", Costs{FeePercent: 0.5}, 1000, 5},
+		{"flat", Costs{FeeFlat: 1.99}, 1000, 1.99},
+		{"percent and flat", Costs{FeePercent: 0.1, FeeFlat: 1}, 2500, 3.5},
+		{"rounded to the cent", Costs{FeePercent: 0.25}, 333.33, 0.83},
+		{"negative settings ignored", Costs{FeePercent: -1, FeeFlat: -5}, 1000, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.costs.Fee(tt.value); got != tt.want {
+				t.Errorf("Fee(%v) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCostsExecutionRate(t *testing.T) {
+	tests := []struct {
+		name   string
+		spread float64
+		side   string
+		want   float64
+	}{
+		{"no spread buy", 0, "Buy", 60000},
+		{"no spread sell", 0, "Sell", 60000},
+		{"buy pays half above", 1, "Buy", 60300},
+		{"sell gets half below", 1, "Sell", 59700},
+		{"negative spread ignored", -2, "Buy", 60000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Costs{SpreadPercent: tt.spread}.ExecutionRate(tt.side, 60000)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("ExecutionRate(%s) = %v, want %v", tt.side, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCostsQuote(t *testing.T) {
+	costs := Costs{FeeFlat: 1}
+	buy, err := costs.Quote("Buy", 101, 50000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buy.USD != 101 || buy.Fee != 1 || buy.Coin != 0.002 {
+		t.Errorf("buy quote = %+v, want 101 USD, 1 fee, 0.002 coin", buy)
+	}
+	sell, err := costs.Quote("Sell", 0.002, 50000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sell.USD != 99 || sell.Fee != 1 || sell.Coin != 0.002 {
+		t.Errorf("sell quote = %+v, want 99 USD, 1 fee, 0.002 coin", sell)
+	}
+
+	if _, err := costs.Quote("Buy", 100, 0); !errors.Is(err, ErrNoRate) {
+		t.Errorf("quote without a rate error = %v, want ErrNoRate", err)
+	}
+	var feeErr *FeeTooHighError
+	if _, err := (Costs{FeeFlat: 5}).Quote("Buy", 5, 50000); !errors.As(err, &feeErr) {
+		t.Errorf("fee over the trade error = %v, want FeeTooHighError", err)
+	}
+}
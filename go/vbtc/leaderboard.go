@@ -0,0 +1,267 @@
+package vbtc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// The leaderboard is opt-in: [Settings] Leaderboard holds a file path (e.g. on
+// a network share) or an http(s) URL, and [Settings] PlayerName the name to
+// post under. Each exit posts the portfolio value, replacing that player's
+// previous score.
+//
+// A file holds {"players": [...]} and is rewritten under a lock on
+// <file>.lock. A URL must answer GET with the same JSON and accept a POST of
+// one player entry.
+const leaderboardLockTimeout = 5 * time.Second
+
+type leaderboardEntry struct {
+	Name     string    `json:"name"`
+	Value    float64   `json:"value"`
+	Currency string    `json:"currency"`
+	Updated  time.Time `json:"updated"`
+}
+
+type leaderboardFile struct {
+	Players []leaderboardEntry `json:"players"`
+}
+
+func leaderboardLocation() string {
+	if cfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Section("Settings").Key("Leaderboard").String())
+}
+
+func leaderboardPlayer() string {
+	if cfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Section("Settings").Key("PlayerName").String())
+}
+
+func isLeaderboardURL(location string) bool {
+	lower := strings.ToLower(location)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+func leaderboardDisplay() string {
+	location, player := leaderboardLocation(), leaderboardPlayer()
+	if location == "" || player == "" {
+		return "off"
+	}
+	return player
+}
+
+// loadLeaderboard returns the players on the leaderboard, best value first.
+func loadLeaderboard(location string) ([]leaderboardEntry, error) {
+	var board leaderboardFile
+	if isLeaderboardURL(location) {
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Get(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch leaderboard: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("leaderboard returned status %d", resp.StatusCode)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&board); err != nil {
+			return nil, fmt.Errorf("leaderboard returned invalid JSON: %w", err)
+		}
+	} else {
+		data, err := os.ReadFile(location)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			if err := json.Unmarshal(data, &board); err != nil {
+				return nil, fmt.Errorf("%s is not a leaderboard file: %w", location, err)
+			}
+		}
+	}
+	sort.SliceStable(board.Players, func(i, j int) bool { return board.Players[i].Value > board.Players[j].Value })
+	return board.Players, nil
+}
+
+// postLeaderboardScore records entry on the leaderboard at location.
+func postLeaderboardScore(location string, entry leaderboardEntry) error {
+	if isLeaderboardURL(location) {
+		body, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Post(location, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to post score: %w", err)
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("leaderboard returned status %d", resp.StatusCode)
+		}
+		return nil
+	}
+
+	lock, err := waitForFileLock(location+".lock", leaderboardLockTimeout)
+	if err == errLockTimeout {
+		return fmt.Errorf("the leaderboard is busy; try again")
+	}
+	if err != nil {
+		return err
+	}
+	defer func() {
+		unlockFile(lock)
+		lock.Close()
+	}()
+	players, err := loadLeaderboard(location)
+	if err != nil {
+		return err
+	}
+	board := leaderboardFile{Players: []leaderboardEntry{entry}}
+	for _, p := range players {
+		if !strings.EqualFold(p.Name, entry.Name) {
+			board.Players = append(board.Players, p)
+		}
+	}
+	sort.SliceStable(board.Players, func(i, j int) bool { return board.Players[i].Value > board.Players[j].Value })
+	data, err := json.MarshalIndent(board, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(location, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+}
+
+// postExitScore posts the portfolio value from the exit screen when the
+// leaderboard is set up, and prints whether it worked.
+func postExitScore(value float64) {
+	location, player := leaderboardLocation(), leaderboardPlayer()
+	if location == "" || player == "" || replaySource != nil {
+		return
+	}
+	entry := leaderboardEntry{Name: player, Value: math.Round(value*100) / 100, Currency: currencyCode(), Updated: time.Now().UTC()}
+	if err := postLeaderboardScore(location, entry); err != nil {
+		slog.Warn("leaderboard post failed", "location", location, "err", err)
+		color.Yellow("Leaderboard: could not post your score: %v", err)
+		return
+	}
+	slog.Info("leaderboard score posted", "player", player, "value", entry.Value)
+	writeAlignedLine("Leaderboard:", "Posted as "+player, color.New(color.FgWhite))
+}
+
+// showLeaderboardScreen lists the players on the configured leaderboard.
+func showLeaderboardScreen(reader *bufio.Reader) {
+	clearScreen()
+	color.Yellow("*** Leaderboard ***")
+	fmt.Println()
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+
+	location := leaderboardLocation()
+	if location == "" {
+		fmt.Println("No leaderboard is set up. Choose Leaderboard in the Config menu to join one.")
+		fmt.Println()
+		return
+	}
+	players, err := loadLeaderboard(location)
+	if err != nil {
+		color.Red("Could not read the leaderboard: %v", err)
+		fmt.Println()
+		return
+	}
+	if len(players) == 0 {
+		fmt.Println("No scores posted yet. Yours is posted when you exit.")
+		fmt.Println()
+		return
+	}
+
+	player := leaderboardPlayer()
+	colorInfo.Printf("%-4s  %-20s  %16s  %-16s\n", "Rank", "Player", "Value", "Updated")
+	for i, p := range players {
+		rowColor := color.New(color.FgWhite)
+		if strings.EqualFold(p.Name, player) {
+			rowColor = colorPositive
+		}
+		value := formatFloat(p.Value, 2) + " " + p.Currency
+		if c, ok := lookupCurrency(p.Currency); ok {
+			value = c.Symbol + formatFloat(p.Value, c.Decimals)
+		}
+		rowColor.Printf("%-4d  %-20s  %16s  %-16s\n", i+1, p.Name, value, p.Updated.Local().Format("2006-01-02 15:04"))
+	}
+	fmt.Println()
+}
+
+// invokeLeaderboardConfig edits [Settings] Leaderboard and PlayerName.
+func invokeLeaderboardConfig(reader *bufio.Reader) {
+	location, player := leaderboardLocation(), leaderboardPlayer()
+	colorInfo.Printf("Leaderboard: %s\n", leaderboardDisplay())
+	if location != "" {
+		fmt.Printf("Location: %s\n", location)
+	}
+	fmt.Print("Leaderboard file path or http(s) URL (Enter to keep, '-' to leave the leaderboard): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	switch input {
+	case "":
+	case "-":
+		location = ""
+	default:
+		location = strings.Trim(input, `"`)
+	}
+	if location != "" {
+		if player == "" {
+			player = os.Getenv("USER")
+			if player == "" {
+				player = os.Getenv("USERNAME")
+			}
+		}
+		fmt.Printf("Player name [%s]: ", player)
+		name, _ := reader.ReadString('\n')
+		if name = strings.TrimSpace(name); name != "" {
+			player = name
+		}
+		if player == "" {
+			color.Red("A player name is required. No changes made.")
+			fmt.Println("Press Enter to continue.")
+			reader.ReadString('\n')
+			return
+		}
+	}
+
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("Leaderboard").SetValue(location)
+		if location != "" {
+			f.Section("Settings").Key("PlayerName").SetValue(player)
+		}
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else if location == "" {
+		slog.Info("leaderboard disabled")
+		color.Green("Leaderboard turned off.")
+	} else {
+		slog.Info("leaderboard configured", "location", location, "player", player)
+		color.Green("Your score will be posted to the leaderboard as %s when you exit.", player)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,161 @@
+package vbtc
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ledgerView is what the ledger table shows: filters, sort order and page.
+// Filters are given as "key:value" words to the ledger command or the f key on
+// the ledger screen; any other words are a note/tag search:
+//
+//	ledger type:sell from:2024-01-01 to:2024-06-30 min:100 #dip
+//
+// Without filters the table shows ledger.csv; with any filter it shows the
+// matching rows of the whole history, archives included.
+type ledgerView struct {
+	Search      string
+	TX          string // "buy", "sell" or an exact TX value, lowercase
+	From, To    time.Time
+	MinUSD      float64
+	NewestFirst bool
+	Page        int // 0-based; -1 is the last page
+}
+
+const defaultLedgerPageSize = 20
+
+// ledgerPageSize is [Settings] LedgerPageSize, the rows per ledger page.
+func ledgerPageSize() int {
+	if cfg != nil {
+		if n, err := cfg.Section("Settings").Key("LedgerPageSize").Int(); err == nil && n > 0 {
+			return n
+		}
+	}
+	return defaultLedgerPageSize
+}
+
+func parseLedgerView(input string) (ledgerView, error) {
+	view := ledgerView{Page: -1}
+	var search []string
+	for _, word := range strings.Fields(input) {
+		key, value, found := strings.Cut(word, ":")
+		if !found || value == "" {
+			search = append(search, word)
+			continue
+		}
+		var err error
+		switch strings.ToLower(key) {
+		case "type", "tx":
+			view.TX = strings.ToLower(strings.ReplaceAll(value, "_", " "))
+		case "from":
+			view.From, err = time.ParseInLocation(exportDateLayout, value, time.Local)
+		case "to":
+			view.To, err = time.ParseInLocation(exportDateLayout, value, time.Local)
+			view.To = view.To.AddDate(0, 0, 1) // inclusive of the whole end day
+		case "min":
+			view.MinUSD, err = strconv.ParseFloat(strings.TrimPrefix(strings.ReplaceAll(value, ",", ""), "$"), 64)
+		case "sort":
+			switch strings.ToLower(value) {
+			case "new", "newest", "desc":
+				view.NewestFirst = true
+			case "old", "oldest", "asc":
+				view.NewestFirst = false
+			default:
+				err = fmt.Errorf("use sort:newest or sort:oldest")
+			}
+		default:
+			search = append(search, word)
+			continue
+		}
+		if err != nil {
+			return ledgerView{}, fmt.Errorf("invalid filter '%s'. Dates are YYYY-MM-DD", word)
+		}
+	}
+	if !view.From.IsZero() && !view.To.IsZero() && !view.To.After(view.From) {
+		return ledgerView{}, fmt.Errorf("the end date is before the start date")
+	}
+	view.Search = strings.Join(search, " ")
+	if view.NewestFirst {
+		view.Page = 0
+	}
+	return view, nil
+}
+
+// filtered reports whether any filter or search is set.
+func (v ledgerView) filtered() bool {
+	return v.Search != "" || v.TX != "" || !v.From.IsZero() || !v.To.IsZero() || v.MinUSD > 0
+}
+
+func (v ledgerView) matches(e LedgerEntry) bool {
+	switch v.TX {
+	case "":
+	case "buy", "sell":
+		if strings.ToLower(ledgerSide(e.TX)) != v.TX {
+			return false
+		}
+	default:
+		if strings.ToLower(e.TX) != v.TX {
+			return false
+		}
+	}
+	if (!v.From.IsZero() || !v.To.IsZero()) && e.DateTime.IsZero() {
+		return false
+	}
+	if (!v.From.IsZero() && e.DateTime.Before(v.From)) || (!v.To.IsZero() && !e.DateTime.Before(v.To)) {
+		return false
+	}
+	if v.MinUSD > 0 && e.USD < v.MinUSD {
+		return false
+	}
+	return v.Search == "" || e.MatchesSearch(v.Search)
+}
+
+// filters returns the view's filters in the syntax parseLedgerView reads.
+func (v ledgerView) filters() string {
+	var parts []string
+	if v.TX != "" {
+		parts = append(parts, "type:"+strings.ReplaceAll(v.TX, " ", "_"))
+	}
+	if !v.From.IsZero() {
+		parts = append(parts, "from:"+v.From.Format(exportDateLayout))
+	}
+	if !v.To.IsZero() {
+		parts = append(parts, "to:"+v.To.AddDate(0, 0, -1).Format(exportDateLayout))
+	}
+	if v.MinUSD > 0 {
+		parts = append(parts, "min:"+strconv.FormatFloat(v.MinUSD, 'f', -1, 64))
+	}
+	if v.Search != "" {
+		parts = append(parts, v.Search)
+	}
+	return strings.Join(parts, " ")
+}
+
+// rows sorts entries in the view's order and returns the current page, the
+// page number (resolving -1) and the page count.
+func (v ledgerView) rows(entries []LedgerEntry) (page []LedgerEntry, pageNum, pages int) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if v.NewestFirst {
+			return entries[i].DateTime.After(entries[j].DateTime)
+		}
+		return entries[i].DateTime.Before(entries[j].DateTime)
+	})
+	size := ledgerPageSize()
+	pages = (len(entries) + size - 1) / size
+	if pages == 0 {
+		return nil, 0, 0
+	}
+	pageNum = v.Page
+	if pageNum < 0 || pageNum >= pages {
+		pageNum = pages - 1
+	}
+	start := pageNum * size
+	end := start + size
+	if end > len(entries) {
+		end = len(entries)
+	}
+	return entries[start:end], pageNum, pages
+}
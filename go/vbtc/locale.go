@@ -0,0 +1,123 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"gopkg.in/ini.v1"
+)
+
+// Numbers on every screen, the ledger screen included, are formatted by a
+// golang.org/x/text/message printer for the language tag in [Settings]
+// NumberFormat (1,234.56 for "en", the default). Only the display changes:
+// vbtc.ini, ledger.csv, exports and the JSON status always use plain numbers
+// with a '.' decimal point, and trade amounts are still typed that way.
+const defaultNumberFormat = "en"
+
+type numberFormatInfo struct {
+	Code string // BCP 47 language tag
+	Name string
+}
+
+// numberFormats are the choices offered in Config; any other tag set by hand
+// in vbtc.ini works too.
+var numberFormats = []numberFormatInfo{
+	{"en", "English"},
+	{"de", "German, Dutch, Spanish"},
+	{"fr", "French, Nordic"},
+	{"de-CH", "Swiss"},
+}
+
+func lookupNumberFormat(code string) (numberFormatInfo, bool) {
+	code = strings.TrimSpace(code)
+	if strings.EqualFold(code, "ch") {
+		code = "de-CH" // written by earlier versions
+	}
+	for _, f := range numberFormats {
+		if strings.EqualFold(f.Code, code) {
+			return f, true
+		}
+	}
+	if tag, err := language.Parse(code); err == nil && tag != language.Und {
+		return numberFormatInfo{Code: tag.String(), Name: tag.String()}, true
+	}
+	return numberFormatInfo{}, false
+}
+
+// numberFormat returns the configured number format, or English if it is unset or unknown.
+func numberFormat() numberFormatInfo {
+	if cfg != nil {
+		if f, ok := lookupNumberFormat(cfg.Section("Settings").Key("NumberFormat").String()); ok {
+			return f
+		}
+	}
+	f, _ := lookupNumberFormat(defaultNumberFormat)
+	return f
+}
+
+func (f numberFormatInfo) printer() *message.Printer {
+	return message.NewPrinter(language.Make(f.Code))
+}
+
+// example shows how the format writes 1234567.89.
+func (f numberFormatInfo) example() string {
+	return f.printer().Sprint(number.Decimal(1234567.89, number.Scale(2)))
+}
+
+// formatDecimal formats v to decimals places in the configured format,
+// without grouping separators unless grouped.
+func formatDecimal(v float64, decimals int, grouped bool) string {
+	options := []number.Option{number.Scale(decimals)}
+	if !grouped {
+		options = append(options, number.NoSeparator())
+	}
+	return numberFormat().printer().Sprint(number.Decimal(v, options...))
+}
+
+// formatCoinAmount formats a coin amount to the satoshi, without grouping.
+func formatCoinAmount(amount float64) string {
+	return formatDecimal(amount, 8, false)
+}
+
+func invokeNumberFormatConfig(reader *bufio.Reader) {
+	current := numberFormat()
+	colorInfo.Printf("Number format: %s\n", current.example())
+	for i, f := range numberFormats {
+		fmt.Printf("  %d. %-14s (%s)\n", i+1, f.example(), f.Name)
+	}
+	fmt.Printf("Choose a number format (1-%d), or press Enter to keep it: ", len(numberFormats))
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(numberFormats) {
+		color.Red("Invalid choice. No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	chosen := numberFormats[choice-1]
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("NumberFormat").SetValue(chosen.Code)
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("number format configured", "format", chosen.Code)
+		color.Green("Numbers will be shown as %s. Amounts are still typed with a '.' decimal point.", chosen.example())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
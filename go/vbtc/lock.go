@@ -0,0 +1,118 @@
+package vbtc
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+// Several vbtc sessions can share one vbtc.ini and ledger.csv. Every write to
+// either happens while holding an advisory lock on vbtc.ini.lock, and every
+// read-modify-write of the portfolio reloads vbtc.ini under that lock, so a
+// session never saves balances another session has already changed.
+//
+// The lock is re-entrant within the process: a trade can hold it across the
+// ini save and the ledger append, which lock again on their own.
+const portfolioLockTimeout = 5 * time.Second
+
+var portfolioLock struct {
+	mu    sync.Mutex
+	depth int
+	file  *os.File
+}
+
+// lockPortfolio waits up to portfolioLockTimeout for the lock and returns the
+// function that releases it.
+func lockPortfolio() (unlock func(), err error) {
+	portfolioLock.mu.Lock()
+	defer portfolioLock.mu.Unlock()
+	if portfolioLock.depth == 0 {
+		file, err := waitForFileLock(iniFilePath+".lock", portfolioLockTimeout)
+		if err == errLockTimeout {
+			return func() {}, fmt.Errorf("vbtc.ini is locked by another vbtc session; try again")
+		}
+		if err != nil {
+			return func() {}, err
+		}
+		portfolioLock.file = file
+	}
+	portfolioLock.depth++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			portfolioLock.mu.Lock()
+			defer portfolioLock.mu.Unlock()
+			portfolioLock.depth--
+			if portfolioLock.depth == 0 {
+				unlockFile(portfolioLock.file)
+				portfolioLock.file.Close()
+				portfolioLock.file = nil
+			}
+		})
+	}, nil
+}
+
+var errLockTimeout = errors.New("lock timed out")
+
+// waitForFileLock opens path and waits up to timeout for an exclusive lock on
+// it. The lock is released by unlockFile and closing the file.
+func waitForFileLock(path string, timeout time.Duration) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("could not open lock file: %w", err)
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		locked, err := tryLockFile(file)
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("could not lock %s: %w", path, err)
+		}
+		if locked {
+			return file, nil
+		}
+		if time.Now().After(deadline) {
+			file.Close()
+			slog.Warn("file lock timed out", "path", path)
+			return nil, errLockTimeout
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// saveIni writes f to vbtc.ini under the portfolio lock.
+func saveIni(f *ini.File) error {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeIni(f)
+}
+
+// updateIni reloads vbtc.ini under the portfolio lock, applies change and
+// saves it, so settings edits never write back stale balances. On success
+// cfg is the saved file.
+func updateIni(change func(f *ini.File)) error {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := ini.LooseLoad(iniFilePath)
+	if err != nil {
+		return err
+	}
+	change(f)
+	if err := writeIni(f); err != nil {
+		return err
+	}
+	cfg = f
+	return nil
+}
@@ -1,14 +1,14 @@
-package main
+// Package vbtc is the vBTC virtual Bitcoin trading simulator. Run is its
+// entry point for cmd/vbtc and the kreftus launcher.
+package vbtc
 
 import (
 	"bufio"
-	"bytes"
 	"encoding/csv"
-	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"math"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -18,30 +18,44 @@ import (
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"errors"
 
-	"github.com/Knetic/govaluate"
 	"github.com/fatih/color"
 	"github.com/shirou/gopsutil/v3/process"
 	"golang.org/x/term"
 	"gopkg.in/ini.v1"
+	"kreftus/shared/apikey"
+	"kreftus/shared/console"
+	"kreftus/shared/logging"
+	"kreftus/shared/version"
+	"vbtc/internal/engine"
 )
 
 const (
-	startingCapital     = 1000.00
-	iniFilePath         = "vbtc.ini"
-	ledgerFilePath      = "ledger.csv"
-	tradeRetryDebounce  = 2 * time.Second
+	startingCapital    = 1000.00
+	tradeRetryDebounce = 2 * time.Second
 )
 
+// Portfolio and ledger files; replay and demo modes point them into replayDir or demoDir.
+var (
+	iniFilePath    = "vbtc.ini"
+	ledgerFilePath = "ledger.csv"
+)
+
+// ledgerHeader is written to new ledger and merged ledger files.
+var ledgerHeader = engine.LedgerHeader
+
 var (
 	sessionStartTime           = time.Now().UTC()
 	sessionStartPortfolioValue float64
 	initialSessionBtcPrice     float64
 	cfg                        *ini.File
+	liveCoinWatchKey           string // from the shared keys.ini; see loadLiveCoinWatchKey
 	apiData                    *ApiDataResponse
 	verbose                    bool
+	batchMode                  bool // no screen output (vbtc status --json)
 )
 
 // Structs for API responses
@@ -62,30 +76,33 @@ type ApiDataResponse struct {
 	Volatility12h_old       float64
 	Sma1h                   float64
 	Rate24hTotalChange      float64
-	Rate24hTotalChange1h     float64
+	Rate24hTotalChange1h    float64
 	HistoricalDataFetchTime time.Time
-	ApiError                string `json:"-"`
-	ApiErrorCode            int    `json:"-"`
+	Sparkline               []float64          `json:"-"` // hourly rates over the 24h history, oldest first
+	Rsi14                   float64            `json:"-"`
+	HasRsi                  bool               `json:"-"`
+	Macd                    float64            `json:"-"`
+	MacdSignal              float64            `json:"-"`
+	HasMacd                 bool               `json:"-"`
+	History                 []HistoryPoint     `json:"-"` // the 24h history, sorted by date
+	CoinRates               map[string]float64 `json:"-"` // USD rates of the tracked coins other than BTC
+	Provider                string             `json:"-"` // PriceProvider that supplied the data
+	ApiError                string             `json:"-"`
+	ApiErrorCode            int                `json:"-"`
 }
 
 type HistoryResponse struct {
-	History []struct {
-		Date int64   `json:"date"`
-		Rate float64 `json:"rate"`
-	} `json:"history"`
+	History []HistoryPoint `json:"history"`
 }
 
-// A struct to hold parsed ledger data for easier handling
-type LedgerEntry struct {
-	TX       string
-	USD      float64
-	BTC      float64
-	BTCPrice float64
-	UserBTC  float64
-	Time     string
-	DateTime time.Time
+type HistoryPoint struct {
+	Date int64   `json:"date"` // Unix milliseconds
+	Rate float64 `json:"rate"`
 }
 
+// LedgerEntry is a parsed ledger row.
+type LedgerEntry = engine.LedgerEntry
+
 // LedgerSummary holds aggregated data from ledger entries.
 type LedgerSummary struct {
 	TotalBuyUSD      float64
@@ -98,6 +115,7 @@ type LedgerSummary struct {
 	SellTransactions int
 	MinUSD           float64
 	MaxUSD           float64
+	TotalFees        float64
 	FirstTime        time.Time
 	LastTime         time.Time
 }
@@ -123,30 +141,81 @@ func (e *ProviderDownError) Error() string {
 }
 
 // --- Main Application ---
-func main() {
+
+// Run starts vBTC with args, the command line after the program name, and
+// returns the exit code. cmd/vbtc and the kreftus launcher both call it.
+func Run(args []string) int {
+	version.Default("1.6")
+	args = logging.Init("vbtc", append([]string{"vbtc"}, args...))[1:]
+	defer logging.Close()
+	if version.HandleArgs("vbtc", args) {
+		return 0
+	}
+	// Ensure Windows console uses UTF-8 and supports ANSI (no-op on non-Windows)
+	console.Setup()
+	applyTheme(console.LoadTheme("vbtc"))
 	// Check for verbose flag (before other args)
-	for _, arg := range os.Args[1:] {
+	for _, arg := range args {
 		if arg == "-verbose" || arg == "-v" {
 			verbose = true
 			break
 		}
 	}
+	// Check for replay mode, which keeps its own portfolio and ledger
+	replay, err := parseReplayArgs(args)
+	if err != nil {
+		color.Red("Replay: %v", err)
+		return 1
+	}
+	if replay != nil {
+		if err := os.MkdirAll(replayDir, 0755); err != nil {
+			color.Red("Replay: %v", err)
+			return 1
+		}
+		replaySource = replay
+		iniFilePath = filepath.Join(replayDir, "vbtc.ini")
+		ledgerFilePath = filepath.Join(replayDir, "ledger.csv")
+		slog.Info("replay mode", "points", len(replay.points), "speed", replay.speed)
+	} else if parseDemoArgs(args) {
+		// Demo mode needs no API key: prices are a generated random walk
+		if err := os.MkdirAll(demoDir, 0755); err != nil {
+			color.Red("Demo: %v", err)
+			return 1
+		}
+		replaySource = newDemoProvider(time.Now(), time.Now().UnixNano())
+		iniFilePath = filepath.Join(demoDir, "vbtc.ini")
+		ledgerFilePath = filepath.Join(demoDir, "ledger.csv")
+		slog.Info("demo mode")
+	}
+	// "vbtc status --json" prints a snapshot for other tools and exits
+	if len(args) > 0 && args[0] == "status" {
+		if len(args) != 2 || strings.TrimLeft(args[1], "-") != "json" {
+			fmt.Fprintln(os.Stderr, "Usage: vbtc status --json")
+			return 2
+		}
+		return runStatusCLI()
+	}
+	// "vbtc backtest" tests a trading strategy against past prices and exits
+	if len(args) > 0 && args[0] == "backtest" {
+		return runBacktestCLI(args[1:])
+	}
 	// Check for help flag
-	if len(os.Args) > 1 && (os.Args[1] == "-help" || os.Args[1] == "-h" || os.Args[1] == "--help") {
+	if len(args) > 0 && (args[0] == "-help" || args[0] == "-h" || args[0] == "--help") {
 		showHelpScreen(nil)
-		return
+		return 0
 	}
 	// Check for config flag (open config and exit, e.g. to fix API key)
-	if len(os.Args) > 1 && (os.Args[1] == "-config" || os.Args[1] == "--config") {
+	if len(args) > 0 && (args[0] == "-config" || args[0] == "--config") {
 		reader := bufio.NewReader(os.Stdin)
 		setup(reader)
 		showConfigScreen(reader)
-		return
+		return 0
 	}
 
 	reader := bufio.NewReader(os.Stdin) // Create the single, authoritative reader.
 	setup(reader)
 	mainLoop(reader)
+	return 0
 }
 
 func setup(reader *bufio.Reader) {
@@ -155,15 +224,17 @@ func setup(reader *bufio.Reader) {
 	if err != nil {
 		fmt.Println("Failed to read ini file, creating a new one.")
 		cfg = ini.Empty()
-		cfg.Section("Settings").Key("ApiKey").SetValue("")
 		cfg.Section("Portfolio").Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", startingCapital))
 		cfg.Section("Portfolio").Key("PlayerBTC").SetValue("0.0")
 		cfg.Section("Portfolio").Key("PlayerInvested").SetValue("0.0")
-		cfg.SaveTo(iniFilePath)
+		saveIni(cfg)
 	}
 
-	if cfg.Section("Settings").Key("ApiKey").String() == "" {
-		showFirstRunSetup(reader)
+	startSessionLog()
+
+	loadLiveCoinWatchKey()
+	if liveCoinWatchKey == "" && replaySource == nil && selectedProviderName() == defaultProvider {
+		showFirstRunSetup(reader) // CoinGecko and Coinbase need no key
 	}
 
 	// Perform the initial data fetch to get a complete data object.
@@ -177,6 +248,31 @@ func setup(reader *bufio.Reader) {
 	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
 	playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
 	sessionStartPortfolioValue = getPortfolioValue(playerUSD, playerBTC, apiData)
+	priceWatch.configure()
+}
+
+// loadLiveCoinWatchKey reads the LiveCoinWatch key from the shared keys.ini,
+// where bmon and vbtc both save it. A key left in vbtc.ini by older versions
+// is moved there once; vbtc.ini keeps it only if keys.ini cannot be written.
+func loadLiveCoinWatchKey() {
+	liveCoinWatchKey = apikey.Load(apikey.LiveCoinWatch)
+	settings := cfg.Section("Settings")
+	if !settings.HasKey("ApiKey") {
+		return
+	}
+	if legacyKey := settings.Key("ApiKey").String(); liveCoinWatchKey == "" && legacyKey != "" {
+		liveCoinWatchKey = legacyKey
+		if err := apikey.Save(apikey.LiveCoinWatch, legacyKey); err != nil {
+			slog.Warn("api key migration failed", "err", err)
+			return
+		}
+		slog.Info("api key moved to shared keys file")
+	}
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").DeleteKey("ApiKey")
+	}); err != nil {
+		slog.Warn("legacy api key not removed", "path", iniFilePath, "err", err)
+	}
 }
 
 func mainLoop(reader *bufio.Reader) {
@@ -184,51 +280,90 @@ func mainLoop(reader *bufio.Reader) {
 		"b": "buy", "buy": "buy",
 		"s": "sell", "sell": "sell",
 		"l": "ledger", "ledger": "ledger",
+		"x": "export", "export": "export",
+		"import": "import",
+		"p":      "pnl", "pnl": "pnl",
+		"status": "status",
+		"stats":  "stats",
+		"o":      "orders", "orders": "orders",
+		"t": "triggers", "triggers": "triggers",
+		"a": "alert", "alert": "alert", "alerts": "alert",
 		"r": "refresh", "refresh": "refresh",
 		"c": "config", "config": "config",
 		"h": "help", "help": "help",
-		"e": "exit", "exit": "exit",
+		"u": "undo", "undo": "undo",
+		"leaderboard": "leaderboard",
+		"history":     "history",
+		"quote":       "quote",
+		"rebalance":   "rebalance",
+		"e":           "exit", "exit": "exit",
 	}
 
+	// Carry on a session a crash or kill cut short.
+	if resumeSavedSession() {
+		slog.Info("session resumed", "start", sessionStartTime, "start_value", sessionStartPortfolioValue)
+	}
+
+	// Finish a trade a crash interrupted between vbtc.ini and ledger.csv.
+	recoverPendingTrade(reader)
+
+	// Offer to set aside ledger rows that would otherwise be skipped or read as zero.
+	checkLedgerIntegrity(reader)
+
+	// Orders and stop-loss/take-profit prices may have been reached while vbtc was closed.
+	checkAutomaticTrades(reader)
+	recordSnapshot()
+
+	typed := "" // kept across an auto-refresh
 	for {
-		showMainScreen()
-		fmt.Print("Enter command: ")
-		input, _ := reader.ReadString('\n')
+		saveSessionState()
+		// A price picked up by the background watcher may have reached an order or trigger.
+		if applyWatchedPrice() {
+			checkAutomaticTrades(reader)
+		}
+		input, exit := promptCommand(reader, typed)
+		if exit == promptPrice {
+			// The prompt already applied the watched price; act on it and redraw.
+			typed = input
+			checkAutomaticTrades(reader)
+			continue
+		}
+		if exit == promptIdle {
+			// Same as refresh, then back to the prompt with the text typed so far.
+			typed = input
+			if reloadedCfg, err := ini.Load(iniFilePath); err == nil {
+				cfg = reloadedCfg
+			}
+			slog.Debug("auto refresh", "typed", len(typed) > 0)
+			apiData = updateApiData(false)
+			checkAutomaticTrades(reader)
+			continue
+		}
+		typed = ""
 		input = strings.TrimSpace(input)
+		logSessionCommand(input)
 		parts := strings.Fields(input)
 		if len(parts) == 0 {
 			continue
 		}
 
 		commandInput := strings.ToLower(parts[0])
-		var amount string
-		if len(parts) > 1 {
-			amount = parts[1]
-		}
-
-		var matchedCommands []string
-		for _, long := range commands {
-			if strings.HasPrefix(long, commandInput) {
-				// Avoid adding duplicates
-				found := false
-				for _, mc := range matchedCommands {
-					if mc == long {
-						found = true
-						break
-					}
-				}
-				if !found {
-					matchedCommands = append(matchedCommands, long)
-				}
-			}
-		}
+
+		matchedCommands := matchCommands(commands, commandInput)
 
 		if len(matchedCommands) == 1 {
 			command := matchedCommands[0]
 			switch command {
 			case "buy":
 				// The invokeTrade function now returns the latest data it fetched.
-				returnedApiData := invokeTrade(reader, "Buy", amount)
+				coin, amount, err := parseTradeArgs(parts[1:])
+				if err != nil {
+					color.Red("%v", err)
+					fmt.Println("Press Enter to continue.")
+					reader.ReadString('\n')
+					continue
+				}
+				returnedApiData := invokeTrade(reader, "Buy", coin, amount)
 				if returnedApiData != nil {
 					apiData = returnedApiData
 				}
@@ -245,8 +380,16 @@ func mainLoop(reader *bufio.Reader) {
 				if isApiDataStale() {
 					apiData = updateApiData(false)
 				}
+				checkAutomaticTrades(reader)
 			case "sell":
-				returnedApiData := invokeTrade(reader, "Sell", amount)
+				coin, amount, err := parseTradeArgs(parts[1:])
+				if err != nil {
+					color.Red("%v", err)
+					fmt.Println("Press Enter to continue.")
+					reader.ReadString('\n')
+					continue
+				}
+				returnedApiData := invokeTrade(reader, "Sell", coin, amount)
 				if returnedApiData != nil {
 					apiData = returnedApiData
 				}
@@ -263,8 +406,48 @@ func mainLoop(reader *bufio.Reader) {
 				if isApiDataStale() {
 					apiData = updateApiData(false)
 				}
+				checkAutomaticTrades(reader)
 			case "ledger":
-				showLedgerScreen(reader)
+				view, err := parseLedgerView(strings.Join(parts[1:], " "))
+				if err != nil {
+					color.Red("%v", err)
+					fmt.Println("Press Enter to continue.")
+					reader.ReadString('\n')
+					continue
+				}
+				showLedgerScreen(reader, view)
+			case "orders":
+				showOrdersScreen(reader, parts[1:])
+			case "triggers":
+				showTriggersScreen(reader, parts[1:])
+			case "alert":
+				showAlertsScreen(reader, parts[1:])
+			case "export":
+				runExportCommand(reader, parts[1:])
+			case "import":
+				runImportCommand(reader, parts[1:])
+			case "pnl":
+				showPnlScreen(reader, parts[1:])
+			case "status":
+				runStatusCommand(reader, parts[1:])
+			case "stats":
+				showStatsScreen(reader)
+			case "undo":
+				runUndoCommand(reader)
+			case "leaderboard":
+				showLeaderboardScreen(reader)
+			case "history":
+				showHistoryScreen(reader)
+			case "quote":
+				showQuote(reader, parts[1:])
+			case "rebalance":
+				if returnedApiData := runRebalanceCommand(reader, parts[1:]); returnedApiData != nil {
+					apiData = returnedApiData
+					if reloadedCfg, err := ini.Load(iniFilePath); err == nil {
+						cfg = reloadedCfg
+					}
+					checkAutomaticTrades(reader)
+				}
 			case "refresh":
 				// Reload config from disk to sync with other potential clients
 				reloadedCfg, err := ini.Load(iniFilePath)
@@ -276,12 +459,18 @@ func mainLoop(reader *bufio.Reader) {
 					cfg = reloadedCfg
 				}
 				apiData = updateApiData(false)
+				checkAutomaticTrades(reader)
 			case "config":
 				showConfigScreen(reader)
 			case "help":
+				if len(parts) > 1 {
+					showCommandHelp(reader, commands, parts[1])
+					continue
+				}
 				showHelpScreen(reader)
 			case "exit":
 				showExitScreen(reader)
+				endSession()
 				return
 			}
 		} else if len(matchedCommands) > 1 {
@@ -310,13 +499,17 @@ func clearScreen() {
 }
 
 func showLoadingScreen() {
+	if batchMode {
+		return
+	}
 	clearScreen()
 	color.Yellow("Loading Data...")
 }
 
+// showMainScreen prints the market and portfolio panels and the command bar.
+// Everything goes through color.Output so renderScreen can capture it for the
+// Bubble Tea prompt.
 func showMainScreen() {
-	clearScreen()
-
 	isNetworkError := apiData != nil && apiData.ApiError == "NetworkError"
 	if isNetworkError {
 		errorMessage := "API Provider Problem"
@@ -328,7 +521,7 @@ func showMainScreen() {
 	}
 
 	// Market Data
-	color.New(color.FgYellow).Println("*** Bitcoin Market ***")
+	colorTitle.Println("*** Bitcoin Market ***")
 
 	isDataAvailable := apiData != nil && apiData.Rate > 0
 
@@ -339,17 +532,17 @@ func showMainScreen() {
 	if isDataAvailable {
 		priceColor24h := color.New(color.FgWhite)
 		if apiData.Rate > apiData.Rate24hAgo {
-			priceColor24h = color.New(color.FgGreen)
+			priceColor24h = colorPositive
 		} else if apiData.Rate < apiData.Rate24hAgo {
-			priceColor24h = color.New(color.FgRed)
+			priceColor24h = colorNegative
 		}
 
 		priceColorSession := color.New(color.FgWhite)
 		if initialSessionBtcPrice > 0 {
 			if apiData.Rate > initialSessionBtcPrice {
-				priceColorSession = color.New(color.FgGreen)
+				priceColorSession = colorPositive
 			} else if apiData.Rate < initialSessionBtcPrice {
-				priceColorSession = color.New(color.FgRed)
+				priceColorSession = colorNegative
 			}
 		}
 
@@ -358,93 +551,52 @@ func showMainScreen() {
 			percentChange = ((apiData.Rate - apiData.Rate24hAgo) / apiData.Rate24hAgo) * 100
 		}
 
-		writeAlignedLine("Bitcoin (USD):", fmt.Sprintf("$%s", formatFloat(apiData.Rate, 2)), priceColorSession)
-
-		if apiData.Sma1h > 0 {
-			smaColor := color.New(color.FgWhite)
-			if apiData.Rate > apiData.Sma1h {
-				smaColor = color.New(color.FgGreen)
-			} else if apiData.Rate < apiData.Sma1h {
-				smaColor = color.New(color.FgRed)
-			}
-			writeAlignedLine("1H SMA:", fmt.Sprintf("$%s", formatFloat(apiData.Sma1h, 2)), smaColor)
+		writeAlignedLine(currencyLabel("Bitcoin"), formatMoney(apiData.Rate), priceColorSession)
+		showPriceChange()
+		if len(apiData.Sparkline) >= 2 {
+			writeAlignedLine("24H Trend:", getSparkline(apiData.Sparkline, sparklinePoints), priceColor24h)
 		}
 
-		writeAlignedLine("24H Ago:", fmt.Sprintf("$%s [%+.2f%%]", formatFloat(apiData.Rate24hAgo, 2), percentChange), priceColor24h)
+		indicators := loadIndicators()
+		showTrendIndicators(indicators)
 
-		highDisplay := formatFloat(apiData.Rate24hHigh, 2)
+		writeAlignedLine("24H Ago:", fmt.Sprintf("%s [%+.2f%%]", formatMoney(apiData.Rate24hAgo), percentChange), priceColor24h)
+
+		highDisplay := formatMoney(apiData.Rate24hHigh)
 		if !apiData.Rate24hHighTime.IsZero() {
 			highDisplay += " (at " + apiData.Rate24hHighTime.Local().Format("15:04") + ")"
 		}
-		lowDisplay := formatFloat(apiData.Rate24hLow, 2)
+		lowDisplay := formatMoney(apiData.Rate24hLow)
 		if !apiData.Rate24hLowTime.IsZero() {
 			lowDisplay += " (at " + apiData.Rate24hLowTime.Local().Format("15:04") + ")"
 		}
 
-		writeAlignedLine("24H High:", fmt.Sprintf("$%s", highDisplay), color.New(color.FgWhite))
-		writeAlignedLine("24H Low:", fmt.Sprintf("$%s", lowDisplay), color.New(color.FgWhite))
-		if apiData.Volatility24h > 0 {
-			volatilityColor := color.New(color.FgWhite)
-			if apiData.Volatility12h > apiData.Volatility12h_old {
-				volatilityColor = color.New(color.FgGreen)
-			} else if apiData.Volatility12h < apiData.Volatility12h_old {
-				volatilityColor = color.New(color.FgRed)
-			}
-			volStr := fmt.Sprintf("%.2f%%", apiData.Volatility24h)
-			range24h := apiData.Rate24hHigh - apiData.Rate24hLow
-			velocityColor := color.New(color.FgWhite)
-			hasVelocity := false
-			var velocity int
-			if apiData.Rate24hTotalChange > 0 && range24h > 0 {
-				velocity = int(math.Round((apiData.Rate24hTotalChange / range24h) * apiData.Volatility24h))
-				hourlyAvg := apiData.Rate24hTotalChange / 24
-				if hourlyAvg > 0 && apiData.Rate24hTotalChange1h >= 0 {
-					multiplier := apiData.Rate24hTotalChange1h / hourlyAvg
-					velocity = int(math.Round(float64(velocity) * multiplier))
-					if velocity >= 50 {
-						velocityColor = color.New(color.FgMagenta)
-					} else if apiData.Rate24hTotalChange1h > hourlyAvg {
-						velocityColor = color.New(color.FgGreen)
-					} else {
-						velocityColor = color.New(color.FgRed)
-					}
-					hasVelocity = true
-					if verbose {
-						fmt.Fprintf(os.Stderr, "Velocity calculation: TotalChange=%.2f, 1HourDeltaTotal=%.2f, 24H High=%.2f, 24H Low=%.2f, range=%.2f, Volatility=%.2f%% (as whole number), hourlyAvg=%.2f, multiplier=%.2f, velocity=%d\n",
-							apiData.Rate24hTotalChange, apiData.Rate24hTotalChange1h, apiData.Rate24hHigh, apiData.Rate24hLow, range24h, apiData.Volatility24h, hourlyAvg, multiplier, velocity)
-					}
-				} else if verbose {
-					fmt.Fprintf(os.Stderr, "Velocity calculation: TotalChange=%.2f, 24H High=%.2f, 24H Low=%.2f, range=%.2f, Volatility=%.2f%% (as whole number), velocity=%d\n",
-						apiData.Rate24hTotalChange, apiData.Rate24hHigh, apiData.Rate24hLow, range24h, apiData.Volatility24h, velocity)
-				}
-				volStr = fmt.Sprintf("%.2f%% [%d]", apiData.Volatility24h, velocity)
-			}
-			valueStartColumn := 22
-			padding := valueStartColumn - len("Volatility:")
-			if padding < 0 {
-				padding = 0
-			}
-			fmt.Print("Volatility:")
-			fmt.Print(strings.Repeat(" ", padding))
-			if hasVelocity {
-				volatilityColor.Print(fmt.Sprintf("%.2f%%", apiData.Volatility24h))
-				velocityColor.Printf(" [%d]\n", velocity)
-			} else {
-				volatilityColor.Println(volStr)
+		writeAlignedLine("24H High:", highDisplay, color.New(color.FgWhite))
+		writeAlignedLine("24H Low:", lowDisplay, color.New(color.FgWhite))
+		showVolatilityIndicators(indicators)
+		writeAlignedLine("24H Volume:", formatMoneyWhole(apiData.Volume), color.New(color.FgWhite))
+		for _, coin := range trackedCoins() {
+			if rate := coinRate(coin); rate > 0 {
+				writeAlignedLine(currencyLabel(coin), formatMoney(rate), color.New(color.FgWhite))
 			}
 		}
-		writeAlignedLine("24H Volume:", fmt.Sprintf("$%s", formatFloat(apiData.Volume, 0)), color.New(color.FgWhite))
 		// Updated: shows when the (historical) API data was fetched, not when the main modal was loaded.
 		dataTime := apiData.FetchTime
 		if !apiData.HistoricalDataFetchTime.IsZero() {
 			dataTime = apiData.HistoricalDataFetchTime
 		}
-		writeAlignedLine("Updated:", dataTime.Local().Format("010206@150405"), color.New(color.FgCyan))
+		updated := dataTime.Local().Format("010206@150405")
+		if apiData.Provider != "" && apiData.Provider != defaultProvider {
+			updated += " (" + apiData.Provider + ")"
+		}
+		writeAlignedLine("Updated:", updated, colorInfo)
+		showApiBudget()
+		showReplayStatus()
 	}
 
 	// Portfolio
-	fmt.Println()
-	color.New(color.FgYellow).Println("*** Portfolio ***")
+	fmt.Fprintln(color.Output)
+	colorTitle.Println("*** Portfolio ***")
 	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
 	playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
 	playerInvested, _ := cfg.Section("Portfolio").Key("PlayerInvested").Float64()
@@ -452,18 +604,18 @@ func showMainScreen() {
 
 	portfolioColor := color.New(color.FgWhite)
 	if portfolioValue > startingCapital {
-		portfolioColor = color.New(color.FgGreen)
+		portfolioColor = colorPositive
 	} else if portfolioValue < startingCapital {
-		portfolioColor = color.New(color.FgRed)
+		portfolioColor = colorNegative
 	}
 
 	if playerBTC > 0 {
 		btcValueDisplay := ""
 		if apiData != nil {
 			btcValue := playerBTC * apiData.Rate
-			btcValueDisplay = fmt.Sprintf(" ($%s)", formatFloat(btcValue, 2))
+			btcValueDisplay = fmt.Sprintf(" (%s)", formatMoney(btcValue))
 		}
-		writeAlignedLine("Bitcoin:", fmt.Sprintf("%.8f%s", playerBTC, btcValueDisplay), color.New(color.FgWhite))
+		writeAlignedLine("Bitcoin:", formatCoinAmount(playerBTC)+btcValueDisplay, color.New(color.FgWhite))
 
 		investedChange := 0.0
 		if playerInvested > 0 && apiData != nil {
@@ -472,15 +624,23 @@ func showMainScreen() {
 		}
 		investedColor := color.New(color.FgWhite)
 		if investedChange > 0.005 { // Add a small tolerance for floating point
-			investedColor = color.New(color.FgGreen)
+			investedColor = colorPositive
 		} else if investedChange < 0 {
-			investedColor = color.New(color.FgRed)
+			investedColor = colorNegative
+		}
+		writeAlignedLine("Invested:", fmt.Sprintf("%s [%+.2f%%]", formatMoney(playerInvested), investedChange), investedColor)
+
+		if stopLoss, takeProfit := loadTriggers(cfg); stopLoss > 0 || takeProfit > 0 {
+			writeAlignedLine("Stop / Target:", fmt.Sprintf("%s / %s", triggerDisplay(stopLoss), triggerDisplay(takeProfit)), colorInfo)
 		}
-		writeAlignedLine("Invested:", fmt.Sprintf("$%s [%+.2f%%]", formatFloat(playerInvested, 2), investedChange), investedColor)
+	}
+	showHeldCoins()
+	if orders := loadOrders(cfg); len(orders) > 0 {
+		writeAlignedLine("Open Orders:", fmt.Sprintf("%d", len(orders)), colorInfo)
 	}
 
-	writeAlignedLine("Cash:", fmt.Sprintf("$%s", formatFloat(playerUSD, 2)), color.New(color.FgWhite))
-	writeAlignedLine("Value (USD):", fmt.Sprintf("$%s", formatFloat(portfolioValue, 2)), portfolioColor)
+	writeAlignedLine("Cash:", formatMoney(playerUSD), color.New(color.FgWhite))
+	writeAlignedLine(currencyLabel("Value"), formatMoney(portfolioValue), portfolioColor)
 
 	if sessionStartPortfolioValue > 0 {
 		sessionChange := portfolioValue - sessionStartPortfolioValue
@@ -495,42 +655,97 @@ func showMainScreen() {
 
 		sessionColor := color.New(color.FgWhite)
 		if roundedCurrentValue > roundedStartValue {
-			sessionColor = color.New(color.FgGreen)
+			sessionColor = colorPositive
 		} else if roundedCurrentValue < roundedStartValue {
-			sessionColor = color.New(color.FgRed)
+			sessionColor = colorNegative
 		}
 		sessionDisplay := fmt.Sprintf("%s [%s]", formatProfitLoss(sessionChange, ""), fmt.Sprintf("%+.2f%%", sessionPercent))
 		writeAlignedLine("Session P/L:", sessionDisplay, sessionColor)
 	}
-
-	fmt.Println()
-	color.New(color.FgYellow).Print("Commands: ")
-	color.New(color.FgGreen).Print("Buy ")
-	color.New(color.FgRed).Print("Sell ")
-	color.New(color.FgYellow).Print("Ledger ")
-	color.New(color.FgCyan).Print("Refresh ")
+	showPnlLines()
+
+	fmt.Fprintln(color.Output)
+	colorTitle.Print("Commands: ")
+	colorPositive.Print("Buy ")
+	colorNegative.Print("Sell ")
+	colorTitle.Print("Ledger ")
+	colorTitle.Print("Export ")
+	colorTitle.Print("PnL ")
+	colorTitle.Print("Stats ")
+	color.New(color.FgMagenta).Print("Orders ")
+	color.New(color.FgMagenta).Print("Triggers ")
+	color.New(color.FgMagenta).Print("Alert ")
+	colorInfo.Print("Refresh ")
 	color.New(color.FgHiBlack).Print("Config ")
 	color.New(color.FgBlue).Print("Help ")
-	color.New(color.FgYellow).Println("Exit")
+	colorTitle.Println("Exit")
 }
 
 func showFirstRunSetup(reader *bufio.Reader) {
 	clearScreen()
 	color.Yellow("*** First Time Setup ***")
-	color.Green("Get Free Key: https://www.livecoinwatch.com/tools/api")
-	for {
-		fmt.Print("Please enter your LiveCoinWatch API Key: ")
-		apiKey, _ := reader.ReadString('\n')
-		apiKey = strings.TrimSpace(apiKey)
-		if testApiKey(apiKey) {
-			cfg.Section("Settings").Key("ApiKey").SetValue(apiKey)
-			cfg.SaveTo(iniFilePath)
-			color.Green("API Key saved. Welcome!")
-			fmt.Println("Press Enter to start.")
-			reader.ReadString('\n')
-			break
+	offerTutorial(reader)
+	apiKey, err := apikey.Prompt(reader, apikey.LiveCoinWatch)
+	if err != nil {
+		fmt.Println("No API key entered. Exiting.")
+		os.Exit(1)
+	}
+	liveCoinWatchKey = apiKey
+	color.Green("API Key saved. Welcome!")
+	fmt.Println("Press Enter to start.")
+	reader.ReadString('\n')
+}
+
+// showVolatility24h prints the 24h volatility line, colored by whether the
+// last 12 hours were more volatile than the 12 before, with the velocity.
+func showVolatility24h() {
+	if apiData.Volatility24h > 0 {
+		volatilityColor := color.New(color.FgWhite)
+		if apiData.Volatility12h > apiData.Volatility12h_old {
+			volatilityColor = colorPositive
+		} else if apiData.Volatility12h < apiData.Volatility12h_old {
+			volatilityColor = colorNegative
+		}
+		volStr := fmt.Sprintf("%.2f%%", apiData.Volatility24h)
+		range24h := apiData.Rate24hHigh - apiData.Rate24hLow
+		velocityColor := color.New(color.FgWhite)
+		hasVelocity := false
+		var velocity int
+		if apiData.Rate24hTotalChange > 0 && range24h > 0 {
+			velocity = int(math.Round((apiData.Rate24hTotalChange / range24h) * apiData.Volatility24h))
+			hourlyAvg := apiData.Rate24hTotalChange / 24
+			if hourlyAvg > 0 && apiData.Rate24hTotalChange1h >= 0 {
+				multiplier := apiData.Rate24hTotalChange1h / hourlyAvg
+				velocity = int(math.Round(float64(velocity) * multiplier))
+				if velocity >= 50 {
+					velocityColor = color.New(color.FgMagenta)
+				} else if apiData.Rate24hTotalChange1h > hourlyAvg {
+					velocityColor = colorPositive
+				} else {
+					velocityColor = colorNegative
+				}
+				hasVelocity = true
+				if verbose {
+					fmt.Fprintf(os.Stderr, "Velocity calculation: TotalChange=%.2f, 1HourDeltaTotal=%.2f, 24H High=%.2f, 24H Low=%.2f, range=%.2f, Volatility=%.2f%% (as whole number), hourlyAvg=%.2f, multiplier=%.2f, velocity=%d\n",
+						apiData.Rate24hTotalChange, apiData.Rate24hTotalChange1h, apiData.Rate24hHigh, apiData.Rate24hLow, range24h, apiData.Volatility24h, hourlyAvg, multiplier, velocity)
+				}
+			} else if verbose {
+				fmt.Fprintf(os.Stderr, "Velocity calculation: TotalChange=%.2f, 24H High=%.2f, 24H Low=%.2f, range=%.2f, Volatility=%.2f%% (as whole number), velocity=%d\n",
+					apiData.Rate24hTotalChange, apiData.Rate24hHigh, apiData.Rate24hLow, range24h, apiData.Volatility24h, velocity)
+			}
+			volStr = fmt.Sprintf("%.2f%% [%d]", apiData.Volatility24h, velocity)
+		}
+		valueStartColumn := 22
+		padding := valueStartColumn - len("Volatility:")
+		if padding < 0 {
+			padding = 0
+		}
+		fmt.Fprint(color.Output, "Volatility:"+strings.Repeat(" ", padding))
+		if hasVelocity {
+			volatilityColor.Print(fmt.Sprintf("%.2f%%", apiData.Volatility24h))
+			velocityColor.Printf(" [%d]\n", velocity)
 		} else {
-			color.Red("Invalid API Key. Please try again.")
+			volatilityColor.Println(volStr)
 		}
 	}
 }
@@ -543,8 +758,21 @@ func showConfigScreen(reader *bufio.Reader) {
 		fmt.Println("2. Reset Portfolio")
 		fmt.Println("3. Archive Ledger")
 		fmt.Println("4. Merge Archived Ledgers")
-		fmt.Println("5. Return to Main Screen")
-		fmt.Print("Enter your choice (Number 1-5): ")
+		fmt.Println("5. Tracked Coins")
+		fmt.Printf("6. Trading Fees (%s)\n", feeModelDisplay())
+		fmt.Printf("7. Spread / Slippage (%s)\n", spreadModelDisplay())
+		fmt.Printf("8. Price Watcher (%s)\n", watchSettingsDisplay())
+		fmt.Printf("9. Price Provider (%s)\n", selectedProviderName())
+		fmt.Printf("A. Audio (%s)\n", soundsDisplay())
+		fmt.Printf("C. Currency (%s)\n", currencyCode())
+		fmt.Printf("I. Indicators (%s)\n", indicatorsDisplay())
+		fmt.Printf("L. Leaderboard (%s)\n", leaderboardDisplay())
+		fmt.Printf("N. Number Format (%s)\n", numberFormat().example())
+		fmt.Printf("R. Risk Limits (%s)\n", riskLimitsDisplay())
+		fmt.Printf("S. Session Log (%s)\n", sessionLogDisplay())
+		fmt.Printf("W. Webhook (%s)\n", webhookDisplay())
+		fmt.Println("0. Return to Main Screen")
+		fmt.Print("Enter your choice (Number 0-9, A, C, I, L, N, R, S or W): ")
 
 		// --- Raw Terminal Input Setup ---
 		fd := int(os.Stdin.Fd())
@@ -643,9 +871,9 @@ func showConfigScreen(reader *bufio.Reader) {
 			return
 		}
 
-		// Handle numeric keys 1-5
-		choice := string(b)
-		if choice >= "1" && choice <= "5" {
+		// Handle numeric keys 0-9, C, I, L, R and S
+		choice := strings.ToUpper(string(b))
+		if (choice >= "0" && choice <= "9") || choice == "A" || choice == "C" || choice == "I" || choice == "L" || choice == "N" || choice == "R" || choice == "S" || choice == "W" {
 			fmt.Println(choice)
 			restoreNeeded = false
 			close(done)
@@ -674,34 +902,49 @@ func showConfigScreen(reader *bufio.Reader) {
 func handleConfigChoice(choice string, reader *bufio.Reader) bool {
 	switch choice {
 	case "1":
-		currentKey := cfg.Section("Settings").Key("ApiKey").String()
+		currentKey := liveCoinWatchKey
 		if currentKey == "" {
 			currentKey = "(not set)"
 		}
-		color.New(color.FgCyan).Printf("Current API Key: %s\n", currentKey)
+		colorInfo.Printf("Current API Key: %s\n", currentKey)
 		fmt.Print("Enter your new LiveCoinWatch API Key: ")
 		newApiKey, _ := reader.ReadString('\n')
 		newApiKey = strings.TrimSpace(newApiKey)
-		if testApiKey(newApiKey) {
-			cfg.Section("Settings").Key("ApiKey").SetValue(newApiKey)
-			cfg.SaveTo(iniFilePath)
-			color.Green("API Key updated successfully.")
-		} else {
+		if !testApiKey(newApiKey) {
 			color.Red("The new API Key is invalid. It has not been saved.")
+		} else if err := apikey.Save(apikey.LiveCoinWatch, newApiKey); err != nil {
+			slog.Error("api key save failed", "err", err)
+			color.Red("Could not save the API Key: %v", err)
+		} else {
+			liveCoinWatchKey = newApiKey
+			priceWatch.configure()
+			color.Green("API Key updated successfully.")
 		}
 		fmt.Println("Press Enter to continue.")
 		reader.ReadString('\n')
 		return false
 	case "2":
-		color.New(color.FgRed).Print("Are you sure you want to reset your portfolio? This cannot be undone. Type 'YES' to confirm: ")
+		colorNegative.Print("Are you sure you want to reset your portfolio? This cannot be undone. Type 'YES' to confirm: ")
 		confirm, _ := reader.ReadString('\n')
 		if strings.TrimSpace(confirm) == "YES" { // This comparison is already case-sensitive
-			cfg.Section("Portfolio").Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", startingCapital))
-			cfg.Section("Portfolio").Key("PlayerBTC").SetValue("0.0")
-			cfg.Section("Portfolio").Key("PlayerInvested").SetValue("0.0")
-			os.Remove(ledgerFilePath)
-			cfg.SaveTo(iniFilePath)
-			color.Green("Portfolio has been reset.")
+			err := updateIni(func(f *ini.File) {
+				f.Section("Portfolio").Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", startingCapital))
+				f.Section("Portfolio").Key("PlayerBTC").SetValue("0.0")
+				f.Section("Portfolio").Key("PlayerInvested").SetValue("0.0")
+				for _, coin := range trackedCoins() {
+					f.Section("Portfolio").DeleteKey(coinBalanceKey(coin))
+					f.Section("Portfolio").DeleteKey(coinInvestedKey(coin))
+				}
+				f.DeleteSection(ordersSection)
+				f.DeleteSection(undoSection)
+				f.DeleteSection(triggersSection)
+				os.Remove(ledgerFilePath) // still under the lock
+			})
+			if err != nil {
+				color.Red("Could not reset the portfolio: %v", err)
+			} else {
+				color.Green("Portfolio has been reset.")
+			}
 		} else {
 			fmt.Println("Portfolio reset cancelled.")
 		}
@@ -714,7 +957,46 @@ func handleConfigChoice(choice string, reader *bufio.Reader) bool {
 	case "4":
 		invokeLedgerMerge(reader)
 		return false
-	case "5", "": // Default to returning if input is empty
+	case "5":
+		invokeTrackedCoinsConfig(reader)
+		return false
+	case "6":
+		invokeFeeConfig(reader)
+		return false
+	case "7":
+		invokeSpreadConfig(reader)
+		return false
+	case "8":
+		invokeWatchConfig(reader)
+		return false
+	case "9":
+		invokeProviderConfig(reader)
+		return false
+	case "A", "a":
+		invokeSoundsConfig(reader)
+		return false
+	case "C", "c":
+		invokeCurrencyConfig(reader)
+		return false
+	case "I", "i":
+		invokeIndicatorsConfig(reader)
+		return false
+	case "L", "l":
+		invokeLeaderboardConfig(reader)
+		return false
+	case "N", "n":
+		invokeNumberFormatConfig(reader)
+		return false
+	case "R", "r":
+		invokeRiskLimitsConfig(reader)
+		return false
+	case "S", "s":
+		invokeSessionLogConfig(reader)
+		return false
+	case "W", "w":
+		invokeWebhookConfig(reader)
+		return false
+	case "0", "": // Default to returning if input is empty
 		return true
 	default:
 		color.Red("Invalid choice. Please try again.")
@@ -726,53 +1008,117 @@ func handleConfigChoice(choice string, reader *bufio.Reader) bool {
 
 func showHelpScreen(reader *bufio.Reader) {
 	clearScreen()
-	color.Yellow("Virtual Bitcoin Trader (vBTC) - Version 1.6")
+	color.Yellow("Virtual Bitcoin Trader (vBTC) - Version %s", version.Version)
 	color.New(color.FgHiBlack).Println("═══════════════════════════════════════════════════════════════")
 	fmt.Println()
 
-	color.New(color.FgCyan).Println("COMMANDS:")
+	colorInfo.Println("COMMANDS:")
 	color.New(color.FgWhite).Print("    buy [amount]     ")
-	color.New(color.FgHiBlack).Println("Purchase a specific USD amount of Bitcoin")
+	color.New(color.FgHiBlack).Println("Purchase a specific cash amount of Bitcoin (in your base currency)")
 	color.New(color.FgWhite).Print("    sell [amount]    ")
 	color.New(color.FgHiBlack).Println("Sell a specific amount of BTC (e.g., 0.5) or satoshis (e.g., 50000s)")
-	color.New(color.FgWhite).Print("    ledger           ")
-	color.New(color.FgHiBlack).Println("View a history of all your transactions")
+	color.New(color.FgWhite).Print("    quote [amount]   ")
+	color.New(color.FgHiBlack).Println("Preview a buy or sell (e.g. 'quote 100', 'quote sell 0.5') without trading")
+	color.New(color.FgWhite).Print("    rebalance <pct>  ")
+	color.New(color.FgHiBlack).Println("Plan the trade that makes BTC <pct>% of cash + BTC, then optionally make it")
+	color.New(color.FgWhite).Print("    undo             ")
+	color.New(color.FgHiBlack).Println("Reverse your last trade within 60 seconds (UndoSeconds in vbtc.ini)")
+	color.New(color.FgWhite).Print("    ledger [filter]  ")
+	color.New(color.FgHiBlack).Println("View your transactions a page at a time (filters: type: from: to: min:, note / #tag)")
+	color.New(color.FgWhite).Print("    orders [...]     ")
+	color.New(color.FgHiBlack).Println("Place, list, cancel, and edit limit orders")
+	color.New(color.FgWhite).Print("    triggers [...]   ")
+	color.New(color.FgHiBlack).Println("Set a stop-loss / take-profit price for your BTC")
+	color.New(color.FgWhite).Print("    alert [...]      ")
+	color.New(color.FgHiBlack).Println("List, add and remove price alerts (e.g. 'alert > 100000')")
+	color.New(color.FgWhite).Print("    pnl [method]     ")
+	color.New(color.FgHiBlack).Println("Realized / unrealized gains per coin (method: fifo or average)")
+	color.New(color.FgWhite).Print("    stats            ")
+	color.New(color.FgHiBlack).Println("Win rate, drawdown, holding times and monthly P/L from the ledger")
+	color.New(color.FgWhite).Print("    history          ")
+	color.New(color.FgHiBlack).Println("Chart of your daily portfolio value (equity curve)")
+	color.New(color.FgWhite).Print("    leaderboard      ")
+	color.New(color.FgHiBlack).Println("Rankings on the shared leaderboard (join it in the Config menu)")
+	color.New(color.FgWhite).Print("    status --json    ")
+	color.New(color.FgHiBlack).Println("Print market data and portfolio as JSON")
+	color.New(color.FgWhite).Print("    export <fmt>     ")
+	color.New(color.FgHiBlack).Println("Export the ledger as json, ofx or qif (optional from/to dates)")
+	color.New(color.FgWhite).Print("    import <file>    ")
+	color.New(color.FgHiBlack).Println("Add trades from a Coinbase or Kraken CSV export to the ledger")
 	color.New(color.FgWhite).Print("    refresh          ")
 	color.New(color.FgHiBlack).Println("Manually update the market data")
 	color.New(color.FgWhite).Print("    config           ")
 	color.New(color.FgHiBlack).Println("Access the configuration menu")
-	color.New(color.FgWhite).Print("    help             ")
-	color.New(color.FgHiBlack).Println("Show this help screen")
+	color.New(color.FgWhite).Print("    help [command]   ")
+	color.New(color.FgHiBlack).Println("Show this help screen, or details and examples for one command")
 	color.New(color.FgWhite).Print("    exit             ")
 	color.New(color.FgHiBlack).Println("Exit the application")
 	fmt.Println()
 
-	color.New(color.FgGreen).Println("TIPS:")
-	color.New(color.FgYellow).Print("    • ")
+	colorPositive.Println("TIPS:")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("Commands may be shortened (e.g. 'b 10' to buy $10 of BTC)")
-	color.New(color.FgYellow).Print("    • ")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("Use 'p' for percentage trades (e.g., '50p' for 50%, '100/3p' for 33.3%)")
-	color.New(color.FgYellow).Print("    • ")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("Add a coin symbol to trade other tracked coins (e.g. 'b 10 eth', 's 50p ltc')")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("Volatility shows the price swing (High vs Low) over the last 24 hours")
-	color.New(color.FgYellow).Print("    • ")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("1H SMA is the average price over the last hour. Green = price is above average")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("'orders buy 100 60000' buys $100 of BTC once the price is at or below $60,000")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("'orders sell 50p 75000' sells half your BTC at or above $75,000; 'orders cancel 1'")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("Limit orders are checked on every refresh and trade, and fill at the current price")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("'triggers stop 55000' / 'triggers take 80000' sell all BTC when the price gets there")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("Add a note when you accept a trade; '#words' become tags you can search with 'ledger #tag'")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("'ledger type:sell from:2024-01-01 min:500' lists 2024 sales of $500+; N/P page, O sorts")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("'alert < 90000' shows a banner and beeps when a refresh or trade sees BTC fall below $90,000")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("The price is checked every minute at the prompt; press Enter to show a new price")
+	colorTitle.Print("    • ")
+	color.New(color.FgHiBlack).Println("'export qif 2024-01-01 2024-06-30' exports the first half of 2024 with cost basis")
 	fmt.Println()
 
 	color.New(color.FgBlue).Println("REQUIREMENTS:")
-	color.New(color.FgYellow).Print("    • ")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("Go runtime")
-	color.New(color.FgYellow).Print("    • ")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("An internet connection")
-	color.New(color.FgYellow).Print("    • ")
+	colorTitle.Print("    • ")
 	color.New(color.FgHiBlack).Println("A free API key from https://www.livecoinwatch.com/tools/api")
 	fmt.Println()
-	color.New(color.FgCyan).Println("COMMAND LINE:")
+	colorInfo.Println("COMMAND LINE:")
 	color.New(color.FgWhite).Print("    -help, -h, --help  ")
 	color.New(color.FgHiBlack).Println("Show this help and exit")
 	color.New(color.FgWhite).Print("    -config, --config  ")
 	color.New(color.FgHiBlack).Println("Open configuration (e.g. to fix API key) and exit")
 	color.New(color.FgWhite).Print("    -verbose, -v       ")
 	color.New(color.FgHiBlack).Println("Print velocity calculation details to stderr")
+	color.New(color.FgWhite).Print("    --replay <file>    ")
+	color.New(color.FgHiBlack).Println("Practice against past prices from a CSV/JSON file (own portfolio)")
+	color.New(color.FgWhite).Print("    --speed <n>        ")
+	color.New(color.FgHiBlack).Println("Replay speed multiplier (default 60: one minute per second)")
+	color.New(color.FgWhite).Print("    --demo             ")
+	color.New(color.FgHiBlack).Println("Try vBTC with simulated prices, no API key needed (own portfolio)")
+	color.New(color.FgWhite).Print("    status --json      ")
+	color.New(color.FgHiBlack).Println("Print market data and portfolio as JSON and exit")
+	color.New(color.FgWhite).Print("    backtest [options] ")
+	color.New(color.FgHiBlack).Println("Test an SMA buy/sell strategy against past prices and exit")
+	color.New(color.FgWhite).Print("    --version          ")
+	color.New(color.FgHiBlack).Println("Print the version and exit")
+	color.New(color.FgWhite).Print("    --check-update     ")
+	color.New(color.FgHiBlack).Println("Check GitHub for a newer release and exit")
+	color.New(color.FgWhite).Print("    --debug            ")
+	color.New(color.FgHiBlack).Println("Write a debug log (API calls, file writes, errors)")
+	color.New(color.FgWhite).Print("    --log-file <path>  ")
+	color.New(color.FgHiBlack).Println("Write the log to <path> instead of the default")
 	fmt.Println()
 	color.New(color.FgHiBlack).Println("═══════════════════════════════════════════════════════════════")
 	fmt.Println()
@@ -856,7 +1202,9 @@ func showHelpScreen(reader *bufio.Reader) {
 	}
 }
 
-func showLedgerScreen(reader *bufio.Reader) {
+// showLedgerScreen shows one page of the ledger, filtered and sorted by view,
+// and the summary of all ledger data.
+func showLedgerScreen(reader *bufio.Reader, view ledgerView) {
 	clearScreen()
 	color.Yellow("*** Ledger ***")
 
@@ -870,6 +1218,18 @@ func showLedgerScreen(reader *bufio.Reader) {
 	}
 	hasAnyData := len(allEntries) > 0
 	ledgerEntries, _ := readAndParseLedger() // current log only (for table)
+	if view.filtered() {
+		ledgerEntries = nil
+		for _, entry := range allEntries {
+			if view.matches(entry) {
+				ledgerEntries = append(ledgerEntries, entry)
+			}
+		}
+		colorInfo.Printf("Filter: %s (%d of %d transactions)\n", view.filters(), len(ledgerEntries), len(allEntries))
+	}
+	matchedRows := len(ledgerEntries)
+	ledgerEntries, pageNum, pages := view.rows(ledgerEntries)
+	view.Page = pageNum
 	currentHasRows := len(ledgerEntries) > 0
 
 	if !hasAnyData {
@@ -880,33 +1240,48 @@ func showLedgerScreen(reader *bufio.Reader) {
 	}
 
 	if currentHasRows {
-		// Sort entries by date and time to ensure chronological order for display.
-		sort.Slice(ledgerEntries, func(i, j int) bool {
-			return ledgerEntries[i].DateTime.Before(ledgerEntries[j].DateTime)
-		})
-
 		// 2. Dynamically calculate column widths for proper alignment.
 		columnOrder := []string{"TX", "USD", "BTC", "BTC(USD)", "User BTC", "Time"}
 		widths := map[string]int{
 			"TX": len("TX"), "USD": len("USD"), "BTC": len("BTC"),
-			"BTC(USD)": len("BTC(USD)"), "User BTC": len("User BTC"), "Time": len("Time"),
+			"BTC(USD)": len("BTC(USD)"), "User BTC": len("User BTC"), "Time": len("Time"), "Coin": len("Coin"), "Fee": len("Fee"), "Note": len("Note"),
+		}
+		// Show which coin each row traded once the ledger holds anything besides BTC,
+		// the fee once any trade was charged one, and notes once any trade has one.
+		showCoin, showFee, showNote := false, false, false
+		for _, entry := range ledgerEntries {
+			showCoin = showCoin || entry.Coin != "BTC"
+			showFee = showFee || entry.Fee > 0
+			showNote = showNote || entry.NoteText() != ""
+			if utf8.RuneCountInString(formatFloat(entry.Fee, 2)) > widths["Fee"] {
+				widths["Fee"] = utf8.RuneCountInString(formatFloat(entry.Fee, 2))
+			}
+		}
+		if showCoin {
+			columnOrder = append(columnOrder, "Coin")
+		}
+		if showFee {
+			columnOrder = append(columnOrder, "Fee")
+		}
+		if showNote {
+			columnOrder = append(columnOrder, "Note")
 		}
 
 		for _, entry := range ledgerEntries {
 			if len(entry.TX) > widths["TX"] {
 				widths["TX"] = len(entry.TX)
 			}
-			if len(formatFloat(entry.USD, 2)) > widths["USD"] {
-				widths["USD"] = len(formatFloat(entry.USD, 2))
+			if utf8.RuneCountInString(formatFloat(entry.USD, 2)) > widths["USD"] {
+				widths["USD"] = utf8.RuneCountInString(formatFloat(entry.USD, 2))
 			}
-			if len(fmt.Sprintf("%.8f", entry.BTC)) > widths["BTC"] {
-				widths["BTC"] = len(fmt.Sprintf("%.8f", entry.BTC))
+			if utf8.RuneCountInString(formatCoinAmount(entry.BTC)) > widths["BTC"] {
+				widths["BTC"] = utf8.RuneCountInString(formatCoinAmount(entry.BTC))
 			}
-			if len(formatFloat(entry.BTCPrice, 2)) > widths["BTC(USD)"] {
-				widths["BTC(USD)"] = len(formatFloat(entry.BTCPrice, 2))
+			if utf8.RuneCountInString(formatFloat(entry.BTCPrice, 2)) > widths["BTC(USD)"] {
+				widths["BTC(USD)"] = utf8.RuneCountInString(formatFloat(entry.BTCPrice, 2))
 			}
-			if len(fmt.Sprintf("%.8f", entry.UserBTC)) > widths["User BTC"] {
-				widths["User BTC"] = len(fmt.Sprintf("%.8f", entry.UserBTC))
+			if utf8.RuneCountInString(formatCoinAmount(entry.UserBTC)) > widths["User BTC"] {
+				widths["User BTC"] = utf8.RuneCountInString(formatCoinAmount(entry.UserBTC))
 			}
 			if len(entry.Time) > widths["Time"] {
 				widths["Time"] = len(entry.Time)
@@ -924,13 +1299,12 @@ func showLedgerScreen(reader *bufio.Reader) {
 		fmt.Println(header)
 		fmt.Println(separator)
 
-		// 4. Print data rows (only show session start line if session start is within displayed range, not in archive)
-		sessionStarted := false
+		// 4. Print data rows, with the session start line between the rows on either side of it
 		sessionStartTruncated := sessionStartTime.Truncate(time.Second)
-		minDisplayTime := ledgerEntries[0].DateTime
-		sessionStartInDisplayRange := !minDisplayTime.IsZero() && !sessionStartTruncated.Before(minDisplayTime)
-		for _, entry := range ledgerEntries {
-			if sessionStartInDisplayRange && !sessionStarted && !entry.DateTime.IsZero() && !entry.DateTime.Before(sessionStartTruncated) {
+		for i, entry := range ledgerEntries {
+			inSession := !entry.DateTime.IsZero() && !entry.DateTime.Before(sessionStartTruncated)
+			prevInSession := i > 0 && !ledgerEntries[i-1].DateTime.IsZero() && !ledgerEntries[i-1].DateTime.Before(sessionStartTruncated)
+			if i > 0 && !ledgerEntries[i-1].DateTime.IsZero() && !entry.DateTime.IsZero() && inSession != prevInSession {
 				totalWidth := len(separator)
 				sessionText := "*** Current Session Start ***"
 				paddingLength := 0
@@ -939,26 +1313,42 @@ func showLedgerScreen(reader *bufio.Reader) {
 				}
 				centeredText := strings.Repeat(" ", paddingLength) + sessionText
 				color.White(centeredText)
-				sessionStarted = true
 			}
 
-			rowColor := color.New(color.FgGreen)
-			if entry.TX == "Sell" {
-				rowColor = color.New(color.FgRed)
+			rowColor := colorPositive
+			if ledgerSide(entry.TX) == "Sell" {
+				rowColor = colorNegative
+			} else if isRevertedTX(entry.TX) {
+				rowColor = color.New(color.FgHiBlack)
 			}
 
 			// Build the row dynamically with correct alignment.
 			rowParts := []string{
 				fmt.Sprintf("%-*s", widths["TX"], entry.TX),                  // Left-align TX
 				fmt.Sprintf("%*s", widths["USD"], formatFloat(entry.USD, 2)), // Right-align numbers
-				fmt.Sprintf("%*s", widths["BTC"], fmt.Sprintf("%.8f", entry.BTC)),
+				fmt.Sprintf("%*s", widths["BTC"], formatCoinAmount(entry.BTC)),
 				fmt.Sprintf("%*s", widths["BTC(USD)"], formatFloat(entry.BTCPrice, 2)),
-				fmt.Sprintf("%*s", widths["User BTC"], fmt.Sprintf("%.8f", entry.UserBTC)),
+				fmt.Sprintf("%*s", widths["User BTC"], formatCoinAmount(entry.UserBTC)),
 				fmt.Sprintf("%*s", widths["Time"], entry.Time),
 			}
+			if showCoin {
+				rowParts = append(rowParts, fmt.Sprintf("%-*s", widths["Coin"], entry.Coin))
+			}
+			if showFee {
+				rowParts = append(rowParts, fmt.Sprintf("%*s", widths["Fee"], formatFloat(entry.Fee, 2)))
+			}
+			if showNote {
+				rowParts = append(rowParts, entry.NoteText()) // last column, not padded
+			}
 			row := strings.Join(rowParts, "  ")
 			rowColor.Println(row)
 		}
+		if pages > 1 {
+			first := pageNum*ledgerPageSize() + 1
+			color.New(color.FgHiBlack).Printf("Page %d of %d (rows %d-%d of %d)\n", pageNum+1, pages, first, first+len(ledgerEntries)-1, matchedRows)
+		}
+	} else if view.filtered() {
+		fmt.Println("No matching transactions.")
 	} else {
 		fmt.Println("Log Empty")
 	}
@@ -977,15 +1367,15 @@ func showLedgerScreen(reader *bufio.Reader) {
 
 	portfolioColor := color.New(color.FgWhite)
 	if portfolioValue > startingCapital {
-		portfolioColor = color.New(color.FgGreen)
+		portfolioColor = colorPositive
 	} else if portfolioValue < startingCapital {
-		portfolioColor = color.New(color.FgRed)
+		portfolioColor = colorNegative
 	}
 
 	// Portfolio Value with session delta in [] (green if up, red if down); brackets white, content colored
 	fmt.Print("Portfolio Value:")
 	fmt.Print(strings.Repeat(" ", summaryValueStartColumn-len("Portfolio Value:")))
-	portfolioColor.Print(fmt.Sprintf("$%s", formatFloat(portfolioValue, 2)))
+	portfolioColor.Print(formatMoney(portfolioValue))
 	if sessionStartPortfolioValue > 0 {
 		sessionPortfolioDelta := portfolioValue - sessionStartPortfolioValue
 		sign := "+"
@@ -993,12 +1383,12 @@ func showLedgerScreen(reader *bufio.Reader) {
 			sign = "-"
 		}
 		absDelta := math.Abs(sessionPortfolioDelta)
-		deltaContent := fmt.Sprintf("%s$%s", sign, formatFloat(absDelta, 2))
+		deltaContent := fmt.Sprintf("%s%s", sign, formatMoney(absDelta))
 		deltaColor := color.New(color.FgWhite)
 		if sessionPortfolioDelta > 0 {
-			deltaColor = color.New(color.FgGreen)
+			deltaColor = colorPositive
 		} else if sessionPortfolioDelta < 0 {
-			deltaColor = color.New(color.FgRed)
+			deltaColor = colorNegative
 		}
 		color.New(color.FgWhite).Print(" [")
 		deltaColor.Print(deltaContent)
@@ -1009,17 +1399,17 @@ func showLedgerScreen(reader *bufio.Reader) {
 
 	// Trading Statistics Section (all-time with session in []); brackets white, content colored
 	if summary.TotalBuyUSD > 0 {
-		v := fmt.Sprintf("$%s", formatFloat(summary.TotalBuyUSD, 2))
+		v := formatMoney(summary.TotalBuyUSD)
 		if sessionSummary != nil {
-			writeAlignedLineWithBrackets("Total Bought (USD):", v, fmt.Sprintf("$%s", formatFloat(sessionSummary.TotalBuyUSD, 2)), color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLineWithBrackets(currencyLabel("Total Bought"), v, formatMoney(sessionSummary.TotalBuyUSD), colorPositive, summaryValueStartColumn)
 		} else {
-			writeAlignedLine("Total Bought (USD):", v, color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLine(currencyLabel("Total Bought"), v, colorPositive, summaryValueStartColumn)
 		}
-		btcVal := fmt.Sprintf("%.8f", summary.TotalBuyBTC)
+		btcVal := formatCoinAmount(summary.TotalBuyBTC)
 		if sessionSummary != nil {
-			writeAlignedLineWithBrackets("Total Bought (BTC):", btcVal, fmt.Sprintf("%.8f", sessionSummary.TotalBuyBTC), color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLineWithBrackets("Total Bought (BTC):", btcVal, formatCoinAmount(sessionSummary.TotalBuyBTC), colorPositive, summaryValueStartColumn)
 		} else {
-			writeAlignedLine("Total Bought (BTC):", btcVal, color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLine("Total Bought (BTC):", btcVal, colorPositive, summaryValueStartColumn)
 		}
 	}
 
@@ -1035,30 +1425,38 @@ func showLedgerScreen(reader *bufio.Reader) {
 	}
 
 	if summary.AvgBuyPrice > 0 {
-		v := fmt.Sprintf("$%s", formatFloat(summary.AvgBuyPrice, 2))
+		v := formatMoney(summary.AvgBuyPrice)
 		if sessionSummary != nil && sessionSummary.AvgBuyPrice > 0 {
-			writeAlignedLineWithBrackets("Average Purchase:", v, fmt.Sprintf("$%s", formatFloat(sessionSummary.AvgBuyPrice, 2)), color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLineWithBrackets("Average Purchase:", v, formatMoney(sessionSummary.AvgBuyPrice), colorPositive, summaryValueStartColumn)
 		} else if sessionSummary != nil {
-			writeAlignedLineWithBrackets("Average Purchase:", v, "$0.00", color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLineWithBrackets("Average Purchase:", v, formatMoney(0), colorPositive, summaryValueStartColumn)
 		} else {
-			writeAlignedLine("Average Purchase:", v, color.New(color.FgGreen), summaryValueStartColumn)
+			writeAlignedLine("Average Purchase:", v, colorPositive, summaryValueStartColumn)
 		}
 	}
 
 	if summary.AvgSalePrice > 0 {
-		v := fmt.Sprintf("$%s", formatFloat(summary.AvgSalePrice, 2))
+		v := formatMoney(summary.AvgSalePrice)
 		if sessionSummary != nil && sessionSummary.AvgSalePrice > 0 {
-			writeAlignedLineWithBrackets("Average Sale:", v, fmt.Sprintf("$%s", formatFloat(sessionSummary.AvgSalePrice, 2)), color.New(color.FgRed), summaryValueStartColumn)
+			writeAlignedLineWithBrackets("Average Sale:", v, formatMoney(sessionSummary.AvgSalePrice), colorNegative, summaryValueStartColumn)
 		} else if sessionSummary != nil {
-			writeAlignedLineWithBrackets("Average Sale:", v, "$0.00", color.New(color.FgRed), summaryValueStartColumn)
+			writeAlignedLineWithBrackets("Average Sale:", v, formatMoney(0), colorNegative, summaryValueStartColumn)
 		} else {
-			writeAlignedLine("Average Sale:", v, color.New(color.FgRed), summaryValueStartColumn)
+			writeAlignedLine("Average Sale:", v, colorNegative, summaryValueStartColumn)
 		}
 	}
 	if totalTransactions > 0 && summary.MaxUSD >= summary.MinUSD {
-		writeAlignedLine("Tx Range:", fmt.Sprintf("$%s - $%s", formatFloat(summary.MinUSD, 2), formatFloat(summary.MaxUSD, 2)), color.New(color.FgWhite), summaryValueStartColumn)
+		writeAlignedLine("Tx Range:", fmt.Sprintf("%s - %s", formatMoney(summary.MinUSD), formatMoney(summary.MaxUSD)), color.New(color.FgWhite), summaryValueStartColumn)
 		if sessionSummary != nil && sessionSummary.MaxUSD >= sessionSummary.MinUSD {
-			writeAlignedLine("Session Tx Range:", fmt.Sprintf("$%s - $%s", formatFloat(sessionSummary.MinUSD, 2), formatFloat(sessionSummary.MaxUSD, 2)), color.New(color.FgWhite), summaryValueStartColumn)
+			writeAlignedLine("Session Tx Range:", fmt.Sprintf("%s - %s", formatMoney(sessionSummary.MinUSD), formatMoney(sessionSummary.MaxUSD)), color.New(color.FgWhite), summaryValueStartColumn)
+		}
+	}
+	if summary.TotalFees > 0 {
+		v := formatMoney(summary.TotalFees)
+		if sessionSummary != nil {
+			writeAlignedLineWithBrackets("Fees Paid:", v, formatMoney(sessionSummary.TotalFees), colorNegative, summaryValueStartColumn)
+		} else {
+			writeAlignedLine("Fees Paid:", v, colorNegative, summaryValueStartColumn)
 		}
 	}
 	totalLen := formatDuration(summary.FirstTime, summary.LastTime)
@@ -1096,6 +1494,11 @@ func showLedgerScreen(reader *bufio.Reader) {
 	}
 
 	fmt.Println("\nPress Enter to return to Main screen, or R to refresh")
+	order := "newest first"
+	if view.NewestFirst {
+		order = "oldest first"
+	}
+	color.New(color.FgHiBlack).Printf("N/P next/previous page, F filter, / search notes and #tags, O %s, C clear\n", order)
 
 	// --- Raw Terminal Input Setup ---
 	// Get the file descriptor for standard input.
@@ -1238,9 +1641,79 @@ func showLedgerScreen(reader *bufio.Reader) {
 			}
 
 			// Recursively call showLedgerScreen to redraw with fresh ledger data
-			showLedgerScreen(reader)
+			showLedgerScreen(reader, view)
 			return
 		}
+
+		// Paging, sorting and filter keys redraw the screen with a changed view.
+		next := view
+		switch b {
+		case 'N', 'n':
+			if view.Page+1 >= pages {
+				continue
+			}
+			next.Page++
+		case 'P', 'p':
+			if view.Page <= 0 {
+				continue
+			}
+			next.Page--
+		case 'O', 'o':
+			next.NewestFirst = !view.NewestFirst
+			next.Page = -1
+			if next.NewestFirst {
+				next.Page = 0
+			}
+		case 'C', 'c':
+			if !view.filtered() {
+				continue
+			}
+			next = ledgerView{NewestFirst: view.NewestFirst, Page: -1}
+			if next.NewestFirst {
+				next.Page = 0
+			}
+		case 'F', 'f', '/':
+		default:
+			continue
+		}
+
+		restoreNeeded = false // Prevent defer from restoring again
+		close(done)
+		wg.Wait()
+		term.Restore(fd, oldState)
+		reader.Reset(os.Stdin)
+
+		if b == 'F' || b == 'f' || b == '/' {
+			if b == '/' {
+				fmt.Print("Search notes and #tags (Enter to clear): ")
+			} else {
+				color.New(color.FgHiBlack).Println("type:buy|sell|<tx>  from:YYYY-MM-DD  to:YYYY-MM-DD  min:<usd>  plus any search words")
+				fmt.Print("Filter (Enter to clear): ")
+			}
+			input, _ := reader.ReadString('\n')
+			input = strings.TrimSpace(input)
+			if b == '/' {
+				// A search keeps the other filters.
+				view.Search = ""
+				input = strings.TrimSpace(view.filters() + " " + input)
+			}
+			parsed, err := parseLedgerView(input)
+			if err != nil {
+				color.Red("%v", err)
+				fmt.Println("Press Enter to continue.")
+				reader.ReadString('\n')
+				parsed = view
+			} else {
+				parsed.NewestFirst = view.NewestFirst
+				parsed.Page = -1
+				if parsed.NewestFirst {
+					parsed.Page = 0
+				}
+			}
+			next = parsed
+		}
+		showLedgerScreen(reader, next)
+		return
 	}
 }
 
@@ -1254,12 +1727,16 @@ func showExitScreen(reader *bufio.Reader) {
 
 	profitColor := color.New(color.FgWhite)
 	if profit > 0 {
-		profitColor = color.New(color.FgGreen)
+		profitColor = colorPositive
 	} else if profit < 0 {
-		profitColor = color.New(color.FgRed)
+		profitColor = colorNegative
 	}
 
-	writeAlignedLine("Portfolio Value:", fmt.Sprintf("$%s", formatFloat(finalValue, 2)), profitColor)
+	writeAlignedLine("Portfolio Value:", formatMoney(finalValue), profitColor)
+	postExitScore(finalValue)
+	recordSnapshot()
+	sessionLog.Info("session ended", "value", finalValue, "currency", currencyCode())
+	webhookPosts.Wait()
 
 	// --- Session Summary ---
 	fmt.Println()
@@ -1282,13 +1759,13 @@ func showExitScreen(reader *bufio.Reader) {
 	roundedFinal := math.Round(finalBtcPrice*100) / 100
 	sessionPriceColor := color.New(color.FgWhite)
 	if roundedFinal > roundedInitial {
-		sessionPriceColor = color.New(color.FgGreen)
+		sessionPriceColor = colorPositive
 	} else if roundedFinal < roundedInitial {
-		sessionPriceColor = color.New(color.FgRed)
+		sessionPriceColor = colorNegative
 	}
 
-	writeAlignedLine("Start BTC(USD):", fmt.Sprintf("$%s", formatFloat(initialSessionBtcPrice, 2)), color.New(color.FgWhite), sessionValueStartColumn)
-	writeAlignedLine("End BTC(USD):", fmt.Sprintf("$%s", formatFloat(finalBtcPrice, 2)), sessionPriceColor, sessionValueStartColumn)
+	writeAlignedLine(fmt.Sprintf("Start BTC(%s):", currencyCode()), formatMoney(initialSessionBtcPrice), color.New(color.FgWhite), sessionValueStartColumn)
+	writeAlignedLine(fmt.Sprintf("End BTC(%s):", currencyCode()), formatMoney(finalBtcPrice), sessionPriceColor, sessionValueStartColumn)
 
 	if sessionStartPortfolioValue > 0 {
 		sessionChange := finalValue - sessionStartPortfolioValue
@@ -1303,9 +1780,9 @@ func showExitScreen(reader *bufio.Reader) {
 
 		sessionColor := color.New(color.FgWhite)
 		if roundedFinalValue > roundedStartValue {
-			sessionColor = color.New(color.FgGreen)
+			sessionColor = colorPositive
 		} else if roundedFinalValue < roundedStartValue {
-			sessionColor = color.New(color.FgRed)
+			sessionColor = colorNegative
 		}
 		sessionDisplay := fmt.Sprintf("%s [%s]", formatProfitLoss(sessionChange, ""), fmt.Sprintf("%+.2f%%", sessionPercent))
 		writeAlignedLine("P/L:", sessionDisplay, sessionColor, sessionValueStartColumn)
@@ -1313,21 +1790,24 @@ func showExitScreen(reader *bufio.Reader) {
 
 	if summary != nil {
 		if summary.TotalBuyUSD > 0 {
-			writeAlignedLine("Total Bought (USD):", fmt.Sprintf("$%s", formatFloat(summary.TotalBuyUSD, 2)), color.New(color.FgGreen), sessionValueStartColumn)
-			writeAlignedLine("Total Bought (BTC):", fmt.Sprintf("%.8f", summary.TotalBuyBTC), color.New(color.FgGreen), sessionValueStartColumn)
+			writeAlignedLine(currencyLabel("Total Bought"), formatMoney(summary.TotalBuyUSD), colorPositive, sessionValueStartColumn)
+			writeAlignedLine("Total Bought (BTC):", formatCoinAmount(summary.TotalBuyBTC), colorPositive, sessionValueStartColumn)
 		}
 		if summary.TotalSellUSD > 0 {
-			writeAlignedLine("Total Sold (USD):", fmt.Sprintf("$%s", formatFloat(summary.TotalSellUSD, 2)), color.New(color.FgRed), sessionValueStartColumn)
-			writeAlignedLine("Total Sold (BTC):", fmt.Sprintf("%.8f", summary.TotalSellBTC), color.New(color.FgRed), sessionValueStartColumn)
+			writeAlignedLine(currencyLabel("Total Sold"), formatMoney(summary.TotalSellUSD), colorNegative, sessionValueStartColumn)
+			writeAlignedLine("Total Sold (BTC):", formatCoinAmount(summary.TotalSellBTC), colorNegative, sessionValueStartColumn)
 		}
 		if summary.AvgBuyPrice > 0 {
-			writeAlignedLine("Average Purchase:", fmt.Sprintf("$%s", formatFloat(summary.AvgBuyPrice, 2)), color.New(color.FgGreen), sessionValueStartColumn)
+			writeAlignedLine("Average Purchase:", formatMoney(summary.AvgBuyPrice), colorPositive, sessionValueStartColumn)
 		}
 		if summary.AvgSalePrice > 0 {
-			writeAlignedLine("Average Sale:", fmt.Sprintf("$%s", formatFloat(summary.AvgSalePrice, 2)), color.New(color.FgRed), sessionValueStartColumn)
+			writeAlignedLine("Average Sale:", formatMoney(summary.AvgSalePrice), colorNegative, sessionValueStartColumn)
+		}
+		if summary.TotalFees > 0 {
+			writeAlignedLine("Fees Paid:", formatMoney(summary.TotalFees), colorNegative, sessionValueStartColumn)
 		}
 		if summary.MaxUSD >= summary.MinUSD {
-			writeAlignedLine("Session Tx Range:", fmt.Sprintf("$%s - $%s", formatFloat(summary.MinUSD, 2), formatFloat(summary.MaxUSD, 2)), color.New(color.FgWhite), sessionValueStartColumn)
+			writeAlignedLine("Session Tx Range:", fmt.Sprintf("%s - %s", formatMoney(summary.MinUSD), formatMoney(summary.MaxUSD)), color.New(color.FgWhite), sessionValueStartColumn)
 		}
 		sessionLen := formatDuration(sessionStartTime, time.Now().UTC())
 		if sessionLen != "" {
@@ -1360,24 +1840,27 @@ func showExitScreen(reader *bufio.Reader) {
 
 		// Display totals
 		if allTimeSummary.TotalBuyUSD > 0 {
-			writeAlignedLine("Total Bought (USD):", fmt.Sprintf("$%s", formatFloat(allTimeSummary.TotalBuyUSD, 2)), color.New(color.FgGreen), ledgerValueStartColumn)
-			writeAlignedLine("Total Bought (BTC):", fmt.Sprintf("%.8f", allTimeSummary.TotalBuyBTC), color.New(color.FgGreen), ledgerValueStartColumn)
+			writeAlignedLine(currencyLabel("Total Bought"), formatMoney(allTimeSummary.TotalBuyUSD), colorPositive, ledgerValueStartColumn)
+			writeAlignedLine("Total Bought (BTC):", formatCoinAmount(allTimeSummary.TotalBuyBTC), colorPositive, ledgerValueStartColumn)
 		}
 		if allTimeSummary.TotalSellUSD > 0 {
-			writeAlignedLine("Total Sold (USD):", fmt.Sprintf("$%s", formatFloat(allTimeSummary.TotalSellUSD, 2)), color.New(color.FgRed), ledgerValueStartColumn)
-			writeAlignedLine("Total Sold (BTC):", fmt.Sprintf("%.8f", allTimeSummary.TotalSellBTC), color.New(color.FgRed), ledgerValueStartColumn)
+			writeAlignedLine(currencyLabel("Total Sold"), formatMoney(allTimeSummary.TotalSellUSD), colorNegative, ledgerValueStartColumn)
+			writeAlignedLine("Total Sold (BTC):", formatCoinAmount(allTimeSummary.TotalSellBTC), colorNegative, ledgerValueStartColumn)
 		}
 
 		// Display average prices
 		if allTimeSummary.AvgBuyPrice > 0 {
-			writeAlignedLine("Average Purchase:", fmt.Sprintf("$%s", formatFloat(allTimeSummary.AvgBuyPrice, 2)), color.New(color.FgGreen), ledgerValueStartColumn)
+			writeAlignedLine("Average Purchase:", formatMoney(allTimeSummary.AvgBuyPrice), colorPositive, ledgerValueStartColumn)
 		}
 		if allTimeSummary.AvgSalePrice > 0 {
-			writeAlignedLine("Average Sale:", fmt.Sprintf("$%s", formatFloat(allTimeSummary.AvgSalePrice, 2)), color.New(color.FgRed), ledgerValueStartColumn)
+			writeAlignedLine("Average Sale:", formatMoney(allTimeSummary.AvgSalePrice), colorNegative, ledgerValueStartColumn)
 		}
 		exitTxCount := allTimeSummary.BuyTransactions + allTimeSummary.SellTransactions
 		if exitTxCount > 0 && allTimeSummary.MaxUSD >= allTimeSummary.MinUSD {
-			writeAlignedLine("Tx Range:", fmt.Sprintf("$%s - $%s", formatFloat(allTimeSummary.MinUSD, 2), formatFloat(allTimeSummary.MaxUSD, 2)), color.New(color.FgWhite), ledgerValueStartColumn)
+			writeAlignedLine("Tx Range:", fmt.Sprintf("%s - %s", formatMoney(allTimeSummary.MinUSD), formatMoney(allTimeSummary.MaxUSD)), color.New(color.FgWhite), ledgerValueStartColumn)
+		}
+		if allTimeSummary.TotalFees > 0 {
+			writeAlignedLine("Fees Paid:", formatMoney(allTimeSummary.TotalFees), colorNegative, ledgerValueStartColumn)
 		}
 		exitTimeLen := formatDuration(allTimeSummary.FirstTime, allTimeSummary.LastTime)
 		if exitTimeLen != "" {
@@ -1388,23 +1871,23 @@ func showExitScreen(reader *bufio.Reader) {
 		netBTC := allTimeSummary.TotalBuyBTC - allTimeSummary.TotalSellBTC
 		netBTCColor := color.New(color.FgWhite)
 		if netBTC > 0 {
-			netBTCColor = color.New(color.FgGreen)
+			netBTCColor = colorPositive
 		} else if netBTC < 0 {
-			netBTCColor = color.New(color.FgRed)
+			netBTCColor = colorNegative
 		}
-		writeAlignedLine("Net BTC Position:", fmt.Sprintf("%.8f", netBTC), netBTCColor, ledgerValueStartColumn)
+		writeAlignedLine("Net BTC Position:", formatCoinAmount(netBTC), netBTCColor, ledgerValueStartColumn)
 
 		// Net Profit/Loss USD
 		netProfitLoss := allTimeSummary.TotalSellUSD - allTimeSummary.TotalBuyUSD
 		netPLColor := color.New(color.FgWhite)
 		if netProfitLoss > 0 {
-			netPLColor = color.New(color.FgGreen)
+			netPLColor = colorPositive
 		} else if netProfitLoss < 0 {
-			netPLColor = color.New(color.FgRed)
+			netPLColor = colorNegative
 		}
-		writeAlignedLine("Net Trading P/L (USD):", fmt.Sprintf("$%s", formatFloat(netProfitLoss, 2)), netPLColor, ledgerValueStartColumn)
+		writeAlignedLine(currencyLabel("Net Trading P/L"), formatMoney(netProfitLoss), netPLColor, ledgerValueStartColumn)
 	} else {
-		color.New(color.FgCyan).Println("No trading history found.")
+		colorInfo.Println("No trading history found.")
 	}
 
 	// Pause the screen if the application was likely run by double-clicking.
@@ -1441,8 +1924,7 @@ func writeAlignedLine(label, value string, c *color.Color, startColumn ...int) {
 	if padding < 0 {
 		padding = 0
 	}
-	fmt.Print(label)
-	fmt.Print(strings.Repeat(" ", padding))
+	fmt.Fprint(color.Output, label+strings.Repeat(" ", padding))
 	c.Println(value)
 }
 
@@ -1456,8 +1938,7 @@ func writeAlignedLineWithBrackets(label, mainPart, bracketContent string, c *col
 	if padding < 0 {
 		padding = 0
 	}
-	fmt.Print(label)
-	fmt.Print(strings.Repeat(" ", padding))
+	fmt.Fprint(color.Output, label+strings.Repeat(" ", padding))
 	c.Print(mainPart)
 	color.New(color.FgWhite).Print(" [")
 	c.Print(bracketContent)
@@ -1466,104 +1947,27 @@ func writeAlignedLineWithBrackets(label, mainPart, bracketContent string, c *col
 
 // --- API and Data Functions ---
 
-func fetchCurrentPriceData(apiKey string) (*ApiDataResponse, error) {
+func fetchCurrentPriceData(apiKey, currency string) (*ApiDataResponse, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is empty")
 	}
-	jsonData := map[string]string{"currency": "USD", "code": "BTC", "meta": "false"}
-	jsonValue, err := json.Marshal(jsonData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal json for current price: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "https://api.livecoinwatch.com/coins/single", bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request for current price: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request for current price: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
-		return nil, &ProviderDownError{StatusCode: resp.StatusCode, Message: "API provider returned server error"}
-	}
-
-	// Check for user-fixable API key errors
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return nil, &ApiKeyError{StatusCode: resp.StatusCode}
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		// Treat any other non-OK status as a provider problem, so the code is displayed.
-		return nil, &ProviderDownError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("API provider returned non-OK status %d", resp.StatusCode)}
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body for current price: %w", err)
-	}
-
 	var data ApiDataResponse
-	if err := json.Unmarshal(body, &data); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response for current price: %w", err)
+	payload := map[string]string{"currency": currency, "code": "BTC", "meta": "false"}
+	if err := postLiveCoinWatch("coins/single", apiKey, payload, &data, "current price"); err != nil {
+		return nil, err
 	}
 	data.FetchTime = time.Now().UTC()
 	return &data, nil
 }
 
-func getHistoricalData(apiKey string, start, end int64) (*HistoryResponse, error) {
+func getHistoricalData(apiKey, currency string, start, end int64) (*HistoryResponse, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is empty")
 	}
-
-	jsonData := map[string]interface{}{"currency": "USD", "code": "BTC", "start": start, "end": end, "meta": false}
-	jsonValue, err := json.Marshal(jsonData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal json for historical price: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "https://api.livecoinwatch.com/coins/single/history", bytes.NewBuffer(jsonValue))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request for historical price: %w", err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request for historical price: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 500 && resp.StatusCode <= 599 {
-		return nil, &ProviderDownError{StatusCode: resp.StatusCode, Message: "API provider returned server error for history"}
-	}
-
-	// Check for user-fixable API key errors
-	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
-		return nil, &ApiKeyError{StatusCode: resp.StatusCode}
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		// Treat any other non-OK status as a provider problem, so the code is displayed.
-		return nil, &ProviderDownError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("API provider for history returned non-OK status %d", resp.StatusCode)}
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body for historical price: %w", err)
-	}
-
 	var history HistoryResponse
-	if err := json.Unmarshal(body, &history); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response for historical price: %w", err)
+	payload := map[string]interface{}{"currency": currency, "code": "BTC", "start": start, "end": end, "meta": false}
+	if err := postLiveCoinWatch("coins/single/history", apiKey, payload, &history, "historical price"); err != nil {
+		return nil, err
 	}
 	return &history, nil
 }
@@ -1578,11 +1982,29 @@ func isApiDataStale() bool {
 
 func updateApiData(skipHistorical bool) *ApiDataResponse {
 	showLoadingScreen()
-	apiKey := cfg.Section("Settings").Key("ApiKey").String()
 
-	// 1. Always fetch the latest current price data.
-	newData, err := fetchCurrentPriceData(apiKey)
-	if err != nil {
+	// 1. Always fetch the latest current price data, falling back to the other providers.
+	var newData *ApiDataResponse
+	var err error
+	var provider PriceProvider
+	for i, p := range priceProviders() {
+		data, fetchErr := p.Current()
+		if fetchErr == nil {
+			newData, provider = data, p
+			if i > 0 {
+				slog.Warn("using fallback price provider", "provider", p.Name(), "err", err)
+				color.Yellow("%s is unavailable; using %s prices.", selectedProviderName(), p.Name())
+			}
+			err = nil
+			break
+		}
+		slog.Debug("price provider failed", "provider", p.Name(), "err", fetchErr)
+		if i == 0 {
+			err = fetchErr
+		}
+	}
+	if newData == nil {
+		slog.Error("current price fetch failed", "err", err)
 		fmt.Printf("Error fetching current price data: %v\n", err)
 
 		var apiKeyErr *ApiKeyError
@@ -1616,11 +2038,14 @@ func updateApiData(skipHistorical bool) *ApiDataResponse {
 		}
 		return newErrorData
 	}
+	newData.Provider = provider.Name()
+	updateCoinRates(provider, newData)
+	sessionLog.Info("price", "provider", newData.Provider, "rate", newData.Rate, "currency", currencyCode())
 
 	if !skipHistorical {
 		// 2. Check if historical data needs to be updated (stale if nil or > 15 mins old).
 		isStale := false
-		if apiData == nil {
+		if apiData == nil || replaySource != nil { // replayed history is local and moves faster than 15 minutes
 			isStale = true
 		} else {
 			// apiData is not nil here, so we can safely access its fields.
@@ -1632,13 +2057,27 @@ func updateApiData(skipHistorical bool) *ApiDataResponse {
 			}
 		}
 
-		if isStale {
-			color.Yellow("Fetching updated historical data...")
-			time.Sleep(1 * time.Second) // Let user see the message
+		// Near the daily API limit, keep the history longer (or indefinitely once it is used up).
+		if isStale && apiData != nil && !historyRefreshAllowed(provider.Name(), apiData.HistoricalDataFetchTime) {
+			isStale = false
+		}
 
-			end := time.Now().UTC()
+		if isStale {
+			end := marketNow()
 			start := end.Add(-24 * time.Hour)
-			history, historyErr := getHistoricalData(apiKey, start.UnixMilli(), end.UnixMilli())
+			// A history fetched in the last 15 minutes, e.g. before a restart, is reused from disk.
+			history, historyFetchTime := loadHistoryCache(newData.Rate)
+			var historyErr error
+			if history == nil {
+				color.Yellow("Fetching updated historical data...")
+				time.Sleep(1 * time.Second) // Let user see the message
+
+				history, historyErr = provider.History(start, end)
+				historyFetchTime = time.Now().UTC()
+				if historyErr == nil && history != nil && len(history.History) > 0 {
+					saveHistoryCache(provider.Name(), history)
+				}
+			}
 
 			if historyErr == nil && history != nil && len(history.History) > 0 {
 				// Successfully fetched new historical data.
@@ -1649,7 +2088,7 @@ func updateApiData(skipHistorical bool) *ApiDataResponse {
 				var closestRate float64
 				minDiff := int64(math.MaxInt64)
 
-				now := time.Now().UTC()
+				now := marketNow()
 				startTs := now.Add(-24 * time.Hour).UnixMilli()
 				midpointTs := now.Add(-12 * time.Hour).UnixMilli()
 
@@ -1742,13 +2181,17 @@ func updateApiData(skipHistorical bool) *ApiDataResponse {
 					}
 				}
 				newData.Rate24hTotalChange1h = totalChange1h
+				newData.Sparkline = hourlySamples(history.History, end, sparklinePoints)
+				setIndicators(newData, history.History)
+				newData.History = history.History
 				if verbose {
 					fmt.Fprintf(os.Stderr, "TotalChange (sum of absolute deltas over 24h history): %.2f from %d points; 1HourDeltaTotal: %.2f\n", totalChange, len(history.History), totalChange1h)
 				}
-				newData.HistoricalDataFetchTime = time.Now().UTC()
+				newData.HistoricalDataFetchTime = historyFetchTime
 			} else {
 				// Historical fetch failed, use fallback.
 				if historyErr != nil {
+					slog.Warn("24h history fetch failed, using fallbacks", "err", historyErr)
 					var apiKeyErr *ApiKeyError
 					// If it's NOT an API key error, flag it as a network error.
 					// If it IS an API key error, we just let it fail silently and use fallbacks,
@@ -1800,22 +2243,21 @@ func updateApiData(skipHistorical bool) *ApiDataResponse {
 }
 
 func testApiKey(apiKey string) bool {
-	jsonData := map[string]string{"currency": "USD", "code": "BTC", "meta": "false"}
-	jsonValue, _ := json.Marshal(jsonData)
-	req, _ := http.NewRequest("POST", "https://api.livecoinwatch.com/coins/single", bytes.NewBuffer(jsonValue))
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", apiKey)
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	return err == nil && resp.StatusCode == 200
+	return apikey.LiveCoinWatch.Validate(apiKey)
 }
 
+// getPortfolioValue values cash and BTC as given, plus any other tracked coins held in cfg.
 func getPortfolioValue(playerUSD, playerBTC float64, apiData *ApiDataResponse) float64 {
-	if apiData != nil {
-		return playerUSD + (playerBTC * apiData.Rate)
+	if apiData == nil {
+		return playerUSD
 	}
-	return playerUSD
+	holdings := map[string]float64{"BTC": playerBTC}
+	if cfg != nil {
+		for _, coin := range trackedCoins() {
+			holdings[coin], _ = cfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+		}
+	}
+	return engine.Value(playerUSD, holdings, marketPrices{apiData})
 }
 
 func copyHistoricalData(source, dest *ApiDataResponse) {
@@ -1834,6 +2276,13 @@ func copyHistoricalData(source, dest *ApiDataResponse) {
 	dest.Rate24hTotalChange = source.Rate24hTotalChange
 	dest.Rate24hTotalChange1h = source.Rate24hTotalChange1h
 	dest.HistoricalDataFetchTime = source.HistoricalDataFetchTime
+	dest.Sparkline = source.Sparkline
+	dest.Rsi14 = source.Rsi14
+	dest.HasRsi = source.HasRsi
+	dest.Macd = source.Macd
+	dest.MacdSignal = source.MacdSignal
+	dest.HasMacd = source.HasMacd
+	dest.History = source.History
 }
 
 func readAndParseLedger() ([]LedgerEntry, error) {
@@ -1847,6 +2296,7 @@ func readAndParseLedger() ([]LedgerEntry, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Rows written before the Coin and Fee columns have fewer fields
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
@@ -1855,22 +2305,7 @@ func readAndParseLedger() ([]LedgerEntry, error) {
 		return nil, nil // No records or just header
 	}
 
-	var ledgerEntries []LedgerEntry
-	for _, record := range records[1:] { // Skip header
-		usd, _ := strconv.ParseFloat(strings.ReplaceAll(record[1], ",", ""), 64)
-		btc, _ := strconv.ParseFloat(strings.ReplaceAll(record[2], ",", ""), 64)
-		btcPrice, _ := strconv.ParseFloat(strings.ReplaceAll(record[3], ",", ""), 64)
-		userBTC, _ := strconv.ParseFloat(strings.ReplaceAll(record[4], ",", ""), 64)
-		dateTime, err := time.ParseInLocation("010206@150405", record[5], time.UTC)
-		if err != nil {
-			fmt.Printf("\nWarning: Could not parse timestamp '%s' in ledger.csv. Ignoring for calculation.\n", record[5])
-		}
-		ledgerEntries = append(ledgerEntries, LedgerEntry{
-			TX: record[0], USD: usd, BTC: btc,
-			BTCPrice: btcPrice, UserBTC: userBTC, Time: record[5], DateTime: dateTime,
-		})
-	}
-	return ledgerEntries, nil
+	return parseLedgerRecords(records[1:], "ledger.csv"), nil // Skip header
 }
 
 func readAllLedgerEntries() ([]LedgerEntry, error) {
@@ -1983,6 +2418,7 @@ func readLedgerFromFile(filePath string) ([]LedgerEntry, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Rows written before the Coin and Fee columns have fewer fields
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
@@ -1991,22 +2427,25 @@ func readLedgerFromFile(filePath string) ([]LedgerEntry, error) {
 		return nil, nil // No records or just header
 	}
 
+	return parseLedgerRecords(records[1:], filePath), nil // Skip header
+}
+
+// parseLedgerRecords converts ledger rows (TX, USD, BTC, BTC(USD), User BTC, Time[, Coin[, Fee[, Note, Tags]]])
+// to entries. The BTC columns hold the traded coin's amounts; rows without a Coin are BTC.
+func parseLedgerRecords(records [][]string, source string) []LedgerEntry {
 	var ledgerEntries []LedgerEntry
-	for _, record := range records[1:] { // Skip header
-		usd, _ := strconv.ParseFloat(strings.ReplaceAll(record[1], ",", ""), 64)
-		btc, _ := strconv.ParseFloat(strings.ReplaceAll(record[2], ",", ""), 64)
-		btcPrice, _ := strconv.ParseFloat(strings.ReplaceAll(record[3], ",", ""), 64)
-		userBTC, _ := strconv.ParseFloat(strings.ReplaceAll(record[4], ",", ""), 64)
-		dateTime, err := time.ParseInLocation("010206@150405", record[5], time.UTC)
+	for _, record := range records {
+		entry, err := engine.ParseLedgerRecord(record)
+		if errors.Is(err, engine.ErrShortRow) {
+			fmt.Fprintf(color.Output, "\nWarning: Skipping short row in %s.\n", source)
+			continue
+		}
 		if err != nil {
-			fmt.Printf("\nWarning: Could not parse timestamp '%s' in %s. Ignoring for calculation.\n", record[5], filePath)
+			fmt.Fprintf(color.Output, "\nWarning: Could not parse timestamp '%s' in %s. Ignoring for calculation.\n", record[5], source)
 		}
-		ledgerEntries = append(ledgerEntries, LedgerEntry{
-			TX: record[0], USD: usd, BTC: btc,
-			BTCPrice: btcPrice, UserBTC: userBTC, Time: record[5], DateTime: dateTime,
-		})
+		ledgerEntries = append(ledgerEntries, entry)
 	}
-	return ledgerEntries, nil
+	return ledgerEntries
 }
 
 func readAndParseLedgerRaw() ([][]string, error) {
@@ -2020,6 +2459,7 @@ func readAndParseLedgerRaw() ([][]string, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Rows written before the Coin and Fee columns have fewer fields
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, err
@@ -2031,24 +2471,24 @@ func readAndParseLedgerRaw() ([][]string, error) {
 }
 
 func writeLedgerRaw(header []string, dataRecords [][]string) error {
-	file, err := os.Create(ledgerFilePath) // Create truncates the file
+	unlock, err := lockPortfolio()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	if err := writer.Write(header); err != nil {
-		return err
-	}
-	if len(dataRecords) > 0 {
-		if err := writer.WriteAll(dataRecords); err != nil {
+	defer unlock()
+	slog.Info("rewriting ledger", "path", ledgerFilePath, "records", len(dataRecords))
+	// Write a temporary copy and rename it over the ledger, so a failed write never truncates it.
+	err = writeFileAtomic(ledgerFilePath, func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		if err := writer.Write(header); err != nil {
 			return err
 		}
+		return writer.WriteAll(dataRecords) // WriteAll flushes
+	})
+	if err != nil {
+		slog.Error("ledger rewrite failed", "path", ledgerFilePath, "err", err)
 	}
-	return nil
+	return err
 }
 
 func getLedgerTotals(entries []LedgerEntry) *LedgerSummary {
@@ -2058,6 +2498,12 @@ func getLedgerTotals(entries []LedgerEntry) *LedgerSummary {
 	var totalWeightedBuyPrice, totalWeightedSellPrice float64
 
 	for _, entry := range entries {
+		if entry.Coin != "" && entry.Coin != "BTC" {
+			continue // Amounts and prices of other coins are not comparable with BTC
+		}
+		if isRevertedTX(entry.TX) {
+			continue // Undone trades moved no money
+		}
 		if !entry.DateTime.IsZero() {
 			if summary.FirstTime.IsZero() || entry.DateTime.Before(summary.FirstTime) {
 				summary.FirstTime = entry.DateTime
@@ -2075,7 +2521,8 @@ func getLedgerTotals(entries []LedgerEntry) *LedgerSummary {
 				summary.MaxUSD = entry.BTCPrice
 			}
 		}
-		switch entry.TX {
+		summary.TotalFees += entry.Fee
+		switch ledgerSide(entry.TX) {
 		case "Buy":
 			summary.TotalBuyUSD += entry.USD
 			summary.TotalBuyBTC += entry.BTC
@@ -2155,6 +2602,15 @@ func invokeLedgerArchive(reader *bufio.Reader) {
 	archiveFileName := fmt.Sprintf("vBTC - Ledger_%s.csv", time.Now().Format("010206@150405"))
 	ledgerAbs, _ := filepath.Abs(ledgerFilePath)
 	archivePath := filepath.Join(filepath.Dir(ledgerAbs), archiveFileName)
+	// Hold the lock from the copy through the purge so no trade lands in between.
+	unlock, err := lockPortfolio()
+	if err != nil {
+		color.Red("Error: %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	defer unlock()
 	sourceFile, err := os.Open(ledgerFilePath)
 	if err != nil {
 		color.Red("Error opening ledger file: %v", err)
@@ -2164,8 +2620,10 @@ func invokeLedgerArchive(reader *bufio.Reader) {
 	}
 	defer sourceFile.Close()
 
+	slog.Info("archiving ledger", "path", archivePath)
 	destFile, err := os.Create(archivePath)
 	if err != nil {
+		slog.Error("ledger archive failed", "path", archivePath, "err", err)
 		color.Red("Error creating archive file: %v", err)
 		fmt.Println("Press Enter to continue.")
 		reader.ReadString('\n')
@@ -2208,6 +2666,7 @@ func invokeLedgerArchive(reader *bufio.Reader) {
 	} else {
 		color.Green("Original ledger has been purged, keeping the last %d transaction(s).", linesToKeep)
 	}
+	unlock()
 
 	fmt.Println("Press Enter to continue.")
 	reader.ReadString('\n')
@@ -2224,6 +2683,7 @@ func readCsvFileRecords(filePath string) ([][]string, error) {
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1 // Rows written before the Coin and Fee columns have fewer fields
 	records, err := reader.ReadAll()
 	if err != nil {
 		if err == io.EOF {
@@ -2251,7 +2711,7 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 	fmt.Println("\nSearching for archives...")
 	archiveFiles, err := filepath.Glob(archivePattern)
 	if err != nil {
-		color.New(color.FgRed).Printf("Error finding archives: %v\n", err)
+		colorNegative.Printf("Error finding archives: %v\n", err)
 		fmt.Println("\nPress Enter to continue.")
 		reader.ReadString('\n')
 		return
@@ -2285,7 +2745,7 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 		var readErr error
 		existingRecords, readErr = readCsvFileRecords(mergedLedgerPath)
 		if readErr != nil {
-			color.New(color.FgRed).Printf("Could not read existing merged ledger at '%s'. It may be corrupt: %v\n", mergedLedgerPath, readErr)
+			colorNegative.Printf("Could not read existing merged ledger at '%s'. It may be corrupt: %v\n", mergedLedgerPath, readErr)
 			fmt.Println("\nPress Enter to continue.")
 			reader.ReadString('\n')
 			return
@@ -2329,7 +2789,7 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 	writeAlignedLine("Archives Found:", fmt.Sprintf("%d", len(filteredArchives)), color.New(color.FgWhite))
 	writeAlignedLine("Transactions in Archives:", fmt.Sprintf("%d", totalScannedTxCount), color.New(color.FgWhite))
 	writeAlignedLine("Existing Merged TXs:", fmt.Sprintf("%d", len(existingRecords)), color.New(color.FgWhite))
-	writeAlignedLine("New Unique TXs to Add:", fmt.Sprintf("%d", len(newUniqueRecords)), color.New(color.FgGreen))
+	writeAlignedLine("New Unique TXs to Add:", fmt.Sprintf("%d", len(newUniqueRecords)), colorPositive)
 	expectedTotal := len(existingRecords) + len(newUniqueRecords)
 	writeAlignedLine("New Total TXs:", fmt.Sprintf("%d", expectedTotal), color.New(color.FgWhite))
 
@@ -2367,7 +2827,8 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 	})
 
 	// Use a temporary file for a safer write operation
-	tempFile, err := os.CreateTemp("", "ledger-merge-*.csv")
+	// Create it beside the ledgers so the rename below never crosses filesystems
+	tempFile, err := os.CreateTemp(ledgerDir, "ledger-merge-*.csv")
 	if err != nil {
 		color.Red("Error creating temporary file: %v", err)
 		fmt.Println("\nPress Enter to continue.")
@@ -2377,8 +2838,7 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 	defer os.Remove(tempFile.Name()) // Ensure temp file is cleaned up on exit
 
 	writer := csv.NewWriter(tempFile)
-	header := []string{"TX", "USD", "BTC", "BTC(USD)", "User BTC", "Time"}
-	if err := writer.Write(header); err != nil {
+	if err := writer.Write(ledgerHeader); err != nil {
 		color.Red("Error writing header to temp file: %v", err)
 		tempFile.Close()
 		return
@@ -2400,6 +2860,7 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 
 	// If verification passes, move the temp file to the final destination
 	if err := os.Rename(tempFile.Name(), mergedLedgerPath); err != nil {
+		slog.Error("merged ledger rename failed", "path", mergedLedgerPath, "err", err)
 		color.Red("Error moving temporary file to final destination: %v", err)
 		return
 	}
@@ -2419,7 +2880,36 @@ func invokeLedgerMerge(reader *bufio.Reader) {
 	reader.ReadString('\n')
 }
 
-func addLedgerEntry(txType string, usdAmount, btcAmount, btcPrice, userBtcAfter float64) error {
+// addLedgerEntry appends a trade of coin; the BTC, BTC(USD) and User BTC columns hold that coin's amount, rate and balance.
+// usdAmount is the cash moved (fee included on buys, deducted on sells) and fee the USD fee charged.
+// note is the user's note; its #tags go in the Tags column.
+func addLedgerEntry(txType, coin string, usdAmount, btcAmount, btcPrice, userBtcAfter, fee float64, note string) error {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	note, tags := splitNoteTags(note)
+	ledger := engine.Ledger{Store: csvLedgerStore{}, Clock: engine.SystemClock{}}
+	_, err = ledger.Append(LedgerEntry{TX: txType, USD: usdAmount, BTC: btcAmount, BTCPrice: btcPrice, UserBTC: userBtcAfter, Coin: coin, Fee: fee, Note: note, Tags: tags})
+	if err != nil {
+		slog.Error("ledger append failed", "path", ledgerFilePath, "err", err)
+		return err
+	}
+	slog.Info("ledger entry written", "tx", txType, "coin", coin, "usd", usdAmount, "btc", btcAmount, "price", btcPrice, "fee", fee, "tags", tags)
+	sessionLog.Info("trade", "tx", txType, "coin", coin, "usd", usdAmount, "amount", btcAmount, "price", btcPrice, "fee", fee)
+	webhookTrade(txType, coin, usdAmount, btcAmount, btcPrice, fee)
+	return nil
+}
+
+// csvLedgerStore is the engine.Storage for ledger.csv. Callers hold the portfolio lock.
+type csvLedgerStore struct{}
+
+func (csvLedgerStore) ReadLedger() ([][]string, error) {
+	return readAndParseLedgerRaw()
+}
+
+func (csvLedgerStore) AppendLedger(row []string) error {
 	file, err := os.OpenFile(ledgerFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		// Return the error to be handled by the caller, which is aware of the terminal state (raw/cooked)
@@ -2428,22 +2918,12 @@ func addLedgerEntry(txType string, usdAmount, btcAmount, btcPrice, userBtcAfter
 	defer file.Close()
 
 	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
-	info, _ := file.Stat()
-	if info.Size() == 0 {
-		writer.Write([]string{"TX", "USD", "BTC", "BTC(USD)", "User BTC", "Time"})
+	if info, err := file.Stat(); err == nil && info.Size() == 0 {
+		writer.Write(ledgerHeader)
 	}
-
-	err = writer.Write([]string{
-		txType,
-		fmt.Sprintf("%.2f", usdAmount),
-		fmt.Sprintf("%.8f", btcAmount),
-		fmt.Sprintf("%.2f", btcPrice),
-		fmt.Sprintf("%.8f", userBtcAfter),
-		time.Now().UTC().Format("010206@150405"),
-	})
-	if err != nil {
+	writer.Write(row)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
 		return fmt.Errorf("failed to write record to ledger: %w", err)
 	}
 	return nil
@@ -2470,7 +2950,7 @@ func waitForEnter(inputChan chan byte, fd int, oldState *term.State) {
 	}
 }
 
-func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResponse {
+func invokeTrade(reader *bufio.Reader, txType, coin, amountString string) *ApiDataResponse {
 	// For the most accurate UI prompt, we should read the latest config from disk here too.
 	// This prevents showing the user a stale "Max" amount if another client has made a trade.
 	promptCfg, err := ini.Load(iniFilePath)
@@ -2481,23 +2961,26 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 		promptCfg = cfg
 	}
 	playerUSD, _ := promptCfg.Section("Portfolio").Key("PlayerUSD").Float64()
-	playerBTC, _ := promptCfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	playerCoin, _ := promptCfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
 
 	var maxAmount float64
 	var prompt string
 	if txType == "Buy" {
 		maxAmount = playerUSD
-		prompt = fmt.Sprintf("Amount in USD [Max $%s]:", formatFloat(maxAmount, 2))
-	} else {
-		maxAmount = playerBTC
+		prompt = fmt.Sprintf("Amount in %s [Max %s]:", currencyCode(), formatMoney(maxAmount))
+	} else if coin == "BTC" {
+		maxAmount = playerCoin
 		prompt = fmt.Sprintf("Amount in BTC [Max %.8f] (or use 's' for satoshis):", maxAmount)
+	} else {
+		maxAmount = playerCoin
+		prompt = fmt.Sprintf("Amount in %s [Max %.8f]:", coin, maxAmount)
 	}
 
 	var tradeAmount float64
 
 	for {
 		clearScreen()
-		color.Yellow("*** %s Bitcoin ***", txType)
+		color.Yellow("*** %s %s ***", txType, coinName(coin))
 
 		userInput := amountString
 		if userInput == "" {
@@ -2509,9 +2992,9 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 			}
 		}
 
-		parsedAmount, parseSuccess := parseTradeAmount(userInput, maxAmount, txType)
-		if !parseSuccess {
-			color.Red("Invalid amount or expression.")
+		parsedAmount, err := parseTradeAmount(userInput, maxAmount, txType)
+		if err != nil {
+			color.Red("Invalid amount: %v.", err)
 			fmt.Println("Press Enter to continue.")
 			reader.ReadString('\n')
 			amountString = "" // Reset to re-prompt
@@ -2536,6 +3019,9 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 		tradeAmount = parsedAmount
 		break
 	}
+	if !checkRiskLimits(reader, txType, coin, tradeAmount) {
+		return apiData
+	}
 
 	// Confirmation Loop
 	offerExpired := false
@@ -2617,14 +3103,15 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 			waitForEnter(inputChan, fd, oldState)
 			return apiData // Return to main menu
 		}
-		if apiData == nil || apiData.Rate == 0 {
+		if apiData == nil || apiData.Rate == 0 || coinRate(coin) == 0 {
 			color.Red("\nError fetching price. Press Enter to continue.")
 			waitForEnter(inputChan, fd, oldState)
 			return apiData
 		}
+		rate := coinRate(coin)
 
 		// Snapshot portfolio from disk when the offer is presented (refreshed on each price retry).
-		snap, err := loadPortfolioSnapshot()
+		snap, err := loadPortfolioSnapshot(coin)
 		if err != nil {
 			color.Red("\nCould not read portfolio for offer verification.")
 			color.Red("Error: %v", err)
@@ -2637,54 +3124,49 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 		offerTimestamp := time.Now() // Record the time the offer is presented.
 
 		clearScreen()
-		color.Yellow("*** %s Bitcoin ***", txType)
+		color.Yellow("*** %s %s ***", txType, coinName(coin))
 		if offerExpired {
 			color.Yellow("\nOffer expired. A new price has been fetched.")
 			offerExpired = false // Reset the flag after showing the message
 		}
 
-		var usdAmount, btcAmount float64
-		if txType == "Buy" {
-			usdAmount = tradeAmount
-			btcAmount = math.Floor((usdAmount/apiData.Rate)*1e8) / 1e8
-		} else { // Sell
-			btcAmount = tradeAmount
-			usdAmount = math.Floor((btcAmount*apiData.Rate)*100) / 100
+		quote, err := quoteTrade(txType, tradeAmount, rate)
+		if err != nil {
+			color.Red("\nTrade not possible: %v.", err)
+			fmt.Println("\nPress Enter to return to the main menu.")
+			waitForEnter(inputChan, fd, oldState)
+			return apiData
 		}
+		usdAmount, btcAmount := quote.USD, quote.Coin
 
 		priceColor := color.New(color.FgWhite)
-		if apiData.Sma1h > 0 {
+		if coin == "BTC" && apiData.Sma1h > 0 {
 			if apiData.Rate > apiData.Sma1h {
-				priceColor = color.New(color.FgGreen)
+				priceColor = colorPositive
 			} else if apiData.Rate < apiData.Sma1h {
-				priceColor = color.New(color.FgRed)
+				priceColor = colorNegative
 			}
 		}
 
 		fmt.Println("\nYou have 2 minutes to accept this offer.")
-		priceColor.Printf("Market Rate: $%s\n", formatFloat(apiData.Rate, 2))
+		priceColor.Printf("Market Rate: %s\n", formatMoney(rate))
+		printExecutionRate(quote, rate)
 
-		var confirmPrompt string
-		if txType == "Buy" {
-			confirmPrompt = fmt.Sprintf("Purchase %.8f BTC for $%s? ", btcAmount, formatFloat(usdAmount, 2))
-		} else {
-			confirmPrompt = fmt.Sprintf("Sell %.8f BTC for $%s? ", btcAmount, formatFloat(usdAmount, 2))
-		}
-
-		fmt.Print(confirmPrompt)
+		fmt.Print(confirmTradePrompt(txType, coin, quote))
 		color.New(color.FgWhite).Print("[")
-		color.New(color.FgGreen).Print("y")
+		colorPositive.Print("y")
 		color.New(color.FgWhite).Print("/")
-		color.New(color.FgCyan).Print("r")
+		colorInfo.Print("r")
 		color.New(color.FgWhite).Print("/")
-		color.New(color.FgRed).Print("n")
+		colorNegative.Print("n")
 		color.New(color.FgWhite).Println("]")
 
 		// Create a new ticker for each offer to handle the countdown.
 		ticker := time.NewTicker(250 * time.Millisecond)
 
 		displayState := "" // e.g., "Initial", "OneMinute", "ThirtySeconds", "Expired"
-		redrawTradeScreen(txType, offerExpired, apiData, tradeAmount, displayState)
+		redrawTradeScreen(txType, coin, offerExpired, apiData, tradeAmount, displayState)
+		shownSeconds := printOfferCountdown(2*time.Minute-time.Since(offerTimestamp), displayState)
 
 	EventLoop:
 		for {
@@ -2702,7 +3184,10 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 
 				if requiredState != displayState {
 					displayState = requiredState
-					redrawTradeScreen(txType, offerExpired, apiData, tradeAmount, displayState)
+					redrawTradeScreen(txType, coin, offerExpired, apiData, tradeAmount, displayState)
+					shownSeconds = printOfferCountdown(secondsRemaining, displayState)
+				} else if int(math.Ceil(secondsRemaining.Seconds())) != shownSeconds {
+					shownSeconds = printOfferCountdown(secondsRemaining, displayState)
 				}
 			case b, ok := <-inputChan:
 				if !ok {
@@ -2792,6 +3277,21 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 						ticker.Stop()
 						break EventLoop // The offer is stale, break inner loop to get a new price.
 					}
+					// The price is locked in once accepted; the note prompt does not count against the offer.
+					ticker.Stop()
+					note := readNoteRaw(inputChan, fd, oldState)
+
+					// Hold the portfolio lock from the reload through the ledger write so another
+					// session cannot change the balances in between.
+					unlock, err := lockPortfolio()
+					if err != nil {
+						color.Red("\nTrade cancelled. %v", err)
+						playSound(soundTradeFailed)
+						fmt.Println("\nPress Enter to continue.")
+						waitForEnter(inputChan, fd, oldState)
+						return apiData
+					}
+					defer unlock()
 
 					// Reload config from disk to get the absolute latest portfolio state before committing the trade.
 					tradeCfg, err := ini.Load(iniFilePath)
@@ -2799,6 +3299,8 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 						color.Red("\nCritical Error: Could not read portfolio file '%s' to finalize trade.", iniFilePath)
 						color.Red("Error: %v", err)
 						color.Red("Your trade has been CANCELLED to prevent data loss.")
+						unlock()
+						playSound(soundTradeFailed)
 						fmt.Println("\nPress Enter to continue.")
 						ticker.Stop()
 						waitForEnter(inputChan, fd, oldState)
@@ -2807,16 +3309,18 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 
 					// Get the most up-to-date portfolio values
 					currentPlayerUSD, _ := tradeCfg.Section("Portfolio").Key("PlayerUSD").Float64()
-					currentPlayerBTC, _ := tradeCfg.Section("Portfolio").Key("PlayerBTC").Float64()
-					currentPlayerInvested, _ := tradeCfg.Section("Portfolio").Key("PlayerInvested").Float64()
+					currentPlayerCoin, _ := tradeCfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+					currentPlayerInvested, _ := tradeCfg.Section("Portfolio").Key(coinInvestedKey(coin)).Float64()
 
 					currentSnapshot := portfolioSnapshot{
 						USD:      currentPlayerUSD,
-						BTC:      currentPlayerBTC,
+						Coin:     currentPlayerCoin,
 						Invested: currentPlayerInvested,
 					}
 					if !portfolioMatchesSnapshot(currentSnapshot, offerSnapshot) {
 						color.Red("\nTrade cancelled. Your portfolio was modified by another session while this offer was open.")
+						unlock()
+						playSound(soundTradeFailed)
 						fmt.Println("\nPress Enter to continue.")
 						ticker.Stop()
 						waitForEnter(inputChan, fd, oldState)
@@ -2825,59 +3329,84 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 
 					// Verify if the trade is still possible with the latest balance
 					if txType == "Buy" && usdAmount > currentPlayerUSD {
-						color.Red("\nTrade cancelled. Your USD balance has changed since the trade was initiated.")
-						color.Red("Your current balance is $%s, but the trade required $%s.", formatFloat(currentPlayerUSD, 2), formatFloat(usdAmount, 2))
+						color.Red("\nTrade cancelled. Your %s balance has changed since the trade was initiated.", currencyCode())
+						color.Red("Your current balance is %s, but the trade required %s.", formatMoney(currentPlayerUSD), formatMoney(usdAmount))
+						unlock()
+						playSound(soundTradeFailed)
 						fmt.Println("\nPress Enter to continue.")
 						ticker.Stop()
 						waitForEnter(inputChan, fd, oldState)
 						return apiData
 					}
-					if txType == "Sell" && btcAmount > currentPlayerBTC {
-						color.Red("\nTrade cancelled. Your BTC balance has changed since the trade was initiated.")
-						color.Red("Your current balance is %.8f BTC, but the trade required %.8f BTC.", currentPlayerBTC, btcAmount)
+					if txType == "Sell" && btcAmount > currentPlayerCoin {
+						color.Red("\nTrade cancelled. Your %s balance has changed since the trade was initiated.", coin)
+						color.Red("Your current balance is %.8f %s, but the trade required %.8f %s.", currentPlayerCoin, coin, btcAmount, coin)
+						unlock()
+						playSound(soundTradeFailed)
 						fmt.Println("\nPress Enter to continue.")
 						ticker.Stop()
 						waitForEnter(inputChan, fd, oldState)
 						return apiData
 					}
 
-					var newUserBtc, newInvested float64
-					if txType == "Buy" {
-						tradeCfg.Section("Portfolio").Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", currentPlayerUSD-usdAmount))
-						newUserBtc = currentPlayerBTC + btcAmount
-						newInvested = currentPlayerInvested + usdAmount
-					} else { // Sell
-						newUserBtc = currentPlayerBTC - btcAmount
-						if newUserBtc < 1e-9 { // Tolerance for float comparison
-							newUserBtc = 0
-							newInvested = 0
-						} else if currentPlayerBTC > 0 {
-							newInvested = currentPlayerInvested * (newUserBtc / currentPlayerBTC)
-						}
-						tradeCfg.Section("Portfolio").Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", currentPlayerUSD+usdAmount))
+					newUserBtc, err := applyTradeToPortfolio(tradeCfg, coin, txType, usdAmount, btcAmount)
+					if err != nil {
+						color.Red("\nTrade cancelled. %v", err)
+						unlock()
+						playSound(soundTradeFailed)
+						fmt.Println("\nPress Enter to continue.")
+						ticker.Stop()
+						waitForEnter(inputChan, fd, oldState)
+						return apiData
+					}
+					recordUndo(tradeCfg, txType, coin, usdAmount, btcAmount, currentSnapshot)
+					// Record the trade first so a crash before the ledger row is written can be repaired at the next start.
+					err = beginTrade(pendingTrade{
+						Coin:   coin,
+						Before: currentSnapshot,
+						After:  portfolioBalances(tradeCfg, coin),
+						Rows:   []pendingRow{{TX: txType, USD: usdAmount, Amount: btcAmount, Rate: quote.Rate, UserCoin: newUserBtc, Fee: quote.Fee, Note: note}},
+					})
+					if err != nil {
+						color.Red("\nTrade cancelled. %v", err)
+						unlock()
+						playSound(soundTradeFailed)
+						fmt.Println("\nPress Enter to continue.")
+						ticker.Stop()
+						waitForEnter(inputChan, fd, oldState)
+						return apiData
 					}
-					tradeCfg.Section("Portfolio").Key("PlayerBTC").SetValue(fmt.Sprintf("%.8f", newUserBtc))
-					tradeCfg.Section("Portfolio").Key("PlayerInvested").SetValue(fmt.Sprintf("%.2f", newInvested))
-					err = tradeCfg.SaveTo(iniFilePath)
+					err = writeIni(tradeCfg)
 					if err != nil {
+						endTrade()
+						slog.Error("portfolio save failed", "path", iniFilePath, "err", err)
 						color.Red("\nTrade failed: Could not save portfolio update to vbtc.ini.")
 						color.Red("Error: %v", err)
 						fmt.Println("\nPlease check file permissions and try again.")
+						unlock()
+						playSound(soundTradeFailed)
+						playSound(soundTradeFailed)
 						fmt.Println("\nPress Enter to continue.")
 						ticker.Stop()
 						waitForEnter(inputChan, fd, oldState)
 					} else {
 						cfg = tradeCfg // Update the global config to reflect the new state
-						err := addLedgerEntry(txType, usdAmount, btcAmount, apiData.Rate, newUserBtc)
+						err := addLedgerEntry(txType, coin, usdAmount, btcAmount, quote.Rate, newUserBtc, quote.Fee, note)
+						if err == nil {
+							endTrade()
+						}
+						unlock()
 						if err != nil {
 							color.Red("\nTransaction complete, but failed to write to ledger.csv.")
 							color.Red("Error: %v", err)
 							fmt.Println("\nPlease ensure the file is not open in another program.")
+							fmt.Println("The row will be added before your next trade or when vBTC next starts.")
 							fmt.Println("\nPress Enter to acknowledge.")
 							waitForEnter(inputChan, fd, oldState)
 						}
 						// Print success message without a newline, sleep, then overwrite with a processing message.
 						fmt.Printf("\n\n%s successful.", txType)
+						playSound(soundTradeDone)
 						time.Sleep(1 * time.Second)
 						ticker.Stop()
 						fmt.Printf("\rReturning to main menu...\n")
@@ -2907,9 +3436,9 @@ func invokeTrade(reader *bufio.Reader, txType, amountString string) *ApiDataResp
 	}
 }
 
-func redrawTradeScreen(txType string, offerExpired bool, apiData *ApiDataResponse, tradeAmount float64, displayState string) {
+func redrawTradeScreen(txType, coin string, offerExpired bool, apiData *ApiDataResponse, tradeAmount float64, displayState string) {
 	clearScreen()
-	color.Yellow("*** %s Bitcoin ***", txType)
+	color.Yellow("*** %s %s ***", txType, coinName(coin))
 	if offerExpired {
 		color.Yellow("\nOffer expired. A new price has been fetched.")
 		// The offerExpired flag is now managed by the caller loop, so no need to reset it here.
@@ -2921,137 +3450,108 @@ func redrawTradeScreen(txType string, offerExpired bool, apiData *ApiDataRespons
 	switch displayState {
 	case "OneMinute":
 		timeLeftMessage = "You have 1 minute to accept this offer."
-		timeLeftColor = color.New(color.FgYellow)
+		timeLeftColor = colorTitle
 	case "ThirtySeconds":
 		timeLeftMessage = "You have 30 seconds to accept this offer."
-		timeLeftColor = color.New(color.FgRed)
+		timeLeftColor = colorNegative
 	case "Expired":
 		timeLeftMessage = "Offer expired, please refresh for new offer."
-		timeLeftColor = color.New(color.FgCyan)
+		timeLeftColor = colorInfo
 	default: // "Initial" or fallback
 		timeLeftMessage = "You have 2 minutes to accept this offer."
 		timeLeftColor = color.New(color.FgWhite)
 	}
 
-	var usdAmount, btcAmount float64
-	if txType == "Buy" {
-		usdAmount = tradeAmount
-		btcAmount = math.Floor((usdAmount/apiData.Rate)*1e8) / 1e8
-	} else { // Sell
-		btcAmount = tradeAmount
-		usdAmount = math.Floor((btcAmount*apiData.Rate)*100) / 100
+	rate := apiData.Rate
+	if coin != "BTC" {
+		rate = apiData.CoinRates[coin]
 	}
 
+	quote, _ := quoteTrade(txType, tradeAmount, rate) // Already validated when the offer was made
+
 	priceColor := color.New(color.FgWhite)
-	if apiData.Sma1h > 0 {
+	if coin == "BTC" && apiData.Sma1h > 0 {
 		if apiData.Rate > apiData.Sma1h {
-			priceColor = color.New(color.FgGreen)
+			priceColor = colorPositive
 		} else if apiData.Rate < apiData.Sma1h {
-			priceColor = color.New(color.FgRed)
+			priceColor = colorNegative
 		}
 	}
 
 	fmt.Println()
 	timeLeftColor.Println(timeLeftMessage)
-	priceColor.Printf("Market Rate: $%s\n", formatFloat(apiData.Rate, 2))
-
-	var confirmPrompt string
-	if txType == "Buy" {
-		confirmPrompt = fmt.Sprintf("Purchase %.8f BTC for $%s? ", btcAmount, formatFloat(usdAmount, 2))
-	} else {
-		confirmPrompt = fmt.Sprintf("Sell %.8f BTC for $%s? ", btcAmount, formatFloat(usdAmount, 2))
-	}
+	priceColor.Printf("Market Rate: %s\n", formatMoney(rate))
+	printExecutionRate(quote, rate)
 
-	fmt.Print(confirmPrompt)
+	fmt.Print(confirmTradePrompt(txType, coin, quote))
 	if displayState == "Expired" {
 		color.New(color.FgWhite).Print("[")
-		color.New(color.FgCyan).Print("r")
+		colorInfo.Print("r")
 		color.New(color.FgWhite).Println("]")
 	} else {
 		color.New(color.FgWhite).Print("[")
-		color.New(color.FgGreen).Print("y")
+		colorPositive.Print("y")
 		color.New(color.FgWhite).Print("/")
-		color.New(color.FgCyan).Print("r")
+		colorInfo.Print("r")
 		color.New(color.FgWhite).Print("/")
-		color.New(color.FgRed).Print("n")
+		colorNegative.Print("n")
 		color.New(color.FgWhite).Println("]")
 	}
 }
 
+// printOfferCountdown rewrites the line under the trade prompt with the time
+// left on the offer, e.g. "Offer valid for 1:43", in the color of displayState.
+// It returns the whole seconds shown so the caller redraws once a second.
+func printOfferCountdown(remaining time.Duration, displayState string) int {
+	seconds := int(math.Ceil(remaining.Seconds()))
+	fmt.Print("\r\033[K")
+	if seconds <= 0 || displayState == "Expired" {
+		return seconds
+	}
+	countdownColor := color.New(color.FgWhite)
+	switch displayState {
+	case "OneMinute":
+		countdownColor = colorTitle
+	case "ThirtySeconds":
+		countdownColor = colorNegative
+	}
+	countdownColor.Printf("Offer valid for %d:%02d", seconds/60, seconds%60)
+	return seconds
+}
+
 type portfolioSnapshot struct {
 	USD      float64
-	BTC      float64
+	Coin     float64
 	Invested float64
 }
 
-func loadPortfolioSnapshot() (portfolioSnapshot, error) {
+func loadPortfolioSnapshot(coin string) (portfolioSnapshot, error) {
 	tradeCfg, err := ini.Load(iniFilePath)
 	if err != nil {
 		return portfolioSnapshot{}, err
 	}
 	usd, _ := tradeCfg.Section("Portfolio").Key("PlayerUSD").Float64()
-	btc, _ := tradeCfg.Section("Portfolio").Key("PlayerBTC").Float64()
-	invested, _ := tradeCfg.Section("Portfolio").Key("PlayerInvested").Float64()
-	return portfolioSnapshot{USD: usd, BTC: btc, Invested: invested}, nil
+	balance, _ := tradeCfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+	invested, _ := tradeCfg.Section("Portfolio").Key(coinInvestedKey(coin)).Float64()
+	return portfolioSnapshot{USD: usd, Coin: balance, Invested: invested}, nil
 }
 
 func portfolioMatchesSnapshot(current, snapshot portfolioSnapshot) bool {
 	const usdTol = 0.005
 	const btcTol = 1e-9
 	return math.Abs(current.USD-snapshot.USD) < usdTol &&
-		math.Abs(current.BTC-snapshot.BTC) < btcTol &&
+		math.Abs(current.Coin-snapshot.Coin) < btcTol &&
 		math.Abs(current.Invested-snapshot.Invested) < usdTol
 }
 
-func parseTradeAmount(input string, maxAmount float64, txType string) (float64, bool) {
-	input = strings.TrimSpace(input)
-	input = strings.ReplaceAll(input, ",", "") // Allow commas
-
-	// Percentage
-	if strings.HasSuffix(input, "p") {
-		percentString := strings.TrimSuffix(input, "p")
-		expression, err := govaluate.NewEvaluableExpression(percentString)
-		if err != nil {
-			return 0, false // Invalid expression
-		}
-		result, err := expression.Evaluate(nil)
-		if err != nil {
-			return 0, false // Evaluation failed
-		}
-		percentVal, ok := result.(float64)
-		if !ok {
-			return 0, false // Result is not a float
-		}
-
-		if percentVal <= 0 || percentVal > 100 {
-			return 0, false
-		}
-		calculatedAmount := (maxAmount * percentVal) / 100
-		if txType == "Sell" {
-			return math.Floor(calculatedAmount*1e8) / 1e8, true // Truncate for BTC
-		}
-		return math.Floor(calculatedAmount*100) / 100, true // Truncate for USD
-	}
-
-	// Satoshis
-	if strings.HasSuffix(input, "s") {
-		if txType == "Buy" {
-			return 0, false
-		}
-		satoshiString := strings.TrimSuffix(input, "s")
-		satoshiVal, err := strconv.ParseFloat(satoshiString, 64)
-		if err != nil {
-			return 0, false
-		}
-		return satoshiVal / 1e8, true
-	}
-
-	// Plain number
-	amount, err := strconv.ParseFloat(input, 64)
-	if err != nil {
-		return 0, false
+// parseTradeAmount reads a trade amount with engine.ParseAmount: a number or
+// expression, a percentage of maxAmount ("50p") or, for sells, satoshis ("50000s").
+func parseTradeAmount(input string, maxAmount float64, txType string) (float64, error) {
+	amount, err := engine.ParseAmount(input, maxAmount, txType)
+	if errors.Is(err, engine.ErrSatoshiBuy) {
+		return 0, fmt.Errorf("%w; buy with a %s amount", err, currencyCode())
 	}
-	return amount, true
+	return amount, err
 }
 
 // --- Utility Functions ---
@@ -3070,50 +3570,19 @@ func getParentProcessName() (string, error) {
 	return parentProcess.Name()
 }
 
+// formatFloat formats num with grouping separators in the configured number format.
 func formatFloat(num float64, decimals int) string {
-	// Use a robust method to format numbers with commas.
-	// 1. Format to a string with the specified number of decimals.
-	str := fmt.Sprintf("%."+strconv.Itoa(decimals)+"f", math.Abs(num))
-	parts := strings.Split(str, ".")
-	integerPart := parts[0]
-	decimalPart := ""
-	if len(parts) > 1 {
-		decimalPart = "." + parts[1]
-	}
-
-	// 2. Add commas to the integer part.
-	n := len(integerPart)
-	if n <= 3 {
-		if num < 0 {
-			return "-" + integerPart + decimalPart
-		}
-		return integerPart + decimalPart
-	}
-
-	// Calculate the number of commas needed.
-	commas := (n - 1) / 3
-	// Create a new byte slice to hold the result.
-	result := make([]byte, n+commas)
-	// Iterate from right to left, inserting commas.
-	for i, j, k := n-1, len(result)-1, 0; i >= 0; i, j, k = i-1, j-1, k+1 {
-		if k > 0 && k%3 == 0 {
-			result[j] = ','
-			j--
-		}
-		result[j] = integerPart[i]
-	}
-
 	if num < 0 {
-		return "-" + string(result) + decimalPart
+		return "-" + formatDecimal(math.Abs(num), decimals, true)
 	}
-	return string(result) + decimalPart
+	return formatDecimal(num, decimals, true)
 }
 
 func formatProfitLoss(value float64, formatSuffix string) string {
 	if value < 0 {
-		return fmt.Sprintf("(%.2f%s)", math.Abs(value), formatSuffix)
+		return fmt.Sprintf("(%s%s)", formatDecimal(math.Abs(value), 2, false), formatSuffix)
 	}
-	return fmt.Sprintf("+%.2f%s", value, formatSuffix)
+	return fmt.Sprintf("+%s%s", formatDecimal(value, 2, false), formatSuffix)
 }
 
 func formatDuration(first, last time.Time) string {
@@ -3166,21 +3635,20 @@ func writeAlignedLineCadence(label string, ledgerCadence, sessionCadence string,
 	if padding < 0 {
 		padding = 0
 	}
-	fmt.Print(label)
-	fmt.Print(strings.Repeat(" ", padding))
+	fmt.Fprint(color.Output, label+strings.Repeat(" ", padding))
 	if sessionCadence == "" {
 		color.New(color.FgWhite).Println(ledgerCadence)
 		return
 	}
 	if ledgerIsSlower {
-		color.New(color.FgRed).Print(ledgerCadence)
+		colorNegative.Print(ledgerCadence)
 		color.New(color.FgWhite).Print(" [")
-		color.New(color.FgGreen).Print(sessionCadence)
+		colorPositive.Print(sessionCadence)
 		color.New(color.FgWhite).Println("]")
 	} else {
-		color.New(color.FgGreen).Print(ledgerCadence)
+		colorPositive.Print(ledgerCadence)
 		color.New(color.FgWhite).Print(" [")
-		color.New(color.FgRed).Print(sessionCadence)
+		colorNegative.Print(sessionCadence)
 		color.New(color.FgWhite).Println("]")
 	}
 }
@@ -0,0 +1,189 @@
+package vbtc
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// In a terminal the main screen and its "Enter command:" prompt run as a
+// Bubble Tea program. Enter quits the program and mainLoop dispatches the line
+// through the commands map and matchCommands, so every other screen keeps
+// reading from the shared bufio.Reader. A price from the background watcher
+// redraws the panel in place; only when it reaches an alert, order or trigger,
+// or after the AutoRefreshMinutes timeout, does the program quit so mainLoop
+// can act and redraw with whatever was typed put back on the prompt.
+
+// promptExit says why the main prompt returned.
+type promptExit int
+
+const (
+	promptEntered promptExit = iota // a line was entered
+	promptIdle                      // AutoRefreshMinutes passed without a key press
+	promptPrice                     // a watched price reached an alert, order or trigger
+)
+
+const promptLabel = "Enter command: "
+
+// watchedPriceMsg tells the prompt the watcher has a new price to show.
+type watchedPriceMsg struct{}
+
+// idleMsg fires AutoRefreshMinutes after the key press numbered seq.
+type idleMsg struct{ seq int }
+
+type promptModel struct {
+	screen      string        // showMainScreen output, colors included
+	input       string        // text typed at the prompt
+	idle        time.Duration // AutoRefreshMinutes; 0 never refreshes
+	width       int           // terminal width; 0 until Bubble Tea reports it
+	keys        int           // key presses so far, to ignore stale idle ticks
+	exit        promptExit
+	done        bool
+	interrupted bool
+}
+
+func (m promptModel) Init() tea.Cmd {
+	return m.idleTick()
+}
+
+func (m promptModel) idleTick() tea.Cmd {
+	if m.idle <= 0 {
+		return nil
+	}
+	seq := m.keys
+	return tea.Tick(m.idle, func(time.Time) tea.Msg { return idleMsg{seq} })
+}
+
+func (m promptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.keys++
+		switch msg.Type {
+		case tea.KeyCtrlC:
+			m.done, m.interrupted = true, true
+			return m, tea.Quit
+		case tea.KeyEnter, tea.KeyCtrlJ: // a line typed ahead ends in \n
+			return m.quit(promptEntered)
+		case tea.KeyBackspace:
+			if runes := []rune(m.input); len(runes) > 0 {
+				m.input = string(runes[:len(runes)-1])
+			}
+		case tea.KeyRunes, tea.KeySpace:
+			m.input += string(msg.Runes)
+		}
+		return m, m.idleTick()
+	case idleMsg:
+		if msg.seq == m.keys {
+			return m.quit(promptIdle)
+		}
+	case watchedPriceMsg:
+		if applyWatchedPrice() {
+			if automaticTradeDue() {
+				return m.quit(promptPrice)
+			}
+			m.screen = renderScreen(showMainScreen)
+		}
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	}
+	return m, nil
+}
+
+func (m promptModel) quit(exit promptExit) (tea.Model, tea.Cmd) {
+	m.exit, m.done = exit, true
+	return m, tea.Quit
+}
+
+// View ends in a newline because Bubble Tea clears the last line on quit.
+func (m promptModel) View() string {
+	cursor := "_"
+	if m.done {
+		cursor = ""
+	}
+	return fitWidth(m.screen, m.width) + promptLabel + m.input + cursor + "\n"
+}
+
+// fitWidth cuts each line of screen to width columns, colors kept, so a
+// narrow terminal shows the panel without wrapped lines pushing it off the
+// top. A width of 0 leaves it as is.
+func fitWidth(screen string, width int) string {
+	if width <= 0 {
+		return screen
+	}
+	lines := strings.Split(screen, "\n")
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderScreen returns what draw prints to color.Output as a string.
+func renderScreen(draw func()) string {
+	var buf bytes.Buffer
+	saved := color.Output
+	color.Output = &buf
+	defer func() { color.Output = saved }()
+	draw()
+	return buf.String()
+}
+
+// canRunPrompt reports whether the Bubble Tea prompt can take over the
+// terminal: both ends are a console and no typed-ahead input is waiting in
+// the shared reader.
+func canRunPrompt(reader *bufio.Reader) bool {
+	return !batchMode && reader.Buffered() == 0 &&
+		term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// promptCommand shows the main screen and reads a command at its prompt,
+// starting from typed (text put back after a redraw). Without a terminal it
+// prints the screen and falls back to readCommand. The price watcher polls
+// only while the prompt is waiting.
+func promptCommand(reader *bufio.Reader, typed string) (string, promptExit) {
+	clearScreen()
+	if !canRunPrompt(reader) {
+		showMainScreen()
+		fmt.Print(promptLabel)
+		priceWatch.setPrompt(true)
+		line, idle := readCommand(reader, typed)
+		priceWatch.setPrompt(false)
+		if idle {
+			return line, promptIdle
+		}
+		return line, promptEntered
+	}
+
+	m := promptModel{
+		screen: renderScreen(showMainScreen),
+		input:  typed,
+		idle:   time.Duration(loadAutoRefreshMinutes()) * time.Minute,
+	}
+	p := tea.NewProgram(m)
+	priceWatch.setProgram(p)
+	priceWatch.setPrompt(true)
+	final, err := p.Run()
+	priceWatch.setPrompt(false)
+	priceWatch.setProgram(nil)
+	reader.Reset(os.Stdin)
+	if err != nil {
+		slog.Error("main screen failed", "err", err)
+		fmt.Print(promptLabel + typed)
+		line, _ := reader.ReadString('\n')
+		return typed + line, promptEntered
+	}
+	result := final.(promptModel)
+	if result.interrupted {
+		endSession()
+		os.Exit(1)
+	}
+	return result.input, result.exit
+}
@@ -0,0 +1,88 @@
+package vbtc
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+// Trades can carry a free-text note, asked for after the offer is accepted.
+// Words starting with # are tags: "bought the dip #strategy1" is stored as
+// Note "bought the dip" and Tags "#strategy1" in the last two ledger columns.
+const maxNoteLength = 200
+
+// splitNoteTags separates #tags from the rest of a note. Tags are lowercased
+// and de-duplicated.
+func splitNoteTags(text string) (note, tags string) {
+	var words, tagList []string
+	seen := make(map[string]bool)
+	for _, word := range strings.Fields(text) {
+		if len(word) > 1 && strings.HasPrefix(word, "#") {
+			tag := strings.ToLower(word)
+			if !seen[tag] {
+				seen[tag] = true
+				tagList = append(tagList, tag)
+			}
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), strings.Join(tagList, " ")
+}
+
+// readNoteRaw reads a line of text from a raw-mode input channel, echoing it.
+// Esc or Enter on an empty line skips the note.
+func readNoteRaw(inputChan chan byte, fd int, oldState *term.State) string {
+	color.New(color.FgHiBlack).Print("\r\nNote (optional, #tags allowed, Enter to skip): ")
+	var line []rune
+	var pending []byte // an incomplete UTF-8 character
+	for {
+		b, ok := <-inputChan
+		if !ok {
+			return ""
+		}
+		switch {
+		case b == 3:
+			term.Restore(fd, oldState)
+			os.Exit(1)
+		case b == 13 || b == 10:
+			fmt.Print("\r\n")
+			return strings.TrimSpace(string(line))
+		case b == 27:
+			// Drain an arrow key sequence; a plain Esc skips the note.
+			select {
+			case <-inputChan:
+				select {
+				case <-inputChan:
+				case <-time.After(10 * time.Millisecond):
+				}
+				continue
+			case <-time.After(10 * time.Millisecond):
+			}
+			fmt.Print("\r\n")
+			return ""
+		case b == 127 || b == 8:
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				fmt.Print("\b \b")
+			}
+		case b < 32:
+			// Ignore other control characters.
+		default:
+			pending = append(pending, b)
+			if !utf8.FullRune(pending) {
+				continue
+			}
+			if len(line) < maxNoteLength {
+				line = append(line, []rune(string(pending))...)
+				fmt.Print(string(pending))
+			}
+			pending = nil
+		}
+	}
+}
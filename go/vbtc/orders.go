@@ -0,0 +1,461 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"vbtc/internal/engine"
+)
+
+// Limit orders live in the [Orders] section of vbtc.ini, one key per order:
+//
+//	[Orders]
+//	NextID = 3
+//	1      = Buy,100.00,60000.00,071525@142233
+//	2      = Sell,0.00150000,72000.00,071525@142301
+//
+// Orders are BTC only. Buy amounts are USD, sell amounts are BTC. Funds are not reserved when an
+// order is placed; the balance is checked when the order fills.
+const ordersSection = "Orders"
+
+// Ledger TX values written for filled orders.
+const (
+	txOrderBuy  = "Order Buy"
+	txOrderSell = "Order Sell"
+)
+
+type limitOrder struct {
+	ID      int
+	Side    string // "Buy" or "Sell"
+	Amount  float64
+	Price   float64
+	Created string // UTC, same layout as the ledger Time column
+}
+
+// orderTX is the ledger TX for a filled order on side.
+func orderTX(side string) string {
+	if side == "Sell" {
+		return txOrderSell
+	}
+	return txOrderBuy
+}
+
+// orderFill is an order executed by checkPendingOrders, pending its ledger write.
+type orderFill struct {
+	order   limitOrder
+	quote   tradeQuote
+	userBtc float64
+}
+
+// ledgerSide maps a ledger TX value to "Buy" or "Sell", so filled orders and
+// stop-loss/take-profit sales count alongside manual trades in totals and colors.
+func ledgerSide(tx string) string {
+	switch tx {
+	case "Buy", txOrderBuy, txImportBuy:
+		return "Buy"
+	case "Sell", txOrderSell, txStopLoss, txTakeProfit, txImportSell:
+		return "Sell"
+	}
+	return tx
+}
+
+func loadOrders(f *ini.File) []limitOrder {
+	var orders []limitOrder
+	if !f.HasSection(ordersSection) {
+		return orders
+	}
+	for _, key := range f.Section(ordersSection).Keys() {
+		id, err := strconv.Atoi(key.Name())
+		if err != nil {
+			continue // NextID and anything unrecognized
+		}
+		fields := strings.Split(key.String(), ",")
+		if len(fields) < 3 || (fields[0] != "Buy" && fields[0] != "Sell") {
+			slog.Warn("ignoring malformed order", "id", key.Name(), "value", key.String())
+			continue
+		}
+		amount, err1 := strconv.ParseFloat(fields[1], 64)
+		price, err2 := strconv.ParseFloat(fields[2], 64)
+		if err1 != nil || err2 != nil || amount <= 0 || price <= 0 {
+			slog.Warn("ignoring malformed order", "id", key.Name(), "value", key.String())
+			continue
+		}
+		order := limitOrder{ID: id, Side: fields[0], Amount: amount, Price: price}
+		if len(fields) > 3 {
+			order.Created = fields[3]
+		}
+		orders = append(orders, order)
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+func (o limitOrder) iniValue() string {
+	amount := fmt.Sprintf("%.2f", o.Amount)
+	if o.Side == "Sell" {
+		amount = fmt.Sprintf("%.8f", o.Amount)
+	}
+	return fmt.Sprintf("%s,%s,%.2f,%s", o.Side, amount, o.Price, o.Created)
+}
+
+// describe returns e.g. "Buy $100.00 at $60,000.00".
+func (o limitOrder) describe() string {
+	if o.Side == "Buy" {
+		return fmt.Sprintf("Buy %s at %s", formatMoney(o.Amount), formatMoney(o.Price))
+	}
+	return fmt.Sprintf("Sell %.8f BTC at %s", o.Amount, formatMoney(o.Price))
+}
+
+// triggered reports whether the order fills at rate: buys at or below the
+// limit price, sells at or above it.
+func (o limitOrder) triggered(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if o.Side == "Buy" {
+		return rate <= o.Price
+	}
+	return rate >= o.Price
+}
+
+// saveOrder adds or replaces an order in f. New orders (ID 0) get the next ID.
+func saveOrder(f *ini.File, order limitOrder) limitOrder {
+	section := f.Section(ordersSection)
+	if order.ID == 0 {
+		nextID, _ := section.Key("NextID").Int()
+		if nextID < 1 {
+			nextID = 1
+		}
+		for _, existing := range loadOrders(f) {
+			if existing.ID >= nextID {
+				nextID = existing.ID + 1
+			}
+		}
+		order.ID = nextID
+		section.Key("NextID").SetValue(strconv.Itoa(nextID + 1))
+	}
+	if order.Created == "" {
+		order.Created = time.Now().UTC().Format("010206@150405")
+	}
+	section.Key(strconv.Itoa(order.ID)).SetValue(order.iniValue())
+	return order
+}
+
+func deleteOrder(f *ini.File, id int) bool {
+	if !f.HasSection(ordersSection) || !f.Section(ordersSection).HasKey(strconv.Itoa(id)) {
+		return false
+	}
+	f.Section(ordersSection).DeleteKey(strconv.Itoa(id))
+	return true
+}
+
+// applyTradeToPortfolio moves cash and coin in the [Portfolio] section of f for a
+// trade of usdAmount/coinAmount and returns the coin balance after the trade.
+// Invested capital is reduced proportionally on sells. A trade of zero or
+// less leaves f unchanged and returns an error.
+func applyTradeToPortfolio(f *ini.File, coin, side string, usdAmount, coinAmount float64) (float64, error) {
+	portfolio := f.Section("Portfolio")
+	var before engine.Position
+	before.Cash, _ = portfolio.Key("PlayerUSD").Float64()
+	before.Coin, _ = portfolio.Key(coinBalanceKey(coin)).Float64()
+	before.Invested, _ = portfolio.Key(coinInvestedKey(coin)).Float64()
+
+	after, err := before.Apply(side, usdAmount, coinAmount)
+	if err != nil {
+		return before.Coin, err
+	}
+	portfolio.Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", after.Cash))
+	portfolio.Key(coinBalanceKey(coin)).SetValue(fmt.Sprintf("%.8f", after.Coin))
+	portfolio.Key(coinInvestedKey(coin)).SetValue(fmt.Sprintf("%.2f", after.Invested))
+	return after.Coin, nil
+}
+
+// checkPendingOrders fills every pending order whose limit the current market
+// rate has reached. It reads vbtc.ini fresh so orders placed or filled by
+// another session are honored exactly once, and reports fills and
+// cancellations on screen.
+func checkPendingOrders(reader *bufio.Reader) {
+	if apiData == nil || apiData.Rate <= 0 || apiData.ApiError != "" {
+		return
+	}
+	unlock, err := lockPortfolio()
+	if err != nil {
+		slog.Warn("order check skipped", "err", err)
+		return
+	}
+	defer unlock()
+	orderCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		slog.Error("order check: could not read portfolio", "path", iniFilePath, "err", err)
+		return
+	}
+	orders := loadOrders(orderCfg)
+	if len(orders) == 0 {
+		return
+	}
+
+	rate := apiData.Rate
+	before := portfolioBalances(orderCfg, "BTC")
+	var fills []orderFill
+	var cancelled []limitOrder
+	for _, order := range orders {
+		if !order.triggered(rate) {
+			continue
+		}
+		playerUSD, _ := orderCfg.Section("Portfolio").Key("PlayerUSD").Float64()
+		playerBTC, _ := orderCfg.Section("Portfolio").Key("PlayerBTC").Float64()
+
+		deleteOrder(orderCfg, order.ID)
+		quote, err := quoteTrade(order.Side, order.Amount, rate)
+		if err != nil || (order.Side == "Buy" && quote.USD > playerUSD+0.005) || (order.Side == "Sell" && quote.Coin > playerBTC+1e-9) {
+			slog.Info("order cancelled: cannot fill", "id", order.ID, "order", order.describe(), "err", err)
+			cancelled = append(cancelled, order)
+			continue
+		}
+		userBtc, err := applyTradeToPortfolio(orderCfg, "BTC", order.Side, quote.USD, quote.Coin)
+		if err != nil {
+			slog.Info("order cancelled: cannot fill", "id", order.ID, "order", order.describe(), "err", err)
+			cancelled = append(cancelled, order)
+			continue
+		}
+		fills = append(fills, orderFill{order: order, quote: quote, userBtc: userBtc})
+	}
+	if len(fills) == 0 && len(cancelled) == 0 {
+		return
+	}
+
+	if len(fills) > 0 {
+		pending := pendingTrade{Coin: "BTC", Before: before, After: portfolioBalances(orderCfg, "BTC")}
+		for _, fill := range fills {
+			pending.Rows = append(pending.Rows, pendingRow{TX: orderTX(fill.order.Side), USD: fill.quote.USD, Amount: fill.quote.Coin, Rate: fill.quote.Rate, UserCoin: fill.userBtc, Fee: fill.quote.Fee})
+		}
+		if err := beginTrade(pending); err != nil {
+			color.Red("Order check failed: %v", err)
+			unlock()
+			fmt.Println("Press Enter to continue.")
+			reader.ReadString('\n')
+			return
+		}
+	}
+	if err := writeIni(orderCfg); err != nil {
+		endTrade()
+		slog.Error("order fill: portfolio save failed", "path", iniFilePath, "err", err)
+		color.Red("Order check failed: could not save portfolio update to vbtc.ini.")
+		color.Red("Error: %v", err)
+		unlock()
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	cfg = orderCfg
+
+	fmt.Println()
+	colorTitle.Println("*** Orders ***")
+	ledgerOK := true
+	for _, fill := range fills {
+		txType := orderTX(fill.order.Side)
+		fillColor := colorPositive
+		if fill.order.Side == "Sell" {
+			fillColor = colorNegative
+		}
+		slog.Info("order filled", "id", fill.order.ID, "order", fill.order.describe(), "rate", rate)
+		fillColor.Printf("Order #%d filled: %.8f BTC for %s at %s\n", fill.order.ID, fill.quote.Coin, formatMoney(fill.quote.USD), formatMoney(fill.quote.Rate))
+		if err := addLedgerEntry(txType, "BTC", fill.quote.USD, fill.quote.Coin, fill.quote.Rate, fill.userBtc, fill.quote.Fee, ""); err != nil {
+			color.Red("Order #%d filled, but failed to write to ledger.csv: %v", fill.order.ID, err)
+			ledgerOK = false
+		}
+	}
+	if ledgerOK {
+		endTrade()
+	}
+	for _, order := range cancelled {
+		color.Yellow("Order #%d cancelled (insufficient balance or fee too high): %s", order.ID, order.describe())
+	}
+	unlock()
+	if len(fills) > 0 {
+		playSound(soundTradeDone)
+	} else if len(cancelled) > 0 {
+		playSound(soundTradeFailed)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// showOrdersScreen lists pending orders and handles the orders subcommands:
+//
+//	orders                          list pending orders
+//	orders buy <usd> <price>        buy when the rate falls to price
+//	orders sell <btc> <price>       sell when the rate rises to price
+//	orders edit <id> <amount> <price>
+//	orders cancel <id|all>
+func showOrdersScreen(reader *bufio.Reader, args []string) {
+	for {
+		clearScreen()
+		color.Yellow("*** Limit Orders ***")
+		if apiData != nil && apiData.Rate > 0 {
+			writeAlignedLine(currencyLabel("Bitcoin"), formatMoney(apiData.Rate), color.New(color.FgWhite))
+		}
+		fmt.Println()
+
+		orders := loadOrders(cfg)
+		if len(orders) == 0 {
+			fmt.Println("No pending orders.")
+		} else {
+			header := fmt.Sprintf("%-4s  %-4s  %14s  %14s  %-13s", "ID", "TX", "Amount", "Price", "Placed")
+			fmt.Println(header)
+			fmt.Println(strings.Repeat("-", len(header)))
+			for _, order := range orders {
+				rowColor := colorPositive
+				amount := formatMoney(order.Amount)
+				if order.Side == "Sell" {
+					rowColor = colorNegative
+					amount = fmt.Sprintf("%.8f", order.Amount)
+				}
+				rowColor.Printf("%-4d  %-4s  %14s  %14s  %-13s\n", order.ID, order.Side, amount, formatMoney(order.Price), order.Created)
+			}
+		}
+		fmt.Println()
+
+		if len(args) == 0 {
+			color.New(color.FgHiBlack).Println("buy <usd> <price> | sell <btc> <price> | edit <id> <amount> <price> | cancel <id|all>")
+			fmt.Print("Order command (Enter to return): ")
+			input, _ := reader.ReadString('\n')
+			args = strings.Fields(strings.TrimSpace(input))
+			if len(args) == 0 {
+				return
+			}
+		}
+
+		message, ok := runOrderCommand(args)
+		if ok {
+			color.Green(message)
+		} else {
+			color.Red(message)
+		}
+		args = nil
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}
+}
+
+// runOrderCommand applies one orders subcommand to vbtc.ini and returns a
+// message for the user and whether it succeeded.
+func runOrderCommand(args []string) (string, bool) {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err.Error(), false
+	}
+	defer unlock()
+	orderCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		return fmt.Sprintf("Could not read portfolio file '%s': %v", iniFilePath, err), false
+	}
+	playerUSD, _ := orderCfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	playerBTC, _ := orderCfg.Section("Portfolio").Key("PlayerBTC").Float64()
+
+	var message string
+	switch strings.ToLower(args[0]) {
+	case "buy", "b", "sell", "s":
+		side := "Buy"
+		if strings.HasPrefix(strings.ToLower(args[0]), "s") {
+			side = "Sell"
+		}
+		if len(args) < 3 {
+			return fmt.Sprintf("Usage: %s <amount> <price>", strings.ToLower(side)), false
+		}
+		order, errMsg := parseOrder(side, args[1], args[2], playerUSD, playerBTC)
+		if errMsg != "" {
+			return errMsg, false
+		}
+		order = saveOrder(orderCfg, order)
+		message = fmt.Sprintf("Order #%d placed: %s", order.ID, order.describe())
+		slog.Info("order placed", "id", order.ID, "order", order.describe())
+	case "edit", "e":
+		if len(args) < 4 {
+			return "Usage: edit <id> <amount> <price>", false
+		}
+		existing, found := findOrder(orderCfg, args[1])
+		if !found {
+			return fmt.Sprintf("No pending order #%s.", args[1]), false
+		}
+		order, errMsg := parseOrder(existing.Side, args[2], args[3], playerUSD, playerBTC)
+		if errMsg != "" {
+			return errMsg, false
+		}
+		order.ID = existing.ID
+		order.Created = existing.Created
+		saveOrder(orderCfg, order)
+		message = fmt.Sprintf("Order #%d updated: %s", order.ID, order.describe())
+		slog.Info("order edited", "id", order.ID, "order", order.describe())
+	case "cancel", "c", "delete", "d":
+		if len(args) < 2 {
+			return "Usage: cancel <id|all>", false
+		}
+		if strings.ToLower(args[1]) == "all" {
+			orderCfg.DeleteSection(ordersSection)
+			message = "All pending orders cancelled."
+		} else {
+			existing, found := findOrder(orderCfg, args[1])
+			if !found {
+				return fmt.Sprintf("No pending order #%s.", args[1]), false
+			}
+			deleteOrder(orderCfg, existing.ID)
+			message = fmt.Sprintf("Order #%d cancelled.", existing.ID)
+		}
+		slog.Info("order cancelled", "arg", args[1])
+	default:
+		return fmt.Sprintf("Unknown order command '%s'.", args[0]), false
+	}
+
+	if err := writeIni(orderCfg); err != nil {
+		slog.Error("order save failed", "path", iniFilePath, "err", err)
+		return fmt.Sprintf("Could not save vbtc.ini: %v", err), false
+	}
+	cfg = orderCfg
+	return message, true
+}
+
+// parseOrder validates an amount (same syntax as buy/sell, including p and s
+// suffixes) and a limit price. The balance only caps percentage amounts; it
+// is checked again when the order fills.
+func parseOrder(side, amountInput, priceInput string, playerUSD, playerBTC float64) (limitOrder, string) {
+	maxAmount := playerUSD
+	if side == "Sell" {
+		maxAmount = playerBTC
+	}
+	amount, err := parseTradeAmount(amountInput, maxAmount, side)
+	if err != nil {
+		return limitOrder{}, fmt.Sprintf("Invalid amount: %v.", err)
+	}
+	if amount <= 0 {
+		return limitOrder{}, "Please enter a positive amount."
+	}
+	if side == "Buy" && amount < 0.01 {
+		return limitOrder{}, "Buy orders must be at least $0.01."
+	}
+	price, err := strconv.ParseFloat(strings.TrimPrefix(strings.ReplaceAll(priceInput, ",", ""), "$"), 64)
+	if err != nil || price <= 0 {
+		return limitOrder{}, "Invalid limit price."
+	}
+	return limitOrder{Side: side, Amount: amount, Price: price}, ""
+}
+
+func findOrder(f *ini.File, idInput string) (limitOrder, bool) {
+	id, err := strconv.Atoi(strings.TrimPrefix(idInput, "#"))
+	if err != nil {
+		return limitOrder{}, false
+	}
+	for _, order := range loadOrders(f) {
+		if order.ID == id {
+			return order, true
+		}
+	}
+	return limitOrder{}, false
+}
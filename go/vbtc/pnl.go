@@ -0,0 +1,295 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// Cost basis is worked out by replaying the full ledger history (ledger.csv,
+// the merged ledger and archives) rather than trusting PlayerInvested, which
+// only knows the running total. [Settings] CostBasis picks the method:
+//
+//	FIFO     sells use up the oldest purchases first
+//	Average  sells use the average cost of everything held (default)
+const (
+	costBasisFIFO    = "FIFO"
+	costBasisAverage = "Average"
+)
+
+func loadCostBasisMethod() string {
+	if cfg != nil && strings.EqualFold(cfg.Section("Settings").Key("CostBasis").String(), costBasisFIFO) {
+		return costBasisFIFO
+	}
+	return costBasisAverage
+}
+
+type costLot struct {
+	qty, cost float64
+}
+
+// coinPosition is one coin's holdings and realized gain after a replay.
+type coinPosition struct {
+	Coin     string
+	Qty      float64 // held according to the ledger
+	Cost     float64 // cost basis of Qty
+	Realized float64
+	Unknown  float64 // coin sold that the ledger has no purchase for (e.g. before an archive was deleted)
+	lots     []costLot
+}
+
+func (p *coinPosition) buy(qty, usd float64) {
+	p.Qty += qty
+	p.Cost += usd
+	p.lots = append(p.lots, costLot{qty: qty, cost: usd})
+}
+
+// sell removes qty at method's cost and returns the cost basis of what was sold.
+func (p *coinPosition) sell(method string, qty, usd float64) float64 {
+	sold := math.Min(qty, p.Qty)
+	p.Unknown += qty - sold
+	var basis float64
+	if method == costBasisFIFO {
+		remaining := sold
+		for remaining > 1e-12 && len(p.lots) > 0 {
+			lot := &p.lots[0]
+			take := math.Min(remaining, lot.qty)
+			portion := lot.cost * take / lot.qty
+			basis += portion
+			lot.qty -= take
+			lot.cost -= portion
+			remaining -= take
+			if lot.qty <= 1e-12 {
+				p.lots = p.lots[1:]
+			}
+		}
+	} else if p.Qty > 0 {
+		basis = p.Cost * sold / p.Qty
+	}
+	p.Qty -= sold
+	p.Cost -= basis
+	if p.Qty <= 1e-12 {
+		p.Qty, p.Cost, p.lots = 0, 0, nil
+	}
+	p.Realized += usd - basis
+	return basis
+}
+
+// replayLedger walks entries in order and returns each entry with its cost
+// basis, plus the resulting position per coin.
+func replayLedger(entries []LedgerEntry, method string) ([]exportRow, map[string]*coinPosition) {
+	positions := make(map[string]*coinPosition)
+	rows := make([]exportRow, 0, len(entries))
+	for _, entry := range entries {
+		p := positions[entry.Coin]
+		if p == nil {
+			p = &coinPosition{Coin: entry.Coin}
+			positions[entry.Coin] = p
+		}
+		row := exportRow{LedgerEntry: entry}
+		switch ledgerSide(entry.TX) {
+		case "Buy":
+			row.CostBasis = entry.USD
+			p.buy(entry.BTC, entry.USD)
+		case "Sell":
+			row.CostBasis = math.Round(p.sell(method, entry.BTC, entry.USD)*100) / 100
+			row.RealizedGain = entry.USD - row.CostBasis
+		}
+		rows = append(rows, row)
+	}
+	return rows, positions
+}
+
+// pnlSummary is the profit and loss of the current holdings.
+type pnlSummary struct {
+	Positions []*coinPosition // sorted, BTC first
+	Realized  float64
+}
+
+var pnlCache struct {
+	key     string
+	summary *pnlSummary
+}
+
+// loadPnl replays the ledger, reusing the last result while ledger.csv and the
+// method are unchanged (archiving and merging always rewrite ledger.csv).
+func loadPnl() (*pnlSummary, error) {
+	method := loadCostBasisMethod()
+	key := method
+	if info, err := os.Stat(ledgerFilePath); err == nil {
+		key = fmt.Sprintf("%s|%d|%d", method, info.ModTime().UnixNano(), info.Size())
+	}
+	if pnlCache.summary != nil && pnlCache.key == key {
+		return pnlCache.summary, nil
+	}
+
+	entries, err := readAllLedgerEntries()
+	if err != nil {
+		return nil, err
+	}
+	_, positions := replayLedger(entries, method)
+	summary := &pnlSummary{}
+	for _, p := range positions {
+		summary.Positions = append(summary.Positions, p)
+		summary.Realized += p.Realized
+	}
+	sort.Slice(summary.Positions, func(i, j int) bool {
+		a, b := summary.Positions[i].Coin, summary.Positions[j].Coin
+		if a == "BTC" || b == "BTC" {
+			return a == "BTC"
+		}
+		return a < b
+	})
+	pnlCache.key, pnlCache.summary = key, summary
+	return summary, nil
+}
+
+// heldCostBasis returns the cost of balance units of p's coin. When the
+// portfolio balance differs from what the ledger replay holds (for example
+// after a reset with archives kept) the replayed unit cost is applied to the
+// real balance; ok is false when the ledger has no cost for the coin at all.
+func (p *coinPosition) heldCostBasis(balance float64) (cost float64, ok bool) {
+	if balance <= 0 {
+		return 0, true
+	}
+	if p == nil || p.Qty <= 0 {
+		return 0, false
+	}
+	return p.Cost * balance / p.Qty, true
+}
+
+// unrealizedPnl values the live portfolio balances against their replayed cost basis.
+func (s *pnlSummary) unrealizedPnl() float64 {
+	var total float64
+	for _, coin := range append([]string{"BTC"}, trackedCoins()...) {
+		balance, _ := cfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+		rate := coinRate(coin)
+		if balance <= 0 || rate <= 0 {
+			continue
+		}
+		if cost, ok := s.position(coin).heldCostBasis(balance); ok {
+			total += balance*rate - cost
+		}
+	}
+	return total
+}
+
+func (s *pnlSummary) position(coin string) *coinPosition {
+	for _, p := range s.Positions {
+		if p.Coin == coin {
+			return p
+		}
+	}
+	return nil
+}
+
+func pnlColor(v float64) *color.Color {
+	switch {
+	case v > 0.005:
+		return colorPositive
+	case v < -0.005:
+		return colorNegative
+	}
+	return color.New(color.FgWhite)
+}
+
+// showPnlLines adds realized and unrealized P/L to the main screen Portfolio section.
+func showPnlLines() {
+	summary, err := loadPnl()
+	if err != nil || len(summary.Positions) == 0 {
+		return
+	}
+	unrealized := summary.unrealizedPnl()
+	writeAlignedLine("Unrealized P/L:", formatProfitLoss(unrealized, ""), pnlColor(unrealized))
+	writeAlignedLine("Realized P/L:", formatProfitLoss(summary.Realized, ""), pnlColor(summary.Realized))
+}
+
+// showPnlScreen shows realized and unrealized gains per coin. "pnl fifo" and
+// "pnl average" switch the cost basis method first.
+func showPnlScreen(reader *bufio.Reader, args []string) {
+	if len(args) > 0 {
+		var method string
+		switch strings.ToLower(args[0]) {
+		case "fifo":
+			method = costBasisFIFO
+		case "avg", "average":
+			method = costBasisAverage
+		default:
+			color.Red("Usage: pnl [fifo|average]")
+			fmt.Println("Press Enter to continue.")
+			reader.ReadString('\n')
+			return
+		}
+		if err := updateIni(func(f *ini.File) {
+			f.Section("Settings").Key("CostBasis").SetValue(method)
+		}); err != nil {
+			slog.Error("config save failed", "path", iniFilePath, "err", err)
+			color.Red("Could not save vbtc.ini: %v", err)
+		} else {
+			slog.Info("cost basis method changed", "method", method)
+		}
+	}
+
+	clearScreen()
+	summary, err := loadPnl()
+	color.Yellow("*** Profit & Loss (%s cost) ***", strings.ToLower(loadCostBasisMethod()))
+	fmt.Println()
+	if err != nil {
+		color.Red("Error reading ledger: %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	if len(summary.Positions) == 0 {
+		fmt.Println("No transactions in the ledger yet.")
+		fmt.Println()
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	for _, p := range summary.Positions {
+		balance, _ := cfg.Section("Portfolio").Key(coinBalanceKey(p.Coin)).Float64()
+		colorInfo.Println(p.Coin)
+		writeAlignedLine("Held:", formatCoinAmount(balance), color.New(color.FgWhite))
+		if balance > 0 {
+			cost, ok := p.heldCostBasis(balance)
+			if !ok {
+				writeAlignedLine("Cost Basis:", "unknown (no purchases in the ledger)", color.New(color.FgHiBlack))
+			} else {
+				writeAlignedLine("Cost Basis:", fmt.Sprintf("%s (avg %s)", formatMoney(cost), formatMoney(cost/balance)), color.New(color.FgWhite))
+				if rate := coinRate(p.Coin); rate > 0 {
+					unrealized := balance*rate - cost
+					writeAlignedLine("Market Value:", formatMoney(balance*rate), color.New(color.FgWhite))
+					display := formatProfitLoss(unrealized, "")
+					if cost > 0 {
+						display += fmt.Sprintf(" [%+.2f%%]", unrealized/cost*100)
+					}
+					writeAlignedLine("Unrealized P/L:", display, pnlColor(unrealized))
+				}
+			}
+		}
+		writeAlignedLine("Realized P/L:", formatProfitLoss(p.Realized, ""), pnlColor(p.Realized))
+		if p.Unknown > 1e-9 {
+			writeAlignedLine("No Cost Basis:", formatCoinAmount(p.Unknown)+" sold without a matching purchase", color.New(color.FgHiBlack))
+		}
+		fmt.Println()
+	}
+
+	unrealized := summary.unrealizedPnl()
+	colorInfo.Println("Total")
+	writeAlignedLine("Unrealized P/L:", formatProfitLoss(unrealized, ""), pnlColor(unrealized))
+	writeAlignedLine("Realized P/L:", formatProfitLoss(summary.Realized, ""), pnlColor(summary.Realized))
+	writeAlignedLine("Combined:", formatProfitLoss(unrealized+summary.Realized, ""), pnlColor(unrealized+summary.Realized))
+	fmt.Println()
+	color.New(color.FgHiBlack).Println("Use 'pnl fifo' or 'pnl average' to change the cost basis method.")
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,134 @@
+package vbtc
+
+import (
+	"bufio"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// [Settings] AutoRefreshMinutes refreshes the main screen after that many
+// minutes without a key press at the command prompt (0, the default, never
+// does). The Bubble Tea prompt (mainscreen.go) times this itself; readCommand
+// handles it when typed-ahead input keeps that prompt from starting, reading
+// keys in raw mode so it can stop waiting. Either way whatever was typed is
+// put back on the redrawn prompt.
+const maxAutoRefreshMinutes = 1440
+
+func loadAutoRefreshMinutes() int {
+	if cfg == nil {
+		return 0
+	}
+	minutes := cfg.Section("Settings").Key("AutoRefreshMinutes").MustInt(0)
+	if minutes < 0 {
+		return 0
+	}
+	return min(minutes, maxAutoRefreshMinutes)
+}
+
+// readCommand reads a line at the main prompt, starting from typed (text put
+// back after an auto-refresh). It returns idle true, with the text typed so
+// far, when the auto-refresh interval passes without a key press. Without the
+// setting or a terminal it is a plain ReadString.
+func readCommand(reader *bufio.Reader, typed string) (line string, idle bool) {
+	minutes := loadAutoRefreshMinutes()
+	fd := int(os.Stdin.Fd())
+	if minutes == 0 || !term.IsTerminal(fd) {
+		input, _ := reader.ReadString('\n')
+		return typed + input, false
+	}
+	oldState, err := term.GetState(fd)
+	if err != nil {
+		input, _ := reader.ReadString('\n')
+		return typed + input, false
+	}
+	if _, err := term.MakeRaw(fd); err != nil {
+		input, _ := reader.ReadString('\n')
+		return typed + input, false
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		wg.Wait()
+		term.Restore(fd, oldState)
+		reader.Reset(os.Stdin)
+		priceWatch.echo("", "")
+	}()
+
+	inputChan := make(chan byte)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(inputChan)
+		for {
+			b, err := cancellableRead(done)
+			if err != nil {
+				return
+			}
+			select {
+			case inputChan <- b:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	text := []rune(typed)
+	priceWatch.echo(typed, typed)
+	interval := time.Duration(minutes) * time.Minute
+	idleTimer := time.NewTimer(interval)
+	defer idleTimer.Stop()
+	var pending []byte // an incomplete UTF-8 character
+	for {
+		select {
+		case <-idleTimer.C:
+			return string(text), true
+		case b, ok := <-inputChan:
+			if !ok {
+				return string(text), false
+			}
+			if !idleTimer.Stop() {
+				<-idleTimer.C
+			}
+			idleTimer.Reset(interval)
+			switch {
+			case b == 3:
+				term.Restore(fd, oldState)
+				os.Exit(1)
+			case b == 13 || b == 10:
+				priceWatch.echo(string(text), "\r\n")
+				return string(text), false
+			case b == 27:
+				// Drain an arrow key sequence; the prompt has no cursor keys.
+				select {
+				case <-inputChan:
+					select {
+					case <-inputChan:
+					case <-time.After(10 * time.Millisecond):
+					}
+				case <-time.After(10 * time.Millisecond):
+				}
+			case b == 127 || b == 8:
+				if len(text) > 0 {
+					text = text[:len(text)-1]
+					priceWatch.echo(string(text), "\b \b")
+				}
+			case b < 32:
+				// Ignore other control characters.
+			default:
+				pending = append(pending, b)
+				if !utf8.FullRune(pending) {
+					continue
+				}
+				text = append(text, []rune(string(pending))...)
+				priceWatch.echo(string(text), string(pending))
+				pending = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package vbtc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"kreftus/shared/lcw"
+)
+
+// PriceProvider is a source of market data. [Settings] Provider selects one
+// (LiveCoinWatch by default); when it fails, the others that need no API key
+// are tried in turn so prices keep coming while LiveCoinWatch is down or the
+// key's quota is used up.
+type PriceProvider interface {
+	Name() string
+	// Current returns the BTC rate, 24h volume and 24h change (Delta.Day, percent),
+	// in the provider's currency.
+	Current() (*ApiDataResponse, error)
+	// History returns BTC rates between start and end, about every 5 minutes.
+	History(start, end time.Time) (*HistoryResponse, error)
+	// Rates returns rates for coin symbols; coins it cannot price are left out.
+	Rates(coins []string) (map[string]float64, error)
+}
+
+const defaultProvider = "LiveCoinWatch"
+
+var providerNames = []string{"LiveCoinWatch", "CoinGecko", "Coinbase"}
+
+// newProvider returns the named provider quoting prices in currency.
+func newProvider(name, apiKey, currency string) PriceProvider {
+	switch strings.ToLower(name) {
+	case "coingecko":
+		return coinGeckoProvider{currency: currency}
+	case "coinbase":
+		return coinbaseProvider{currency: currency}
+	}
+	return liveCoinWatchProvider{apiKey: apiKey, currency: currency}
+}
+
+func selectedProviderName() string {
+	if cfg == nil {
+		return defaultProvider
+	}
+	return newProvider(cfg.Section("Settings").Key("Provider").String(), "", "").Name()
+}
+
+// priceProviders returns the selected provider followed by the fallbacks.
+// LiveCoinWatch is only a fallback when an API key is configured. A replay
+// is the only provider.
+func priceProviders() []PriceProvider {
+	if replaySource != nil {
+		return []PriceProvider{replaySource}
+	}
+	apiKey := liveCoinWatchKey
+	selected := selectedProviderName()
+	currency := currencyCode()
+	providers := []PriceProvider{newProvider(selected, apiKey, currency)}
+	for _, name := range providerNames {
+		if name == selected || (name == defaultProvider && apiKey == "") {
+			continue
+		}
+		providers = append(providers, newProvider(name, apiKey, currency))
+	}
+	return providers
+}
+
+// providerGet fetches url and decodes the JSON body into v, mapping HTTP
+// failures to the same errors the LiveCoinWatch calls return.
+func providerGet(provider, url string, v interface{}) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to execute request to %s: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return &ApiKeyError{StatusCode: resp.StatusCode}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &ProviderDownError{StatusCode: resp.StatusCode, Message: fmt.Sprintf("%s returned status %d", provider, resp.StatusCode)}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body from %s: %w", provider, err)
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("failed to unmarshal response from %s: %w", provider, err)
+	}
+	return nil
+}
+
+// LiveCoinWatch requests go through the shared lcw client, which retries
+// with exponential backoff and jitter, as in bmon, so a brief network hiccup
+// does not show up as "API Provider Problem". vbtc waits less than bmon
+// before giving up, since the prompt is waiting and the fallback providers
+// are tried next.
+const (
+	maxRequestAttempts   = 3
+	retryBaseDelay       = 1 * time.Second
+	liveCoinWatchTimeout = 10 * time.Second
+)
+
+// liveCoinWatchClient returns a client for apiKey. Every attempt counts
+// against the API budget.
+func liveCoinWatchClient(apiKey string) *lcw.Client {
+	return &lcw.Client{
+		APIKey:    apiKey,
+		Attempts:  maxRequestAttempts,
+		BaseDelay: retryBaseDelay,
+		Timeout:   liveCoinWatchTimeout,
+		OnAttempt: recordApiCall,
+	}
+}
+
+// postLiveCoinWatch sends payload to endpoint (e.g. "coins/single") and
+// decodes the response into out. Refused keys and used-up credits become an
+// ApiKeyError and other bad statuses a ProviderDownError, so the UI can tell
+// them apart; what names the data in messages, e.g. "current price".
+func postLiveCoinWatch(endpoint, apiKey string, payload, out interface{}, what string) error {
+	err := liveCoinWatchClient(apiKey).Post(context.Background(), endpoint, payload, out)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, lcw.ErrCreditsExhausted) {
+		return &ApiKeyError{StatusCode: http.StatusForbidden}
+	}
+	var statusErr *lcw.StatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.KeyRejected():
+			return &ApiKeyError{StatusCode: statusErr.StatusCode}
+		case statusErr.StatusCode >= 500 && statusErr.StatusCode <= 599:
+			return &ProviderDownError{StatusCode: statusErr.StatusCode, Message: "API provider returned server error for " + what}
+		default:
+			// Treat any other non-OK status as a provider problem, so the code is displayed.
+			return &ProviderDownError{StatusCode: statusErr.StatusCode, Message: fmt.Sprintf("API provider for %s returned non-OK status %d", what, statusErr.StatusCode)}
+		}
+	}
+	return fmt.Errorf("failed to fetch %s: %w", what, err)
+}
+
+type liveCoinWatchProvider struct {
+	apiKey   string
+	currency string
+}
+
+func (p liveCoinWatchProvider) Name() string { return "LiveCoinWatch" }
+
+func (p liveCoinWatchProvider) Current() (*ApiDataResponse, error) {
+	return fetchCurrentPriceData(p.apiKey, p.currency)
+}
+
+func (p liveCoinWatchProvider) History(start, end time.Time) (*HistoryResponse, error) {
+	return getHistoricalData(p.apiKey, p.currency, start.UnixMilli(), end.UnixMilli())
+}
+
+func (p liveCoinWatchProvider) Rates(coins []string) (map[string]float64, error) {
+	return fetchCoinRates(p.apiKey, p.currency, coins)
+}
+
+// coinGeckoProvider uses the public CoinGecko API (no key, rate limited).
+type coinGeckoProvider struct {
+	currency string
+}
+
+const coinGeckoAPI = "https://api.coingecko.com/api/v3"
+
+type coinGeckoMarket struct {
+	Symbol         string  `json:"symbol"`
+	CurrentPrice   float64 `json:"current_price"`
+	TotalVolume    float64 `json:"total_volume"`
+	PriceChange24h float64 `json:"price_change_percentage_24h"`
+}
+
+func (p coinGeckoProvider) Name() string { return "CoinGecko" }
+
+// markets returns the largest coin by market cap for each symbol.
+func (p coinGeckoProvider) markets(coins []string) (map[string]coinGeckoMarket, error) {
+	symbols := strings.ToLower(strings.Join(coins, ","))
+	var markets []coinGeckoMarket
+	if err := providerGet(p.Name(), coinGeckoAPI+"/coins/markets?vs_currency="+strings.ToLower(p.currency)+"&order=market_cap_desc&symbols="+url.QueryEscape(symbols), &markets); err != nil {
+		return nil, err
+	}
+	bySymbol := make(map[string]coinGeckoMarket, len(markets))
+	for _, m := range markets {
+		symbol := strings.ToUpper(m.Symbol)
+		if _, seen := bySymbol[symbol]; !seen && m.CurrentPrice > 0 {
+			bySymbol[symbol] = m
+		}
+	}
+	return bySymbol, nil
+}
+
+func (p coinGeckoProvider) Current() (*ApiDataResponse, error) {
+	markets, err := p.markets([]string{"BTC"})
+	if err != nil {
+		return nil, err
+	}
+	btc, ok := markets["BTC"]
+	if !ok {
+		return nil, fmt.Errorf("CoinGecko returned no BTC price")
+	}
+	data := &ApiDataResponse{Rate: btc.CurrentPrice, Volume: btc.TotalVolume, FetchTime: time.Now().UTC()}
+	data.Delta.Day = btc.PriceChange24h
+	return data, nil
+}
+
+func (p coinGeckoProvider) History(start, end time.Time) (*HistoryResponse, error) {
+	var chart struct {
+		Prices [][2]float64 `json:"prices"`
+	}
+	chartURL := fmt.Sprintf("%s/coins/bitcoin/market_chart/range?vs_currency=%s&from=%d&to=%d", coinGeckoAPI, strings.ToLower(p.currency), start.Unix(), end.Unix())
+	if err := providerGet(p.Name(), chartURL, &chart); err != nil {
+		return nil, err
+	}
+	history := &HistoryResponse{}
+	for _, point := range chart.Prices {
+		history.History = append(history.History, HistoryPoint{Date: int64(point[0]), Rate: point[1]})
+	}
+	return history, nil
+}
+
+func (p coinGeckoProvider) Rates(coins []string) (map[string]float64, error) {
+	markets, err := p.markets(coins)
+	if err != nil {
+		return nil, err
+	}
+	rates := make(map[string]float64, len(markets))
+	for symbol, m := range markets {
+		rates[symbol] = m.CurrentPrice
+	}
+	return rates, nil
+}
+
+// coinbaseProvider uses the public Coinbase Exchange market data API (no key).
+// Currencies Coinbase has no BTC market for fail over to the other providers.
+type coinbaseProvider struct {
+	currency string
+}
+
+const coinbaseAPI = "https://api.exchange.coinbase.com"
+
+func (p coinbaseProvider) Name() string { return "Coinbase" }
+
+func (p coinbaseProvider) Current() (*ApiDataResponse, error) {
+	var stats struct {
+		Open   string `json:"open"`
+		Last   string `json:"last"`
+		Volume string `json:"volume"`
+	}
+	if err := providerGet(p.Name(), coinbaseAPI+"/products/BTC-"+p.currency+"/stats", &stats); err != nil {
+		return nil, err
+	}
+	last, err := strconv.ParseFloat(stats.Last, 64)
+	if err != nil || last <= 0 {
+		return nil, fmt.Errorf("Coinbase returned no BTC price")
+	}
+	open, _ := strconv.ParseFloat(stats.Open, 64)
+	volume, _ := strconv.ParseFloat(stats.Volume, 64)
+	data := &ApiDataResponse{Rate: last, Volume: volume * last, FetchTime: time.Now().UTC()}
+	if open > 0 {
+		data.Delta.Day = (last - open) / open * 100
+	}
+	return data, nil
+}
+
+func (p coinbaseProvider) History(start, end time.Time) (*HistoryResponse, error) {
+	// Candles are [time, low, high, open, close, volume], newest first; 288 five-minute candles cover 24h.
+	var candles [][6]float64
+	candlesURL := fmt.Sprintf("%s/products/BTC-%s/candles?granularity=300&start=%s&end=%s", coinbaseAPI, p.currency,
+		url.QueryEscape(start.UTC().Format(time.RFC3339)), url.QueryEscape(end.UTC().Format(time.RFC3339)))
+	if err := providerGet(p.Name(), candlesURL, &candles); err != nil {
+		return nil, err
+	}
+	history := &HistoryResponse{}
+	for i := len(candles) - 1; i >= 0; i-- {
+		history.History = append(history.History, HistoryPoint{Date: int64(candles[i][0]) * 1000, Rate: candles[i][4]})
+	}
+	return history, nil
+}
+
+func (p coinbaseProvider) Rates(coins []string) (map[string]float64, error) {
+	rates := make(map[string]float64, len(coins))
+	var firstErr error
+	for _, coin := range coins {
+		var ticker struct {
+			Price string `json:"price"`
+		}
+		if err := providerGet(p.Name(), fmt.Sprintf("%s/products/%s-%s/ticker", coinbaseAPI, coin, p.currency), &ticker); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if rate, err := strconv.ParseFloat(ticker.Price, 64); err == nil && rate > 0 {
+			rates[coin] = rate
+		}
+	}
+	if len(rates) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return rates, nil
+}
+
+// invokeProviderConfig edits [Settings] Provider.
+func invokeProviderConfig(reader *bufio.Reader) {
+	selected := selectedProviderName()
+	colorInfo.Printf("Price provider: %s\n", selected)
+	for i, name := range providerNames {
+		note := ""
+		if name != defaultProvider {
+			note = " (no API key needed)"
+		}
+		fmt.Printf("  %d. %s%s\n", i+1, name, note)
+	}
+	fmt.Printf("Choose a provider (1-%d), or press Enter to keep %s: ", len(providerNames), selected)
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > len(providerNames) {
+		color.Red("Invalid choice. No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	name := providerNames[choice-1]
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("Provider").SetValue(name)
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("price provider changed", "provider", name)
+		priceWatch.configure()
+		apiData = updateApiData(true)
+		if name == defaultProvider && liveCoinWatchKey == "" {
+			color.Yellow("LiveCoinWatch needs an API key; set one with Update API Key.")
+		}
+		color.Green("Price provider set to %s.", name)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
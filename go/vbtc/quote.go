@@ -0,0 +1,93 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// showQuote handles "quote [buy|sell] <amount> [coin]": it prices a trade at
+// the current rate with the spread and fees, like the confirmation screen,
+// without offering to make it. The side defaults to buy, or sell for a
+// satoshi amount.
+func showQuote(reader *bufio.Reader, args []string) {
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+
+	side := "Buy"
+	if len(args) > 0 {
+		switch strings.ToLower(args[0]) {
+		case "buy", "b":
+			args = args[1:]
+		case "sell", "s":
+			side = "Sell"
+			args = args[1:]
+		}
+	}
+	coin, amountInput, err := parseTradeArgs(args)
+	if err != nil {
+		color.Red("%v", err)
+		return
+	}
+	if amountInput == "" {
+		color.Red("Usage: quote [buy|sell] <amount> [coin]")
+		return
+	}
+	if side == "Buy" && strings.HasSuffix(amountInput, "s") {
+		side = "Sell"
+	}
+
+	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	playerCoin, _ := cfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+	maxAmount := playerUSD
+	if side == "Sell" {
+		maxAmount = playerCoin
+	}
+	amount, err := parseTradeAmount(amountInput, maxAmount, side)
+	if err != nil {
+		color.Red("Invalid amount: %v.", err)
+		return
+	}
+	if amount <= 0 {
+		color.Red("Please enter a positive number.")
+		return
+	}
+	rate := coinRate(coin)
+	quote, err := quoteTrade(side, amount, rate)
+	if err != nil {
+		color.Red("No quote: %v.", err)
+		return
+	}
+
+	fmt.Println()
+	color.Yellow("*** Quote: %s %s ***", side, coinName(coin))
+	writeAlignedLine("Market Rate:", formatMoney(rate), color.New(color.FgWhite))
+	if quote.Rate != rate {
+		writeAlignedLine("Execution Price:", fmt.Sprintf("%s (%.3f%% spread)", formatMoney(quote.Rate), currentSpread()), color.New(color.FgHiBlack))
+	}
+	sideColor := colorPositive
+	if side == "Buy" {
+		writeAlignedLine("You Pay:", formatMoney(quote.USD), color.New(color.FgWhite))
+		writeAlignedLine("You Get:", fmt.Sprintf("%.8f %s", quote.Coin, coin), sideColor)
+	} else {
+		sideColor = colorNegative
+		writeAlignedLine("You Sell:", fmt.Sprintf("%.8f %s", quote.Coin, coin), sideColor)
+		writeAlignedLine("You Get:", formatMoney(quote.USD), color.New(color.FgWhite))
+	}
+	if quote.Fee > 0 {
+		writeAlignedLine("Fee:", formatMoney(quote.Fee), color.New(color.FgWhite))
+	}
+	if amount > maxAmount {
+		if side == "Buy" {
+			color.Yellow("This is more than your %s balance of %s.", currencyCode(), formatMoney(playerUSD))
+		} else {
+			color.Yellow("This is more than your %s balance of %.8f.", coin, playerCoin)
+		}
+	}
+	color.New(color.FgHiBlack).Println("Quote only; nothing was traded. Prices move, so a real trade may differ.")
+	fmt.Println()
+}
@@ -0,0 +1,109 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// minRebalanceTrade is the smallest trade, in the base currency, worth making
+// to reach a target; anything smaller counts as already on target.
+const minRebalanceTrade = 1.0
+
+// rebalanceTrade works out the trade that brings BTC to targetPercent of cash
+// plus BTC at rate. Buys are rounded down to the cent and sells to the
+// satoshi. Fees and spread are not included, so the result lands just short
+// of the target when they are set.
+func rebalanceTrade(cash, btc, rate, targetPercent float64) (side string, amount float64) {
+	btcValue := btc * rate
+	target := (cash + btcValue) * targetPercent / 100
+	diff := target - btcValue
+	if math.Abs(diff) < minRebalanceTrade {
+		return "", 0
+	}
+	if diff > 0 {
+		return "Buy", math.Min(math.Floor(diff*100)/100, cash)
+	}
+	if targetPercent == 0 {
+		return "Sell", btc // all of it, without rounding dust left behind
+	}
+	return "Sell", math.Min(math.Floor(-diff/rate*1e8)/1e8, btc)
+}
+
+// runRebalanceCommand handles "rebalance <percent>": it shows the buy or sell
+// that would make BTC that percent of cash plus BTC and, if confirmed, opens
+// the trade screen with it. Other tracked coins are left out of the split.
+// It returns the market data from the trade screen, or nil if none was opened.
+func runRebalanceCommand(reader *bufio.Reader, args []string) *ApiDataResponse {
+	if len(args) != 1 {
+		color.Red("Usage: rebalance <percent>, e.g. 'rebalance 60' for 60% BTC / 40% cash")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return nil
+	}
+	targetPercent, err := strconv.ParseFloat(strings.TrimSuffix(args[0], "%"), 64)
+	if err != nil || targetPercent < 0 || targetPercent > 100 {
+		color.Red("The target must be a percentage from 0 to 100, not '%s'.", args[0])
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return nil
+	}
+	rate := coinRate("BTC")
+	if rate <= 0 {
+		color.Red("No BTC price available. Refresh and try again.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return nil
+	}
+	cash, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	btc, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	total := cash + btc*rate
+	if total <= 0 {
+		color.Red("There is nothing to rebalance.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return nil
+	}
+
+	clearScreen()
+	color.Yellow("*** Rebalance ***")
+	writeAlignedLine(currencyLabel("Bitcoin"), formatMoney(rate), color.New(color.FgWhite))
+	writeAlignedLine("Cash + BTC:", formatMoney(total), color.New(color.FgWhite))
+	currentPercent := btc * rate / total * 100
+	writeAlignedLine("Now:", fmt.Sprintf("%.1f%% BTC / %.1f%% cash", currentPercent, 100-currentPercent), color.New(color.FgWhite))
+	writeAlignedLine("Target:", fmt.Sprintf("%s%% BTC / %s%% cash", strconv.FormatFloat(targetPercent, 'f', -1, 64), strconv.FormatFloat(100-targetPercent, 'f', -1, 64)), colorInfo)
+
+	side, amount := rebalanceTrade(cash, btc, rate, targetPercent)
+	if side == "" || amount <= 0 {
+		fmt.Println()
+		color.Green("Already on target; no trade needed.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return nil
+	}
+	var amountInput string
+	if side == "Buy" {
+		writeAlignedLine("Plan:", fmt.Sprintf("Buy %s of BTC (about %s BTC)", formatMoney(amount), formatCoinAmount(amount/rate)), colorPositive)
+		amountInput = strconv.FormatFloat(amount, 'f', 2, 64)
+	} else {
+		writeAlignedLine("Plan:", fmt.Sprintf("Sell %s BTC (about %s)", formatCoinAmount(amount), formatMoney(amount*rate)), colorNegative)
+		amountInput = strconv.FormatFloat(amount, 'f', 8, 64)
+	}
+	if feePercent, feeFlat := loadFeeModel(); feePercent > 0 || feeFlat > 0 || currentSpread() > 0 {
+		color.New(color.FgHiBlack).Println("Fees and spread are not in the plan, so the result lands just short of the target.")
+	}
+	fmt.Println()
+	fmt.Print("Open the trade screen with this trade? (y/n): ")
+	confirm, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		fmt.Println("Rebalance cancelled.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return nil
+	}
+	return invokeTrade(reader, side, "BTC", amountInput)
+}
@@ -0,0 +1,323 @@
+package vbtc
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+
+	"vbtc/internal/engine"
+)
+
+// A trade changes two files: the balances in vbtc.ini, then a row in
+// ledger.csv. Before saving vbtc.ini, a trade writes what it is about to do to
+// vbtc.pending.json next to it, and removes that file once the ledger row is
+// written. vbtc.ini itself is replaced in one rename, so it always holds
+// either the old balances or the new ones. If vbtc is killed or crashes in
+// between, the next start finds the pending file and finishes the job: a
+// trade whose balances were saved gets its missing ledger rows, one whose
+// balances were not saved is reported as not made.
+//
+// The session (its start, P/L baseline and statistics window) is saved to
+// vbtc.session.json when it starts and again after every command and refresh,
+// and removed on a normal exit. If vbtc is killed or crashes, outside a trade
+// or inside one (the pending record carries the session too), the next start
+// carries on that session instead of starting a new one at the recovered
+// balances.
+//
+// Pending orders, alerts and triggers need no journal: they live in vbtc.ini
+// and are saved the moment they change.
+const (
+	pendingTradeName = "vbtc.pending.json"
+	sessionStateName = "vbtc.session.json"
+	maxSessionResume = 24 * time.Hour // older sessions are not carried on
+)
+
+// pendingTrade is one or more trades of Coin saved together, as in a refresh
+// that fills several limit orders.
+type pendingTrade struct {
+	Started time.Time         `json:"started"`
+	Coin    string            `json:"coin"`
+	Before  portfolioSnapshot `json:"before"`
+	After   portfolioSnapshot `json:"after"`
+	Rows    []pendingRow      `json:"rows"`
+	Session *sessionState     `json:"session,omitempty"`
+}
+
+// sessionState is what the session P/L and session statistics are measured from.
+type sessionState struct {
+	Start      time.Time `json:"start"`
+	StartValue float64   `json:"startValue"`
+	StartRate  float64   `json:"startRate"`
+}
+
+func currentSession() *sessionState {
+	return &sessionState{Start: sessionStartTime, StartValue: sessionStartPortfolioValue, StartRate: initialSessionBtcPrice}
+}
+
+// resumeSession restores the session s describes, unless it is missing or
+// older than maxSessionResume.
+func resumeSession(s *sessionState) bool {
+	if s == nil || s.Start.IsZero() || time.Since(s.Start) > maxSessionResume {
+		return false
+	}
+	sessionStartTime, sessionStartPortfolioValue, initialSessionBtcPrice = s.Start, s.StartValue, s.StartRate
+	return true
+}
+
+func sessionStatePath() string {
+	return filepath.Join(filepath.Dir(iniFilePath), sessionStateName)
+}
+
+// saveSessionState writes the current session next to vbtc.ini.
+func saveSessionState() {
+	data, err := json.MarshalIndent(currentSession(), "", "  ")
+	if err != nil {
+		return
+	}
+	if err := writeFileAtomic(sessionStatePath(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		slog.Warn("session state write failed", "path", sessionStatePath(), "err", err)
+	}
+}
+
+// resumeSavedSession carries on the session a crashed or killed vbtc left
+// in vbtc.session.json, and reports whether it did.
+func resumeSavedSession() bool {
+	data, err := os.ReadFile(sessionStatePath())
+	if err != nil {
+		return false
+	}
+	var s sessionState
+	if err := json.Unmarshal(data, &s); err != nil {
+		slog.Warn("unreadable session state ignored", "path", sessionStatePath(), "err", err)
+		return false
+	}
+	return resumeSession(&s)
+}
+
+// endSession removes the saved session on a normal exit, so the next start
+// begins a new one.
+func endSession() {
+	if err := os.Remove(sessionStatePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("session state remove failed", "path", sessionStatePath(), "err", err)
+	}
+}
+
+type pendingRow struct {
+	TX       string  `json:"tx"`
+	USD      float64 `json:"usd"`
+	Amount   float64 `json:"amount"`
+	Rate     float64 `json:"rate"`
+	UserCoin float64 `json:"userCoin"`
+	Fee      float64 `json:"fee"`
+	Note     string  `json:"note,omitempty"`
+	// Time dates the row when it is not the trade's start (imported trades).
+	Time time.Time `json:"time,omitempty"`
+}
+
+func pendingTradePath() string {
+	return filepath.Join(filepath.Dir(iniFilePath), pendingTradeName)
+}
+
+// writeIni saves f over vbtc.ini in one rename, so a crash mid-write never
+// leaves a truncated portfolio. Callers hold the portfolio lock.
+func writeIni(f *ini.File) error {
+	return writeFileAtomic(iniFilePath, func(w io.Writer) error {
+		_, err := f.WriteTo(w)
+		return err
+	})
+}
+
+// beginTrade records trade before its balances are saved. A trade that
+// cannot be recorded must not be made. A record left by an earlier trade is
+// settled first, the way recoverPendingTrade does: completed if vbtc.ini holds
+// its After balances, dropped if it still holds Before. While completing it
+// keeps failing, no new trade is made. Callers hold the portfolio lock.
+func beginTrade(trade pendingTrade) error {
+	if data, err := os.ReadFile(pendingTradePath()); err == nil {
+		var earlier pendingTrade
+		if json.Unmarshal(data, &earlier) == nil && len(earlier.Rows) > 0 {
+			if err := settleEarlierTrade(earlier); err != nil {
+				return err
+			}
+		}
+	}
+	if trade.Started.IsZero() {
+		trade.Started = time.Now().UTC()
+	}
+	if trade.Session == nil {
+		trade.Session = currentSession()
+	}
+	data, err := json.MarshalIndent(trade, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(pendingTradePath(), func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	}); err != nil {
+		slog.Error("pending trade write failed", "path", pendingTradePath(), "err", err)
+		return fmt.Errorf("could not write %s: %w", pendingTradeName, err)
+	}
+	return nil
+}
+
+// settleEarlierTrade finishes or drops the record of an earlier trade, judged
+// by the balances vbtc.ini holds now.
+func settleEarlierTrade(earlier pendingTrade) error {
+	saved, err := ini.Load(iniFilePath)
+	if err != nil {
+		return fmt.Errorf("could not check an earlier trade against vbtc.ini: %w", err)
+	}
+	balances := portfolioBalances(saved, earlier.Coin)
+	switch {
+	case portfolioMatchesSnapshot(balances, earlier.After):
+		added, err := addMissingPendingRows(earlier)
+		if err != nil {
+			return fmt.Errorf("an earlier trade is still missing from ledger.csv: %w", err)
+		}
+		slog.Info("earlier pending trade completed", "coin", earlier.Coin, "rows_added", added)
+	case portfolioMatchesSnapshot(balances, earlier.Before):
+		slog.Info("earlier pending trade was not made", "coin", earlier.Coin)
+	default:
+		slog.Warn("earlier pending trade unresolved: balances changed since", "coin", earlier.Coin)
+	}
+	return nil
+}
+
+// endTrade removes the record once the ledger has been written. After a
+// failed ledger write the record is kept, for the next trade or start to finish.
+func endTrade() {
+	if err := os.Remove(pendingTradePath()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("pending trade remove failed", "path", pendingTradePath(), "err", err)
+	}
+}
+
+// fixedClock dates recovered ledger rows with the time of the interrupted trade.
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+// recoverPendingTrade finishes or reports a trade that was interrupted by a
+// crash, before the main screen is first shown.
+func recoverPendingTrade(reader *bufio.Reader) {
+	data, err := os.ReadFile(pendingTradePath())
+	if err != nil {
+		return
+	}
+	unlock, err := lockPortfolio()
+	if err != nil {
+		slog.Warn("pending trade check skipped", "err", err)
+		return
+	}
+	defer unlock()
+
+	var trade pendingTrade
+	if err := json.Unmarshal(data, &trade); err != nil || len(trade.Rows) == 0 {
+		slog.Warn("unreadable pending trade removed", "path", pendingTradePath(), "err", err)
+		endTrade()
+		return
+	}
+	recoveryCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		slog.Error("pending trade check: could not read portfolio", "path", iniFilePath, "err", err)
+		return
+	}
+
+	clearScreen()
+	color.Yellow("*** Interrupted Trade ***")
+	fmt.Printf("A trade of %s started at %s did not finish:\n", trade.Coin, trade.Started.Local().Format("01/02/06 15:04:05"))
+	for _, row := range trade.Rows {
+		fmt.Printf("  %s %s %s for %s\n", row.TX, formatCoinAmount(row.Amount), trade.Coin, formatMoney(row.USD))
+	}
+	fmt.Println()
+
+	balances := portfolioBalances(recoveryCfg, trade.Coin)
+	switch {
+	case portfolioMatchesSnapshot(balances, trade.After):
+		added, err := addMissingPendingRows(trade)
+		if err != nil {
+			slog.Error("pending trade ledger repair failed", "err", err)
+			color.Red("The balances were saved, but the ledger could not be completed: %v", err)
+			color.Red("vBTC will try again next time it starts.")
+			fmt.Println("Press Enter to continue.")
+			reader.ReadString('\n')
+			return
+		}
+		slog.Info("pending trade recovered", "coin", trade.Coin, "rows_added", added)
+		if added > 0 {
+			color.Green("The trade was made. %d missing ledger row(s) were added.", added)
+		} else {
+			color.Green("The trade was made and is in the ledger; nothing needed fixing.")
+		}
+	case portfolioMatchesSnapshot(balances, trade.Before):
+		slog.Info("pending trade was not made", "coin", trade.Coin)
+		color.Yellow("The trade was not made; your balances and ledger are unchanged.")
+	default:
+		slog.Warn("pending trade unresolved: balances changed since", "coin", trade.Coin)
+		color.Yellow("Your balances have changed since then (another session?), so vBTC cannot tell whether it was made.")
+		color.Yellow("Check the ledger; nothing has been changed.")
+	}
+	if resumeSession(trade.Session) {
+		slog.Info("session resumed", "start", sessionStartTime, "start_value", sessionStartPortfolioValue)
+		fmt.Printf("Continuing the session started at %s.\n", sessionStartTime.Local().Format("01/02/06 15:04:05"))
+	}
+	endTrade()
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// addMissingPendingRows appends the rows of trade that ledger.csv does not
+// already have, dated when the trade started (or at the row's own time),
+// and returns how many it added.
+func addMissingPendingRows(trade pendingTrade) (int, error) {
+	entries, err := engine.Ledger{Store: csvLedgerStore{}}.Entries()
+	if err != nil {
+		return 0, err
+	}
+	added := 0
+	for _, row := range trade.Rows {
+		if ledgerHasPendingRow(entries, trade, row) {
+			continue
+		}
+		at := trade.Started
+		if !row.Time.IsZero() {
+			at = row.Time
+		}
+		ledger := engine.Ledger{Store: csvLedgerStore{}, Clock: fixedClock(at)}
+		note, tags := splitNoteTags(row.Note)
+		if _, err := ledger.Append(LedgerEntry{TX: row.TX, USD: row.USD, BTC: row.Amount, BTCPrice: row.Rate, UserBTC: row.UserCoin, Coin: trade.Coin, Fee: row.Fee, Note: note, Tags: tags}); err != nil {
+			return added, err
+		}
+		added++
+	}
+	return added, nil
+}
+
+// ledgerHasPendingRow reports whether entries hold row, written no earlier
+// than the second the trade started, or at the row's own time when it has one.
+func ledgerHasPendingRow(entries []LedgerEntry, trade pendingTrade, row pendingRow) bool {
+	since := trade.Started.Truncate(time.Second)
+	for _, e := range entries {
+		if !row.Time.IsZero() && !e.DateTime.Equal(row.Time.Truncate(time.Second)) {
+			continue
+		}
+		if e.TX == row.TX && e.Coin == trade.Coin && (!row.Time.IsZero() || !e.DateTime.Before(since)) &&
+			math.Abs(e.USD-row.USD) < 0.005 && math.Abs(e.BTC-row.Amount) < 1e-8 {
+			return true
+		}
+	}
+	return false
+}
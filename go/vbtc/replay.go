@@ -0,0 +1,239 @@
+package vbtc
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Replay mode ("vbtc --replay <file> [--speed N]") trades against historical
+// BTC prices instead of a live provider. Market time starts at the first price
+// in the file and runs speed times faster than the wall clock. The portfolio
+// and ledger live in a separate replay directory so practice trades never
+// touch the real ones.
+const (
+	defaultReplaySpeed = 60.0
+	replayDir          = "replay"
+)
+
+// replaySource is the replay feed, or nil when trading live.
+var replaySource *replayProvider
+
+type replayProvider struct {
+	points    []HistoryPoint // sorted by Date
+	speed     float64
+	start     time.Time // market time when startReal was taken
+	startReal time.Time
+	demo      bool // generated prices from --demo rather than a file
+}
+
+// parseReplayArgs reads --replay <file> and --speed <n> from args. It returns
+// nil when --replay is not given.
+func parseReplayArgs(args []string) (*replayProvider, error) {
+	file := ""
+	speed := defaultReplaySpeed
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg != "-replay" && arg != "--replay" && arg != "-speed" && arg != "--speed" {
+			continue
+		}
+		if i+1 >= len(args) {
+			return nil, fmt.Errorf("%s needs a value", arg)
+		}
+		i++
+		if strings.HasSuffix(arg, "replay") {
+			file = args[i]
+			continue
+		}
+		s, err := strconv.ParseFloat(args[i], 64)
+		if err != nil || s <= 0 {
+			return nil, fmt.Errorf("invalid speed '%s'", args[i])
+		}
+		speed = s
+	}
+	if file == "" {
+		return nil, nil
+	}
+	points, err := loadReplayFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if len(points) < 2 {
+		return nil, fmt.Errorf("%s has fewer than two prices", file)
+	}
+	start := time.UnixMilli(points[0].Date).UTC()
+	return &replayProvider{points: points, speed: speed, start: start, startReal: time.Now()}, nil
+}
+
+// loadReplayFile reads prices from a .json file (the LiveCoinWatch history
+// format {"history":[{"date":ms,"rate":x}]}, a bare array of those points, or
+// [[ms, rate], ...]) or from a CSV of time,price rows.
+func loadReplayFile(path string) ([]HistoryPoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var points []HistoryPoint
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		points, err = parseReplayJSON(data)
+	} else {
+		points, err = parseReplayCSV(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Date < points[j].Date })
+	return points, nil
+}
+
+func parseReplayJSON(data []byte) ([]HistoryPoint, error) {
+	var history HistoryResponse
+	if err := json.Unmarshal(data, &history); err == nil && len(history.History) > 0 {
+		return history.History, nil
+	}
+	var points []HistoryPoint
+	if err := json.Unmarshal(data, &points); err == nil && len(points) > 0 && points[0].Date > 0 {
+		return points, nil
+	}
+	var pairs [][2]float64
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("unrecognized JSON price format")
+	}
+	for _, pair := range pairs {
+		points = append(points, HistoryPoint{Date: int64(pair[0]), Rate: pair[1]})
+	}
+	return points, nil
+}
+
+// parseReplayCSV reads time,price rows. Time may be Unix seconds or
+// milliseconds, RFC 3339, or "2006-01-02 15:04:05" (UTC). A header row is skipped.
+func parseReplayCSV(data []byte) ([]HistoryPoint, error) {
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var points []HistoryPoint
+	for i, record := range records {
+		if len(record) < 2 {
+			continue
+		}
+		rate, rateErr := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		at, timeErr := parseReplayTime(strings.TrimSpace(record[0]))
+		if rateErr != nil || timeErr != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: expected time,price", i+1)
+		}
+		points = append(points, HistoryPoint{Date: at.UnixMilli(), Rate: rate})
+	}
+	return points, nil
+}
+
+func parseReplayTime(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > 1e11 {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.UTC); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time '%s'", s)
+}
+
+// now is the replayed market time, which stops at the last price.
+func (p *replayProvider) now() time.Time {
+	elapsed := time.Duration(float64(time.Since(p.startReal)) * p.speed)
+	last := time.UnixMilli(p.points[len(p.points)-1].Date).UTC()
+	if now := p.start.Add(elapsed); now.Before(last) {
+		return now
+	}
+	return last
+}
+
+func (p *replayProvider) finished() bool {
+	return p.now().UnixMilli() >= p.points[len(p.points)-1].Date
+}
+
+// rateAt returns the last price at or before t.
+func (p *replayProvider) rateAt(t time.Time) float64 {
+	ms := t.UnixMilli()
+	i := sort.Search(len(p.points), func(i int) bool { return p.points[i].Date > ms })
+	if i == 0 {
+		return p.points[0].Rate
+	}
+	return p.points[i-1].Rate
+}
+
+func (p *replayProvider) Name() string {
+	if p.demo {
+		return "Demo"
+	}
+	return "Replay"
+}
+
+func (p *replayProvider) Current() (*ApiDataResponse, error) {
+	now := p.now()
+	data := &ApiDataResponse{Rate: p.rateAt(now), FetchTime: time.Now().UTC()}
+	if dayAgo := p.rateAt(now.Add(-24 * time.Hour)); dayAgo > 0 {
+		data.Delta.Day = (data.Rate - dayAgo) / dayAgo * 100
+	}
+	return data, nil
+}
+
+func (p *replayProvider) History(start, end time.Time) (*HistoryResponse, error) {
+	history := &HistoryResponse{}
+	for _, point := range p.points {
+		if point.Date >= start.UnixMilli() && point.Date <= end.UnixMilli() {
+			history.History = append(history.History, point)
+		}
+	}
+	return history, nil
+}
+
+// Rates has no prices for other coins; they stay unpriced during a replay.
+func (p *replayProvider) Rates(coins []string) (map[string]float64, error) {
+	return map[string]float64{}, nil
+}
+
+// marketNow is the current market time: replayed time in replay mode, otherwise the wall clock.
+func marketNow() time.Time {
+	if replaySource != nil {
+		return replaySource.now()
+	}
+	return time.Now().UTC()
+}
+
+// showReplayStatus adds the replay clock to the main screen.
+func showReplayStatus() {
+	if replaySource == nil {
+		return
+	}
+	if replaySource.demo {
+		writeAlignedLine("Demo Prices:", "simulated, not real market data", color.New(color.FgMagenta))
+		return
+	}
+	first := replaySource.points[0].Date
+	span := float64(replaySource.points[len(replaySource.points)-1].Date - first)
+	progress := math.Min(float64(marketNow().UnixMilli()-first)/span*100, 100)
+	status := fmt.Sprintf("%s (%sx, %.0f%%)", marketNow().Local().Format("010206@150405"), strconv.FormatFloat(replaySource.speed, 'f', -1, 64), progress)
+	if replaySource.finished() {
+		status += " finished"
+	}
+	writeAlignedLine("Replay Time:", status, color.New(color.FgMagenta))
+}
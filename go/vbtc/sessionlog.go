@@ -0,0 +1,151 @@
+package vbtc
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// [Settings] SessionLog = true keeps an audit trail in sessions/<date>.log
+// next to vbtc.ini: the commands entered, prices fetched, trades made and any
+// warnings or errors. It is separate from the --debug log, which is for
+// diagnosing vbtc itself and is off unless asked for.
+const sessionLogDir = "sessions"
+
+// sessionLogOn mirrors the setting so the log handler needs no config lookup.
+var sessionLogOn bool
+
+// sessionLog records session events; warnings and errors logged through slog
+// are added by the handler installed in startSessionLog.
+var sessionLog = slog.New(slog.NewTextHandler(sessionLogWriter{}, nil))
+
+func loadSessionLogSetting() bool {
+	return cfg != nil && cfg.Section("Settings").Key("SessionLog").MustBool(false)
+}
+
+func sessionLogPath(day time.Time) string {
+	return filepath.Join(filepath.Dir(iniFilePath), sessionLogDir, day.Format("2006-01-02")+".log")
+}
+
+// sessionLogWriter appends to the log for the current day, so a session that
+// runs past midnight continues in the next day's file.
+type sessionLogWriter struct{}
+
+func (sessionLogWriter) Write(p []byte) (int, error) {
+	if !sessionLogOn {
+		return len(p), nil
+	}
+	path := sessionLogPath(time.Now())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+	return file.Write(p)
+}
+
+// teeHandler passes each record to every handler that wants it.
+type teeHandler []slog.Handler
+
+func (t teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range t {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t teeHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, h := range t {
+		if h.Enabled(ctx, r.Level) {
+			h.Handle(ctx, r.Clone())
+		}
+	}
+	return nil
+}
+
+func (t teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithAttrs(attrs)
+	}
+	return out
+}
+
+func (t teeHandler) WithGroup(name string) slog.Handler {
+	out := make(teeHandler, len(t))
+	for i, h := range t {
+		out[i] = h.WithGroup(name)
+	}
+	return out
+}
+
+// startSessionLog reads the setting, copies slog warnings and errors into the
+// session log and records the start of the session.
+func startSessionLog() {
+	sessionLogOn = loadSessionLogSetting()
+	errorsHandler := slog.NewTextHandler(sessionLogWriter{}, &slog.HandlerOptions{Level: slog.LevelWarn})
+	slog.SetDefault(slog.New(teeHandler{slog.Default().Handler(), errorsHandler}))
+	sessionLog.Info("session started", "ini", iniFilePath, "replay", replaySource != nil)
+}
+
+// logSessionCommand records a command typed at the main prompt.
+func logSessionCommand(input string) {
+	if input = strings.TrimSpace(input); input != "" {
+		sessionLog.Info("command", "input", input)
+	}
+}
+
+func sessionLogDisplay() string {
+	if sessionLogOn {
+		return "on"
+	}
+	return "off"
+}
+
+// invokeSessionLogConfig turns [Settings] SessionLog on or off.
+func invokeSessionLogConfig(reader *bufio.Reader) {
+	colorInfo.Printf("Session log: %s\n", sessionLogDisplay())
+	fmt.Printf("Logs go to %s\n", filepath.Join(filepath.Dir(iniFilePath), sessionLogDir, "<date>.log"))
+	fmt.Print("Keep a session log of commands, prices, trades and errors? (y/n, Enter to keep): ")
+	input, _ := reader.ReadString('\n')
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input != "y" && input != "n" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	on := input == "y"
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("SessionLog").SetValue(fmt.Sprintf("%t", on))
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		if on {
+			sessionLogOn = true
+			sessionLog.Info("session log turned on")
+			color.Green("Session log on.")
+		} else {
+			sessionLog.Info("session log turned off")
+			sessionLogOn = false
+			color.Green("Session log off.")
+		}
+		slog.Info("session log configured", "on", on)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
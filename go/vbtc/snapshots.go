@@ -0,0 +1,268 @@
+package vbtc
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+// snapshots.csv, next to ledger.csv, keeps one portfolio value per day: the
+// row for today is written when a session starts and replaced when it ends,
+// so the history command can chart growth over weeks.
+const (
+	snapshotsName    = "snapshots.csv"
+	snapshotDateFmt  = "2006-01-02"
+	equityCurveRows  = 12
+	equityCurveWidth = 60
+)
+
+var snapshotHeader = []string{"Date", "Value", "USD", "BTC", "BTCPrice", "Currency"}
+
+type portfolioValueSnapshot struct {
+	Date     time.Time
+	Value    float64
+	USD      float64
+	BTC      float64
+	BTCPrice float64
+	Currency string
+}
+
+func snapshotsFilePath() string {
+	return filepath.Join(filepath.Dir(ledgerFilePath), snapshotsName)
+}
+
+// loadSnapshots reads snapshots.csv, oldest first. Rows that do not parse are skipped.
+func loadSnapshots() ([]portfolioValueSnapshot, error) {
+	file, err := os.Open(snapshotsFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []portfolioValueSnapshot
+	for _, record := range records {
+		if len(record) < 5 || record[0] == snapshotHeader[0] {
+			continue
+		}
+		date, err := time.ParseInLocation(snapshotDateFmt, record[0], time.Local)
+		if err != nil {
+			continue
+		}
+		s := portfolioValueSnapshot{Date: date, Currency: defaultCurrency}
+		s.Value, _ = strconv.ParseFloat(record[1], 64)
+		s.USD, _ = strconv.ParseFloat(record[2], 64)
+		s.BTC, _ = strconv.ParseFloat(record[3], 64)
+		s.BTCPrice, _ = strconv.ParseFloat(record[4], 64)
+		if len(record) > 5 && record[5] != "" {
+			s.Currency = record[5]
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, nil
+}
+
+// recordSnapshot saves today's portfolio value, replacing an earlier row for
+// today. Nothing is saved without a market price, since the value would leave
+// out the coins held.
+func recordSnapshot() {
+	if cfg == nil || apiData == nil || apiData.Rate <= 0 {
+		return
+	}
+	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	today := time.Now().Format(snapshotDateFmt)
+	row := []string{
+		today,
+		strconv.FormatFloat(getPortfolioValue(playerUSD, playerBTC, apiData), 'f', 2, 64),
+		strconv.FormatFloat(playerUSD, 'f', 2, 64),
+		strconv.FormatFloat(playerBTC, 'f', 8, 64),
+		strconv.FormatFloat(apiData.Rate, 'f', 2, 64),
+		currencyCode(),
+	}
+
+	unlock, err := lockPortfolio()
+	if err != nil {
+		slog.Warn("snapshot skipped", "err", err)
+		return
+	}
+	defer unlock()
+	var records [][]string
+	if file, err := os.Open(snapshotsFilePath()); err == nil {
+		reader := csv.NewReader(file)
+		reader.FieldsPerRecord = -1
+		records, _ = reader.ReadAll()
+		file.Close()
+	}
+	if len(records) == 0 || len(records[0]) == 0 || records[0][0] != snapshotHeader[0] {
+		records = append([][]string{snapshotHeader}, records...)
+	}
+	if last := records[len(records)-1]; len(last) > 0 && last[0] == today {
+		records[len(records)-1] = row
+	} else {
+		records = append(records, row)
+	}
+	err = writeFileAtomic(snapshotsFilePath(), func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		writer.WriteAll(records)
+		return writer.Error()
+	})
+	if err != nil {
+		slog.Warn("snapshot write failed", "path", snapshotsFilePath(), "err", err)
+		return
+	}
+	slog.Debug("snapshot recorded", "date", today, "value", row[1])
+}
+
+// sampleSnapshots picks at most width snapshots spread evenly over all of
+// them, always keeping the first and last.
+func sampleSnapshots(snapshots []portfolioValueSnapshot, width int) []portfolioValueSnapshot {
+	if len(snapshots) <= width {
+		return snapshots
+	}
+	sampled := make([]portfolioValueSnapshot, width)
+	for i := range sampled {
+		sampled[i] = snapshots[i*(len(snapshots)-1)/(width-1)]
+	}
+	return sampled
+}
+
+// printEquityCurve draws the values as an ASCII chart, one column per
+// snapshot, green where the value is at or above the first and red below it.
+func printEquityCurve(snapshots []portfolioValueSnapshot) {
+	low, high := snapshots[0].Value, snapshots[0].Value
+	for _, s := range snapshots {
+		low, high = min(low, s.Value), max(high, s.Value)
+	}
+	if high-low < 0.005 {
+		high = low + 1 // a flat line in the middle of the chart
+		low--
+	}
+	rowOf := func(value float64) int {
+		return int(math.Round((value - low) / (high - low) * float64(equityCurveRows-1)))
+	}
+	labelWidth := max(utf8.RuneCountInString(formatMoney(high)), utf8.RuneCountInString(formatMoney(low)))
+	green, red := colorPositive, colorNegative
+
+	for row := equityCurveRows - 1; row >= 0; row-- {
+		label := ""
+		switch row {
+		case equityCurveRows - 1:
+			label = formatMoney(high)
+		case 0:
+			label = formatMoney(low)
+		case equityCurveRows / 2:
+			label = formatMoney(low + (high-low)*float64(row)/float64(equityCurveRows-1))
+		}
+		fmt.Printf("%*s |", labelWidth, label)
+		for i, s := range snapshots {
+			r := rowOf(s.Value)
+			previous := r
+			if i > 0 {
+				previous = rowOf(snapshots[i-1].Value)
+			}
+			c := green
+			if s.Value < snapshots[0].Value {
+				c = red
+			}
+			switch {
+			case row == r:
+				c.Print("*")
+			case row > min(r, previous) && row < max(r, previous):
+				c.Print("|")
+			default:
+				fmt.Print(" ")
+			}
+		}
+		fmt.Println()
+	}
+	fmt.Printf("%*s +%s\n", labelWidth, "", strings.Repeat("-", len(snapshots)))
+	first, last := snapshots[0].Date.Format(snapshotDateFmt), snapshots[len(snapshots)-1].Date.Format(snapshotDateFmt)
+	gap := max(len(snapshots)-len(first)-len(last), 1)
+	if len(snapshots) == 1 {
+		fmt.Printf("%*s  %s\n", labelWidth, "", first)
+	} else {
+		fmt.Printf("%*s  %s%s%s\n", labelWidth, "", first, strings.Repeat(" ", gap), last)
+	}
+}
+
+// showHistoryScreen charts the daily snapshots with a summary of the change.
+func showHistoryScreen(reader *bufio.Reader) {
+	clearScreen()
+	color.Yellow("*** Portfolio History ***")
+	fmt.Println()
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+
+	snapshots, err := loadSnapshots()
+	if err != nil {
+		color.Red("Could not read %s: %v", snapshotsName, err)
+		fmt.Println()
+		return
+	}
+	// Values in another base currency cannot share the chart.
+	code := currencyCode()
+	var kept []portfolioValueSnapshot
+	for _, s := range snapshots {
+		if s.Currency == code {
+			kept = append(kept, s)
+		}
+	}
+	if len(kept) == 0 {
+		fmt.Println("No snapshots yet. One is saved each day you run vBTC.")
+		fmt.Println()
+		return
+	}
+
+	printEquityCurve(sampleSnapshots(kept, equityCurveWidth))
+	fmt.Println()
+
+	first, latest := kept[0], kept[len(kept)-1]
+	best, worst := first, first
+	for _, s := range kept {
+		if s.Value > best.Value {
+			best = s
+		}
+		if s.Value < worst.Value {
+			worst = s
+		}
+	}
+	change := latest.Value - first.Value
+	changeColor := color.New(color.FgWhite)
+	if change > 0 {
+		changeColor = colorPositive
+	} else if change < 0 {
+		changeColor = colorNegative
+	}
+	writeAlignedLine("Snapshots:", fmt.Sprintf("%d days since %s", len(kept), first.Date.Format(snapshotDateFmt)), color.New(color.FgWhite))
+	writeAlignedLine("First Value:", formatMoney(first.Value), color.New(color.FgWhite))
+	writeAlignedLine("Latest Value:", formatMoney(latest.Value), changeColor)
+	changePercent := "n/a"
+	if first.Value > 0 {
+		changePercent = fmt.Sprintf("%+.2f%%", change/first.Value*100)
+	}
+	writeAlignedLineWithBrackets("Change:", signOf(change)+formatMoney(math.Abs(change)), changePercent, changeColor, 0)
+	writeAlignedLine("Best Day:", fmt.Sprintf("%s (%s)", formatMoney(best.Value), best.Date.Format(snapshotDateFmt)), colorPositive)
+	writeAlignedLine("Worst Day:", fmt.Sprintf("%s (%s)", formatMoney(worst.Value), worst.Date.Format(snapshotDateFmt)), colorNegative)
+	fmt.Println()
+}
@@ -0,0 +1,96 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"kreftus/shared/notify"
+)
+
+// Sounds use the same beeps as bmon (a tone on Windows, the terminal bell
+// elsewhere). Trade sounds are off unless [Settings] TradeSounds = true; price
+// alert and price watcher beeps follow [Alerts] Beep, which is on by default.
+type sound int
+
+const (
+	soundTradeDone sound = iota
+	soundTradeFailed
+	soundAlertUp
+	soundAlertDown
+)
+
+func tradeSoundsEnabled(f *ini.File) bool {
+	return f != nil && f.Section("Settings").Key("TradeSounds").MustBool(false)
+}
+
+// playSound beeps for s when its kind of sound is turned on.
+func playSound(s sound) {
+	switch s {
+	case soundTradeDone, soundTradeFailed:
+		if !tradeSoundsEnabled(cfg) {
+			return
+		}
+	default:
+		if !alertBeepEnabled(cfg) {
+			return
+		}
+	}
+	switch s {
+	case soundTradeDone:
+		notify.Beep(1000, 150)
+	case soundTradeFailed:
+		notify.Beep(300, 400)
+	case soundAlertUp:
+		notify.Beep(1200, 350)
+	case soundAlertDown:
+		notify.Beep(400, 350)
+	}
+}
+
+// soundsDisplay summarizes the sound settings for the config menu.
+func soundsDisplay() string {
+	trades, alerts := "Off", "Off"
+	if tradeSoundsEnabled(cfg) {
+		trades = "On"
+	}
+	if alertBeepEnabled(cfg) {
+		alerts = "On"
+	}
+	return fmt.Sprintf("Trades %s, Alerts %s", trades, alerts)
+}
+
+func invokeSoundsConfig(reader *bufio.Reader) {
+	colorInfo.Printf("Sounds: %s\n", soundsDisplay())
+	trades := askOnOff(reader, "Beep when a trade completes or fails? (y/n, Enter to keep): ", tradeSoundsEnabled(cfg))
+	alerts := askOnOff(reader, "Beep on price alerts and watcher moves? (y/n, Enter to keep): ", alertBeepEnabled(cfg))
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("TradeSounds").SetValue(strconv.FormatBool(trades))
+		f.Section(alertsSection).Key("Beep").SetValue(strconv.FormatBool(alerts))
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("sounds configured", "trades", trades, "alerts", alerts)
+		color.Green("Sounds: %s", soundsDisplay())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// askOnOff reads y or n, returning current for anything else.
+func askOnOff(reader *bufio.Reader, prompt string, current bool) bool {
+	fmt.Print(prompt)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y":
+		return true
+	case "n":
+		return false
+	}
+	return current
+}
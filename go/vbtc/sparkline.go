@@ -0,0 +1,72 @@
+package vbtc
+
+import (
+	"strings"
+	"time"
+)
+
+// sparklinePoints is the number of glyphs in the main screen sparkline, one
+// per hour of the 24h history.
+const sparklinePoints = 24
+
+// sparkChars are the block elements the sparkline is drawn with, lowest first,
+// the same set bmon uses.
+var sparkChars = []rune{'▁', '▂', '▃', '▄', '▅', '▆', '▇', '█'}
+
+// hourlySamples reduces history (sorted by date) to the last rate of each of
+// the n hours ending at end, oldest first. Hours without a point repeat the
+// rate before them; hours before the first point are left out.
+func hourlySamples(history []HistoryPoint, end time.Time, n int) []float64 {
+	if len(history) == 0 || n <= 0 {
+		return nil
+	}
+	startMs := end.Add(-time.Duration(n) * time.Hour).UnixMilli()
+	samples := make([]float64, 0, n)
+	i := 0
+	last, seen := 0.0, false
+	for hour := 1; hour <= n; hour++ {
+		bucketEnd := startMs + int64(hour)*time.Hour.Milliseconds()
+		for i < len(history) && history[i].Date < bucketEnd {
+			last, seen = history[i].Rate, true
+			i++
+		}
+		if seen {
+			samples = append(samples, last)
+		}
+	}
+	return samples
+}
+
+// getSparkline renders history as a sparkline of width glyphs scaled between
+// its low and high, most recent on the right. It is blank when there are
+// fewer than two points or the price did not move.
+func getSparkline(history []float64, width int) string {
+	if len(history) < 2 {
+		return strings.Repeat(" ", width)
+	}
+	minPrice, maxPrice := history[0], history[0]
+	for _, price := range history {
+		minPrice, maxPrice = min(minPrice, price), max(maxPrice, price)
+	}
+	priceRange := maxPrice - minPrice
+	if priceRange < 0.00000001 {
+		return strings.Repeat(" ", width)
+	}
+
+	var sparkRunes []rune
+	for _, price := range history {
+		charIndex := int((price - minPrice) / priceRange * float64(len(sparkChars)-1))
+		if charIndex >= len(sparkChars) {
+			charIndex = len(sparkChars) - 1
+		}
+		sparkRunes = append(sparkRunes, sparkChars[charIndex])
+	}
+	// Keep exactly width glyphs, truncating or padding on the left.
+	if len(sparkRunes) > width {
+		sparkRunes = sparkRunes[len(sparkRunes)-width:]
+	}
+	if len(sparkRunes) < width {
+		sparkRunes = append([]rune(strings.Repeat(" ", width-len(sparkRunes))), sparkRunes...)
+	}
+	return string(sparkRunes)
+}
@@ -0,0 +1,105 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// The simulated bid/ask spread is set in [Settings] as SpreadPercent, the full
+// width of the spread as a percent of the mid-market rate. Buys execute half
+// the spread above the rate and sells half below. With SpreadVolatility = true
+// the width grows with BTC's 24h volatility: on a day that swings more than
+// spreadReferenceVolatility the spread widens in proportion, up to
+// spreadMaxScale times the configured width.
+const (
+	spreadReferenceVolatility = 5.0
+	spreadMaxScale            = 4.0
+)
+
+func loadSpreadModel() (percent float64, volatilityScaled bool) {
+	if cfg == nil {
+		return 0, false
+	}
+	percent, _ = cfg.Section("Settings").Key("SpreadPercent").Float64()
+	volatilityScaled, _ = cfg.Section("Settings").Key("SpreadVolatility").Bool()
+	return math.Max(percent, 0), volatilityScaled
+}
+
+// currentSpread returns the spread width in percent after volatility scaling.
+func currentSpread() float64 {
+	percent, volatilityScaled := loadSpreadModel()
+	if percent == 0 || !volatilityScaled || apiData == nil || apiData.Volatility24h <= spreadReferenceVolatility {
+		return percent
+	}
+	return percent * math.Min(apiData.Volatility24h/spreadReferenceVolatility, spreadMaxScale)
+}
+
+// printExecutionRate adds the effective price to a trade confirmation when
+// the spread moves it away from the market rate.
+func printExecutionRate(q tradeQuote, marketRate float64) {
+	if math.Abs(q.Rate-marketRate) < 0.005 {
+		return
+	}
+	color.New(color.FgHiBlack).Printf("Execution Price: %s (%.3f%% spread)\n", formatMoney(q.Rate), currentSpread())
+}
+
+func spreadModelDisplay() string {
+	percent, volatilityScaled := loadSpreadModel()
+	if percent == 0 {
+		return "none"
+	}
+	display := fmt.Sprintf("%s%%", strconv.FormatFloat(percent, 'f', -1, 64))
+	if volatilityScaled {
+		display += ", volatility scaled"
+	}
+	return display
+}
+
+// invokeSpreadConfig edits SpreadPercent and SpreadVolatility. Enter keeps a value.
+func invokeSpreadConfig(reader *bufio.Reader) {
+	percent, volatilityScaled := loadSpreadModel()
+	colorInfo.Printf("Current spread: %s\n", spreadModelDisplay())
+
+	newPercent, ok := promptSettingValue(reader, fmt.Sprintf("Spread width in percent [%s]: ", strconv.FormatFloat(percent, 'f', -1, 64)), percent, 20)
+	if !ok {
+		return
+	}
+	current := "n"
+	if volatilityScaled {
+		current = "y"
+	}
+	fmt.Printf("Widen the spread on volatile days? (y/n) [%s]: ", current)
+	input, _ := reader.ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "":
+	case "y", "yes":
+		volatilityScaled = true
+	case "n", "no":
+		volatilityScaled = false
+	default:
+		color.Red("Invalid value. No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("SpreadPercent").SetValue(strconv.FormatFloat(newPercent, 'f', -1, 64))
+		f.Section("Settings").Key("SpreadVolatility").SetValue(strconv.FormatBool(volatilityScaled))
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		slog.Info("spread updated", "percent", newPercent, "volatility", volatilityScaled)
+		color.Green("Spread set to %s.", spreadModelDisplay())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,280 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// Performance statistics are computed from the whole ledger history with the
+// same cost basis replay as pnl. Holding times always match sales to the
+// oldest purchases first, whatever the cost basis method.
+
+// holdingBuckets are the holding time ranges, each up to its limit.
+var holdingBuckets = []struct {
+	label string
+	limit time.Duration
+}{
+	{"Under 1 hour", time.Hour},
+	{"1 hour - 1 day", 24 * time.Hour},
+	{"1 day - 1 week", 7 * 24 * time.Hour},
+	{"1 week - 30 days", 30 * 24 * time.Hour},
+	{"Over 30 days", math.MaxInt64},
+}
+
+type monthPnl struct {
+	Month    string // YYYY-MM, local time
+	Realized float64
+	Trades   int
+}
+
+type tradeStats struct {
+	Trades, Buys, Sells int
+	BuyUSD, SellUSD     float64
+	Wins, Losses        int
+	LargestGain         float64
+	LargestLoss         float64
+	LargestGainAt       time.Time
+	LargestLossAt       time.Time
+	MaxDrawdown         float64 // dollars from the highest P/L to the lowest after it
+	MaxDrawdownPct      float64 // of starting capital plus that peak
+	DrawdownPeak        time.Time
+	DrawdownTrough      time.Time
+	Holding             []int // sales per holdingBuckets entry
+	AvgHolding          time.Duration
+	Months              []monthPnl
+}
+
+type datedLot struct {
+	qty float64
+	at  time.Time
+}
+
+// computeTradeStats replays rows (from replayLedger, in time order). The P/L
+// curve used for drawdown marks every open position at the last traded price
+// of its coin, then at the current rate for the final point.
+func computeTradeStats(rows []exportRow) *tradeStats {
+	stats := &tradeStats{Holding: make([]int, len(holdingBuckets))}
+	lots := make(map[string][]datedLot)
+	positions := make(map[string]*coinPosition)
+	lastPrice := make(map[string]float64)
+	months := make(map[string]*monthPnl)
+	var realized, peak float64
+	var peakAt time.Time
+	var totalHolding time.Duration
+	var holdingSales int
+
+	track := func(pnl float64, at time.Time) {
+		if pnl > peak || peakAt.IsZero() {
+			peak, peakAt = pnl, at
+		}
+		if drop := peak - pnl; drop > stats.MaxDrawdown {
+			stats.MaxDrawdown = drop
+			stats.MaxDrawdownPct = drop / (startingCapital + peak) * 100
+			stats.DrawdownPeak, stats.DrawdownTrough = peakAt, at
+		}
+	}
+	unrealized := func(price func(coin string) float64) float64 {
+		var total float64
+		for coin, p := range positions {
+			if rate := price(coin); rate > 0 {
+				total += p.Qty*rate - p.Cost
+			}
+		}
+		return total
+	}
+
+	for _, row := range rows {
+		side := ledgerSide(row.TX)
+		if side != "Buy" && side != "Sell" {
+			continue
+		}
+		stats.Trades++
+		p := positions[row.Coin]
+		if p == nil {
+			p = &coinPosition{Coin: row.Coin}
+			positions[row.Coin] = p
+		}
+		month := row.DateTime.Local().Format("2006-01")
+		if months[month] == nil {
+			months[month] = &monthPnl{Month: month}
+		}
+		months[month].Trades++
+
+		if side == "Buy" {
+			stats.Buys++
+			stats.BuyUSD += row.USD
+			p.buy(row.BTC, row.USD)
+			lots[row.Coin] = append(lots[row.Coin], datedLot{qty: row.BTC, at: row.DateTime})
+		} else {
+			stats.Sells++
+			stats.SellUSD += row.USD
+			// Mirror the replay's position so the P/L curve uses the same cost basis.
+			p.sell(loadCostBasisMethod(), row.BTC, row.USD)
+			realized += row.RealizedGain
+			months[month].Realized += row.RealizedGain
+			if row.RealizedGain > 0.005 {
+				stats.Wins++
+			} else if row.RealizedGain < -0.005 {
+				stats.Losses++
+			}
+			if row.RealizedGain > stats.LargestGain {
+				stats.LargestGain, stats.LargestGainAt = row.RealizedGain, row.DateTime
+			}
+			if row.RealizedGain < stats.LargestLoss {
+				stats.LargestLoss, stats.LargestLossAt = row.RealizedGain, row.DateTime
+			}
+
+			// Holding time: the quantity-weighted age of the oldest lots sold.
+			remaining := row.BTC
+			var weighted, matched float64
+			queue := lots[row.Coin]
+			for remaining > 1e-12 && len(queue) > 0 {
+				take := math.Min(remaining, queue[0].qty)
+				if !queue[0].at.IsZero() && !row.DateTime.IsZero() {
+					weighted += take * float64(row.DateTime.Sub(queue[0].at))
+					matched += take
+				}
+				queue[0].qty -= take
+				remaining -= take
+				if queue[0].qty <= 1e-12 {
+					queue = queue[1:]
+				}
+			}
+			lots[row.Coin] = queue
+			if matched > 0 {
+				held := time.Duration(weighted / matched)
+				totalHolding += held
+				holdingSales++
+				for i, bucket := range holdingBuckets {
+					if held < bucket.limit {
+						stats.Holding[i]++
+						break
+					}
+				}
+			}
+		}
+
+		if row.BTC > 0 && row.BTCPrice > 0 {
+			lastPrice[row.Coin] = row.BTCPrice
+		}
+		track(realized+unrealized(func(coin string) float64 { return lastPrice[coin] }), row.DateTime)
+	}
+	if stats.Trades > 0 {
+		track(realized+unrealized(func(coin string) float64 {
+			if rate := coinRate(coin); rate > 0 {
+				return rate
+			}
+			return lastPrice[coin]
+		}), time.Now().UTC())
+	}
+	if holdingSales > 0 {
+		stats.AvgHolding = totalHolding / time.Duration(holdingSales)
+	}
+	for _, m := range months {
+		stats.Months = append(stats.Months, *m)
+	}
+	sort.Slice(stats.Months, func(i, j int) bool { return stats.Months[i].Month < stats.Months[j].Month })
+	return stats
+}
+
+// formatHolding returns a duration as e.g. "3d 4h", "2h 15m" or "40m".
+func formatHolding(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd %dh", int(d.Hours())/24, int(d.Hours())%24)
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh %dm", int(d.Hours()), int(d.Minutes())%60)
+	}
+	return fmt.Sprintf("%dm", int(d.Minutes()))
+}
+
+const statsMonths = 12
+
+// showStatsScreen shows trading performance statistics from the ledger.
+func showStatsScreen(reader *bufio.Reader) {
+	clearScreen()
+	color.Yellow("*** Performance Statistics ***")
+	fmt.Println()
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+
+	entries, err := readAllLedgerEntries()
+	if err != nil {
+		color.Red("Error reading ledger: %v", err)
+		return
+	}
+	rows, _ := replayLedger(entries, loadCostBasisMethod())
+	stats := computeTradeStats(rows)
+	if stats.Trades == 0 {
+		fmt.Println("No transactions in the ledger yet.")
+		fmt.Println()
+		return
+	}
+	white := color.New(color.FgWhite)
+
+	colorInfo.Println("Trades")
+	writeAlignedLine("Trades:", fmt.Sprintf("%d (%d buys, %d sells)", stats.Trades, stats.Buys, stats.Sells), white)
+	writeAlignedLine("Avg Trade Size:", formatMoney((stats.BuyUSD+stats.SellUSD)/float64(stats.Trades)), white)
+	if stats.Buys > 0 {
+		writeAlignedLine("Avg Buy:", formatMoney(stats.BuyUSD/float64(stats.Buys)), colorPositive)
+	}
+	if stats.Sells > 0 {
+		writeAlignedLine("Avg Sale:", formatMoney(stats.SellUSD/float64(stats.Sells)), colorNegative)
+	}
+	fmt.Println()
+
+	colorInfo.Printf("Results (%s cost)\n", strings.ToLower(loadCostBasisMethod()))
+	if decided := stats.Wins + stats.Losses; decided > 0 {
+		winRate := float64(stats.Wins) / float64(decided) * 100
+		rateColor := colorPositive
+		if winRate < 50 {
+			rateColor = colorNegative
+		}
+		writeAlignedLine("Win Rate:", fmt.Sprintf("%.1f%% (%d won, %d lost)", winRate, stats.Wins, stats.Losses), rateColor)
+	} else {
+		writeAlignedLine("Win Rate:", "no closed trades yet", color.New(color.FgHiBlack))
+	}
+	if stats.LargestGain > 0 {
+		writeAlignedLine("Largest Gain:", fmt.Sprintf("%s on %s", formatProfitLoss(stats.LargestGain, ""), stats.LargestGainAt.Local().Format("2006-01-02")), pnlColor(stats.LargestGain))
+	}
+	if stats.LargestLoss < 0 {
+		writeAlignedLine("Largest Loss:", fmt.Sprintf("%s on %s", formatProfitLoss(stats.LargestLoss, ""), stats.LargestLossAt.Local().Format("2006-01-02")), pnlColor(stats.LargestLoss))
+	}
+	if stats.MaxDrawdown > 0.005 {
+		writeAlignedLine("Max Drawdown:", fmt.Sprintf("-%s (%.2f%%) %s to %s", formatMoney(stats.MaxDrawdown), stats.MaxDrawdownPct,
+			stats.DrawdownPeak.Local().Format("2006-01-02"), stats.DrawdownTrough.Local().Format("2006-01-02")), colorNegative)
+	} else {
+		writeAlignedLine("Max Drawdown:", "none", white)
+	}
+	fmt.Println()
+
+	if stats.AvgHolding > 0 {
+		colorInfo.Println("Holding Time (per sale)")
+		writeAlignedLine("Average:", formatHolding(stats.AvgHolding), white)
+		for i, bucket := range holdingBuckets {
+			if stats.Holding[i] > 0 {
+				writeAlignedLine(bucket.label+":", fmt.Sprintf("%d", stats.Holding[i]), white)
+			}
+		}
+		fmt.Println()
+	}
+
+	colorInfo.Println("Monthly Realized P/L")
+	months := stats.Months
+	if len(months) > statsMonths {
+		color.New(color.FgHiBlack).Printf("(last %d of %d months)\n", statsMonths, len(months))
+		months = months[len(months)-statsMonths:]
+	}
+	for _, m := range months {
+		writeAlignedLine(m.Month+":", fmt.Sprintf("%s (%d trades)", formatProfitLoss(m.Realized, ""), m.Trades), pnlColor(m.Realized))
+	}
+	fmt.Println()
+}
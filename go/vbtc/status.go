@@ -0,0 +1,176 @@
+package vbtc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// statusSnapshot is the JSON written by "status --json" for other tools.
+type statusSnapshot struct {
+	Time      time.Time       `json:"time"`
+	Provider  string          `json:"provider,omitempty"`
+	Currency  string          `json:"currency"` // base currency of every amount below
+	Market    *statusMarket   `json:"market,omitempty"`
+	Portfolio statusPortfolio `json:"portfolio"`
+	Session   statusSession   `json:"session"`
+	Pnl       *statusPnl      `json:"pnl,omitempty"`
+	ApiCalls  map[string]int  `json:"apiCallsToday,omitempty"` // LiveCoinWatch requests per endpoint (UTC day)
+	Error     string          `json:"error,omitempty"`
+}
+
+type statusMarket struct {
+	Rate           float64            `json:"rate"`
+	Rate24hAgo     float64            `json:"rate24hAgo"`
+	Change24hPct   float64            `json:"change24hPercent"`
+	High24h        float64            `json:"high24h"`
+	Low24h         float64            `json:"low24h"`
+	Volatility24h  float64            `json:"volatility24hPercent"`
+	Sma1h          float64            `json:"sma1h"`
+	Volume24h      float64            `json:"volume24h"`
+	CoinRates      map[string]float64 `json:"coinRates,omitempty"`
+	Updated        time.Time          `json:"updated"`
+	HistoryUpdated time.Time          `json:"historyUpdated,omitempty"`
+}
+
+type statusPortfolio struct {
+	USD        float64               `json:"usd"`
+	BTC        float64               `json:"btc"`
+	BTCValue   float64               `json:"btcValue"`
+	Invested   float64               `json:"invested"`
+	Value      float64               `json:"value"`
+	Coins      map[string]statusCoin `json:"coins,omitempty"`
+	OpenOrders int                   `json:"openOrders"`
+	StopLoss   float64               `json:"stopLoss,omitempty"`
+	TakeProfit float64               `json:"takeProfit,omitempty"`
+}
+
+type statusCoin struct {
+	Balance float64 `json:"balance"`
+	Value   float64 `json:"value"`
+}
+
+type statusSession struct {
+	Start      time.Time `json:"start"`
+	StartValue float64   `json:"startValue"`
+	PnL        float64   `json:"pnl"`
+	PnLPercent float64   `json:"pnlPercent"`
+}
+
+type statusPnl struct {
+	Method     string  `json:"method"`
+	Realized   float64 `json:"realized"`
+	Unrealized float64 `json:"unrealized"`
+}
+
+func buildStatusSnapshot() statusSnapshot {
+	snap := statusSnapshot{Time: time.Now().UTC(), Currency: currencyCode()}
+	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	playerInvested, _ := cfg.Section("Portfolio").Key("PlayerInvested").Float64()
+	value := getPortfolioValue(playerUSD, playerBTC, apiData)
+
+	if apiData != nil && apiData.Rate > 0 {
+		snap.Provider = apiData.Provider
+		snap.Market = &statusMarket{
+			Rate:           apiData.Rate,
+			Rate24hAgo:     apiData.Rate24hAgo,
+			High24h:        apiData.Rate24hHigh,
+			Low24h:         apiData.Rate24hLow,
+			Volatility24h:  apiData.Volatility24h,
+			Sma1h:          apiData.Sma1h,
+			Volume24h:      apiData.Volume,
+			CoinRates:      apiData.CoinRates,
+			Updated:        apiData.FetchTime,
+			HistoryUpdated: apiData.HistoricalDataFetchTime,
+		}
+		if apiData.Rate24hAgo > 0 {
+			snap.Market.Change24hPct = (apiData.Rate - apiData.Rate24hAgo) / apiData.Rate24hAgo * 100
+		}
+	}
+	if calls := loadApiUsage().Calls; len(calls) > 0 {
+		snap.ApiCalls = calls
+	}
+	if apiData != nil && apiData.ApiError != "" {
+		snap.Error = apiData.ApiError
+	}
+
+	snap.Portfolio = statusPortfolio{USD: playerUSD, BTC: playerBTC, BTCValue: playerBTC * coinRate("BTC"), Invested: playerInvested, Value: value}
+	for _, coin := range trackedCoins() {
+		balance, _ := cfg.Section("Portfolio").Key(coinBalanceKey(coin)).Float64()
+		if balance <= 0 {
+			continue
+		}
+		if snap.Portfolio.Coins == nil {
+			snap.Portfolio.Coins = make(map[string]statusCoin)
+		}
+		snap.Portfolio.Coins[coin] = statusCoin{Balance: balance, Value: balance * coinRate(coin)}
+	}
+	snap.Portfolio.OpenOrders = len(loadOrders(cfg))
+	snap.Portfolio.StopLoss, snap.Portfolio.TakeProfit = loadTriggers(cfg)
+
+	snap.Session = statusSession{Start: sessionStartTime, StartValue: sessionStartPortfolioValue}
+	if sessionStartPortfolioValue > 0 {
+		snap.Session.PnL = value - sessionStartPortfolioValue
+		snap.Session.PnLPercent = snap.Session.PnL / sessionStartPortfolioValue * 100
+	}
+
+	if summary, err := loadPnl(); err == nil && len(summary.Positions) > 0 {
+		snap.Pnl = &statusPnl{Method: loadCostBasisMethod(), Realized: summary.Realized, Unrealized: summary.unrealizedPnl()}
+	}
+	return snap
+}
+
+func writeStatusJSON(snap statusSnapshot) error {
+	data, err := json.MarshalIndent(snap, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(data))
+	return err
+}
+
+// runStatusCommand handles "status --json" at the prompt.
+func runStatusCommand(reader *bufio.Reader, args []string) {
+	if len(args) != 1 || strings.TrimLeft(args[0], "-") != "json" {
+		color.Red("Usage: status --json")
+	} else if err := writeStatusJSON(buildStatusSnapshot()); err != nil {
+		color.Red("Could not write status: %v", err)
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// runStatusCLI handles "vbtc status --json": it fetches fresh data without
+// any screen output and prints only the JSON, for scripts and dashboards.
+func runStatusCLI() int {
+	var err error
+	cfg, err = ini.Load(iniFilePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "vbtc: could not read %s: %v\n", iniFilePath, err)
+		return 1
+	}
+	loadLiveCoinWatchKey()
+	// Progress and warnings from the fetch and ledger replay go to stderr so stdout stays pure JSON.
+	stdout, colorOut := os.Stdout, color.Output
+	os.Stdout, color.Output = os.Stderr, os.Stderr
+	batchMode = true
+	apiData = updateApiData(false)
+	playerUSD, _ := cfg.Section("Portfolio").Key("PlayerUSD").Float64()
+	playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	sessionStartPortfolioValue = getPortfolioValue(playerUSD, playerBTC, apiData)
+	snap := buildStatusSnapshot()
+	os.Stdout, color.Output = stdout, colorOut
+
+	if err := writeStatusJSON(snap); err != nil {
+		fmt.Fprintf(os.Stderr, "vbtc: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,24 @@
+package vbtc
+
+import (
+	"github.com/fatih/color"
+	"kreftus/shared/console"
+)
+
+// Panel colors, which a theme in the shared themes.ini can override. One-line
+// messages (color.Red, color.Green, ...) keep the standard colors.
+var (
+	colorPositive = color.New(color.FgGreen)  // gains, rises, buys
+	colorNegative = color.New(color.FgRed)    // losses, falls, sells
+	colorTitle    = color.New(color.FgYellow) // screen titles and section headers
+	colorInfo     = color.New(color.FgCyan)   // labels, hints and highlighted values
+)
+
+// applyTheme overrides the panel colors with any roles defined by the user's
+// theme in the shared themes.ini (positive, negative, title, info).
+func applyTheme(theme *console.Theme) {
+	colorPositive = theme.Color("positive", color.FgGreen)
+	colorNegative = theme.Color("negative", color.FgRed)
+	colorTitle = theme.Color("title", color.FgYellow)
+	colorInfo = theme.Color("info", color.FgCyan)
+}
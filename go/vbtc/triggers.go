@@ -0,0 +1,397 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"golang.org/x/term"
+	"gopkg.in/ini.v1"
+)
+
+// Stop-loss and take-profit prices for the BTC position live in vbtc.ini:
+//
+//	[Triggers]
+//	StopLoss   = 55000.00
+//	TakeProfit = 80000.00
+//
+// When a refresh or trade sees the rate at or below StopLoss, or at or above
+// TakeProfit, the whole BTC position is sold. Both triggers are cleared once
+// the position has been sold.
+const (
+	triggersSection = "Triggers"
+	txStopLoss      = "Stop Loss"
+	txTakeProfit    = "Take Profit"
+
+	// triggerConfirmWindow is how long the user has to keep the position
+	// before a fired trigger sells automatically.
+	triggerConfirmWindow = 30 * time.Second
+)
+
+func loadTriggers(f *ini.File) (stopLoss, takeProfit float64) {
+	if f == nil || !f.HasSection(triggersSection) {
+		return 0, 0
+	}
+	stopLoss, _ = f.Section(triggersSection).Key("StopLoss").Float64()
+	takeProfit, _ = f.Section(triggersSection).Key("TakeProfit").Float64()
+	return stopLoss, takeProfit
+}
+
+// checkAutomaticTrades runs everything that trades or alerts on its own when
+// the market moves. Called after each refresh and trade.
+func checkAutomaticTrades(reader *bufio.Reader) {
+	checkPriceAlerts(reader)
+	checkPendingOrders(reader)
+	checkStopTriggers(reader)
+}
+
+// automaticTradeDue reports whether checkAutomaticTrades would act at the
+// current rate: an alert has fired or re-armed, or an order or trigger is reached.
+func automaticTradeDue() bool {
+	if apiData == nil || apiData.Rate <= 0 || apiData.ApiError != "" {
+		return false
+	}
+	dueCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		return false
+	}
+	rate := apiData.Rate
+	for _, alert := range loadAlerts(dueCfg) {
+		if alert.reached(rate) == alert.Armed {
+			return true
+		}
+	}
+	for _, order := range loadOrders(dueCfg) {
+		if order.triggered(rate) {
+			return true
+		}
+	}
+	stopLoss, takeProfit := loadTriggers(dueCfg)
+	playerBTC, _ := dueCfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	return playerBTC > 0 && ((stopLoss > 0 && rate <= stopLoss) || (takeProfit > 0 && rate >= takeProfit))
+}
+
+// checkStopTriggers sells the BTC position when the current rate crosses the
+// stop-loss or take-profit price. The user gets triggerConfirmWindow to keep
+// the position (which disarms the trigger); otherwise it sells.
+func checkStopTriggers(reader *bufio.Reader) {
+	if apiData == nil || apiData.Rate <= 0 || apiData.ApiError != "" {
+		return
+	}
+	triggerCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		slog.Error("trigger check: could not read portfolio", "path", iniFilePath, "err", err)
+		return
+	}
+	stopLoss, takeProfit := loadTriggers(triggerCfg)
+	playerBTC, _ := triggerCfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	if playerBTC <= 0 || (stopLoss <= 0 && takeProfit <= 0) {
+		return
+	}
+
+	rate := apiData.Rate
+	var txType, key string
+	var limit float64
+	switch {
+	case stopLoss > 0 && rate <= stopLoss:
+		txType, key, limit = txStopLoss, "StopLoss", stopLoss
+	case takeProfit > 0 && rate >= takeProfit:
+		txType, key, limit = txTakeProfit, "TakeProfit", takeProfit
+	default:
+		return
+	}
+	quote, err := quoteTrade("Sell", playerBTC, rate)
+	if err != nil {
+		slog.Warn("trigger fired but sale not possible", "trigger", txType, "err", err)
+		return
+	}
+	usdAmount := quote.USD
+	slog.Info("trigger fired", "trigger", txType, "limit", limit, "rate", rate, "btc", playerBTC)
+
+	clearScreen()
+	titleColor := colorNegative
+	if txType == txTakeProfit {
+		titleColor = colorPositive
+	}
+	titleColor.Printf("*** %s Triggered ***\n", txType)
+	fmt.Println()
+	writeAlignedLine(txType+":", formatMoney(limit), color.New(color.FgWhite))
+	writeAlignedLine("Market Rate:", formatMoney(rate), titleColor)
+	if math.Abs(quote.Rate-rate) >= 0.005 {
+		writeAlignedLine("Execution Price:", formatMoney(quote.Rate), color.New(color.FgWhite))
+	}
+	writeAlignedLine("Position:", fmt.Sprintf("%.8f BTC (%s)", playerBTC, formatMoney(usdAmount)), color.New(color.FgWhite))
+	fmt.Println()
+
+	if !confirmWithTimeout(reader, strings.TrimSpace(confirmTradePrompt("Sell", "BTC", quote)), triggerConfirmWindow) {
+		// Keeping the position disarms this trigger so the next refresh does not fire it again.
+		err := updateIni(func(f *ini.File) {
+			f.Section(triggersSection).DeleteKey(key)
+		})
+		if err != nil {
+			slog.Error("trigger disarm failed", "path", iniFilePath, "err", err)
+			color.Red("Could not save vbtc.ini: %v", err)
+		} else {
+			color.Yellow("Position kept. The %s has been removed.", strings.ToLower(txType))
+		}
+		slog.Info("trigger declined", "trigger", txType)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+
+	unlock, err := lockPortfolio()
+	if err != nil {
+		color.Red("Sale cancelled. %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	defer unlock()
+	// Re-read right before writing so a trade made elsewhere during the countdown is not overwritten.
+	triggerCfg, err = ini.Load(iniFilePath)
+	if err != nil {
+		color.Red("Critical Error: Could not read portfolio file '%s'. Sale CANCELLED.", iniFilePath)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	currentBTC, _ := triggerCfg.Section("Portfolio").Key("PlayerBTC").Float64()
+	if math.Abs(currentBTC-playerBTC) >= 1e-9 {
+		color.Red("Sale cancelled. Your BTC balance was changed by another session.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	before := portfolioBalances(triggerCfg, "BTC")
+	newUserBtc, err := applyTradeToPortfolio(triggerCfg, "BTC", "Sell", usdAmount, playerBTC)
+	if err != nil {
+		color.Red("Sale cancelled. %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	triggerCfg.DeleteSection(triggersSection)
+	if err := beginTrade(pendingTrade{
+		Coin:   "BTC",
+		Before: before,
+		After:  portfolioBalances(triggerCfg, "BTC"),
+		Rows:   []pendingRow{{TX: txType, USD: usdAmount, Amount: playerBTC, Rate: quote.Rate, UserCoin: newUserBtc, Fee: quote.Fee}},
+	}); err != nil {
+		color.Red("Sale cancelled. %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	if err := writeIni(triggerCfg); err != nil {
+		endTrade()
+		slog.Error("trigger sale: portfolio save failed", "path", iniFilePath, "err", err)
+		color.Red("Sale failed: Could not save portfolio update to vbtc.ini.")
+		color.Red("Error: %v", err)
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	cfg = triggerCfg
+	if err := addLedgerEntry(txType, "BTC", usdAmount, playerBTC, quote.Rate, newUserBtc, quote.Fee, ""); err != nil {
+		color.Red("Sale complete, but failed to write to ledger.csv.")
+		color.Red("Error: %v", err)
+	} else {
+		endTrade()
+	}
+	unlock()
+	playSound(soundTradeDone)
+	titleColor.Printf("Sold %.8f BTC for %s.\n", playerBTC, formatMoney(usdAmount))
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
+
+// confirmWithTimeout asks a [Y/n] question and returns true on y or Enter, or
+// when timeout passes without an answer; n or Esc return false. Without a
+// terminal the default (true) applies immediately.
+func confirmWithTimeout(reader *bufio.Reader, question string, timeout time.Duration) bool {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return true
+	}
+	oldState, err := term.GetState(fd)
+	if err != nil {
+		return true
+	}
+	if _, err := term.MakeRaw(fd); err != nil {
+		return true
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	defer func() {
+		close(done)
+		wg.Wait()
+		term.Restore(fd, oldState)
+		reader.Reset(os.Stdin)
+		fmt.Print("\r\n")
+	}()
+
+	inputChan := make(chan byte)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(inputChan)
+		for {
+			b, err := cancellableRead(done)
+			if err != nil {
+				return
+			}
+			select {
+			case inputChan <- b:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return true
+		}
+		fmt.Printf("\r%s [Y/n] (selling in %2ds) ", question, int(math.Ceil(remaining.Seconds())))
+		select {
+		case <-ticker.C:
+		case b, ok := <-inputChan:
+			if !ok {
+				return true
+			}
+			switch b {
+			case 3: // Ctrl+C
+				term.Restore(fd, oldState)
+				os.Exit(1)
+			case 'y', 'Y', 13, 10:
+				return true
+			case 'n', 'N', 27:
+				return false
+			}
+		}
+	}
+}
+
+// showTriggersScreen shows and sets the stop-loss and take-profit prices:
+//
+//	triggers                      show current triggers
+//	triggers stop <price|off>
+//	triggers take <price|off>
+//	triggers off                  clear both
+func showTriggersScreen(reader *bufio.Reader, args []string) {
+	for {
+		clearScreen()
+		color.Yellow("*** Stop-Loss / Take-Profit ***")
+		stopLoss, takeProfit := loadTriggers(cfg)
+		playerBTC, _ := cfg.Section("Portfolio").Key("PlayerBTC").Float64()
+		if apiData != nil && apiData.Rate > 0 {
+			writeAlignedLine(currencyLabel("Bitcoin"), formatMoney(apiData.Rate), color.New(color.FgWhite))
+		}
+		writeAlignedLine("Position:", fmt.Sprintf("%.8f BTC", playerBTC), color.New(color.FgWhite))
+		writeAlignedLine("Stop Loss:", triggerDisplay(stopLoss), colorNegative)
+		writeAlignedLine("Take Profit:", triggerDisplay(takeProfit), colorPositive)
+		fmt.Println()
+
+		if len(args) == 0 {
+			color.New(color.FgHiBlack).Println("stop <price|off> | take <price|off> | off")
+			fmt.Print("Trigger command (Enter to return): ")
+			input, _ := reader.ReadString('\n')
+			args = strings.Fields(strings.TrimSpace(input))
+			if len(args) == 0 {
+				return
+			}
+		}
+
+		message, ok := runTriggerCommand(args)
+		if ok {
+			color.Green(message)
+		} else {
+			color.Red(message)
+		}
+		args = nil
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}
+}
+
+func triggerDisplay(price float64) string {
+	if price <= 0 {
+		return "off"
+	}
+	return formatMoney(price)
+}
+
+// runTriggerCommand applies one triggers subcommand to vbtc.ini and returns a
+// message for the user and whether it succeeded.
+func runTriggerCommand(args []string) (string, bool) {
+	unlock, err := lockPortfolio()
+	if err != nil {
+		return err.Error(), false
+	}
+	defer unlock()
+	triggerCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		return fmt.Sprintf("Could not read portfolio file '%s': %v", iniFilePath, err), false
+	}
+	rate := 0.0
+	if apiData != nil {
+		rate = apiData.Rate
+	}
+
+	var message string
+	switch sub := strings.ToLower(args[0]); {
+	case sub == "off" || sub == "clear":
+		triggerCfg.DeleteSection(triggersSection)
+		message = "Stop-loss and take-profit cleared."
+	case strings.HasPrefix(sub, "stop") || strings.HasPrefix(sub, "take") || sub == "sl" || sub == "tp":
+		isStop := strings.HasPrefix(sub, "stop") || sub == "sl"
+		key, name := "TakeProfit", "Take-profit"
+		if isStop {
+			key, name = "StopLoss", "Stop-loss"
+		}
+		if len(args) < 2 {
+			return fmt.Sprintf("Usage: %s <price|off>", sub), false
+		}
+		if strings.EqualFold(args[1], "off") {
+			triggerCfg.Section(triggersSection).DeleteKey(key)
+			message = name + " cleared."
+			break
+		}
+		price, err := strconv.ParseFloat(strings.TrimPrefix(strings.ReplaceAll(args[1], ",", ""), "$"), 64)
+		if err != nil || price <= 0 {
+			return "Invalid price.", false
+		}
+		// A trigger already past the market would sell on the next refresh.
+		if rate > 0 && isStop && price >= rate {
+			return fmt.Sprintf("Stop-loss must be below the market rate (%s).", formatMoney(rate)), false
+		}
+		if rate > 0 && !isStop && price <= rate {
+			return fmt.Sprintf("Take-profit must be above the market rate (%s).", formatMoney(rate)), false
+		}
+		triggerCfg.Section(triggersSection).Key(key).SetValue(fmt.Sprintf("%.2f", price))
+		message = fmt.Sprintf("%s set at %s.", name, formatMoney(price))
+	default:
+		return fmt.Sprintf("Unknown trigger command '%s'.", args[0]), false
+	}
+
+	if err := writeIni(triggerCfg); err != nil {
+		slog.Error("trigger save failed", "path", iniFilePath, "err", err)
+		return fmt.Sprintf("Could not save vbtc.ini: %v", err), false
+	}
+	slog.Info("triggers updated", "args", strings.Join(args, " "))
+	cfg = triggerCfg
+	return message, true
+}
@@ -0,0 +1,251 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// The first run offers a short tutorial before asking for an API key: the
+// user buys some Bitcoin, opens the ledger and refreshes, against made-up
+// prices. The tutorial keeps its own portfolio in memory; nothing it does is
+// saved to vbtc.ini or the ledger.
+var tutorialPrices = []float64{60000.00, 61250.50}
+
+type tutorial struct {
+	reader   *bufio.Reader
+	price    float64
+	cash     float64
+	btc      float64
+	invested float64
+	ledger   []LedgerEntry
+	skipped  bool
+}
+
+// offerTutorial asks whether to run the tutorial and runs it if so.
+func offerTutorial(reader *bufio.Reader) {
+	fmt.Println("New to vBTC? A two-minute tutorial walks you through a trade with made-up prices.")
+	fmt.Print("Start the tutorial? (Y/n): ")
+	input, _ := reader.ReadString('\n')
+	if strings.EqualFold(strings.TrimSpace(input), "n") {
+		fmt.Println()
+		return
+	}
+	t := &tutorial{reader: reader, price: tutorialPrices[0], cash: startingCapital}
+	t.run()
+	clearScreen()
+	color.Yellow("*** First Time Setup ***")
+}
+
+func (t *tutorial) run() {
+	steps := []func(){t.introStep, t.buyStep, t.ledgerStep, t.refreshStep, t.finishStep}
+	for _, step := range steps {
+		if t.skipped {
+			return
+		}
+		step()
+	}
+}
+
+// showScreen draws a cut-down main screen from the tutorial portfolio.
+func (t *tutorial) showScreen() {
+	clearScreen()
+	colorTitle.Println("*** Bitcoin Market (Tutorial) ***")
+	writeAlignedLine(currencyLabel("Bitcoin"), formatMoney(t.price), color.New(color.FgWhite))
+	color.New(color.FgHiBlack).Println("Made-up prices; nothing in the tutorial is saved.")
+	fmt.Println()
+	colorTitle.Println("*** Portfolio ***")
+	value := t.cash + t.btc*t.price
+	if t.btc > 0 {
+		writeAlignedLine("Bitcoin:", fmt.Sprintf("%.8f (%s)", t.btc, formatMoney(t.btc*t.price)), color.New(color.FgWhite))
+		change := (t.btc*t.price - t.invested) / t.invested * 100
+		writeAlignedLine("Invested:", fmt.Sprintf("%s [%+.2f%%]", formatMoney(t.invested), change), gainColor(change))
+	}
+	writeAlignedLine("Cash:", formatMoney(t.cash), color.New(color.FgWhite))
+	writeAlignedLine(currencyLabel("Value"), formatMoney(value), gainColor(value-startingCapital))
+	fmt.Println()
+}
+
+// gainColor is green above zero, red below and white at zero.
+func gainColor(change float64) *color.Color {
+	switch {
+	case change > 0.005:
+		return colorPositive
+	case change < -0.005:
+		return colorNegative
+	}
+	return color.New(color.FgWhite)
+}
+
+// say prints the tutorial's explanation in cyan.
+func (t *tutorial) say(lines ...string) {
+	for _, line := range lines {
+		colorInfo.Println(line)
+	}
+	fmt.Println()
+}
+
+// pause waits for Enter; typing "skip" ends the tutorial.
+func (t *tutorial) pause() {
+	fmt.Print("Press Enter to continue, or type 'skip' to end the tutorial: ")
+	input, _ := t.reader.ReadString('\n')
+	t.skipped = strings.EqualFold(strings.TrimSpace(input), "skip")
+}
+
+// prompt reads commands until one names command, the way the main prompt
+// matches a shortcut or prefix, and returns its arguments. Typing "skip"
+// ends the tutorial.
+func (t *tutorial) prompt(command, hint string) []string {
+	for {
+		fmt.Print("Enter command: ")
+		input, _ := t.reader.ReadString('\n')
+		parts := strings.Fields(strings.ToLower(input))
+		if len(parts) == 0 {
+			color.Yellow(hint)
+			continue
+		}
+		if parts[0] == "skip" {
+			t.skipped = true
+			return nil
+		}
+		if strings.HasPrefix(command, parts[0]) {
+			return parts[1:]
+		}
+		color.Yellow("That works once you are live. For now, %s", hint)
+	}
+}
+
+func (t *tutorial) introStep() {
+	t.showScreen()
+	t.say(
+		fmt.Sprintf("vBTC gives you %s of virtual cash to trade Bitcoin at real market prices.", formatMoney(startingCapital)),
+		"The main screen shows the price at the top and your portfolio below it.",
+		"Commands are typed at the prompt, and any command may be shortened: 'b' is buy, 'l' is ledger.",
+	)
+	t.pause()
+}
+
+func (t *tutorial) buyStep() {
+	t.showScreen()
+	t.say(
+		"Let's buy some Bitcoin. 'buy 100' spends $100 of your cash.",
+		"Amounts may also be percentages ('buy 25p' spends a quarter of your cash) or sums ('buy 50*2').",
+	)
+	hint := "type 'buy 100' to buy $100 of Bitcoin."
+	for {
+		args := t.prompt("buy", hint)
+		if t.skipped {
+			return
+		}
+		if len(args) == 0 {
+			color.Yellow("Add an amount: %s", hint)
+			continue
+		}
+		amount, err := parseTradeAmount(strings.Join(args, ""), t.cash, "Buy")
+		switch {
+		case err != nil:
+			color.Red("Invalid amount: %v.", err)
+			continue
+		case amount <= 0:
+			color.Red("Please enter a positive number.")
+			continue
+		case amount > t.cash:
+			color.Red("Amount exceeds your balance.")
+			continue
+		}
+		coin := math.Floor(amount/t.price*1e8) / 1e8
+
+		fmt.Println()
+		color.Yellow("*** Confirm Buy ***")
+		writeAlignedLine("Market Rate:", formatMoney(t.price), color.New(color.FgWhite))
+		t.say(
+			"This is the confirmation screen. When you are live, the offer is good for two minutes:",
+			"Y accepts it, R fetches a new price and N or Esc cancels. Fees and spread, if you set them, show here too.",
+		)
+		fmt.Printf("Purchase %.8f BTC for %s? (y/n): ", coin, formatMoney(amount))
+		input, _ := t.reader.ReadString('\n')
+		if !strings.EqualFold(strings.TrimSpace(input), "y") {
+			color.Yellow("Cancelled. Try again: %s", hint)
+			continue
+		}
+		t.cash -= amount
+		t.btc += coin
+		t.invested += amount
+		now := time.Now()
+		t.ledger = append(t.ledger, LedgerEntry{TX: "Buy", USD: amount, BTC: coin, BTCPrice: t.price, UserBTC: t.btc, Time: now.UTC().Format("010206@150405"), DateTime: now, Coin: "BTC"})
+		color.Green("Bought %.8f BTC for %s.", coin, formatMoney(amount))
+		fmt.Println()
+		t.pause()
+		return
+	}
+}
+
+func (t *tutorial) ledgerStep() {
+	t.showScreen()
+	t.say(
+		"Every trade is written to your ledger. Type 'ledger' (or 'l') to see it.",
+		"When you are live, 'ledger type:sell' or 'ledger #tag' filters it, and 'stats' and 'pnl' summarize it.",
+	)
+	t.prompt("ledger", "type 'ledger' to see your trades.")
+	if t.skipped {
+		return
+	}
+	fmt.Println()
+	color.Yellow("*** Transaction Ledger ***")
+	header := fmt.Sprintf("%-4s  %10s  %10s  %10s  %10s  %13s", "TX", "USD", "BTC", "BTC(USD)", "User BTC", "Time")
+	fmt.Println(header)
+	fmt.Println(strings.Repeat("-", len(header)))
+	for _, entry := range t.ledger {
+		colorPositive.Printf("%-4s  %10s  %10.8f  %10s  %10.8f  %13s\n", entry.TX, formatFloat(entry.USD, 2), entry.BTC, formatFloat(entry.BTCPrice, 2), entry.UserBTC, entry.Time)
+	}
+	fmt.Println()
+	t.pause()
+}
+
+func (t *tutorial) refreshStep() {
+	t.showScreen()
+	t.say(
+		"Prices move while you watch. 'refresh' (or 'r') fetches the latest market data.",
+		"When you are live, the price is also checked every minute while the prompt waits.",
+	)
+	t.prompt("refresh", "type 'refresh' to update the price.")
+	if t.skipped {
+		return
+	}
+	previous := t.price
+	t.price = tutorialPrices[1]
+	t.showScreen()
+	move := t.price - previous
+	gainColor(move).Printf("Bitcoin moved %s%s since your last look.\n", signOf(move), formatMoney(math.Abs(move)))
+	if t.btc > 0 {
+		t.say("Your Bitcoin is worth more now; selling it ('sell 100p' sells all of it) would lock in the gain.")
+	} else {
+		fmt.Println()
+	}
+	t.pause()
+}
+
+func (t *tutorial) finishStep() {
+	clearScreen()
+	color.Yellow("*** Tutorial Complete ***")
+	fmt.Println()
+	t.say("That's the basics. A few more things to try once you are live:")
+	examples := [][2]string{
+		{"sell 50p", "Sell half your Bitcoin"},
+		{"quote 100", "See what a trade would cost without making it"},
+		{"orders buy 100 60000", "Buy automatically once the price drops to $60,000"},
+		{"help sell", "Details and examples for any command; 'help' lists them all"},
+	}
+	for _, example := range examples {
+		color.New(color.FgWhite).Printf("    %-22s", example[0])
+		color.New(color.FgHiBlack).Println(example[1])
+	}
+	fmt.Println()
+	t.say(fmt.Sprintf("Your real portfolio starts with %s of cash.", formatMoney(startingCapital)))
+	fmt.Print("Press Enter to set up live prices.")
+	t.reader.ReadString('\n')
+}
@@ -0,0 +1,232 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"vbtc/internal/engine"
+)
+
+// The last manual trade can be undone for [Settings] UndoSeconds (default 60,
+// 0 disables undo). Each trade saves the balances from before and after it in
+// vbtc.ini, in the same write as the trade itself:
+//
+//	[Undo]
+//	At     = 1718000000
+//	TX     = Buy
+//	Coin   = BTC
+//	USD    = 100.00
+//	Amount = 0.00150000
+//	Before = 1000.00,0.00000000,0.00
+//	After  = 900.00,0.00150000,100.00
+//
+// Undo puts the Before balances back, provided the portfolio still holds the
+// After balances, and renames the ledger row's TX to "Reverted Buy" so the
+// row stays in the history but no longer counts as a trade.
+const (
+	undoSection        = "Undo"
+	defaultUndoSeconds = 60
+	txRevertedPrefix   = "Reverted "
+)
+
+func isRevertedTX(tx string) bool {
+	return strings.HasPrefix(tx, txRevertedPrefix)
+}
+
+func undoGracePeriod() time.Duration {
+	seconds := defaultUndoSeconds
+	if cfg != nil && cfg.Section("Settings").HasKey("UndoSeconds") {
+		if n, err := cfg.Section("Settings").Key("UndoSeconds").Int(); err == nil && n >= 0 {
+			seconds = n
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func portfolioBalances(f *ini.File, coin string) portfolioSnapshot {
+	portfolio := f.Section("Portfolio")
+	usd, _ := portfolio.Key("PlayerUSD").Float64()
+	held, _ := portfolio.Key(coinBalanceKey(coin)).Float64()
+	invested, _ := portfolio.Key(coinInvestedKey(coin)).Float64()
+	return portfolioSnapshot{USD: usd, Coin: held, Invested: invested}
+}
+
+func (s portfolioSnapshot) iniValue() string {
+	return fmt.Sprintf("%.2f,%.8f,%.2f", s.USD, s.Coin, s.Invested)
+}
+
+func parseBalances(value string) (portfolioSnapshot, bool) {
+	fields := strings.Split(value, ",")
+	if len(fields) != 3 {
+		return portfolioSnapshot{}, false
+	}
+	var v [3]float64
+	for i, field := range fields {
+		n, err := strconv.ParseFloat(field, 64)
+		if err != nil {
+			return portfolioSnapshot{}, false
+		}
+		v[i] = n
+	}
+	return portfolioSnapshot{USD: v[0], Coin: v[1], Invested: v[2]}, true
+}
+
+// recordUndo saves what is needed to undo a trade into f, which already holds
+// the portfolio after the trade.
+func recordUndo(f *ini.File, txType, coin string, usdAmount, coinAmount float64, before portfolioSnapshot) {
+	f.DeleteSection(undoSection)
+	if undoGracePeriod() <= 0 {
+		return
+	}
+	section := f.Section(undoSection)
+	section.Key("At").SetValue(strconv.FormatInt(time.Now().Unix(), 10))
+	section.Key("TX").SetValue(txType)
+	section.Key("Coin").SetValue(coin)
+	section.Key("USD").SetValue(fmt.Sprintf("%.2f", usdAmount))
+	section.Key("Amount").SetValue(fmt.Sprintf("%.8f", coinAmount))
+	section.Key("Before").SetValue(before.iniValue())
+	section.Key("After").SetValue(portfolioBalances(f, coin).iniValue())
+}
+
+// runUndoCommand reverses the last manual trade if it is within the grace
+// period and nothing has changed the portfolio since.
+func runUndoCommand(reader *bufio.Reader) {
+	defer func() {
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+	}()
+	undoCfg, err := ini.Load(iniFilePath)
+	if err != nil {
+		color.Red("Could not read portfolio file '%s': %v", iniFilePath, err)
+		return
+	}
+	if !undoCfg.HasSection(undoSection) {
+		color.Yellow("There is no trade to undo.")
+		return
+	}
+	section := undoCfg.Section(undoSection)
+	at := time.Unix(section.Key("At").MustInt64(0), 0)
+	txType, coin := section.Key("TX").String(), section.Key("Coin").String()
+	usdText, amountText := section.Key("USD").String(), section.Key("Amount").String()
+	before, okBefore := parseBalances(section.Key("Before").String())
+	after, okAfter := parseBalances(section.Key("After").String())
+	if !okBefore || !okAfter || coin == "" || (txType != "Buy" && txType != "Sell") {
+		color.Red("The saved undo information is damaged; the trade cannot be undone.")
+		return
+	}
+	grace := undoGracePeriod()
+	if age := time.Since(at); grace <= 0 || age > grace {
+		color.Yellow("The last trade is older than %d seconds and can no longer be undone.", int(grace.Seconds()))
+		return
+	}
+
+	amount, _ := strconv.ParseFloat(amountText, 64)
+	usd, _ := strconv.ParseFloat(usdText, 64)
+	clearScreen()
+	color.Yellow("*** Undo Trade ***")
+	fmt.Println()
+	writeAlignedLine("Trade:", fmt.Sprintf("%s %.8f %s for %s", txType, amount, coin, formatMoney(usd)), color.New(color.FgWhite))
+	writeAlignedLine("Made:", fmt.Sprintf("%s (%ds ago)", at.Local().Format("15:04:05"), int(time.Since(at).Seconds())), color.New(color.FgWhite))
+	writeAlignedLine(fmt.Sprintf("%s Restored To:", currencyCode()), formatMoney(before.USD), color.New(color.FgWhite))
+	writeAlignedLine(coin+" Restored To:", fmt.Sprintf("%.8f", before.Coin), color.New(color.FgWhite))
+	fmt.Println()
+	fmt.Print("Undo this trade? (y/n): ")
+	confirm, _ := reader.ReadString('\n')
+	if strings.ToLower(strings.TrimSpace(confirm)) != "y" {
+		color.Yellow("Undo cancelled.")
+		return
+	}
+
+	unlock, err := lockPortfolio()
+	if err != nil {
+		color.Red("Undo cancelled. %v", err)
+		return
+	}
+	defer unlock()
+	// Re-read after the prompt so a trade made by another session in the meantime is noticed.
+	undoCfg, err = ini.Load(iniFilePath)
+	if err != nil {
+		color.Red("Could not read portfolio file '%s': %v", iniFilePath, err)
+		return
+	}
+	if !undoCfg.HasSection(undoSection) || undoCfg.Section(undoSection).Key("At").MustInt64(0) != at.Unix() {
+		color.Red("Undo cancelled. Another trade was made in the meantime.")
+		return
+	}
+	if time.Since(at) > grace {
+		color.Yellow("The grace period ran out. The trade stands.")
+		return
+	}
+	current := portfolioBalances(undoCfg, coin)
+	if math.Abs(current.USD-after.USD) >= 0.005 || math.Abs(current.Coin-after.Coin) >= 1e-9 {
+		color.Red("Undo cancelled. Your portfolio has changed since the trade (order fill, trigger or another session).")
+		return
+	}
+
+	// Balances first, then the ledger: if the ledger cannot be changed the
+	// saved vbtc.ini is put back, so the two never disagree.
+	original, err := os.ReadFile(iniFilePath)
+	if err != nil {
+		color.Red("Could not read portfolio file '%s': %v", iniFilePath, err)
+		return
+	}
+	portfolio := undoCfg.Section("Portfolio")
+	portfolio.Key("PlayerUSD").SetValue(fmt.Sprintf("%.2f", before.USD))
+	portfolio.Key(coinBalanceKey(coin)).SetValue(fmt.Sprintf("%.8f", before.Coin))
+	portfolio.Key(coinInvestedKey(coin)).SetValue(fmt.Sprintf("%.2f", before.Invested))
+	undoCfg.DeleteSection(undoSection)
+	if err := writeIni(undoCfg); err != nil {
+		slog.Error("undo: portfolio save failed", "path", iniFilePath, "err", err)
+		color.Red("Undo cancelled. Could not save vbtc.ini: %v", err)
+		return
+	}
+	if err := revertLedgerRow(txType, coin, usdText, amountText, at); err != nil {
+		slog.Error("undo: ledger update failed", "err", err)
+		if rollbackErr := writeFileAtomic(iniFilePath, func(w io.Writer) error {
+			_, err := w.Write(original)
+			return err
+		}); rollbackErr != nil {
+			slog.Error("undo: portfolio rollback failed", "path", iniFilePath, "err", rollbackErr)
+			color.Red("Could not mark the trade in ledger.csv (%v), and vbtc.ini could not be put back: %v", err, rollbackErr)
+			color.Red("The balances are restored but the ledger still shows the trade.")
+			return
+		}
+		color.Red("Undo cancelled. Could not mark the trade in ledger.csv: %v", err)
+		return
+	}
+	cfg = undoCfg
+	slog.Info("trade undone", "tx", txType, "coin", coin, "usd", usdText, "amount", amountText)
+	sessionLog.Info("trade undone", "tx", txType, "coin", coin, "usd", usdText, "amount", amountText)
+	color.Green("%s of %s undone. Balances restored.", txType, coin)
+}
+
+// revertLedgerRow renames the TX of the newest ledger.csv row matching the
+// trade to "Reverted <TX>".
+func revertLedgerRow(txType, coin, usdText, amountText string, at time.Time) error {
+	records, err := readAndParseLedgerRaw()
+	if err != nil {
+		return err
+	}
+	for i := len(records) - 1; i >= 1; i-- {
+		record := records[i]
+		if len(record) < 7 || record[0] != txType || record[6] != coin || record[1] != usdText || record[2] != amountText {
+			continue
+		}
+		// Ledger times are whole seconds and written just after the trade is saved.
+		if t, err := time.ParseInLocation(engine.LedgerTimeFormat, record[5], time.UTC); err != nil || t.Before(at.Add(-time.Minute)) {
+			break
+		}
+		record[0] = txRevertedPrefix + txType
+		return writeLedgerRaw(records[0], records[1:])
+	}
+	return fmt.Errorf("no matching transaction")
+}
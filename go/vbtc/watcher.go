@@ -0,0 +1,265 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+)
+
+// While the command prompt is waiting for input, a background goroutine polls
+// the BTC price every [Settings] WatchSeconds (0, the default, disables it). The
+// Bubble Tea prompt redraws the main screen with each new price; the plain
+// prompt announces it on its own line underneath, and the next main screen
+// picks it up. When the price has moved WatchAlertPercent or more since the last
+// alert, the terminal beeps (high for up, low for down).
+const (
+	defaultWatchSeconds = 0
+	minWatchSeconds     = 15
+)
+
+type priceWatcher struct {
+	mu         sync.Mutex
+	interval   time.Duration
+	alertPct   float64
+	provider   PriceProvider
+	atPrompt   bool
+	promptRate float64 // apiData.Rate when the prompt was shown
+	rate       float64 // latest polled rate not yet applied to apiData
+	polledAt   time.Time
+	alertRate  float64      // rate at the last beep (or the first poll)
+	typed      string       // text typed at a raw-mode prompt, reprinted after a price line
+	program    *tea.Program // the Bubble Tea prompt while it runs
+	wake       chan struct{}
+	start      sync.Once
+	lastScreen float64 // rate on the previous main screen; main goroutine only
+}
+
+var priceWatch = &priceWatcher{wake: make(chan struct{}, 1)}
+
+func loadWatchSettings() (seconds int, alertPct float64) {
+	if cfg == nil {
+		return 0, 0
+	}
+	seconds = cfg.Section("Settings").Key("WatchSeconds").MustInt(defaultWatchSeconds)
+	if seconds > 0 && seconds < minWatchSeconds {
+		seconds = minWatchSeconds
+	}
+	alertPct, _ = cfg.Section("Settings").Key("WatchAlertPercent").Float64()
+	return seconds, math.Max(alertPct, 0)
+}
+
+// configure loads the watch settings from cfg and starts the goroutine the first time.
+func (w *priceWatcher) configure() {
+	seconds, alertPct := loadWatchSettings()
+	w.mu.Lock()
+	w.interval = time.Duration(seconds) * time.Second
+	w.alertPct = alertPct
+	w.provider = priceProviders()[0]
+	w.mu.Unlock()
+	w.start.Do(func() { go w.run() })
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// setPrompt marks whether the main prompt is waiting for input; the watcher
+// only polls and prints while it is. Called from the main goroutine.
+func (w *priceWatcher) setPrompt(waiting bool) {
+	w.mu.Lock()
+	w.atPrompt = waiting
+	if waiting && apiData != nil {
+		w.promptRate = apiData.Rate
+	}
+	w.mu.Unlock()
+}
+
+// setProgram directs new prices to the running Bubble Tea prompt, or back to
+// printed lines when p is nil.
+func (w *priceWatcher) setProgram(p *tea.Program) {
+	w.mu.Lock()
+	w.program = p
+	w.mu.Unlock()
+}
+
+func (w *priceWatcher) run() {
+	for {
+		w.mu.Lock()
+		interval := w.interval
+		w.mu.Unlock()
+		if interval <= 0 {
+			<-w.wake
+			continue
+		}
+		select {
+		case <-time.After(interval):
+			w.poll()
+		case <-w.wake:
+		}
+	}
+}
+
+func (w *priceWatcher) poll() {
+	w.mu.Lock()
+	atPrompt, provider := w.atPrompt, w.provider
+	w.mu.Unlock()
+	if !atPrompt {
+		return
+	}
+	data, err := provider.Current()
+	if err != nil {
+		slog.Debug("price watch fetch failed", "err", err)
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	previous := w.rate
+	if previous == 0 {
+		previous = w.promptRate
+	}
+	w.rate, w.polledAt = data.Rate, data.FetchTime
+	if w.alertRate == 0 {
+		w.alertRate = previous
+	}
+	if !w.atPrompt || previous <= 0 || math.Abs(data.Rate-previous) < 0.005 {
+		return
+	}
+
+	change := data.Rate - previous
+	if w.program != nil {
+		// Send waits for the program, which must not wait on w.mu.
+		go w.program.Send(watchedPriceMsg{})
+	} else {
+		lineColor := colorPositive
+		if change < 0 {
+			lineColor = colorNegative
+		}
+		fmt.Print("\r\033[K")
+		lineColor.Printf("%s BTC %s (%s%s) - press Enter to update\r\n", w.polledAt.Local().Format("15:04:05"), formatMoney(data.Rate), signOf(change), formatMoney(math.Abs(change)))
+		fmt.Print(promptLabel + w.typed)
+	}
+
+	if w.alertPct > 0 && w.alertRate > 0 {
+		move := (data.Rate - w.alertRate) / w.alertRate * 100
+		if math.Abs(move) >= w.alertPct {
+			slog.Info("price alert", "rate", data.Rate, "move_pct", move)
+			if move > 0 {
+				playSound(soundAlertUp)
+			} else {
+				playSound(soundAlertDown)
+			}
+			w.alertRate = data.Rate
+		}
+	}
+}
+
+// echo prints s for the raw-mode prompt and records typed as its text, so a
+// price line printed meanwhile does not garble or lose it.
+func (w *priceWatcher) echo(typed, s string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.typed = typed
+	fmt.Print(s)
+}
+
+// applyWatchedPrice moves the latest polled rate into apiData so the next
+// screen shows it. Returns true if the rate changed.
+func applyWatchedPrice() bool {
+	priceWatch.mu.Lock()
+	rate, polledAt := priceWatch.rate, priceWatch.polledAt
+	priceWatch.rate = 0
+	priceWatch.mu.Unlock()
+	if rate <= 0 || apiData == nil || apiData.Rate <= 0 || rate == apiData.Rate {
+		return false
+	}
+	updated := *apiData
+	updated.Rate = rate
+	updated.FetchTime = polledAt
+	apiData = &updated
+	return true
+}
+
+// showPriceChange prints how far the rate moved since the previous main screen.
+func showPriceChange() {
+	if apiData == nil || apiData.Rate <= 0 {
+		return
+	}
+	previous := priceWatch.lastScreen
+	priceWatch.lastScreen = apiData.Rate
+	if previous <= 0 || math.Abs(apiData.Rate-previous) < 0.005 {
+		return
+	}
+	change := apiData.Rate - previous
+	changeColor := colorPositive
+	if change < 0 {
+		changeColor = colorNegative
+	}
+	writeAlignedLine("Since Last Screen:", fmt.Sprintf("%s%s [%+.2f%%]", signOf(change), formatMoney(math.Abs(change)), change/previous*100), changeColor)
+}
+
+func signOf(v float64) string {
+	if v < 0 {
+		return "-"
+	}
+	return "+"
+}
+
+func watchSettingsDisplay() string {
+	seconds, alertPct := loadWatchSettings()
+	if seconds == 0 {
+		return "off"
+	}
+	display := fmt.Sprintf("every %ds", seconds)
+	if alertPct > 0 {
+		display += fmt.Sprintf(", beep on %s%% moves", strconv.FormatFloat(alertPct, 'f', -1, 64))
+	}
+	if minutes := loadAutoRefreshMinutes(); minutes > 0 {
+		display += fmt.Sprintf(", refresh after %dm idle", minutes)
+	}
+	return display
+}
+
+// invokeWatchConfig edits WatchSeconds, WatchAlertPercent and
+// AutoRefreshMinutes. Enter keeps a value.
+func invokeWatchConfig(reader *bufio.Reader) {
+	seconds, alertPct := loadWatchSettings()
+	colorInfo.Printf("Price watcher: %s\n", watchSettingsDisplay())
+
+	newSeconds, ok := promptSettingValue(reader, fmt.Sprintf("Seconds between price checks, 0 for off (min %d) [%d]: ", minWatchSeconds, seconds), float64(seconds), 86400)
+	if !ok {
+		return
+	}
+	newAlert, ok := promptSettingValue(reader, fmt.Sprintf("Beep when the price moves this percent, 0 for never [%s]: ", strconv.FormatFloat(alertPct, 'f', -1, 64)), alertPct, 100)
+	if !ok {
+		return
+	}
+	refreshMinutes := loadAutoRefreshMinutes()
+	newRefresh, ok := promptSettingValue(reader, fmt.Sprintf("Refresh the main screen after this many idle minutes, 0 for never [%d]: ", refreshMinutes), float64(refreshMinutes), maxAutoRefreshMinutes)
+	if !ok {
+		return
+	}
+
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("WatchSeconds").SetValue(strconv.Itoa(int(newSeconds)))
+		f.Section("Settings").Key("WatchAlertPercent").SetValue(strconv.FormatFloat(newAlert, 'f', -1, 64))
+		f.Section("Settings").Key("AutoRefreshMinutes").SetValue(strconv.Itoa(int(newRefresh)))
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else {
+		priceWatch.configure()
+		slog.Info("price watcher updated", "seconds", int(newSeconds), "alert_pct", newAlert, "refresh_minutes", int(newRefresh))
+		color.Green("Price watcher set to %s.", watchSettingsDisplay())
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}
@@ -0,0 +1,108 @@
+package vbtc
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"gopkg.in/ini.v1"
+	"kreftus/shared/notify"
+)
+
+// [Settings] Webhook is a URL that completed trades and fired price alerts
+// are POSTed to. notify.Webhook sends the text as both "content" (Discord)
+// and "text" (Slack, Mattermost), so chat webhooks accept it as is.
+
+// webhookPosts tracks posts still in flight so the session can wait for them
+// before exiting.
+var webhookPosts sync.WaitGroup
+
+func webhookURL() string {
+	if cfg == nil {
+		return ""
+	}
+	return strings.TrimSpace(cfg.Section("Settings").Key("Webhook").String())
+}
+
+// sendWebhook posts title and message in the background when a webhook is
+// set. Failures go to the log (and so the session log) rather than
+// interrupting the trade screen.
+func sendWebhook(title, message string) {
+	url := webhookURL()
+	if url == "" {
+		return
+	}
+	webhookPosts.Add(1)
+	go func() {
+		defer webhookPosts.Done()
+		if err := notify.Webhook(url, "vbtc", title, message); err != nil {
+			slog.Warn("webhook failed", "title", title, "err", err)
+		}
+	}()
+}
+
+// webhookTrade reports a trade written to the ledger.
+func webhookTrade(txType, coin string, usdAmount, amount, price, fee float64) {
+	message := fmt.Sprintf("%s %s for %s at %s", formatCoinAmount(amount), coin, formatMoney(usdAmount), formatMoney(price))
+	if fee > 0 {
+		message += fmt.Sprintf(" (fee %s)", formatMoney(fee))
+	}
+	sendWebhook(fmt.Sprintf("%s %s", txType, coin), message)
+}
+
+func webhookDisplay() string {
+	if webhookURL() == "" {
+		return "off"
+	}
+	return "on"
+}
+
+// invokeWebhookConfig sets or clears [Settings] Webhook and sends a test
+// message to a new URL.
+func invokeWebhookConfig(reader *bufio.Reader) {
+	current := webhookURL()
+	if current == "" {
+		current = "(not set)"
+	}
+	colorInfo.Printf("Webhook: %s\n", current)
+	fmt.Print("Enter a Discord/Slack webhook URL, 'off' to disable, or Enter to keep: ")
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		fmt.Println("No changes made.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	url := input
+	if strings.EqualFold(input, "off") {
+		url = ""
+	} else if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+		color.Red("The webhook must be an http:// or https:// URL. It has not been saved.")
+		fmt.Println("Press Enter to continue.")
+		reader.ReadString('\n')
+		return
+	}
+	if err := updateIni(func(f *ini.File) {
+		f.Section("Settings").Key("Webhook").SetValue(url)
+	}); err != nil {
+		slog.Error("config save failed", "path", iniFilePath, "err", err)
+		color.Red("Could not save vbtc.ini: %v", err)
+	} else if url == "" {
+		slog.Info("webhook configured", "on", false)
+		color.Green("Webhook off.")
+	} else {
+		slog.Info("webhook configured", "on", true)
+		fmt.Println("Sending a test message...")
+		if err := notify.Webhook(url, "vbtc", "Webhook connected", "Trades and price alerts will be posted here."); err != nil {
+			color.Yellow("Saved, but the test message failed: %v", err)
+		} else {
+			color.Green("Webhook on. A test message was sent.")
+		}
+	}
+	fmt.Println("Press Enter to continue.")
+	reader.ReadString('\n')
+}